@@ -0,0 +1,92 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the methods that replace the credentials and the trusted CAs of a transport
+// wrapper that is already in use.
+
+package authentication
+
+import (
+	"context"
+	"crypto/x509"
+)
+
+// Credentials contains the credentials used by the transport wrapper to request tokens. Empty
+// fields mean that the current value should be preserved.
+type Credentials struct {
+	User         string
+	Password     string
+	ClientID     string
+	ClientSecret string
+	Tokens       []string
+}
+
+// Reload replaces the credentials used to request tokens. The change is applied atomically: if
+// the new tokens can't be parsed the wrapper isn't modified. When new tokens are given they
+// replace the current ones. When the user name or the client identifier change the current tokens
+// are discarded, so that new ones will be requested with the new credentials. Otherwise the
+// current tokens are preserved, and the new credentials will be used when they need to be
+// renewed. Requests that are in progress aren't affected.
+func (w *TransportWrapper) Reload(ctx context.Context, value Credentials) error {
+	// Parse the tokens before acquiring the lock, so that we don't modify anything if they
+	// aren't valid:
+	var accessToken, refreshToken, pullSecretAccessToken *tokenInfo
+	if len(value.Tokens) > 0 {
+		var err error
+		accessToken, refreshToken, pullSecretAccessToken, err = parseTokens(
+			ctx, w.logger, w.tokenParser, value.Tokens,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Replace the credentials:
+	w.tokenMutex.Lock()
+	defer w.tokenMutex.Unlock()
+	identityChanged := (value.User != "" && value.User != w.user) ||
+		(value.ClientID != "" && value.ClientID != w.clientID)
+	if value.User != "" {
+		w.user = value.User
+	}
+	if value.Password != "" {
+		w.password = value.Password
+	}
+	if value.ClientID != "" {
+		w.clientID = value.ClientID
+	}
+	if value.ClientSecret != "" {
+		w.clientSecret = value.ClientSecret
+	}
+	switch {
+	case len(value.Tokens) > 0:
+		w.accessToken = accessToken
+		w.refreshToken = refreshToken
+		w.pullSecretAccessToken = pullSecretAccessToken
+		w.logger.Debug(ctx, "Replaced tokens")
+	case identityChanged:
+		w.accessToken = nil
+		w.refreshToken = nil
+		w.logger.Debug(ctx, "Identity changed, discarded tokens")
+	}
+	return nil
+}
+
+// SetTrustedCAs replaces the certificate pool that contains the certificate authorities that are
+// trusted when sending requests to the token server.
+func (w *TransportWrapper) SetTrustedCAs(ctx context.Context, value *x509.CertPool) {
+	w.clientSelector.SetTrustedCAs(ctx, value)
+}
@@ -0,0 +1,412 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the implementation of the batch executor, that runs many requests with
+// bounded concurrency and collects the result of each of them.
+
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	ocmerrors "github.com/openshift-online/ocm-sdk-go/errors"
+	"github.com/openshift-online/ocm-sdk-go/logging"
+)
+
+// DefaultBatchWorkers is the default number of items of a batch that are executed concurrently.
+const DefaultBatchWorkers = 10
+
+// BatchMode determines what the batch executor does when an item fails.
+type BatchMode int
+
+const (
+	// BatchCollectAll runs all the items, even if some of them fail. This is the default.
+	BatchCollectAll BatchMode = iota
+
+	// BatchStopOnError stops starting new items after the first failure, and cancels the
+	// items that are running, including those that are waiting for the rate limits of the
+	// connection.
+	BatchStopOnError
+)
+
+// String generates a human readable representation of the mode.
+func (m BatchMode) String() string {
+	switch m {
+	case BatchCollectAll:
+		return "collect-all"
+	case BatchStopOnError:
+		return "stop-on-error"
+	default:
+		return fmt.Sprintf("BatchMode(%d)", int(m))
+	}
+}
+
+// BatchFunc is a function that executes one item of a batch. It receives the connection of the
+// batch executor, so that it can create and send the requests it needs.
+type BatchFunc[T any] func(ctx context.Context, connection *Connection) (T, error)
+
+// BatchRequest is the interface implemented by the requests of the generated clients. For example
+// the SendContext method of *cmv1.ClusterGetRequest returns *cmv1.ClusterGetResponse.
+type BatchRequest[T any] interface {
+	SendContext(ctx context.Context) (T, error)
+}
+
+// BatchExecutorBuilder contains the data and logic needed to create a batch executor. Don't
+// create objects of this type directly, use the NewBatchExecutor function instead.
+type BatchExecutorBuilder[T any] struct {
+	logger     logging.Logger
+	connection *Connection
+	workers    int
+	mode       BatchMode
+	progress   func(progress *BatchProgress)
+}
+
+// BatchExecutor runs many items, for example requests of the generated clients, with a bounded
+// number of them running concurrently. All the requests are sent using the same connection, so
+// they share its rate limits: when a limit is reached workers wait for it instead of sending more
+// requests, and the concurrency only limits how many requests are in flight or waiting at the
+// same time. Don't create objects of this type directly, use the NewBatchExecutor function
+// instead.
+type BatchExecutor[T any] struct {
+	logger     logging.Logger
+	connection *Connection
+	workers    int
+	mode       BatchMode
+	progress   func(progress *BatchProgress)
+}
+
+// BatchItemResult contains the result of one item of a batch.
+type BatchItemResult[T any] struct {
+	// Index is the position of the item in the batch, starting with zero.
+	Index int
+
+	// Value is the value returned by the item. For requests of the generated clients this is
+	// the response, and it may be non nil even when the request failed.
+	Value T
+
+	// Err is the error returned by the item, if any.
+	Err error
+
+	// Error contains the details sent by the server when the item failed with an API error.
+	// It is nil when the item succeeded or when it failed for other reasons, for example
+	// because the server couldn't be reached.
+	Error *ocmerrors.Error
+
+	// Skipped is true when the item wasn't executed, or was cancelled while waiting or
+	// running, because the batch stopped after a failure or because the context was done.
+	Skipped bool
+
+	// Duration is the time that the item took to run.
+	Duration time.Duration
+}
+
+// BatchResult contains the results of all the items of a batch.
+type BatchResult[T any] struct {
+	// Items contains the result of each item, in the same order that they were given.
+	Items []BatchItemResult[T]
+
+	// Succeeded is the number of items that succeeded.
+	Succeeded int
+
+	// Failed is the number of items that failed.
+	Failed int
+
+	// Skipped is the number of items that weren't executed or were cancelled.
+	Skipped int
+}
+
+// Failures returns the results of the items that failed.
+func (r *BatchResult[T]) Failures() []BatchItemResult[T] {
+	var failures []BatchItemResult[T]
+	for _, item := range r.Items {
+		if item.Err != nil && !item.Skipped {
+			failures = append(failures, item)
+		}
+	}
+	return failures
+}
+
+// BatchProgress contains the progress of a batch. It is passed to the progress callback after each
+// item finishes.
+type BatchProgress struct {
+	// Total is the number of items of the batch.
+	Total int
+
+	// Completed is the number of items that have finished, including the ones that failed and
+	// the ones that were skipped.
+	Completed int
+
+	// Succeeded is the number of items that have succeeded.
+	Succeeded int
+
+	// Failed is the number of items that have failed.
+	Failed int
+
+	// Skipped is the number of items that have been skipped.
+	Skipped int
+
+	// Index is the position of the item that has just finished.
+	Index int
+
+	// Err is the error of the item that has just finished, if any.
+	Err error
+
+	// Elapsed is the time since the batch started.
+	Elapsed time.Duration
+}
+
+// NewBatchExecutor creates a builder that can then be used to configure and create a batch
+// executor. The type parameter is the type of the values returned by the items. For example, to
+// add a label to many subscriptions and report the ones that failed:
+//
+//	executor, err := sdk.NewBatchExecutor[*amv1.GenericLabelsAddResponse]().
+//		Logger(logger).
+//		Connection(connection).
+//		Workers(20).
+//		OnProgress(func(progress *sdk.BatchProgress) {
+//			fmt.Printf("%d/%d\n", progress.Completed, progress.Total)
+//		}).
+//		Build()
+//	if err != nil {
+//		return err
+//	}
+//	label, err := amv1.NewLabel().Key("mykey").Value("myvalue").Build()
+//	if err != nil {
+//		return err
+//	}
+//	subscriptions := connection.AccountsMgmt().V1().Subscriptions()
+//	requests := make([]sdk.BatchRequest[*amv1.GenericLabelsAddResponse], len(ids))
+//	for i, id := range ids {
+//		requests[i] = subscriptions.Subscription(id).Labels().Add().Body(label)
+//	}
+//	result, err := executor.RunRequests(ctx, requests...)
+//	if err != nil {
+//		return err
+//	}
+//	for _, failure := range result.Failures() {
+//		fmt.Printf("%s: %v\n", ids[failure.Index], failure.Err)
+//	}
+func NewBatchExecutor[T any]() *BatchExecutorBuilder[T] {
+	return &BatchExecutorBuilder[T]{
+		workers: DefaultBatchWorkers,
+		mode:    BatchCollectAll,
+	}
+}
+
+// Logger sets the logger that will be used to send messages to the log. This is mandatory.
+func (b *BatchExecutorBuilder[T]) Logger(value logging.Logger) *BatchExecutorBuilder[T] {
+	b.logger = value
+	return b
+}
+
+// Connection sets the connection that will be passed to the items. This is mandatory.
+func (b *BatchExecutorBuilder[T]) Connection(value *Connection) *BatchExecutorBuilder[T] {
+	b.connection = value
+	return b
+}
+
+// Workers sets the maximum number of items that will run concurrently. The default is ten.
+func (b *BatchExecutorBuilder[T]) Workers(value int) *BatchExecutorBuilder[T] {
+	b.workers = value
+	return b
+}
+
+// Mode sets what the executor does when an item fails. The default is to run all the items and
+// collect all the results.
+func (b *BatchExecutorBuilder[T]) Mode(value BatchMode) *BatchExecutorBuilder[T] {
+	b.mode = value
+	return b
+}
+
+// OnProgress sets a function that will be called after each item finishes. Calls are never
+// concurrent, so the function doesn't need to synchronize access to its own data, but it should
+// return quickly as the workers wait for it.
+func (b *BatchExecutorBuilder[T]) OnProgress(
+	value func(progress *BatchProgress)) *BatchExecutorBuilder[T] {
+	b.progress = value
+	return b
+}
+
+// Build uses the information stored in the builder to create a new batch executor.
+func (b *BatchExecutorBuilder[T]) Build() (result *BatchExecutor[T], err error) {
+	// Check parameters:
+	if b.logger == nil {
+		err = fmt.Errorf("logger is mandatory")
+		return
+	}
+	if b.connection == nil {
+		err = fmt.Errorf("connection is mandatory")
+		return
+	}
+	if b.workers <= 0 {
+		err = fmt.Errorf(
+			"number of workers %d isn't valid, it should be greater than zero",
+			b.workers,
+		)
+		return
+	}
+	switch b.mode {
+	case BatchCollectAll, BatchStopOnError:
+	default:
+		err = fmt.Errorf("mode '%s' isn't valid", b.mode)
+		return
+	}
+
+	// Create and populate the object:
+	result = &BatchExecutor[T]{
+		logger:     b.logger,
+		connection: b.connection,
+		workers:    b.workers,
+		mode:       b.mode,
+		progress:   b.progress,
+	}
+	return
+}
+
+// RunRequests sends the given requests of the generated clients. See the Run method for details.
+func (e *BatchExecutor[T]) RunRequests(ctx context.Context,
+	requests ...BatchRequest[T]) (result *BatchResult[T], err error) {
+	items := make([]BatchFunc[T], len(requests))
+	for i, request := range requests {
+		request := request
+		items[i] = func(ctx context.Context, _ *Connection) (T, error) {
+			return request.SendContext(ctx)
+		}
+	}
+	result, err = e.Run(ctx, items...)
+	return
+}
+
+// Run executes the given items and returns the result of each of them. In the collect-all mode
+// failures of individual items are only reported in the result, and the returned error is only
+// non nil if the context is done before all the items have been executed. In the stop-on-error
+// mode the returned error is the first failure, and the items that didn't run are marked as
+// skipped. The result is returned even when the error isn't nil.
+func (e *BatchExecutor[T]) Run(ctx context.Context,
+	items ...BatchFunc[T]) (result *BatchResult[T], err error) {
+	// Prepare the result, marking all the items as skipped till they run:
+	result = &BatchResult[T]{
+		Items: make([]BatchItemResult[T], len(items)),
+	}
+	for i := range result.Items {
+		result.Items[i].Index = i
+		result.Items[i].Skipped = true
+	}
+
+	// This context is used to stop the workers after the first failure:
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Start the workers, but not more than items:
+	workers := e.workers
+	if workers > len(items) {
+		workers = len(items)
+	}
+	queue := make(chan int, len(items))
+	for i := range items {
+		queue <- i
+	}
+	close(queue)
+	start := time.Now()
+	lock := &sync.Mutex{}
+	var stopErr error
+	wg := &sync.WaitGroup{}
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for j := range queue {
+				item := e.execute(runCtx, j, items[j])
+
+				// Update the result and notify the progress while holding the lock,
+				// so that callbacks aren't concurrent:
+				lock.Lock()
+				if item.Err != nil && runCtx.Err() != nil && !item.Skipped &&
+					errors.Is(item.Err, context.Canceled) {
+					item.Skipped = true
+				}
+				result.Items[j] = item
+				switch {
+				case item.Skipped:
+					result.Skipped++
+				case item.Err != nil:
+					result.Failed++
+					e.logger.Debug(ctx, "Batch item %d failed: %v", j, item.Err)
+					if e.mode == BatchStopOnError && stopErr == nil {
+						stopErr = fmt.Errorf("batch item %d failed: %w", j, item.Err)
+						cancel()
+					}
+				default:
+					result.Succeeded++
+				}
+				e.notify(&BatchProgress{
+					Total:     len(items),
+					Completed: result.Succeeded + result.Failed + result.Skipped,
+					Succeeded: result.Succeeded,
+					Failed:    result.Failed,
+					Skipped:   result.Skipped,
+					Index:     j,
+					Err:       item.Err,
+					Elapsed:   time.Since(start),
+				})
+				lock.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Decide what error to return:
+	switch {
+	case stopErr != nil:
+		err = stopErr
+	case result.Skipped > 0:
+		err = ctx.Err()
+	}
+	return
+}
+
+// execute runs one item, unless the context is already done.
+func (e *BatchExecutor[T]) execute(ctx context.Context, index int,
+	item BatchFunc[T]) (result BatchItemResult[T]) {
+	result.Index = index
+	err := ctx.Err()
+	if err != nil {
+		result.Err = err
+		result.Skipped = true
+		return
+	}
+	start := time.Now()
+	result.Value, result.Err = item(ctx, e.connection)
+	result.Duration = time.Since(start)
+	if result.Err != nil {
+		var apiErr *ocmerrors.Error
+		if errors.As(result.Err, &apiErr) {
+			result.Error = apiErr
+		}
+	}
+	return
+}
+
+// notify calls the progress callback, if any.
+func (e *BatchExecutor[T]) notify(progress *BatchProgress) {
+	if e.progress != nil {
+		e.progress(progress)
+	}
+}
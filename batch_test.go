@@ -0,0 +1,257 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the batch executor.
+
+package sdk
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"             // nolint
+	. "github.com/onsi/gomega"                         // nolint
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Batch executor", func() {
+	var ctx context.Context
+	var server *FakeServer
+	var connection *Connection
+	var ids []string
+
+	BeforeEach(func() {
+		var err error
+
+		// Create the context:
+		ctx = context.Background()
+
+		// Create the server and some clusters:
+		server, err = NewFakeServer().Build()
+		Expect(err).ToNot(HaveOccurred())
+		ids = nil
+		for i := 0; i < 3; i++ {
+			object, err := cmv1.NewCluster().Name("mycluster").Build()
+			Expect(err).ToNot(HaveOccurred())
+			object, err = server.AddCluster(object)
+			Expect(err).ToNot(HaveOccurred())
+			ids = append(ids, object.ID())
+		}
+
+		// Create the connection:
+		connection, err = NewConnectionBuilder().
+			Logger(logger).
+			URL(server.URL()).
+			Tokens(MakeTokenString("Bearer", 5*time.Minute)).
+			BuildContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		err := connection.Close()
+		Expect(err).ToNot(HaveOccurred())
+		server.Close()
+	})
+
+	It("Can't be created without a connection", func() {
+		_, err := NewBatchExecutor[int]().
+			Logger(logger).
+			Build()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("connection"))
+	})
+
+	It("Collects the results of all the requests", func() {
+		executor, err := NewBatchExecutor[*cmv1.ClusterGetResponse]().
+			Logger(logger).
+			Connection(connection).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		clusters := connection.ClustersMgmt().V1().Clusters()
+		result, err := executor.RunRequests(
+			ctx,
+			clusters.Cluster(ids[0]).Get(),
+			clusters.Cluster("junk").Get(),
+			clusters.Cluster(ids[1]).Get(),
+			clusters.Cluster(ids[2]).Get(),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Succeeded).To(Equal(3))
+		Expect(result.Failed).To(Equal(1))
+		Expect(result.Skipped).To(BeZero())
+		Expect(result.Items).To(HaveLen(4))
+		Expect(result.Items[0].Value.Body().ID()).To(Equal(ids[0]))
+		Expect(result.Items[2].Value.Body().ID()).To(Equal(ids[1]))
+		Expect(result.Items[3].Value.Body().ID()).To(Equal(ids[2]))
+		failures := result.Failures()
+		Expect(failures).To(HaveLen(1))
+		Expect(failures[0].Index).To(Equal(1))
+		Expect(failures[0].Error).ToNot(BeNil())
+		Expect(failures[0].Error.Status()).To(Equal(http.StatusNotFound))
+		Expect(failures[0].Error.Code()).To(Equal("CLUSTERS-MGMT-404"))
+	})
+
+	It("Stops after the first failure", func() {
+		executor, err := NewBatchExecutor[string]().
+			Logger(logger).
+			Connection(connection).
+			Workers(1).
+			Mode(BatchStopOnError).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		get := func(id string) BatchFunc[string] {
+			return func(ctx context.Context, connection *Connection) (string, error) {
+				response, err := connection.ClustersMgmt().V1().Clusters().Cluster(id).Get().
+					SendContext(ctx)
+				if err != nil {
+					return "", err
+				}
+				return response.Body().ID(), nil
+			}
+		}
+		result, err := executor.Run(ctx, get(ids[0]), get("junk"), get(ids[1]), get(ids[2]))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("batch item 1 failed"))
+		Expect(result.Succeeded).To(Equal(1))
+		Expect(result.Failed).To(Equal(1))
+		Expect(result.Skipped).To(Equal(2))
+		Expect(result.Items[0].Value).To(Equal(ids[0]))
+		Expect(result.Items[1].Error.Status()).To(Equal(http.StatusNotFound))
+		Expect(result.Items[2].Skipped).To(BeTrue())
+		Expect(result.Items[3].Skipped).To(BeTrue())
+	})
+
+	It("Limits the number of concurrent items", func() {
+		executor, err := NewBatchExecutor[int]().
+			Logger(logger).
+			Connection(connection).
+			Workers(3).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		lock := &sync.Mutex{}
+		running := 0
+		maximum := 0
+		items := make([]BatchFunc[int], 10)
+		for i := range items {
+			i := i
+			items[i] = func(ctx context.Context, connection *Connection) (int, error) {
+				lock.Lock()
+				running++
+				if running > maximum {
+					maximum = running
+				}
+				lock.Unlock()
+				time.Sleep(10 * time.Millisecond)
+				lock.Lock()
+				running--
+				lock.Unlock()
+				return i, nil
+			}
+		}
+		result, err := executor.Run(ctx, items...)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Succeeded).To(Equal(10))
+		Expect(maximum).To(BeNumerically("<=", 3))
+		for i, item := range result.Items {
+			Expect(item.Value).To(Equal(i))
+		}
+	})
+
+	It("Reports the progress after each item", func() {
+		var progresses []BatchProgress
+		executor, err := NewBatchExecutor[int]().
+			Logger(logger).
+			Connection(connection).
+			Workers(4).
+			OnProgress(func(progress *BatchProgress) {
+				progresses = append(progresses, *progress)
+			}).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		failure := errors.New("failed")
+		items := make([]BatchFunc[int], 8)
+		for i := range items {
+			i := i
+			items[i] = func(ctx context.Context, connection *Connection) (int, error) {
+				if i%2 == 0 {
+					return 0, failure
+				}
+				return i, nil
+			}
+		}
+		_, err = executor.Run(ctx, items...)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(progresses).To(HaveLen(8))
+		for i, progress := range progresses {
+			Expect(progress.Total).To(Equal(8))
+			Expect(progress.Completed).To(Equal(i + 1))
+		}
+		last := progresses[len(progresses)-1]
+		Expect(last.Succeeded).To(Equal(4))
+		Expect(last.Failed).To(Equal(4))
+	})
+
+	It("Cancels the items waiting for the rate limits when it stops", func() {
+		limited, err := NewConnectionBuilder().
+			Logger(logger).
+			URL(server.URL()).
+			Tokens(MakeTokenString("Bearer", 5*time.Minute)).
+			RateLimit(0.1, 1).
+			BuildContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		defer func() {
+			err := limited.Close()
+			Expect(err).ToNot(HaveOccurred())
+		}()
+		executor, err := NewBatchExecutor[string]().
+			Logger(logger).
+			Connection(limited).
+			Workers(4).
+			Mode(BatchStopOnError).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+
+		// Only one of the requests can be sent, the rest will be waiting for the rate limit when
+		// the first item fails:
+		get := func(id string) BatchFunc[string] {
+			return func(ctx context.Context, connection *Connection) (string, error) {
+				response, err := connection.ClustersMgmt().V1().Clusters().Cluster(id).Get().
+					SendContext(ctx)
+				if err != nil {
+					return "", err
+				}
+				return response.Body().ID(), nil
+			}
+		}
+		fail := func(ctx context.Context, connection *Connection) (string, error) {
+			time.Sleep(50 * time.Millisecond)
+			return "", errors.New("failed")
+		}
+		start := time.Now()
+		result, err := executor.Run(ctx, fail, get(ids[0]), get(ids[1]), get(ids[2]))
+		Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+		Expect(err).To(HaveOccurred())
+		Expect(result.Failed).To(Equal(1))
+		Expect(result.Items[0].Skipped).To(BeFalse())
+		Expect(result.Succeeded).To(BeNumerically("<=", 1))
+		Expect(result.Skipped).To(BeNumerically(">=", 2))
+	})
+})
@@ -0,0 +1,201 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the record and replay cassettes.
+
+package sdk
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/onsi/gomega/ghttp"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"             // nolint
+	. "github.com/onsi/gomega"                         // nolint
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Cassette", func() {
+	var ctx context.Context
+	var dir string
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		dir = GinkgoT().TempDir()
+	})
+
+	// record records a cassette in the given file, sending a request to get a cluster to a real
+	// server, and returns the tokens that were used:
+	record := func(file string) (accessToken, refreshToken string) {
+		// Create the servers:
+		accessToken = MakeTokenString("Bearer", 5*time.Minute)
+		refreshToken = MakeTokenString("Refresh", 10*time.Hour)
+		oidServer := MakeTCPServer()
+		defer oidServer.Close()
+		oidServer.AppendHandlers(
+			RespondWithAccessAndRefreshTokens(accessToken, refreshToken),
+		)
+		apiServer := MakeTCPServer()
+		defer apiServer.Close()
+		apiServer.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters/123"),
+				RespondWithJSON(http.StatusOK, `{
+					"kind": "Cluster",
+					"id": "123",
+					"name": "my-cluster"
+				}`),
+			),
+		)
+
+		// Create the cassette and the connection:
+		cassette, err := NewCassette(file).
+			Record(true).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		connection, err := NewConnectionBuilder().
+			Logger(logger).
+			URL(apiServer.URL()).
+			TokenURL(oidServer.URL() + "/token").
+			Tokens(refreshToken).
+			TransportWrapper(cassette.Wrap).
+			BuildContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+
+		// Send the request:
+		response, err := connection.ClustersMgmt().V1().Clusters().Cluster("123").Get().
+			SendContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Body().Name()).To(Equal("my-cluster"))
+
+		// Save the cassette:
+		err = connection.Close()
+		Expect(err).ToNot(HaveOccurred())
+		err = cassette.Close()
+		Expect(err).ToNot(HaveOccurred())
+		return
+	}
+
+	// replay creates a connection that replays the given cassette:
+	replay := func(cassette *Cassette) *Connection {
+		connection, err := NewConnectionBuilder().
+			Logger(logger).
+			URL("https://api.example.com").
+			TokenURL("https://sso.example.com/token").
+			Tokens(MakeTokenString("Refresh", 10*time.Hour)).
+			RetryLimit(0).
+			TransportWrapper(cassette.Wrap).
+			BuildContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		return connection
+	}
+
+	It("Records token and API requests with secrets redacted", func() {
+		file := filepath.Join(dir, "cassette.yaml")
+		accessToken, refreshToken := record(file)
+		content, err := os.ReadFile(file)
+		Expect(err).ToNot(HaveOccurred())
+		text := string(content)
+		Expect(text).ToNot(ContainSubstring(accessToken))
+		Expect(text).ToNot(ContainSubstring(refreshToken))
+		Expect(text).To(ContainSubstring("refresh_token=***"))
+		Expect(text).To(ContainSubstring(`"access_token": "***"`))
+		Expect(text).To(ContainSubstring("/api/clusters_mgmt/v1/clusters/123"))
+	})
+
+	It("Records in JSON format", func() {
+		file := filepath.Join(dir, "cassette.json")
+		record(file)
+		cassette, err := NewCassette(file).Build()
+		Expect(err).ToNot(HaveOccurred())
+		interactions := cassette.Interactions()
+		Expect(interactions).To(HaveLen(2))
+		Expect(interactions[0].Request.Method).To(Equal(http.MethodPost))
+		Expect(interactions[1].Request.Method).To(Equal(http.MethodGet))
+		Expect(interactions[1].Request.Header.Get("Authorization")).To(Equal("***"))
+		Expect(interactions[1].Response.Status).To(Equal(http.StatusOK))
+	})
+
+	It("Replays the recorded interactions", func() {
+		file := filepath.Join(dir, "cassette.yaml")
+		record(file)
+		cassette, err := NewCassette(file).Build()
+		Expect(err).ToNot(HaveOccurred())
+		connection := replay(cassette)
+		defer func() {
+			err = connection.Close()
+			Expect(err).ToNot(HaveOccurred())
+		}()
+		response, err := connection.ClustersMgmt().V1().Clusters().Cluster("123").Get().
+			SendContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Body().ID()).To(Equal("123"))
+		Expect(response.Body().Name()).To(Equal("my-cluster"))
+	})
+
+	It("Fails if the request doesn't match", func() {
+		file := filepath.Join(dir, "cassette.yaml")
+		record(file)
+		cassette, err := NewCassette(file).Build()
+		Expect(err).ToNot(HaveOccurred())
+		connection := replay(cassette)
+		defer func() {
+			err = connection.Close()
+			Expect(err).ToNot(HaveOccurred())
+		}()
+		_, err = connection.ClustersMgmt().V1().Clusters().Cluster("456").Get().
+			SendContext(ctx)
+		Expect(err).To(HaveOccurred())
+		var mismatchErr *CassetteMismatchError
+		Expect(errors.As(err, &mismatchErr)).To(BeTrue())
+		Expect(mismatchErr.File).To(Equal(file))
+		Expect(mismatchErr.Method).To(Equal(http.MethodGet))
+		Expect(mismatchErr.URL).To(ContainSubstring("/api/clusters_mgmt/v1/clusters/456"))
+	})
+
+	It("Can match the body", func() {
+		file := filepath.Join(dir, "cassette.yaml")
+		record(file)
+
+		// Change the recorded body of the token request so that it doesn't match, then the
+		// token request should fail:
+		cassette, err := NewCassette(file).MatchBody(true).Build()
+		Expect(err).ToNot(HaveOccurred())
+		cassette.Interactions()[0].Request.Body = "grant_type=password"
+		connection := replay(cassette)
+		defer func() {
+			err = connection.Close()
+			Expect(err).ToNot(HaveOccurred())
+		}()
+		_, err = connection.ClustersMgmt().V1().Clusters().Cluster("123").Get().
+			SendContext(ctx)
+		Expect(err).To(HaveOccurred())
+		var mismatchErr *CassetteMismatchError
+		Expect(errors.As(err, &mismatchErr)).To(BeTrue())
+	})
+
+	It("Can't be created in replay mode if the file doesn't exist", func() {
+		cassette, err := NewCassette(filepath.Join(dir, "missing.yaml")).Build()
+		Expect(err).To(HaveOccurred())
+		Expect(cassette).To(BeNil())
+		Expect(err.Error()).To(ContainSubstring("missing.yaml"))
+	})
+})
@@ -0,0 +1,231 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the HTTP handler that exposes the health check of the connection, intended
+// for readiness probes.
+
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/openshift-online/ocm-sdk-go/logging"
+)
+
+// DefaultCheckTimeout is the default maximum time that the check handler waits for the checks to
+// complete.
+const DefaultCheckTimeout = 10 * time.Second
+
+// CheckHandlerBuilder contains the data and logic needed to create a check handler. Don't create
+// objects of this type directly, use the NewCheckHandler function instead.
+type CheckHandlerBuilder struct {
+	logger     logging.Logger
+	connection *Connection
+	services   []string
+	timeout    time.Duration
+}
+
+// CheckHandler is an HTTP handler that checks the connection and writes the report as a JSON
+// document. The response status is 200 when all the checks pass and 503 otherwise, so it can be
+// used directly as a Kubernetes readiness probe. Don't create objects of this type directly, use
+// the NewCheckHandler function instead.
+type CheckHandler struct {
+	logger     logging.Logger
+	connection *Connection
+	services   []string
+	timeout    time.Duration
+}
+
+// Make sure that we implement the interface:
+var _ http.Handler = (*CheckHandler)(nil)
+
+// NewCheckHandler creates a builder that can then be used to configure and create a check
+// handler. For example, to expose the check of the clusters management service in the
+// `/readyz` path:
+//
+//	handler, err := sdk.NewCheckHandler().
+//		Logger(logger).
+//		Connection(connection).
+//		Services("clusters_mgmt").
+//		Build()
+//	if err != nil {
+//		return err
+//	}
+//	http.Handle("/readyz", handler)
+func NewCheckHandler() *CheckHandlerBuilder {
+	return &CheckHandlerBuilder{
+		timeout: DefaultCheckTimeout,
+	}
+}
+
+// Logger sets the logger that the handler will use to send messages to the log. This is
+// mandatory.
+func (b *CheckHandlerBuilder) Logger(value logging.Logger) *CheckHandlerBuilder {
+	b.logger = value
+	return b
+}
+
+// Connection sets the connection that will be checked. This is mandatory.
+func (b *CheckHandlerBuilder) Connection(value *Connection) *CheckHandlerBuilder {
+	b.connection = value
+	return b
+}
+
+// Services adds the names of services that will be checked. The default is to check all the
+// services returned by the CheckedServices function.
+func (b *CheckHandlerBuilder) Services(values ...string) *CheckHandlerBuilder {
+	b.services = append(b.services, values...)
+	return b
+}
+
+// Timeout sets the maximum time that the handler will wait for the checks to complete. The
+// default is ten seconds.
+func (b *CheckHandlerBuilder) Timeout(value time.Duration) *CheckHandlerBuilder {
+	b.timeout = value
+	return b
+}
+
+// Build uses the data stored in the builder to create a new check handler.
+func (b *CheckHandlerBuilder) Build() (result *CheckHandler, err error) {
+	// Check parameters:
+	if b.logger == nil {
+		err = fmt.Errorf("logger is mandatory")
+		return
+	}
+	if b.connection == nil {
+		err = fmt.Errorf("connection is mandatory")
+		return
+	}
+	if b.timeout <= 0 {
+		err = fmt.Errorf(
+			"timeout %s isn't valid, it should be greater than zero",
+			b.timeout,
+		)
+		return
+	}
+	for _, service := range b.services {
+		_, ok := checkedServices[service]
+		if !ok {
+			err = fmt.Errorf(
+				"service '%s' isn't valid, valid services are %s",
+				service, allServiceNames(),
+			)
+			return
+		}
+	}
+
+	// Copy the services, so that changes to the builder don't affect the handler:
+	var services []string
+	if len(b.services) > 0 {
+		services = make([]string, len(b.services))
+		copy(services, b.services)
+	}
+
+	// Create and populate the object:
+	result = &CheckHandler{
+		logger:     b.logger,
+		connection: b.connection,
+		services:   services,
+		timeout:    b.timeout,
+	}
+	return
+}
+
+// ServeHTTP is the implementation of the http.Handler interface.
+func (h *CheckHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+	defer cancel()
+	var body checkReportJSON
+	report, err := h.connection.Check(ctx, h.services...)
+	if err != nil {
+		body.Error = err.Error()
+	} else {
+		body = makeCheckReportJSON(report)
+	}
+	status := http.StatusOK
+	if !body.Healthy {
+		status = http.StatusServiceUnavailable
+	}
+	data, err := json.MarshalIndent(body, "", "  ")
+	if err != nil {
+		h.logger.Error(ctx, "Can't marshal check report: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_, err = w.Write(data)
+	if err != nil {
+		h.logger.Error(ctx, "Can't write check report: %v", err)
+	}
+}
+
+// checkReportJSON is the JSON representation of the check report.
+type checkReportJSON struct {
+	Healthy    bool                `json:"healthy"`
+	Error      string              `json:"error,omitempty"`
+	TokenValid bool                `json:"token_valid"`
+	TokenError string              `json:"token_error,omitempty"`
+	Services   []*serviceCheckJSON `json:"services,omitempty"`
+}
+
+// serviceCheckJSON is the JSON representation of the check of a service.
+type serviceCheckJSON struct {
+	Service       string  `json:"service"`
+	Healthy       bool    `json:"healthy"`
+	Address       string  `json:"address,omitempty"`
+	RemoteAddress string  `json:"remote_address,omitempty"`
+	ServerVersion string  `json:"server_version,omitempty"`
+	Status        int     `json:"status,omitempty"`
+	Latency       float64 `json:"latency"`
+	TokenValid    bool    `json:"token_valid"`
+	Error         string  `json:"error,omitempty"`
+}
+
+// makeCheckReportJSON converts the check report into its JSON representation. The latency is
+// converted to seconds.
+func makeCheckReportJSON(report *CheckReport) checkReportJSON {
+	result := checkReportJSON{
+		Healthy:    report.Healthy(),
+		TokenValid: report.TokenValid,
+		Services:   make([]*serviceCheckJSON, len(report.Services)),
+	}
+	if report.TokenError != nil {
+		result.TokenError = report.TokenError.Error()
+	}
+	for i, service := range report.Services {
+		item := &serviceCheckJSON{
+			Service:       service.Service,
+			Healthy:       service.Healthy(),
+			Address:       service.Address,
+			RemoteAddress: service.RemoteAddress,
+			ServerVersion: service.ServerVersion,
+			Status:        service.Status,
+			Latency:       service.Latency.Seconds(),
+			TokenValid:    service.TokenValid,
+		}
+		if service.Error != nil {
+			item.Error = service.Error.Error()
+		}
+		result.Services[i] = item
+	}
+	return result
+}
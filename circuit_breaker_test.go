@@ -0,0 +1,137 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the circuit breakers.
+
+package sdk
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/onsi/gomega/ghttp"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"             // nolint
+	. "github.com/onsi/gomega"                         // nolint
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Circuit breaker", func() {
+	var ctx context.Context
+	var token string
+	var defaultServer *ghttp.Server
+	var alternativeServer *ghttp.Server
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		token = MakeTokenString("Bearer", 15*time.Minute)
+		defaultServer = MakeTCPServer()
+		alternativeServer = MakeTCPServer()
+	})
+
+	AfterEach(func() {
+		defaultServer.Close()
+		alternativeServer.Close()
+	})
+
+	It("Stops retrying when the breaker opens", func() {
+		// The alternative server will always fail:
+		alternativeServer.RouteToHandler(
+			http.MethodGet,
+			"/api/clusters_mgmt/v1/clusters",
+			RespondWithJSON(http.StatusInternalServerError, "{}"),
+		)
+
+		// Note that breakers are per host name, not per port, so in order to have different
+		// breakers for the two servers we need to use a different host name:
+		alternativeURL := strings.Replace(alternativeServer.URL(), "127.0.0.1", "localhost", 1)
+
+		// Create the connection:
+		metricsServer := NewMetricsServer()
+		defer metricsServer.Close()
+		connection, err := NewConnectionBuilder().
+			Logger(logger).
+			Tokens(token).
+			URL(defaultServer.URL()).
+			AlternativeURL("/api/clusters_mgmt", alternativeURL).
+			RetryInterval(10 * time.Millisecond).
+			CircuitBreaker(true).
+			CircuitBreakerFailureThreshold(2).
+			CircuitBreakerCoolDown(time.Minute).
+			MetricsSubsystem("my").
+			MetricsRegisterer(metricsServer.Registry()).
+			BuildContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		defer func() {
+			err = connection.Close()
+			Expect(err).ToNot(HaveOccurred())
+		}()
+
+		// The first request should fail with the breaker error after the first retry:
+		_, err = connection.Get().Path("/api/clusters_mgmt/v1/clusters").SendContext(ctx)
+		Expect(err).To(HaveOccurred())
+		var breakerErr *CircuitBreakerError
+		Expect(errors.As(err, &breakerErr)).To(BeTrue())
+		Expect(breakerErr.State).To(Equal(CircuitBreakerOpen))
+		Expect(alternativeServer.ReceivedRequests()).To(HaveLen(2))
+
+		// Next requests should be rejected without sending them:
+		_, err = connection.Get().Path("/api/clusters_mgmt/v1/clusters").SendContext(ctx)
+		Expect(errors.As(err, &breakerErr)).To(BeTrue())
+		Expect(alternativeServer.ReceivedRequests()).To(HaveLen(2))
+
+		// Requests to the default server should not be affected:
+		defaultServer.AppendHandlers(RespondWithJSON(http.StatusOK, "{}"))
+		response, err := connection.Get().Path("/api/accounts_mgmt/v1/accounts").SendContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Status()).To(Equal(http.StatusOK))
+
+		// Check the metrics:
+		metrics := metricsServer.Metrics()
+		Expect(metrics).To(MatchLine(`^my_circuit_breaker_state\{server="tcp:localhost"\} 1$`))
+		Expect(metrics).To(MatchLine(`^my_circuit_breaker_state\{server="tcp:127.0.0.1"\} 0$`))
+		Expect(metrics).To(MatchLine(`^my_circuit_breaker_rejected_count\{server="tcp:localhost"\} 2$`))
+	})
+
+	It("Is disabled by default", func() {
+		defaultServer.RouteToHandler(
+			http.MethodGet,
+			"/api/clusters_mgmt/v1/clusters",
+			RespondWithJSON(http.StatusInternalServerError, "{}"),
+		)
+		connection, err := NewConnectionBuilder().
+			Logger(logger).
+			Tokens(token).
+			URL(defaultServer.URL()).
+			RetryLimit(0).
+			BuildContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		defer func() {
+			err = connection.Close()
+			Expect(err).ToNot(HaveOccurred())
+		}()
+		for i := 0; i < 10; i++ {
+			response, err := connection.Get().
+				Path("/api/clusters_mgmt/v1/clusters").
+				SendContext(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.Status()).To(Equal(http.StatusInternalServerError))
+		}
+	})
+})
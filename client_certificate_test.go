@@ -0,0 +1,317 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the support for TLS client certificates.
+
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/onsi/gomega/ghttp"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"             // nolint
+	. "github.com/onsi/gomega"                         // nolint
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Client certificate", func() {
+	var ctx context.Context
+	var tmp string
+	var token string
+	var apiServer *ghttp.Server
+	var apiCA string
+
+	BeforeEach(func() {
+		var err error
+		ctx = context.Background()
+		tmp, err = os.MkdirTemp("", "certs-*")
+		Expect(err).ToNot(HaveOccurred())
+		token = MakeTokenString("Bearer", 5*time.Minute)
+		apiServer, apiCA = MakeTCPMTLSServer()
+	})
+
+	AfterEach(func() {
+		apiServer.Close()
+		err := os.Remove(apiCA)
+		Expect(err).ToNot(HaveOccurred())
+		err = os.RemoveAll(tmp)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	// writeCertificate generates a client certificate with the given common name and writes it
+	// to files inside the temporary directory. It returns the names of the files.
+	writeCertificate := func(name string) (certFile, keyFile string) {
+		cert, key := MakeClientCertificate(name)
+		certFile = filepath.Join(tmp, "tls.crt")
+		keyFile = filepath.Join(tmp, "tls.key")
+		err := os.WriteFile(certFile, cert, 0600)
+		Expect(err).ToNot(HaveOccurred())
+		err = os.WriteFile(keyFile, key, 0600)
+		Expect(err).ToNot(HaveOccurred())
+		return
+	}
+
+	// verifyClient checks that the client presented a certificate with the given common name.
+	verifyClient := func(name string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.TLS).ToNot(BeNil())
+			Expect(r.TLS.PeerCertificates).ToNot(BeEmpty())
+			Expect(r.TLS.PeerCertificates[0].Subject.CommonName).To(Equal(name))
+		}
+	}
+
+	It("Presents the certificate loaded from files", func() {
+		apiServer.AppendHandlers(
+			ghttp.CombineHandlers(
+				verifyClient("myclient"),
+				RespondWithJSON(http.StatusOK, "{}"),
+			),
+		)
+		certFile, keyFile := writeCertificate("myclient")
+		connection, err := NewConnectionBuilder().
+			Logger(logger).
+			URL(apiServer.URL()).
+			TrustedCAFile(apiCA).
+			Tokens(token).
+			ClientCertificate(certFile, keyFile).
+			BuildContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		defer func() {
+			err = connection.Close()
+			Expect(err).ToNot(HaveOccurred())
+		}()
+		actualCert, actualKey := connection.ClientCertificate()
+		Expect(actualCert).To(Equal(certFile))
+		Expect(actualKey).To(Equal(keyFile))
+		_, err = connection.Get().Path("/api/clusters_mgmt/v1/clusters").SendContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("Presents the certificate from memory", func() {
+		apiServer.AppendHandlers(
+			ghttp.CombineHandlers(
+				verifyClient("myclient"),
+				RespondWithJSON(http.StatusOK, "{}"),
+			),
+		)
+		cert, key := MakeClientCertificate("myclient")
+		connection, err := NewConnectionBuilder().
+			Logger(logger).
+			URL(apiServer.URL()).
+			TrustedCAFile(apiCA).
+			Tokens(token).
+			ClientCertificatePEM(cert, key).
+			BuildContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		defer func() {
+			err = connection.Close()
+			Expect(err).ToNot(HaveOccurred())
+		}()
+		_, err = connection.Get().Path("/api/clusters_mgmt/v1/clusters").SendContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("Fails if the server requires a certificate and there is none", func() {
+		connection, err := NewConnectionBuilder().
+			Logger(logger).
+			URL(apiServer.URL()).
+			TrustedCAFile(apiCA).
+			Tokens(token).
+			RetryLimit(0).
+			BuildContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		defer func() {
+			err = connection.Close()
+			Expect(err).ToNot(HaveOccurred())
+		}()
+		_, err = connection.Get().Path("/api/clusters_mgmt/v1/clusters").SendContext(ctx)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Reloads the certificate when the files change", func() {
+		apiServer.AppendHandlers(
+			ghttp.CombineHandlers(
+				verifyClient("first"),
+				RespondWithJSON(http.StatusOK, "{}"),
+			),
+			ghttp.CombineHandlers(
+				verifyClient("second"),
+				RespondWithJSON(http.StatusOK, "{}"),
+			),
+		)
+		certFile, keyFile := writeCertificate("first")
+		connection, err := NewConnectionBuilder().
+			Logger(logger).
+			URL(apiServer.URL()).
+			TrustedCAFile(apiCA).
+			Tokens(token).
+			ClientCertificate(certFile, keyFile).
+			BuildContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		defer func() {
+			err = connection.Close()
+			Expect(err).ToNot(HaveOccurred())
+		}()
+		_, err = connection.Get().Path("/api/clusters_mgmt/v1/clusters").SendContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+
+		// Replace the files, making sure that the modification time changes even if the file
+		// system doesn't have enough resolution, and close the existing connections so that
+		// a new TLS handshake is needed:
+		writeCertificate("second")
+		later := time.Now().Add(time.Minute)
+		Expect(os.Chtimes(certFile, later, later)).To(Succeed())
+		Expect(os.Chtimes(keyFile, later, later)).To(Succeed())
+		apiServer.HTTPTestServer.CloseClientConnections()
+		_, err = connection.Get().Path("/api/clusters_mgmt/v1/clusters").SendContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("Presents different certificates for alternative URLs and token URL", func() {
+		// Prepare the servers:
+		altServer, altCA := MakeTCPMTLSServer()
+		defer func() {
+			altServer.Close()
+			Expect(os.Remove(altCA)).To(Succeed())
+		}()
+		oidServer, oidCA := MakeTCPMTLSServer()
+		defer func() {
+			oidServer.Close()
+			Expect(os.Remove(oidCA)).To(Succeed())
+		}()
+		oidServer.AppendHandlers(
+			ghttp.CombineHandlers(
+				verifyClient("sso"),
+				RespondWithAccessAndRefreshTokens(token, ""),
+			),
+		)
+		apiServer.AppendHandlers(
+			ghttp.CombineHandlers(
+				verifyClient("default"),
+				RespondWithJSON(http.StatusOK, "{}"),
+			),
+		)
+		altServer.AppendHandlers(
+			ghttp.CombineHandlers(
+				verifyClient("clusters"),
+				RespondWithJSON(http.StatusOK, "{}"),
+			),
+		)
+
+		// Generate the certificates:
+		files := map[string][]string{}
+		for _, name := range []string{"default", "clusters", "sso"} {
+			cert, key := MakeClientCertificate(name)
+			certFile := filepath.Join(tmp, name+".crt")
+			keyFile := filepath.Join(tmp, name+".key")
+			Expect(os.WriteFile(certFile, cert, 0600)).To(Succeed())
+			Expect(os.WriteFile(keyFile, key, 0600)).To(Succeed())
+			files[name] = []string{certFile, keyFile}
+		}
+
+		// Create the connection:
+		connection, err := NewConnectionBuilder().
+			Logger(logger).
+			URL(apiServer.URL()).
+			AlternativeURL("/api/clusters_mgmt", altServer.URL()).
+			TokenURL(oidServer.URL()).
+			Client("myclient", "mysecret").
+			TrustedCAFile(apiCA).
+			TrustedCAFile(altCA).
+			TrustedCAFile(oidCA).
+			ClientCertificate(files["default"][0], files["default"][1]).
+			AlternativeURLClientCertificate(
+				"/api/clusters_mgmt",
+				files["clusters"][0], files["clusters"][1],
+			).
+			TokenURLClientCertificate(files["sso"][0], files["sso"][1]).
+			BuildContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		defer func() {
+			err = connection.Close()
+			Expect(err).ToNot(HaveOccurred())
+		}()
+
+		// Send the requests:
+		_, err = connection.Get().Path("/api/accounts_mgmt/v1/accounts").SendContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = connection.Get().Path("/api/clusters_mgmt/v1/clusters").SendContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("Can be loaded from the configuration", func() {
+		apiServer.AppendHandlers(
+			ghttp.CombineHandlers(
+				verifyClient("myclient"),
+				RespondWithJSON(http.StatusOK, "{}"),
+			),
+		)
+		certFile, keyFile := writeCertificate("myclient")
+		config := fmt.Sprintf(
+			"url: %s\n"+
+				"trusted_cas:\n"+
+				"- %s\n"+
+				"client_certificate:\n"+
+				"  cert_file: %s\n"+
+				"  key_file: %s\n",
+			apiServer.URL(), apiCA, certFile, keyFile,
+		)
+		connection, err := NewConnectionBuilder().
+			Logger(logger).
+			Tokens(token).
+			Load(strings.NewReader(config)).
+			BuildContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		defer func() {
+			err = connection.Close()
+			Expect(err).ToNot(HaveOccurred())
+		}()
+		_, err = connection.Get().Path("/api/clusters_mgmt/v1/clusters").SendContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("Can't be created if the files don't exist", func() {
+		connection, err := NewConnectionBuilder().
+			Logger(logger).
+			URL(apiServer.URL()).
+			Tokens(token).
+			ClientCertificate(filepath.Join(tmp, "junk.crt"), filepath.Join(tmp, "junk.key")).
+			BuildContext(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(connection).To(BeNil())
+		Expect(err.Error()).To(ContainSubstring("junk.crt"))
+	})
+
+	It("Can't be created with certificate for unknown prefix", func() {
+		certFile, keyFile := writeCertificate("myclient")
+		connection, err := NewConnectionBuilder().
+			Logger(logger).
+			URL(apiServer.URL()).
+			Tokens(token).
+			AlternativeURLClientCertificate("/api/clusters_mgmt", certFile, keyFile).
+			BuildContext(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(connection).To(BeNil())
+		Expect(err.Error()).To(ContainSubstring("/api/clusters_mgmt"))
+	})
+})
@@ -0,0 +1,461 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the implementation of the cluster waiter, that waits till a cluster reaches
+// a state while copying the install or uninstall log to a writer.
+
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/openshift-online/ocm-sdk-go/helpers"
+	"github.com/openshift-online/ocm-sdk-go/logging"
+)
+
+// DefaultClusterWaitInterval is the default time that the cluster waiter waits between attempts
+// to retrieve the cluster.
+const DefaultClusterWaitInterval = 30 * time.Second
+
+// ClusterWaiterBuilder contains the data and logic needed to create a cluster waiter. Don't
+// create instances of this type directly, use the NewClusterWaiter function instead.
+type ClusterWaiterBuilder struct {
+	logger      logging.Logger
+	connection  *Connection
+	interval    time.Duration
+	logWriter   io.Writer
+	errorStates []cmv1.ClusterState
+}
+
+// ClusterWaiter waits till clusters reach a state, or till they are completely removed. While
+// waiting it can copy the install or uninstall log of the cluster to a writer, and it stops
+// early when the cluster reaches a state that means that it will never reach the desired one,
+// like `error`.
+//
+// The waiter uses the same polling engine as the Poll methods of the generated clients, so the
+// backoff, jitter, timeout and progress callbacks can be configured adding options to the context
+// with the helpers.WithPollOptions function. The GiveUpError option is always enabled, so the
+// context doesn't need a deadline.
+type ClusterWaiter struct {
+	logger      logging.Logger
+	connection  *Connection
+	interval    time.Duration
+	logWriter   io.Writer
+	errorStates map[cmv1.ClusterState]bool
+}
+
+// ClusterWaitResult contains the outcome of waiting for a cluster. It is returned even when
+// waiting fails, so that callers can report what happened till then.
+type ClusterWaitResult struct {
+	// Cluster is the last version of the cluster retrieved. It will be nil if the cluster
+	// could never be retrieved.
+	Cluster *cmv1.Cluster
+
+	// State is the last state of the cluster. It will be empty if the cluster doesn't exist.
+	State cmv1.ClusterState
+
+	// Deleted is true if the cluster doesn't exist any more.
+	Deleted bool
+
+	// Transitions contains the changes of state observed, in the order they were observed.
+	// The first one has an empty From state and corresponds to the first time that the
+	// cluster was retrieved. If the cluster has been removed the last one has an empty To
+	// state.
+	Transitions []ClusterStateTransition
+}
+
+// ClusterStateTransition describes a change in the state of a cluster.
+type ClusterStateTransition struct {
+	// From is the state before the change.
+	From cmv1.ClusterState
+
+	// To is the state after the change.
+	To cmv1.ClusterState
+
+	// Time is the time when the change was observed. Note that the change may have happened
+	// earlier, up to the interval between attempts.
+	Time time.Time
+}
+
+// ClusterStateError is the error returned by the cluster waiter when the cluster reaches a state
+// that means that it will never reach the desired one, or when it has been removed.
+type ClusterStateError struct {
+	// ID is the identifier of the cluster.
+	ID string
+
+	// State is the state of the cluster. It will be empty if the cluster has been removed.
+	State cmv1.ClusterState
+
+	// Code is the provision error code reported in the status of the cluster, if any.
+	Code string
+
+	// Reason is the provision error message reported in the status of the cluster, if any.
+	Reason string
+}
+
+// Error is the implementation of the error interface.
+func (e *ClusterStateError) Error() string {
+	buffer := &strings.Builder{}
+	if e.State == "" {
+		fmt.Fprintf(buffer, "cluster '%s' doesn't exist", e.ID)
+	} else {
+		fmt.Fprintf(buffer, "cluster '%s' is in state '%s'", e.ID, e.State)
+	}
+	switch {
+	case e.Code != "" && e.Reason != "":
+		fmt.Fprintf(buffer, ": %s: %s", e.Code, e.Reason)
+	case e.Code != "":
+		fmt.Fprintf(buffer, ": %s", e.Code)
+	case e.Reason != "":
+		fmt.Fprintf(buffer, ": %s", e.Reason)
+	}
+	return buffer.String()
+}
+
+// NewClusterWaiter creates a builder that can then be used to configure and create a cluster
+// waiter.
+func NewClusterWaiter() *ClusterWaiterBuilder {
+	return &ClusterWaiterBuilder{
+		interval: DefaultClusterWaitInterval,
+		errorStates: []cmv1.ClusterState{
+			cmv1.ClusterStateError,
+		},
+	}
+}
+
+// Logger sets the logger that the waiter will use to send messages to the log. This is
+// mandatory.
+func (b *ClusterWaiterBuilder) Logger(value logging.Logger) *ClusterWaiterBuilder {
+	b.logger = value
+	return b
+}
+
+// Connection sets the connection that will be used to retrieve the clusters and their logs. This
+// is mandatory.
+func (b *ClusterWaiterBuilder) Connection(value *Connection) *ClusterWaiterBuilder {
+	b.connection = value
+	return b
+}
+
+// Interval sets the time to wait between attempts to retrieve the cluster. The default is thirty
+// seconds.
+func (b *ClusterWaiterBuilder) Interval(value time.Duration) *ClusterWaiterBuilder {
+	b.interval = value
+	return b
+}
+
+// LogWriter sets the writer where the waiter will copy the install log when waiting for a state,
+// or the uninstall log when waiting for the removal of the cluster. Each line is written only
+// once, even if the log is retrieved many times. The default is to not retrieve the logs.
+func (b *ClusterWaiterBuilder) LogWriter(value io.Writer) *ClusterWaiterBuilder {
+	b.logWriter = value
+	return b
+}
+
+// ErrorStates sets the states that mean that the cluster will never reach the desired state, so
+// that the waiter stops and returns a ClusterStateError. This replaces any states previously
+// configured. The default is `error`.
+func (b *ClusterWaiterBuilder) ErrorStates(values ...cmv1.ClusterState) *ClusterWaiterBuilder {
+	b.errorStates = values
+	return b
+}
+
+// Build uses the data stored in the builder to create a new cluster waiter.
+func (b *ClusterWaiterBuilder) Build() (result *ClusterWaiter, err error) {
+	// Check parameters:
+	if b.logger == nil {
+		err = fmt.Errorf("logger is mandatory")
+		return
+	}
+	if b.connection == nil {
+		err = fmt.Errorf("connection is mandatory")
+		return
+	}
+	if b.interval <= 0 {
+		err = fmt.Errorf(
+			"interval %s isn't valid, it should be greater than zero",
+			b.interval,
+		)
+		return
+	}
+
+	// Copy the error states:
+	errorStates := make(map[cmv1.ClusterState]bool, len(b.errorStates))
+	for _, state := range b.errorStates {
+		errorStates[state] = true
+	}
+
+	// Create and populate the object:
+	result = &ClusterWaiter{
+		logger:      b.logger,
+		connection:  b.connection,
+		interval:    b.interval,
+		logWriter:   b.logWriter,
+		errorStates: errorStates,
+	}
+	return
+}
+
+// WaitForState waits till the cluster with the given identifier is in one of the given states.
+// It returns a ClusterStateError if the cluster reaches one of the error states or if it doesn't
+// exist, and a helpers.PollError if the context is cancelled or its deadline expires.
+func (w *ClusterWaiter) WaitForState(ctx context.Context, id string,
+	states ...cmv1.ClusterState) (result *ClusterWaitResult, err error) {
+	if len(states) == 0 {
+		err = fmt.Errorf("at least one state is required")
+		return
+	}
+	targets := make(map[cmv1.ClusterState]bool, len(states))
+	for _, state := range states {
+		targets[state] = true
+	}
+	result, err = w.wait(ctx, id, targets, "install")
+	return
+}
+
+// WaitForDeletion waits till the cluster with the given identifier has been completely removed.
+// It returns a ClusterStateError if the cluster reaches one of the error states after starting
+// the uninstallation, and a helpers.PollError if the context is cancelled or its deadline
+// expires.
+func (w *ClusterWaiter) WaitForDeletion(ctx context.Context,
+	id string) (result *ClusterWaitResult, err error) {
+	result, err = w.wait(ctx, id, nil, "uninstall")
+	return
+}
+
+// wait contains the logic shared by the WaitForState and WaitForDeletion methods. When the
+// targets map is empty it waits till the cluster is removed.
+func (w *ClusterWaiter) wait(ctx context.Context, id string, targets map[cmv1.ClusterState]bool,
+	log string) (result *ClusterWaitResult, err error) {
+	result = &ClusterWaitResult{}
+	client := w.connection.ClustersMgmt().V1().Clusters().Cluster(id)
+	var tailer *clusterLogTailer
+	if w.logWriter != nil {
+		tailer = &clusterLogTailer{
+			logger: w.logger,
+			name:   log,
+			client: client.Logs().Install(),
+			writer: w.logWriter,
+		}
+		if log == "uninstall" {
+			tailer.client = client.Logs().Uninstall()
+		}
+	}
+
+	// The cluster is done when it reaches one of the target states or one of the error states.
+	// When waiting for the removal the error states are only relevant after the uninstallation
+	// has started, otherwise a cluster that failed to install could never be waited for.
+	uninstalling := false
+	done := func(state cmv1.ClusterState) bool {
+		if state == cmv1.ClusterStateUninstalling {
+			uninstalling = true
+		}
+		if len(targets) == 0 {
+			return uninstalling && w.errorStates[state]
+		}
+		return targets[state] || w.errorStates[state]
+	}
+
+	task := func(ctx context.Context) (status int, current interface{}, err error) {
+		response, err := client.Get().SendContext(ctx)
+		if response == nil {
+			return
+		}
+		now := time.Now()
+		status = response.Status()
+		current = response
+		switch status {
+		case http.StatusOK:
+			err = nil
+			cluster := response.Body()
+			result.Cluster = cluster
+			result.record(cluster.State(), now)
+			if tailer != nil {
+				err = tailer.tail(ctx, done(cluster.State()))
+				if err != nil {
+					status = 0
+				}
+			}
+		case http.StatusNotFound:
+			err = nil
+			result.Deleted = true
+			result.record("", now)
+			if tailer != nil {
+				err = tailer.tail(ctx, true)
+				if err != nil {
+					status = 0
+				}
+			}
+		case http.StatusTooManyRequests:
+			err = nil
+		default:
+			// Other client errors, like missing permissions, won't go away by waiting, so
+			// return a zero status to stop polling immediately:
+			if status < http.StatusInternalServerError {
+				status = 0
+			} else {
+				err = nil
+			}
+		}
+		return
+	}
+	predicate := func(current interface{}) bool {
+		response := current.(*cmv1.ClusterGetResponse)
+		if response.Status() == http.StatusNotFound {
+			return true
+		}
+		return done(response.Body().State())
+	}
+	options := helpers.PollOptionsFromContext(ctx)
+	options.GiveUpError = true
+	_, err = helpers.PollContext(
+		helpers.WithPollOptions(ctx, options),
+		w.interval,
+		[]int{http.StatusOK, http.StatusNotFound},
+		[]func(interface{}) bool{predicate},
+		task,
+	)
+	if err != nil {
+		return
+	}
+
+	// Check if the cluster ended in the desired state:
+	switch {
+	case result.Deleted:
+		if len(targets) != 0 {
+			err = &ClusterStateError{
+				ID: id,
+			}
+		}
+	case !targets[result.State] && w.errorStates[result.State]:
+		status := result.Cluster.Status()
+		err = &ClusterStateError{
+			ID:     id,
+			State:  result.State,
+			Code:   status.ProvisionErrorCode(),
+			Reason: status.ProvisionErrorMessage(),
+		}
+	}
+	return
+}
+
+// record adds a transition if the given state is different to the last one observed.
+func (r *ClusterWaitResult) record(state cmv1.ClusterState, now time.Time) {
+	if len(r.Transitions) > 0 && r.State == state {
+		return
+	}
+	r.Transitions = append(r.Transitions, ClusterStateTransition{
+		From: r.State,
+		To:   state,
+		Time: now,
+	})
+	r.State = state
+}
+
+// clusterLogTailer copies to a writer the lines of a cluster log that haven't been copied yet.
+type clusterLogTailer struct {
+	logger  logging.Logger
+	name    string
+	client  *cmv1.LogClient
+	writer  io.Writer
+	lines   int
+	partial string
+}
+
+// tail retrieves the lines of the log that haven't been copied yet and writes them. The last line
+// of the log is only written when it is complete, or when the flush flag is true, as it may
+// still be growing. Failures to retrieve the log are written to the log of the waiter and
+// ignored, as logs are frequently not available till the installation starts, or after the
+// cluster has been removed. In that case flushing writes the incomplete last line seen in the
+// previous call, if any. The returned error is only for failures to write.
+func (t *clusterLogTailer) tail(ctx context.Context, flush bool) error {
+	response, err := t.client.Get().Offset(t.lines).SendContext(ctx)
+	if err != nil {
+		if response != nil && response.Status() == http.StatusNotFound {
+			t.logger.Debug(ctx, "The %s log isn't available", t.name)
+		} else {
+			t.logger.Warn(ctx, "Can't retrieve %s log: %v", t.name, err)
+		}
+		if flush && t.partial != "" {
+			_, err = io.WriteString(t.writer, t.partial+"\n")
+			if err != nil {
+				return fmt.Errorf("can't write cluster log: %w", err)
+			}
+			t.partial = ""
+			t.lines++
+		}
+		return nil
+	}
+	t.partial = ""
+	for _, line := range strings.SplitAfter(response.Body().Content(), "\n") {
+		if line == "" {
+			continue
+		}
+		if !strings.HasSuffix(line, "\n") {
+			if !flush {
+				t.partial = line
+				break
+			}
+			line += "\n"
+		}
+		_, err = io.WriteString(t.writer, line)
+		if err != nil {
+			return fmt.Errorf("can't write cluster log: %w", err)
+		}
+		t.lines++
+	}
+	return nil
+}
+
+// WaitForClusterState waits till the cluster with the given identifier is in one of the given
+// states, using a cluster waiter with the logger of the connection and the default
+// configuration. Use the NewClusterWaiter function to copy the install log to a writer or to
+// change the interval and the error states.
+func WaitForClusterState(ctx context.Context, connection *Connection, id string,
+	states ...cmv1.ClusterState) (result *ClusterWaitResult, err error) {
+	waiter, err := NewClusterWaiter().
+		Logger(connection.Logger()).
+		Connection(connection).
+		Build()
+	if err != nil {
+		return
+	}
+	result, err = waiter.WaitForState(ctx, id, states...)
+	return
+}
+
+// WaitForClusterDeletion waits till the cluster with the given identifier has been completely
+// removed, using a cluster waiter with the logger of the connection and the default
+// configuration. Use the NewClusterWaiter function to copy the uninstall log to a writer or to
+// change the interval and the error states.
+func WaitForClusterDeletion(ctx context.Context, connection *Connection,
+	id string) (result *ClusterWaitResult, err error) {
+	waiter, err := NewClusterWaiter().
+		Logger(connection.Logger()).
+		Connection(connection).
+		Build()
+	if err != nil {
+		return
+	}
+	result, err = waiter.WaitForDeletion(ctx, id)
+	return
+}
@@ -0,0 +1,281 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the cluster waiter.
+
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/onsi/gomega/ghttp"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/openshift-online/ocm-sdk-go/helpers"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"             // nolint
+	. "github.com/onsi/gomega"                         // nolint
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Cluster waiter", func() {
+	var ctx context.Context
+	var server *FakeServer
+	var connection *Connection
+	var output *bytes.Buffer
+	var waiter *ClusterWaiter
+
+	BeforeEach(func() {
+		var err error
+
+		// Create the context:
+		ctx = context.Background()
+
+		// Create the server. Note that clusters will only change state when the tests
+		// explicitly advance them.
+		server, err = NewFakeServer().Build()
+		Expect(err).ToNot(HaveOccurred())
+
+		// Create the connection:
+		connection, err = NewConnectionBuilder().
+			Logger(logger).
+			URL(server.URL()).
+			Tokens(MakeTokenString("Bearer", 5*time.Minute)).
+			BuildContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+
+		// Create the waiter:
+		output = &bytes.Buffer{}
+		waiter, err = NewClusterWaiter().
+			Logger(logger).
+			Connection(connection).
+			Interval(time.Millisecond).
+			LogWriter(output).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		err := connection.Close()
+		Expect(err).ToNot(HaveOccurred())
+		server.Close()
+	})
+
+	// addCluster adds a cluster directly to the server:
+	addCluster := func(builder *cmv1.ClusterBuilder) string {
+		object, err := builder.Name("mycluster").Build()
+		Expect(err).ToNot(HaveOccurred())
+		object, err = server.AddCluster(object)
+		Expect(err).ToNot(HaveOccurred())
+		return object.ID()
+	}
+
+	// onAttempt returns a context that calls the given function after each attempt that doesn't
+	// finish the wait:
+	onAttempt := func(callback func(number int)) context.Context {
+		return helpers.WithPollOptions(ctx, helpers.PollOptions{
+			OnAttempt: func(attempt *helpers.PollAttempt) {
+				if !attempt.Done {
+					callback(attempt.Number)
+				}
+			},
+		})
+	}
+
+	It("Can't be created without a connection", func() {
+		_, err := NewClusterWaiter().
+			Logger(logger).
+			Build()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("connection"))
+	})
+
+	It("Waits for the state and copies each line of the install log once", func() {
+		id := addCluster(cmv1.NewCluster())
+		err := server.AppendClusterLog(id, "install", "line 1")
+		Expect(err).ToNot(HaveOccurred())
+		ctx = onAttempt(func(number int) {
+			err := server.AppendClusterLog(id, "install", fmt.Sprintf("line %d", number+1))
+			Expect(err).ToNot(HaveOccurred())
+			_, err = server.AdvanceCluster(id)
+			Expect(err).ToNot(HaveOccurred())
+		})
+		result, err := waiter.WaitForState(ctx, id, cmv1.ClusterStateReady)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.State).To(Equal(cmv1.ClusterStateReady))
+		Expect(result.Cluster.ID()).To(Equal(id))
+		Expect(result.Deleted).To(BeFalse())
+		Expect(output.String()).To(Equal("line 1\nline 2\nline 3\n"))
+		Expect(result.Transitions).To(HaveLen(3))
+		Expect(result.Transitions[0].From).To(BeEmpty())
+		Expect(result.Transitions[0].To).To(Equal(cmv1.ClusterStatePending))
+		Expect(result.Transitions[1].From).To(Equal(cmv1.ClusterStatePending))
+		Expect(result.Transitions[1].To).To(Equal(cmv1.ClusterStateInstalling))
+		Expect(result.Transitions[2].From).To(Equal(cmv1.ClusterStateInstalling))
+		Expect(result.Transitions[2].To).To(Equal(cmv1.ClusterStateReady))
+		for i := 1; i < len(result.Transitions); i++ {
+			Expect(result.Transitions[i].Time).To(BeTemporally(">=", result.Transitions[i-1].Time))
+		}
+	})
+
+	It("Stops when the cluster is in the error state", func() {
+		id := addCluster(
+			cmv1.NewCluster().
+				Status(
+					cmv1.NewClusterStatus().
+						ProvisionErrorCode("OCM3055").
+						ProvisionErrorMessage("Quota exceeded"),
+				),
+		)
+		ctx = onAttempt(func(number int) {
+			err := server.SetClusterState(id, cmv1.ClusterStateError)
+			Expect(err).ToNot(HaveOccurred())
+		})
+		result, err := waiter.WaitForState(ctx, id, cmv1.ClusterStateReady)
+		Expect(err).To(HaveOccurred())
+		var stateErr *ClusterStateError
+		Expect(errors.As(err, &stateErr)).To(BeTrue())
+		Expect(stateErr.ID).To(Equal(id))
+		Expect(stateErr.State).To(Equal(cmv1.ClusterStateError))
+		Expect(stateErr.Code).To(Equal("OCM3055"))
+		Expect(err.Error()).To(ContainSubstring("Quota exceeded"))
+		Expect(result.State).To(Equal(cmv1.ClusterStateError))
+	})
+
+	It("Accepts the error state if it is explicitly requested", func() {
+		id := addCluster(cmv1.NewCluster().State(cmv1.ClusterStateError))
+		result, err := waiter.WaitForState(ctx, id, cmv1.ClusterStateError)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.State).To(Equal(cmv1.ClusterStateError))
+	})
+
+	It("Fails if the cluster doesn't exist", func() {
+		result, err := waiter.WaitForState(ctx, "123", cmv1.ClusterStateReady)
+		Expect(err).To(HaveOccurred())
+		var stateErr *ClusterStateError
+		Expect(errors.As(err, &stateErr)).To(BeTrue())
+		Expect(stateErr.State).To(BeEmpty())
+		Expect(result.Deleted).To(BeTrue())
+	})
+
+	It("Waits for the deletion and copies the uninstall log", func() {
+		id := addCluster(cmv1.NewCluster().State(cmv1.ClusterStateReady))
+		_, err := connection.ClustersMgmt().V1().Clusters().Cluster(id).Delete().
+			SendContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		err = server.AppendClusterLog(id, "uninstall", "deleting")
+		Expect(err).ToNot(HaveOccurred())
+		ctx = onAttempt(func(number int) {
+			_, err := server.AdvanceCluster(id)
+			Expect(err).ToNot(HaveOccurred())
+		})
+		result, err := waiter.WaitForDeletion(ctx, id)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Deleted).To(BeTrue())
+		Expect(result.State).To(BeEmpty())
+		Expect(output.String()).To(Equal("deleting\n"))
+		Expect(result.Transitions).To(HaveLen(2))
+		Expect(result.Transitions[0].To).To(Equal(cmv1.ClusterStateUninstalling))
+		Expect(result.Transitions[1].From).To(Equal(cmv1.ClusterStateUninstalling))
+		Expect(result.Transitions[1].To).To(BeEmpty())
+	})
+
+	It("Returns the partial result when the deadline expires", func() {
+		id := addCluster(cmv1.NewCluster())
+		ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		result, err := waiter.WaitForState(ctx, id, cmv1.ClusterStateReady)
+		Expect(err).To(HaveOccurred())
+		var pollErr *helpers.PollError
+		Expect(errors.As(err, &pollErr)).To(BeTrue())
+		Expect(result.State).To(Equal(cmv1.ClusterStatePending))
+		Expect(result.Transitions).To(HaveLen(1))
+	})
+
+	It("Can be used without a log writer", func() {
+		id := addCluster(cmv1.NewCluster().State(cmv1.ClusterStateReady))
+		result, err := WaitForClusterState(ctx, connection, id, cmv1.ClusterStateReady)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.State).To(Equal(cmv1.ClusterStateReady))
+	})
+})
+
+var _ = Describe("Cluster log tailer", func() {
+	var ctx context.Context
+	var server *ghttp.Server
+	var connection *Connection
+
+	BeforeEach(func() {
+		var err error
+
+		// Create the context:
+		ctx = context.Background()
+
+		// Create the server:
+		server = MakeTCPServer()
+
+		// Create the connection:
+		connection, err = NewConnectionBuilder().
+			Logger(logger).
+			URL(server.URL()).
+			Tokens(MakeTokenString("Bearer", 5*time.Minute)).
+			BuildContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		err := connection.Close()
+		Expect(err).ToNot(HaveOccurred())
+		server.Close()
+	})
+
+	It("Flushes the last incomplete line when the log is no longer available", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			RespondWithJSON(http.StatusOK, `{
+				"kind": "Log",
+				"id": "uninstall",
+				"content": "line 1\npart"
+			}`),
+			RespondWithJSON(http.StatusNotFound, `{
+				"kind": "Error",
+				"id": "404",
+				"reason": "Not found"
+			}`),
+		)
+
+		// Tail the log:
+		output := &bytes.Buffer{}
+		tailer := &clusterLogTailer{
+			logger: logger,
+			name:   "uninstall",
+			client: connection.ClustersMgmt().V1().Clusters().Cluster("123").Logs().
+				Uninstall(),
+			writer: output,
+		}
+		err := tailer.tail(ctx, false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(output.String()).To(Equal("line 1\n"))
+		err = tailer.tail(ctx, true)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(output.String()).To(Equal("line 1\npart\n"))
+	})
+})
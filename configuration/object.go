@@ -44,11 +44,16 @@ type Builder struct {
 	// the node was loaded from. This is used to generate error messages that include the name
 	// of the file.
 	titles map[*yaml.Node]string
+
+	// files contains the names of the files and directories that were read while loading the
+	// configuration, including the files referenced with the `file` tag.
+	files []string
 }
 
 // Object contains configuration data.
 type Object struct {
-	tree *yaml.Node
+	tree  *yaml.Node
+	files []string
 }
 
 // New creates a new builder that can be use to populate a configuration object.
@@ -96,8 +101,9 @@ func (b *Builder) Build() (object *Object, err error) {
 	b.registerTag("variable", yaml.ScalarNode, b.processVariableTag)
 	b.registerTag("yaml", yaml.ScalarNode, b.processYamlTag)
 
-	// Initialize the titles index:
+	// Initialize the titles index and the list of files:
 	b.titles = map[*yaml.Node]string{}
+	b.files = nil
 
 	// Merge the sources:
 	tree := &yaml.Node{}
@@ -127,7 +133,8 @@ func (b *Builder) Build() (object *Object, err error) {
 
 	// Create and populate the object:
 	object = &Object{
-		tree: tree,
+		tree:  tree,
+		files: b.files,
 	}
 
 	return
@@ -151,6 +158,7 @@ func (b *Builder) mergeReader(src io.Reader, dst *yaml.Node) error {
 }
 
 func (b *Builder) mergeFile(src string, dst *yaml.Node) error {
+	b.addFile(src)
 	info, err := os.Stat(src)
 	if err != nil {
 		return err
@@ -188,6 +196,16 @@ func (b *Builder) mergeDir(src string, dst *yaml.Node) error {
 	return nil
 }
 
+// addFile adds the given file to the list of files read, unless it is already there.
+func (b *Builder) addFile(file string) {
+	for _, current := range b.files {
+		if current == file {
+			return
+		}
+	}
+	b.files = append(b.files, file)
+}
+
 func (b *Builder) mergeAny(src interface{}, dst *yaml.Node) error {
 	buffer, err := yaml.Marshal(src)
 	if err != nil {
@@ -366,6 +384,14 @@ func (o *Object) Populate(v interface{}) error {
 	return o.tree.Decode(v)
 }
 
+// Files returns the names of the files and directories that were read to load the configuration,
+// including the files referenced with the `file` tag. This is intended for programs that want to
+// detect changes in the configuration and load it again. The caller must not modify the returned
+// slice.
+func (o *Object) Files() []string {
+	return o.files
+}
+
 // Effective returns an array of bytes containing the YAML representation of the configuration
 // after processing all the tags.
 func (o *Object) Effective() (out []byte, err error) {
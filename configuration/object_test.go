@@ -231,6 +231,31 @@ var _ = Describe("Object", func() {
 			Expect(config.YourKey).To(Equal("yourvalue"))
 		})
 
+		It("Returns the files that were read", func() {
+			// Create a temporary directory containing a configuration file that references
+			// another file:
+			tmp, err := os.MkdirTemp("", "*.test.d")
+			Expect(err).ToNot(HaveOccurred())
+			defer func() {
+				err = os.RemoveAll(tmp)
+				Expect(err).ToNot(HaveOccurred())
+			}()
+			secret := filepath.Join(tmp, "secret.txt")
+			err = os.WriteFile(secret, []byte("mysecret"), 0600)
+			Expect(err).ToNot(HaveOccurred())
+			first := filepath.Join(tmp, "my.yaml")
+			err = os.WriteFile(first, []byte("mykey: !file "+secret), 0600)
+			Expect(err).ToNot(HaveOccurred())
+
+			// Load the configuration:
+			object, err := New().
+				Load(tmp).
+				Load("yourkey: yourvalue").
+				Build()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(object.Files()).To(Equal([]string{tmp, first, secret}))
+		})
+
 		It("Honours order of files in directory", func() {
 			// Create a temporary directory containing two configuration files:
 			tmp, err := os.MkdirTemp("", "*.test.d")
@@ -120,6 +120,7 @@ func (b *Builder) processVariableTag(node *yaml.Node) error {
 // content of the file.
 func (b *Builder) processFileTag(node *yaml.Node) error {
 	file := node.Value
+	b.addFile(file)
 	data, err := os.ReadFile(file) // #nosec G304
 	if err != nil {
 		return b.nodeError(node, "%w", err)
@@ -0,0 +1,352 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the health check of the connection, that retrieves the metadata of the
+// services to verify that they are reachable and that the credentials are valid.
+
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptrace"
+	neturl "net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CheckReport contains the result of checking a connection.
+type CheckReport struct {
+	// TokenValid indicates if the connection could obtain a valid access token. This will be
+	// true when the connection doesn't use authentication.
+	TokenValid bool
+
+	// TokenError contains the error that prevented obtaining the access token, if any.
+	TokenError error
+
+	// Services contains the results of the checks of the services, sorted by name.
+	Services []*ServiceCheck
+}
+
+// ServiceCheck contains the result of checking one service.
+type ServiceCheck struct {
+	// Service is the name of the service, for example `clusters_mgmt`.
+	Service string
+
+	// Address is the base URL selected for the service, taking into account the alternative
+	// URLs.
+	Address string
+
+	// RemoteAddress is the network address of the server that answered the request, for
+	// example `10.0.0.1:443`. It will be empty if no network connection could be established.
+	RemoteAddress string
+
+	// ServerVersion is the version reported by the server in the metadata.
+	ServerVersion string
+
+	// Status is the HTTP status code of the metadata response, or zero if no response was
+	// received.
+	Status int
+
+	// Latency is the time it took to retrieve the metadata.
+	Latency time.Duration
+
+	// TokenValid indicates if the access token was accepted by the service.
+	TokenValid bool
+
+	// Error contains the error that happened while retrieving the metadata, if any.
+	Error error
+}
+
+// Healthy returns true if the token is valid and all the services were checked successfully.
+func (r *CheckReport) Healthy() bool {
+	if r == nil || !r.TokenValid {
+		return false
+	}
+	for _, service := range r.Services {
+		if !service.Healthy() {
+			return false
+		}
+	}
+	return true
+}
+
+// Healthy returns true if the metadata of the service was retrieved successfully.
+func (s *ServiceCheck) Healthy() bool {
+	return s != nil && s.Error == nil && s.Status == http.StatusOK && s.TokenValid
+}
+
+// checkedService describes how to check one of the services.
+type checkedService struct {
+	prefix   string
+	metadata func(ctx context.Context, c *Connection) (status int, version string, err error)
+}
+
+// checkedServices contains the services that are checked, indexed by name.
+var checkedServices = map[string]checkedService{
+	"access_transparency": {
+		prefix: "/api/access_transparency/v1",
+		metadata: func(ctx context.Context, c *Connection) (status int, version string,
+			err error) {
+			response, err := c.AccessTransparency().V1().Get().SendContext(ctx)
+			if err == nil {
+				version = response.Body().ServerVersion()
+			}
+			return response.Status(), version, err
+		},
+	},
+	"accounts_mgmt": {
+		prefix: "/api/accounts_mgmt/v1",
+		metadata: func(ctx context.Context, c *Connection) (status int, version string,
+			err error) {
+			response, err := c.AccountsMgmt().V1().Get().SendContext(ctx)
+			if err == nil {
+				version = response.Body().ServerVersion()
+			}
+			return response.Status(), version, err
+		},
+	},
+	"addons_mgmt": {
+		prefix: "/api/addons_mgmt/v1",
+		metadata: func(ctx context.Context, c *Connection) (status int, version string,
+			err error) {
+			response, err := c.AddonsMgmt().V1().Get().SendContext(ctx)
+			if err == nil {
+				version = response.Body().ServerVersion()
+			}
+			return response.Status(), version, err
+		},
+	},
+	"authorizations": {
+		prefix: "/api/authorizations/v1",
+		metadata: func(ctx context.Context, c *Connection) (status int, version string,
+			err error) {
+			response, err := c.Authorizations().V1().Get().SendContext(ctx)
+			if err == nil {
+				version = response.Body().ServerVersion()
+			}
+			return response.Status(), version, err
+		},
+	},
+	"clusters_mgmt": {
+		prefix: "/api/clusters_mgmt/v1",
+		metadata: func(ctx context.Context, c *Connection) (status int, version string,
+			err error) {
+			response, err := c.ClustersMgmt().V1().Get().SendContext(ctx)
+			if err == nil {
+				version = response.Body().ServerVersion()
+			}
+			return response.Status(), version, err
+		},
+	},
+	"job_queue": {
+		prefix: "/api/job_queue/v1",
+		metadata: func(ctx context.Context, c *Connection) (status int, version string,
+			err error) {
+			response, err := c.JobQueue().V1().Get().SendContext(ctx)
+			if err == nil {
+				version = response.Body().ServerVersion()
+			}
+			return response.Status(), version, err
+		},
+	},
+	"osd_fleet_mgmt": {
+		prefix: "/api/osd_fleet_mgmt/v1",
+		metadata: func(ctx context.Context, c *Connection) (status int, version string,
+			err error) {
+			response, err := c.OSDFleetMgmt().V1().Get().SendContext(ctx)
+			if err == nil {
+				version = response.Body().ServerVersion()
+			}
+			return response.Status(), version, err
+		},
+	},
+	"service_logs": {
+		prefix: "/api/service_logs/v1",
+		metadata: func(ctx context.Context, c *Connection) (status int, version string,
+			err error) {
+			response, err := c.ServiceLogs().V1().Get().SendContext(ctx)
+			if err == nil {
+				version = response.Body().ServerVersion()
+			}
+			return response.Status(), version, err
+		},
+	},
+	"service_mgmt": {
+		prefix: "/api/service_mgmt/v1",
+		metadata: func(ctx context.Context, c *Connection) (status int, version string,
+			err error) {
+			response, err := c.ServiceMgmt().V1().Get().SendContext(ctx)
+			if err == nil {
+				version = response.Body().ServerVersion()
+			}
+			return response.Status(), version, err
+		},
+	},
+	"status_board": {
+		prefix: "/api/status-board/v1",
+		metadata: func(ctx context.Context, c *Connection) (status int, version string,
+			err error) {
+			response, err := c.StatusBoard().V1().Get().SendContext(ctx)
+			if err == nil {
+				version = response.Body().ServerVersion()
+			}
+			return response.Status(), version, err
+		},
+	},
+	"web_rca": {
+		prefix: "/api/web-rca/v1",
+		metadata: func(ctx context.Context, c *Connection) (status int, version string,
+			err error) {
+			response, err := c.WebRCA().V1().Get().SendContext(ctx)
+			if err == nil {
+				version = response.Body().ServerVersion()
+			}
+			return response.Status(), version, err
+		},
+	},
+}
+
+// CheckedServices returns the sorted names of the services that can be checked with the Check
+// method.
+func CheckedServices() []string {
+	result := make([]string, 0, len(checkedServices))
+	for name := range checkedServices {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Check verifies that the connection can obtain a valid access token and retrieves the metadata
+// of the given services, concurrently, to verify that they are reachable. If no service is given
+// all the services returned by the CheckedServices function are checked. Failures of the checks
+// are reported in the returned report, the returned error will only be non nil if the connection
+// is closed or if one of the given service names isn't valid.
+func (c *Connection) Check(ctx context.Context, services ...string) (report *CheckReport,
+	err error) {
+	err = c.checkClosed()
+	if err != nil {
+		return
+	}
+	if len(services) == 0 {
+		services = CheckedServices()
+	}
+	for _, service := range services {
+		_, ok := checkedServices[service]
+		if !ok {
+			err = fmt.Errorf(
+				"service '%s' isn't valid, valid services are %s",
+				service, allServiceNames(),
+			)
+			return
+		}
+	}
+
+	// Check the token first, as otherwise each service would try to request it:
+	report = &CheckReport{
+		TokenValid: true,
+		Services:   make([]*ServiceCheck, len(services)),
+	}
+	_, _, report.TokenError = c.TokensContext(ctx)
+	if report.TokenError != nil {
+		c.logger.Warn(ctx, "Can't get tokens: %v", report.TokenError)
+		report.TokenValid = false
+	}
+
+	// Check the services:
+	wg := &sync.WaitGroup{}
+	wg.Add(len(services))
+	for i, service := range services {
+		go func(i int, service string) {
+			defer wg.Done()
+			report.Services[i] = c.checkService(ctx, service, report.TokenValid)
+		}(i, service)
+	}
+	wg.Wait()
+	sort.Slice(report.Services, func(i, j int) bool {
+		return report.Services[i].Service < report.Services[j].Service
+	})
+	return
+}
+
+// checkService checks one service.
+func (c *Connection) checkService(ctx context.Context, name string,
+	tokenValid bool) *ServiceCheck {
+	service := checkedServices[name]
+	result := &ServiceCheck{
+		Service: name,
+	}
+
+	// Find the address that will be used:
+	request := &http.Request{
+		URL: &neturl.URL{
+			Path: service.prefix,
+		},
+	}
+	address, err := c.selectServer(ctx, request)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	result.Address = address.Text
+
+	// There is no point in sending the request if there is no valid token, as the connection
+	// would try to request it again:
+	if !tokenValid {
+		result.Error = fmt.Errorf("can't get tokens")
+		return result
+	}
+
+	// Retrieve the metadata, remembering the remote address of the network connection:
+	var lock sync.Mutex
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			lock.Lock()
+			defer lock.Unlock()
+			if info.Conn != nil && info.Conn.RemoteAddr() != nil {
+				result.RemoteAddress = info.Conn.RemoteAddr().String()
+			}
+		},
+	}
+	start := time.Now()
+	status, version, err := service.metadata(httptrace.WithClientTrace(ctx, trace), c)
+	lock.Lock()
+	defer lock.Unlock()
+	result.Latency = time.Since(start)
+	result.Status = status
+	result.ServerVersion = version
+	result.Error = err
+	result.TokenValid = status != http.StatusUnauthorized &&
+		status != http.StatusForbidden
+	if err != nil {
+		c.logger.Warn(ctx, "Can't retrieve metadata of service '%s': %v", name, err)
+	}
+	return result
+}
+
+// allServiceNames returns a string containing the names of the services that can be checked,
+// quoted and separated by commas.
+func allServiceNames() string {
+	names := CheckedServices()
+	for i, name := range names {
+		names[i] = "'" + name + "'"
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,198 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the connection health check and the check handler.
+
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/onsi/gomega/ghttp"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"             // nolint
+	. "github.com/onsi/gomega"                         // nolint
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Check", func() {
+	var ctx context.Context
+	var apiServer *ghttp.Server
+	var altServer *ghttp.Server
+	var connection *Connection
+
+	BeforeEach(func() {
+		var err error
+		ctx = context.Background()
+
+		// Prepare the servers:
+		apiServer = MakeTCPServer()
+		apiServer.RouteToHandler(
+			http.MethodGet,
+			"/api/clusters_mgmt/v1",
+			RespondWithJSON(http.StatusOK, `{"server_version": "123"}`),
+		)
+		apiServer.RouteToHandler(
+			http.MethodGet,
+			"/api/accounts_mgmt/v1",
+			RespondWithJSON(http.StatusUnauthorized, `{
+				"kind": "Error",
+				"id": "401",
+				"reason": "Invalid token"
+			}`),
+		)
+		altServer = MakeTCPServer()
+		altServer.RouteToHandler(
+			http.MethodGet,
+			"/api/service_logs/v1",
+			RespondWithJSON(http.StatusOK, `{"server_version": "456"}`),
+		)
+
+		// Create the connection:
+		connection, err = NewConnectionBuilder().
+			Logger(logger).
+			URL(apiServer.URL()).
+			AlternativeURL("/api/service_logs", altServer.URL()).
+			Tokens(MakeTokenString("Bearer", 5*time.Minute)).
+			BuildContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		err := connection.Close()
+		Expect(err).ToNot(HaveOccurred())
+		apiServer.Close()
+		altServer.Close()
+	})
+
+	It("Reports healthy services", func() {
+		report, err := connection.Check(ctx, "service_logs", "clusters_mgmt")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Healthy()).To(BeTrue())
+		Expect(report.TokenValid).To(BeTrue())
+		Expect(report.Services).To(HaveLen(2))
+
+		first := report.Services[0]
+		Expect(first.Service).To(Equal("clusters_mgmt"))
+		Expect(first.Healthy()).To(BeTrue())
+		Expect(first.Status).To(Equal(http.StatusOK))
+		Expect(first.ServerVersion).To(Equal("123"))
+		Expect(first.Address).To(Equal(apiServer.URL()))
+		Expect(first.RemoteAddress).To(Equal(apiServer.Addr()))
+		Expect(first.Latency).To(BeNumerically(">", 0))
+		Expect(first.TokenValid).To(BeTrue())
+		Expect(first.Error).ToNot(HaveOccurred())
+
+		second := report.Services[1]
+		Expect(second.Service).To(Equal("service_logs"))
+		Expect(second.Healthy()).To(BeTrue())
+		Expect(second.ServerVersion).To(Equal("456"))
+		Expect(second.Address).To(Equal(altServer.URL()))
+		Expect(second.RemoteAddress).To(Equal(altServer.Addr()))
+	})
+
+	It("Reports rejected tokens", func() {
+		report, err := connection.Check(ctx, "clusters_mgmt", "accounts_mgmt")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Healthy()).To(BeFalse())
+		Expect(report.TokenValid).To(BeTrue())
+		Expect(report.Services).To(HaveLen(2))
+		service := report.Services[0]
+		Expect(service.Service).To(Equal("accounts_mgmt"))
+		Expect(service.Healthy()).To(BeFalse())
+		Expect(service.Status).To(Equal(http.StatusUnauthorized))
+		Expect(service.TokenValid).To(BeFalse())
+		Expect(service.Error).To(HaveOccurred())
+		Expect(service.Error.Error()).To(ContainSubstring("Invalid token"))
+		Expect(report.Services[1].Healthy()).To(BeTrue())
+	})
+
+	It("Rejects unknown services", func() {
+		report, err := connection.Check(ctx, "junk")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("junk"))
+		Expect(report).To(BeNil())
+	})
+
+	It("Checks all services by default", func() {
+		apiServer.SetAllowUnhandledRequests(true)
+		apiServer.SetUnhandledRequestStatusCode(http.StatusNotFound)
+		report, err := connection.Check(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Healthy()).To(BeFalse())
+		Expect(report.Services).To(HaveLen(len(CheckedServices())))
+	})
+
+	Describe("Handler", func() {
+		// serve sends a request to the handler and returns the status and the decoded body.
+		serve := func(handler http.Handler) (status int, body map[string]interface{}) {
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+			err := json.Unmarshal(recorder.Body.Bytes(), &body)
+			Expect(err).ToNot(HaveOccurred())
+			status = recorder.Code
+			return
+		}
+
+		It("Returns 200 when healthy", func() {
+			handler, err := NewCheckHandler().
+				Logger(logger).
+				Connection(connection).
+				Services("clusters_mgmt").
+				Build()
+			Expect(err).ToNot(HaveOccurred())
+			status, body := serve(handler)
+			Expect(status).To(Equal(http.StatusOK))
+			Expect(body["healthy"]).To(BeTrue())
+			Expect(body["token_valid"]).To(BeTrue())
+			services := body["services"].([]interface{})
+			Expect(services).To(HaveLen(1))
+			service := services[0].(map[string]interface{})
+			Expect(service["service"]).To(Equal("clusters_mgmt"))
+			Expect(service["server_version"]).To(Equal("123"))
+			Expect(service["address"]).To(Equal(apiServer.URL()))
+			Expect(service["latency"]).To(BeNumerically(">", 0))
+		})
+
+		It("Returns 503 when not healthy", func() {
+			handler, err := NewCheckHandler().
+				Logger(logger).
+				Connection(connection).
+				Services("clusters_mgmt", "accounts_mgmt").
+				Build()
+			Expect(err).ToNot(HaveOccurred())
+			status, body := serve(handler)
+			Expect(status).To(Equal(http.StatusServiceUnavailable))
+			Expect(body["healthy"]).To(BeFalse())
+		})
+
+		It("Can't be created with unknown services", func() {
+			handler, err := NewCheckHandler().
+				Logger(logger).
+				Connection(connection).
+				Services("junk").
+				Build()
+			Expect(err).To(HaveOccurred())
+			Expect(handler).To(BeNil())
+		})
+	})
+})
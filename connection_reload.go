@@ -0,0 +1,416 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the implementation of the mechanism that reloads the configuration of the
+// connection when the files that it was loaded from change.
+
+package sdk
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/openshift-online/ocm-sdk-go/authentication"
+	"github.com/openshift-online/ocm-sdk-go/internal"
+	"github.com/openshift-online/ocm-sdk-go/logging"
+)
+
+// connectionReloader checks periodically if the files that the configuration of the connection was
+// loaded from have changed, and in that case loads the configuration again and applies it to the
+// connection.
+type connectionReloader struct {
+	logger         logging.Logger
+	connection     *Connection
+	sources        []interface{}
+	baseURLs       map[string]string
+	baseTrustedCAs []interface{}
+	interval       time.Duration
+	reloadMetric   *prometheus.CounterVec
+	lock           *sync.Mutex
+	files          []string
+	fingerprint    string
+	caFingerprint  string
+	stopCh         chan struct{}
+	doneCh         chan struct{}
+}
+
+// createReloader creates the reloader for the given connection. The result will be nil if the
+// configuration wasn't loaded with the Load method, or if it was loaded from sources that can't
+// be read again.
+func (b *ConnectionBuilder) createReloader(ctx context.Context,
+	connection *Connection) (result *connectionReloader, err error) {
+	// Check that the sources can be read again:
+	if b.reloadInterval < 0 {
+		err = fmt.Errorf(
+			"reload interval %s isn't valid, it should be greater or equal than zero",
+			b.reloadInterval,
+		)
+		return
+	}
+	reloadable := len(b.loadSources) > 0
+	for _, source := range b.loadSources {
+		if _, ok := source.(io.Reader); ok {
+			reloadable = false
+			break
+		}
+	}
+	if !reloadable {
+		if b.reloadInterval > 0 {
+			err = fmt.Errorf(
+				"configuration reload requires configuration loaded from files or " +
+					"text with the Load method",
+			)
+		}
+		return
+	}
+
+	// Calculate the URLs and trusted CAs that don't come from the configuration, as those
+	// need to be preserved when the configuration is reloaded:
+	baseURLs := map[string]string{}
+	for prefix, base := range b.urlTable {
+		if !b.loadedURLs[prefix] {
+			baseURLs[prefix] = base
+		}
+	}
+	var baseTrustedCAs []interface{}
+	for _, trustedCA := range b.trustedCAs {
+		file, ok := trustedCA.(string)
+		if ok && b.loadedTrustedCAs[file] {
+			continue
+		}
+		baseTrustedCAs = append(baseTrustedCAs, trustedCA)
+	}
+
+	// Register the metric:
+	var reloadMetric *prometheus.CounterVec
+	if b.metricsSubsystem != "" && b.metricsRegisterer != nil {
+		reloadMetric = prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Subsystem: b.metricsSubsystem,
+				Name:      "configuration_reload_count",
+				Help:      "Number of times that the configuration has been reloaded.",
+			},
+			reloadMetricsLabels,
+		)
+		err = b.metricsRegisterer.Register(reloadMetric)
+		if err != nil {
+			registered, ok := err.(prometheus.AlreadyRegisteredError)
+			if ok {
+				reloadMetric = registered.ExistingCollector.(*prometheus.CounterVec)
+				err = nil
+			} else {
+				return
+			}
+		}
+	}
+
+	// Create the object:
+	result = &connectionReloader{
+		logger:         b.logger,
+		connection:     connection,
+		sources:        b.loadSources,
+		baseURLs:       baseURLs,
+		baseTrustedCAs: baseTrustedCAs,
+		interval:       b.reloadInterval,
+		reloadMetric:   reloadMetric,
+		lock:           &sync.Mutex{},
+	}
+
+	// Calculate the initial fingerprints:
+	_, files, err := readConnectionConfig(b.loadSources...)
+	if err != nil {
+		return
+	}
+	result.files = result.watchedFiles(files, b.trustedCAs)
+	result.fingerprint = fingerprintFiles(result.files)
+	result.caFingerprint = fingerprintTrustedCAs(b.trustedCAs)
+
+	// Start the loop that checks for changes:
+	if result.interval > 0 {
+		result.stopCh = make(chan struct{})
+		result.doneCh = make(chan struct{})
+		go result.run()
+	}
+
+	return
+}
+
+// Reload loads again the configuration that was used to create the connection with the Load
+// method of the builder, and applies the credentials, trusted CAs and URLs to the connection. See
+// the ReloadInterval method of the builder for details. It returns an error if the configuration
+// can't be loaded or isn't valid, and in that case the connection isn't modified.
+func (c *Connection) Reload(ctx context.Context) error {
+	err := c.checkClosed()
+	if err != nil {
+		return err
+	}
+	if c.parent != nil {
+		return c.parent.Reload(ctx)
+	}
+	if c.reloader == nil {
+		return fmt.Errorf(
+			"connection configuration can't be reloaded because it wasn't loaded from " +
+				"files or text with the Load method",
+		)
+	}
+	return c.reloader.reload(ctx, true)
+}
+
+// run checks periodically if the files have changed till the reloader is stopped.
+func (r *connectionReloader) run() {
+	defer close(r.doneCh)
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.stopCh:
+			return
+		case <-ticker.C:
+			// Errors are already written to the log and reflected in the metrics, and the
+			// next attempt will be made in the next tick.
+			_ = r.reload(context.Background(), false)
+		}
+	}
+}
+
+// stop stops the loop that checks for changes and waits till it finishes.
+func (r *connectionReloader) stop() {
+	if r.stopCh == nil {
+		return
+	}
+	close(r.stopCh)
+	<-r.doneCh
+}
+
+// reload loads the configuration again and applies it to the connection, if the files have
+// changed or if the force flag is true.
+func (r *connectionReloader) reload(ctx context.Context, force bool) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	// Check if the files have changed:
+	fingerprint := fingerprintFiles(r.files)
+	if !force && fingerprint == r.fingerprint {
+		return nil
+	}
+
+	// Note that the fingerprint is only updated when the configuration has been applied, so
+	// that if this fails it will be attempted again the next time even if the files don't
+	// change.
+
+	// Load and apply the configuration:
+	config, files, err := readConnectionConfig(r.sources...)
+	if err == nil {
+		err = r.apply(ctx, config)
+	}
+	if err != nil {
+		r.logger.Error(ctx, "Can't reload configuration: %v", err)
+		r.count("failure")
+		return err
+	}
+
+	// Update the list of files to watch, as it may have changed:
+	r.files = r.watchedFiles(files, r.trustedCAs(config))
+	r.fingerprint = fingerprintFiles(r.files)
+	r.logger.Info(ctx, "Reloaded configuration")
+	r.count("success")
+	return nil
+}
+
+// apply applies the configuration to the connection. All the values are calculated and checked
+// before modifying the connection, so that it isn't modified if something fails.
+func (r *connectionReloader) apply(ctx context.Context, config *connectionConfig) error {
+	connection := r.connection
+
+	// Calculate the new URL table. Note that when the connection uses a region the base URL
+	// has been discovered and it shouldn't be replaced.
+	urls := map[string]string{}
+	for prefix, base := range r.baseURLs {
+		urls[prefix] = base
+	}
+	if config.URL != nil {
+		urls[""] = *config.URL
+	}
+	for prefix, base := range config.AlternativeURLs {
+		urls[prefix] = base
+	}
+	if connection.region != "" {
+		urls[""] = connection.URL()
+	}
+	urlTable, err := makeURLTable(ctx, r.logger, urls)
+	if err != nil {
+		return err
+	}
+
+	// Load the trusted CAs, if they have changed:
+	var trustedCAs *x509.CertPool
+	trustedCASources := r.trustedCAs(config)
+	caFingerprint := fingerprintTrustedCAs(trustedCASources)
+	if caFingerprint != r.caFingerprint {
+		trustedCAs, err = internal.LoadTrustedCAs(ctx, r.logger, trustedCASources...)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Replace the credentials:
+	if connection.authnWrapper != nil {
+		credentials := authentication.Credentials{
+			Tokens: config.Tokens,
+		}
+		if config.User != nil {
+			credentials.User = *config.User
+		}
+		if config.Password != nil {
+			credentials.Password = *config.Password
+		}
+		if config.ClientID != nil {
+			credentials.ClientID = *config.ClientID
+		}
+		if config.ClientSecret != nil {
+			credentials.ClientSecret = *config.ClientSecret
+		}
+		err = connection.authnWrapper.Reload(ctx, credentials)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Replace the trusted CAs:
+	if trustedCAs != nil {
+		connection.clientSelector.SetTrustedCAs(ctx, trustedCAs)
+		if connection.authnWrapper != nil {
+			connection.authnWrapper.SetTrustedCAs(ctx, trustedCAs)
+		}
+		r.caFingerprint = caFingerprint
+	}
+
+	// Replace the URL table:
+	connection.urlTableMutex.Lock()
+	connection.urlTable = urlTable
+	connection.urlTableMutex.Unlock()
+
+	return nil
+}
+
+// trustedCAs returns the complete list of trusted CA sources, including the ones that don't come
+// from the given configuration.
+func (r *connectionReloader) trustedCAs(config *connectionConfig) []interface{} {
+	result := make([]interface{}, 0, len(r.baseTrustedCAs)+len(config.TrustedCAs))
+	result = append(result, r.baseTrustedCAs...)
+	for _, trustedCA := range config.TrustedCAs {
+		result = append(result, trustedCA)
+	}
+	return result
+}
+
+// watchedFiles returns the list of files that need to be watched, which are the configuration files
+// and the trusted CA files.
+func (r *connectionReloader) watchedFiles(files []string, trustedCAs []interface{}) []string {
+	result := make([]string, 0, len(files)+len(trustedCAs))
+	result = append(result, files...)
+	for _, trustedCA := range trustedCAs {
+		file, ok := trustedCA.(string)
+		if ok {
+			result = append(result, file)
+		}
+	}
+	return result
+}
+
+// count increments the reload metric, if enabled.
+func (r *connectionReloader) count(result string) {
+	if r.reloadMetric != nil {
+		r.reloadMetric.With(map[string]string{
+			metricsResultLabel: result,
+		}).Inc()
+	}
+}
+
+// fingerprintFiles calculates a fingerprint of the content of the given files. For directories it
+// uses the names of the files that they contain, so that added or removed files are detected.
+// Note that the content is used instead of modification times because files that are mounted
+// from Kubernetes secrets are replaced using symbolic links, and the modification times aren't
+// reliable.
+func fingerprintFiles(files []string) string {
+	hash := sha256.New()
+	for _, file := range files {
+		hash.Write([]byte(file)) // #nosec G104
+		hash.Write([]byte{0})    // #nosec G104
+		info, err := os.Stat(file)
+		switch {
+		case err != nil:
+			hash.Write([]byte("!missing")) // #nosec G104
+		case info.IsDir():
+			entries, err := os.ReadDir(file)
+			if err != nil {
+				hash.Write([]byte("!unreadable")) // #nosec G104
+				break
+			}
+			names := make([]string, len(entries))
+			for i, entry := range entries {
+				names[i] = entry.Name()
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				hash.Write([]byte(name)) // #nosec G104
+				hash.Write([]byte{0})    // #nosec G104
+			}
+		default:
+			data, err := os.ReadFile(file) // #nosec G304
+			if err != nil {
+				hash.Write([]byte("!unreadable")) // #nosec G104
+				break
+			}
+			hash.Write(data) // #nosec G104
+		}
+		hash.Write([]byte{0}) // #nosec G104
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// fingerprintTrustedCAs calculates a fingerprint of the given trusted CA sources, including the
+// content of the files.
+func fingerprintTrustedCAs(trustedCAs []interface{}) string {
+	var files []string
+	for _, trustedCA := range trustedCAs {
+		switch source := trustedCA.(type) {
+		case string:
+			files = append(files, source)
+		default:
+			files = append(files, fmt.Sprintf("!%p", source))
+		}
+	}
+	return fingerprintFiles(files)
+}
+
+// Names of the labels used by the reload metric:
+const metricsResultLabel = "result"
+
+// Labels used by the reload metric:
+var reloadMetricsLabels = []string{
+	metricsResultLabel,
+}
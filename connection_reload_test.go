@@ -0,0 +1,263 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the configuration reload.
+
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/onsi/gomega/ghttp"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"             // nolint
+	. "github.com/onsi/gomega"                         // nolint
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Configuration reload", func() {
+	var ctx context.Context
+	var tmp string
+	var apiServer *ghttp.Server
+
+	// writeFile writes the given content to a file inside the temporary directory, and returns
+	// the complete path.
+	writeFile := func(name, content string) string {
+		path := filepath.Join(tmp, name)
+		err := os.WriteFile(path, []byte(content), 0600)
+		Expect(err).ToNot(HaveOccurred())
+		return path
+	}
+
+	BeforeEach(func() {
+		var err error
+		ctx = context.Background()
+		tmp, err = os.MkdirTemp("", "reload-*")
+		Expect(err).ToNot(HaveOccurred())
+		apiServer = MakeTCPServer()
+	})
+
+	AfterEach(func() {
+		apiServer.Close()
+		err := os.RemoveAll(tmp)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("Reloads tokens when a referenced file changes", func() {
+		// Prepare the configuration:
+		firstToken := MakeTokenString("Bearer", 5*time.Minute)
+		secondToken := MakeTokenString("Bearer", 10*time.Minute)
+		tokenFile := writeFile("token.txt", firstToken)
+		configFile := writeFile("config.yaml", fmt.Sprintf(
+			"url: %s\ntokens:\n- !file/trim %s\n",
+			apiServer.URL(), tokenFile,
+		))
+
+		// Prepare the server so that it saves the authorization headers:
+		var headers []string
+		apiServer.RouteToHandler(
+			http.MethodGet,
+			"/api/clusters_mgmt/v1/clusters",
+			func(w http.ResponseWriter, r *http.Request) {
+				headers = append(headers, r.Header.Get("Authorization"))
+				RespondWithJSON(http.StatusOK, "{}")(w, r)
+			},
+		)
+
+		// Create the connection:
+		connection, err := NewConnectionBuilder().
+			Logger(logger).
+			Load(configFile).
+			ReloadInterval(10 * time.Millisecond).
+			BuildContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		defer func() {
+			err = connection.Close()
+			Expect(err).ToNot(HaveOccurred())
+		}()
+
+		// Send a request with the first token:
+		_, err = connection.Get().Path("/api/clusters_mgmt/v1/clusters").SendContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(headers).To(Equal([]string{"Bearer " + firstToken}))
+
+		// Change the token and wait till it is used:
+		writeFile("token.txt", secondToken)
+		Eventually(func() string {
+			_, err := connection.Get().Path("/api/clusters_mgmt/v1/clusters").SendContext(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			return headers[len(headers)-1]
+		}).Should(Equal("Bearer " + secondToken))
+	})
+
+	It("Reloads alternative URLs", func() {
+		// Prepare the configuration:
+		token := MakeTokenString("Bearer", 5*time.Minute)
+		configFile := writeFile("config.yaml", fmt.Sprintf(
+			"url: %s\nalternative_urls:\n  /api/clusters_mgmt: https://first.example.com\n",
+			apiServer.URL(),
+		))
+
+		// Create the connection:
+		connection, err := NewConnectionBuilder().
+			Logger(logger).
+			Tokens(token).
+			AlternativeURL("/api/accounts_mgmt", "https://accounts.example.com").
+			Load(configFile).
+			BuildContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		defer func() {
+			err = connection.Close()
+			Expect(err).ToNot(HaveOccurred())
+		}()
+		Expect(connection.AlternativeURLs()).To(Equal(map[string]string{
+			"/api/accounts_mgmt": "https://accounts.example.com",
+			"/api/clusters_mgmt": "https://first.example.com",
+		}))
+
+		// Change the configuration and reload it explicitly:
+		writeFile("config.yaml", fmt.Sprintf(
+			"url: %s\nalternative_urls:\n  /api/service_logs: https://second.example.com\n",
+			apiServer.URL(),
+		))
+		err = connection.Reload(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(connection.URL()).To(Equal(apiServer.URL()))
+		Expect(connection.AlternativeURLs()).To(Equal(map[string]string{
+			"/api/accounts_mgmt": "https://accounts.example.com",
+			"/api/service_logs":  "https://second.example.com",
+		}))
+	})
+
+	It("Preserves the configuration and reports failures", func() {
+		// Prepare the configuration:
+		metricsServer := NewMetricsServer()
+		defer metricsServer.Close()
+		token := MakeTokenString("Bearer", 5*time.Minute)
+		configFile := writeFile("config.yaml", fmt.Sprintf(
+			"url: %s\nalternative_urls:\n  /api/clusters_mgmt: https://first.example.com\n",
+			apiServer.URL(),
+		))
+
+		// Create the connection:
+		connection, err := NewConnectionBuilder().
+			Logger(logger).
+			Tokens(token).
+			Load(configFile).
+			MetricsSubsystem("my").
+			MetricsRegisterer(metricsServer.Registry()).
+			BuildContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		defer func() {
+			err = connection.Close()
+			Expect(err).ToNot(HaveOccurred())
+		}()
+
+		// Write a configuration with an invalid URL, and a valid one:
+		writeFile("config.yaml", fmt.Sprintf(
+			"url: %s\nalternative_urls:\n  junk: https://second.example.com\n",
+			apiServer.URL(),
+		))
+		err = connection.Reload(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(connection.AlternativeURLs()).To(Equal(map[string]string{
+			"/api/clusters_mgmt": "https://first.example.com",
+		}))
+		writeFile("config.yaml", fmt.Sprintf(
+			"url: %s\nalternative_urls:\n  /api/clusters_mgmt: https://second.example.com\n",
+			apiServer.URL(),
+		))
+		err = connection.Reload(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(connection.AlternativeURLs()).To(Equal(map[string]string{
+			"/api/clusters_mgmt": "https://second.example.com",
+		}))
+
+		// Check the metrics:
+		metrics := metricsServer.Metrics()
+		Expect(metrics).To(MatchLine(`^my_configuration_reload_count\{result="failure"\} 1$`))
+		Expect(metrics).To(MatchLine(`^my_configuration_reload_count\{result="success"\} 1$`))
+	})
+
+	It("Retries failed reloads even if the files don't change", func() {
+		// Prepare the configuration:
+		metricsServer := NewMetricsServer()
+		defer metricsServer.Close()
+		token := MakeTokenString("Bearer", 5*time.Minute)
+		configFile := writeFile("config.yaml", fmt.Sprintf(
+			"url: %s\n",
+			apiServer.URL(),
+		))
+
+		// Create the connection:
+		connection, err := NewConnectionBuilder().
+			Logger(logger).
+			Tokens(token).
+			Load(configFile).
+			ReloadInterval(10 * time.Millisecond).
+			MetricsSubsystem("my").
+			MetricsRegisterer(metricsServer.Registry()).
+			BuildContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		defer func() {
+			err = connection.Close()
+			Expect(err).ToNot(HaveOccurred())
+		}()
+
+		// Write a configuration with an invalid URL and wait for a few checks:
+		writeFile("config.yaml", fmt.Sprintf(
+			"url: %s\nalternative_urls:\n  junk: https://second.example.com\n",
+			apiServer.URL(),
+		))
+		time.Sleep(200 * time.Millisecond)
+
+		// Check that the reload was attempted more than once:
+		metrics := metricsServer.Metrics()
+		Expect(metrics).To(MatchLine(`^my_configuration_reload_count\{result="failure"\} ([2-9]|\d\d+)$`))
+	})
+
+	It("Can't reload configuration that wasn't loaded", func() {
+		connection, err := NewConnectionBuilder().
+			Logger(logger).
+			URL(apiServer.URL()).
+			Tokens(MakeTokenString("Bearer", 5*time.Minute)).
+			BuildContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		defer func() {
+			err = connection.Close()
+			Expect(err).ToNot(HaveOccurred())
+		}()
+		err = connection.Reload(ctx)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Can't enable reload for configuration loaded from a reader", func() {
+		connection, err := NewConnectionBuilder().
+			Logger(logger).
+			Tokens(MakeTokenString("Bearer", 5*time.Minute)).
+			Load(strings.NewReader("url: " + apiServer.URL())).
+			ReloadInterval(time.Second).
+			BuildContext(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(connection).To(BeNil())
+	})
+})
@@ -0,0 +1,438 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the types used to load the OpenAPI specifications and to validate values
+// against the schemas that they contain.
+
+package contract
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	atv1 "github.com/openshift-online/ocm-sdk-go/accesstransparency/v1"
+	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
+	addonsv1 "github.com/openshift-online/ocm-sdk-go/addonsmgmt/v1"
+	azv1 "github.com/openshift-online/ocm-sdk-go/authorizations/v1"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	cmv2alpha1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v2alpha1"
+	jqv1 "github.com/openshift-online/ocm-sdk-go/jobqueue/v1"
+	osdv1 "github.com/openshift-online/ocm-sdk-go/osdfleetmgmt/v1"
+	slv1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+	smv1 "github.com/openshift-online/ocm-sdk-go/servicemgmt/v1"
+	sbv1 "github.com/openshift-online/ocm-sdk-go/statusboard/v1"
+	wrv1 "github.com/openshift-online/ocm-sdk-go/webrca/v1"
+)
+
+// document contains the parts of an OpenAPI specification that are used for validation.
+type document struct {
+	Paths      map[string]map[string]*operation `json:"paths"`
+	Components struct {
+		Schemas map[string]*schema `json:"schemas"`
+	} `json:"components"`
+}
+
+type operation struct {
+	Parameters  []*parameter         `json:"parameters"`
+	RequestBody *requestBody         `json:"requestBody"`
+	Responses   map[string]*response `json:"responses"`
+}
+
+type parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *schema `json:"schema"`
+}
+
+type requestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*mediaType `json:"content"`
+}
+
+type response struct {
+	Content map[string]*mediaType `json:"content"`
+}
+
+type mediaType struct {
+	Schema *schema `json:"schema"`
+}
+
+type schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 string             `json:"type"`
+	Format               string             `json:"format"`
+	Enum                 []interface{}      `json:"enum"`
+	Items                *schema            `json:"items"`
+	Properties           map[string]*schema `json:"properties"`
+	AdditionalProperties *additional        `json:"additionalProperties"`
+	Required             []string           `json:"required"`
+}
+
+// additional is the value of the `additionalProperties` attribute of a schema, which can be a
+// boolean or a schema.
+type additional struct {
+	allowed bool
+	schema  *schema
+}
+
+// UnmarshalJSON is the implementation of the json.Unmarshaler interface.
+func (a *additional) UnmarshalJSON(data []byte) error {
+	var flag bool
+	err := json.Unmarshal(data, &flag)
+	if err == nil {
+		a.allowed = flag
+		return nil
+	}
+	a.allowed = true
+	a.schema = &schema{}
+	return json.Unmarshal(data, a.schema)
+}
+
+// specification is a set of OpenAPI documents indexed so that the operation corresponding to a
+// request can be found quickly.
+type specification struct {
+	templates []*template
+}
+
+// template is a path of an OpenAPI document, like `/api/clusters_mgmt/v1/clusters/{cluster_id}`,
+// split into segments.
+type template struct {
+	segments   []string
+	literals   int
+	operations map[string]*operation
+	schemas    map[string]*schema
+}
+
+// Cache of the default specification, as loading it is expensive:
+var (
+	defaultSpecificationOnce  sync.Once
+	defaultSpecificationValue *specification
+	defaultSpecificationError error
+)
+
+// defaultDocuments returns the OpenAPI documents embedded in the generated packages.
+func defaultDocuments() [][]byte {
+	return [][]byte{
+		atv1.OpenAPI,
+		amv1.OpenAPI,
+		addonsv1.OpenAPI,
+		azv1.OpenAPI,
+		cmv1.OpenAPI,
+		cmv2alpha1.OpenAPI,
+		jqv1.OpenAPI,
+		osdv1.OpenAPI,
+		slv1.OpenAPI,
+		smv1.OpenAPI,
+		sbv1.OpenAPI,
+		wrv1.OpenAPI,
+	}
+}
+
+// defaultSpecification returns the specification built from the documents embedded in the
+// generated packages.
+func defaultSpecification() (result *specification, err error) {
+	defaultSpecificationOnce.Do(func() {
+		defaultSpecificationValue, defaultSpecificationError = loadSpecification(
+			defaultDocuments(),
+		)
+	})
+	result = defaultSpecificationValue
+	err = defaultSpecificationError
+	return
+}
+
+// loadSpecification parses the given OpenAPI documents and creates the index.
+func loadSpecification(documents [][]byte) (result *specification, err error) {
+	result = &specification{}
+	for i, data := range documents {
+		doc := &document{}
+		err = json.Unmarshal(data, doc)
+		if err != nil {
+			err = fmt.Errorf("can't parse OpenAPI document %d: %w", i, err)
+			result = nil
+			return
+		}
+		for path, operations := range doc.Paths {
+			current := &template{
+				segments:   splitPath(path),
+				operations: map[string]*operation{},
+				schemas:    doc.Components.Schemas,
+			}
+			for _, segment := range current.segments {
+				if !isVariable(segment) {
+					current.literals++
+				}
+			}
+			for method, operation := range operations {
+				current.operations[strings.ToUpper(method)] = operation
+			}
+			result.templates = append(result.templates, current)
+		}
+	}
+
+	// Sort the templates so that the ones with more literal segments are tried first. That
+	// way `/clusters/deleted` is preferred to `/clusters/{cluster_id}`.
+	sort.SliceStable(result.templates, func(i, j int) bool {
+		return result.templates[i].literals > result.templates[j].literals
+	})
+
+	return
+}
+
+// find returns the template that matches the given path, and the values of the path variables.
+func (s *specification) find(path string) (result *template, variables map[string]string) {
+	segments := splitPath(path)
+	for _, current := range s.templates {
+		if len(current.segments) != len(segments) {
+			continue
+		}
+		matched := map[string]string{}
+		for i, segment := range current.segments {
+			if isVariable(segment) {
+				matched[strings.Trim(segment, "{}")] = segments[i]
+				continue
+			}
+			if segment != segments[i] {
+				matched = nil
+				break
+			}
+		}
+		if matched != nil {
+			result = current
+			variables = matched
+			return
+		}
+	}
+	return
+}
+
+// resolve returns the schema that a reference like `#/components/schemas/Cluster` points to.
+func (t *template) resolve(current *schema) *schema {
+	for current != nil && current.Ref != "" {
+		name := strings.TrimPrefix(current.Ref, "#/components/schemas/")
+		current = t.schemas[name]
+	}
+	return current
+}
+
+// response returns the response of the operation for the given status code, or the default
+// response if there is no specific one.
+func (o *operation) response(code int) *response {
+	result, ok := o.Responses[strconv.Itoa(code)]
+	if ok {
+		return result
+	}
+	return o.Responses["default"]
+}
+
+// jsonSchema returns the schema of the `application/json` content, or nil if there is no such
+// content.
+func jsonSchema(content map[string]*mediaType) *schema {
+	media, ok := content["application/json"]
+	if !ok || media == nil {
+		return nil
+	}
+	return media.Schema
+}
+
+// validator validates values against schemas, accumulating the violations that it finds.
+type validator struct {
+	template      *template
+	unknownFields bool
+	violations    []string
+}
+
+// validate checks that the given value, decoded from JSON, matches the given schema. The location
+// is used to build the messages, for example `body.region.id`.
+func (v *validator) validate(location string, value interface{}, current *schema) {
+	current = v.template.resolve(current)
+	if current == nil || value == nil {
+		return
+	}
+	switch current.kind() {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			v.report("%s should be an object but it is %s", location, describe(value))
+			return
+		}
+		for _, name := range current.Required {
+			if _, ok := object[name]; !ok {
+				v.report("%s.%s is required but it is missing", location, name)
+			}
+		}
+		names := make([]string, 0, len(object))
+		for name := range object {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			field := location + "." + name
+			property, ok := current.Properties[name]
+			switch {
+			case ok:
+				v.validate(field, object[name], property)
+			case current.AdditionalProperties != nil && current.AdditionalProperties.schema != nil:
+				v.validate(field, object[name], current.AdditionalProperties.schema)
+			case current.AdditionalProperties != nil && !current.AdditionalProperties.allowed:
+				v.report("%s isn't allowed", field)
+			case current.AdditionalProperties == nil && v.unknownFields &&
+				len(current.Properties) > 0:
+				v.report("%s isn't defined in the specification", field)
+			}
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			v.report("%s should be an array but it is %s", location, describe(value))
+			return
+		}
+		for i, item := range items {
+			v.validate(fmt.Sprintf("%s[%d]", location, i), item, current.Items)
+		}
+	case "string":
+		text, ok := value.(string)
+		if !ok {
+			v.report("%s should be a string but it is %s", location, describe(value))
+			return
+		}
+		if current.Format == "date-time" {
+			_, err := time.Parse(time.RFC3339, text)
+			if err != nil {
+				v.report("%s should be a date and time but it is '%s'", location, text)
+			}
+		}
+		if len(current.Enum) > 0 {
+			for _, allowed := range current.Enum {
+				if allowed == text {
+					return
+				}
+			}
+			v.report("%s has value '%s' which isn't one of the allowed values", location, text)
+		}
+	case "integer":
+		number, ok := value.(float64)
+		if !ok || number != math.Trunc(number) {
+			v.report("%s should be an integer but it is %s", location, describe(value))
+			return
+		}
+		if current.Format == "int32" && (number < math.MinInt32 || number > math.MaxInt32) {
+			v.report("%s has value %v which doesn't fit in 32 bits", location, number)
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			v.report("%s should be a number but it is %s", location, describe(value))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			v.report("%s should be a boolean but it is %s", location, describe(value))
+		}
+	}
+}
+
+// kind returns the type of the schema. Most of the schemas in the specifications don't have an
+// explicit type, so it is derived from the other attributes if needed.
+func (s *schema) kind() string {
+	switch {
+	case s.Type != "":
+		return s.Type
+	case s.Properties != nil || s.AdditionalProperties != nil:
+		return "object"
+	case s.Items != nil:
+		return "array"
+	default:
+		return ""
+	}
+}
+
+// validateText checks that the given text, taken from a path or query parameter, can be converted
+// to the type of the given schema.
+func (v *validator) validateText(location string, text string, current *schema) {
+	current = v.template.resolve(current)
+	if current == nil {
+		return
+	}
+	var err error
+	switch current.kind() {
+	case "integer":
+		bits := 64
+		if current.Format == "int32" {
+			bits = 32
+		}
+		_, err = strconv.ParseInt(text, 10, bits)
+	case "number":
+		_, err = strconv.ParseFloat(text, 64)
+	case "boolean":
+		_, err = strconv.ParseBool(text)
+	}
+	if err != nil {
+		v.report("%s should be of type %s but it is '%s'", location, current.Type, text)
+	}
+}
+
+func (v *validator) report(format string, args ...interface{}) {
+	v.violations = append(v.violations, fmt.Sprintf(format, args...))
+}
+
+// describe returns a short description of the type of a value decoded from JSON, to be used in
+// messages.
+func describe(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "an object"
+	case []interface{}:
+		return "an array"
+	case string:
+		return "a string"
+	case float64:
+		return "a number"
+	case bool:
+		return "a boolean"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// splitPath splits a path into segments, ignoring leading and trailing slashes.
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+// isVariable checks if a segment of a template is a variable, like `{cluster_id}`.
+func isVariable(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
+
+// isJSON checks if the given content type is JSON.
+func isJSON(header http.Header) bool {
+	value := header.Get("Content-Type")
+	if value == "" {
+		return true
+	}
+	return strings.HasPrefix(strings.TrimSpace(strings.ToLower(value)), "application/json")
+}
@@ -0,0 +1,543 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the implementation of a transport wrapper that validates requests and
+// responses against the OpenAPI specifications of the services.
+
+package contract
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/openshift-online/ocm-sdk-go/logging"
+	"github.com/openshift-online/ocm-sdk-go/metrics"
+)
+
+// Mode determines what the wrapper does when it finds a violation of the specification.
+type Mode int
+
+const (
+	// ReportMode writes a warning to the log and updates the metrics, but the request and the
+	// response are processed normally.
+	ReportMode Mode = iota
+
+	// StrictMode makes the call fail with an error of type *Error, in addition to writing the
+	// warning to the log and updating the metrics. Requests that violate the specification
+	// aren't sent to the server.
+	StrictMode
+)
+
+// String returns the name of the mode.
+func (m Mode) String() string {
+	switch m {
+	case ReportMode:
+		return "report"
+	case StrictMode:
+		return "strict"
+	default:
+		return fmt.Sprintf("Mode(%d)", int(m))
+	}
+}
+
+// Directions of violations:
+const (
+	// RequestDirection is used for violations found in requests.
+	RequestDirection = "request"
+
+	// ResponseDirection is used for violations found in responses.
+	ResponseDirection = "response"
+)
+
+// Violation describes a difference between a request or a response and the specification.
+type Violation struct {
+	// Direction is RequestDirection or ResponseDirection.
+	Direction string
+
+	// Method is the HTTP method of the request.
+	Method string
+
+	// Path is the path of the request.
+	Path string
+
+	// Message describes the violation.
+	Message string
+}
+
+// String returns a textual representation of the violation.
+func (v Violation) String() string {
+	return fmt.Sprintf("%s of %s '%s': %s", v.Direction, v.Method, v.Path, v.Message)
+}
+
+// Error is the error returned in strict mode when a request or a response violates the
+// specification.
+type Error struct {
+	// Violations contains the details of the violations that were found.
+	Violations []Violation
+}
+
+// Error is the implementation of the error interface.
+func (e *Error) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		messages[i] = violation.String()
+	}
+	return "contract violation: " + strings.Join(messages, "; ")
+}
+
+// TransportWrapperBuilder contains the data and logic needed to create a new contract validation
+// transport wrapper. The wrapper checks that requests use paths and methods that are defined in
+// the OpenAPI specifications, that path and query parameters have the right types, and that
+// bodies of requests and responses match the schemas. This is intended for tests and canary jobs
+// that need to detect differences between the version of the model used to generate the SDK and
+// the version used by the servers.
+//
+// By default the specifications embedded in the generated packages, like the OpenAPI variable
+// of the clustersmgmt/v1 package, are used.
+//
+// Note that bodies of error responses are only checked to be JSON objects, because the
+// specifications describe the `id` field of errors as an integer while the servers send it as a
+// string.
+//
+// When the metrics subsystem is set the wrapper will generate the following Prometheus metric:
+//
+//	<subsystem>_contract_violation_count - Number of violations of the specifications.
+//
+// This metric will have the `apiservice` label and a `direction` label with the value `request`
+// or `response`.
+//
+// Don't create objects of this type directly; use the NewTransportWrapper function instead.
+type TransportWrapperBuilder struct {
+	logger            logging.Logger
+	mode              Mode
+	documents         [][]byte
+	unknownFields     bool
+	metricsSubsystem  string
+	metricsRegisterer prometheus.Registerer
+}
+
+// TransportWrapper contains the data and logic needed to wrap an HTTP round tripper with another
+// one that validates requests and responses against the OpenAPI specifications.
+type TransportWrapper struct {
+	logger         logging.Logger
+	mode           Mode
+	specification  *specification
+	unknownFields  bool
+	violationCount *prometheus.CounterVec
+}
+
+// roundTripper is a round tripper that validates requests and responses.
+type roundTripper struct {
+	owner     *TransportWrapper
+	transport http.RoundTripper
+}
+
+// Make sure that we implement the interface:
+var _ http.RoundTripper = (*roundTripper)(nil)
+
+// NewTransportWrapper creates a new builder that can then be used to configure and create a new
+// contract validation transport wrapper.
+func NewTransportWrapper() *TransportWrapperBuilder {
+	return &TransportWrapperBuilder{
+		mode:              ReportMode,
+		metricsRegisterer: prometheus.DefaultRegisterer,
+	}
+}
+
+// Logger sets the logger that will be used by the wrapper and by the round trippers that it
+// creates. This is mandatory.
+func (b *TransportWrapperBuilder) Logger(value logging.Logger) *TransportWrapperBuilder {
+	b.logger = value
+	return b
+}
+
+// Mode sets the behaviour of the wrapper when it finds a violation. The default is ReportMode.
+func (b *TransportWrapperBuilder) Mode(value Mode) *TransportWrapperBuilder {
+	b.mode = value
+	return b
+}
+
+// Specifications adds OpenAPI documents, in JSON format, that will be used instead of the ones
+// embedded in the generated packages. This is useful to validate against a version of the model
+// different to the one used to generate the SDK.
+func (b *TransportWrapperBuilder) Specifications(values ...[]byte) *TransportWrapperBuilder {
+	b.documents = append(b.documents, values...)
+	return b
+}
+
+// UnknownFields sets a flag that indicates if fields of objects that aren't defined in the
+// specification should be reported as violations. The default is false, as servers usually add
+// new fields before the SDK is updated and the generated types ignore them.
+func (b *TransportWrapperBuilder) UnknownFields(value bool) *TransportWrapperBuilder {
+	b.unknownFields = value
+	return b
+}
+
+// MetricsSubsystem sets the name of the subsystem that will be used by the wrapper to register
+// metrics with Prometheus. If this isn't explicitly specified, or if it is an empty string, then
+// no metrics will be registered.
+func (b *TransportWrapperBuilder) MetricsSubsystem(value string) *TransportWrapperBuilder {
+	b.metricsSubsystem = value
+	return b
+}
+
+// MetricsRegisterer sets the Prometheus registerer that will be used to register the metrics. The
+// default is to use the default Prometheus registerer and there is usually no need to change that.
+// This is intended for unit tests, where it is convenient to have a registerer that doesn't
+// interfere with the rest of the system.
+func (b *TransportWrapperBuilder) MetricsRegisterer(
+	value prometheus.Registerer) *TransportWrapperBuilder {
+	if value == nil {
+		value = prometheus.DefaultRegisterer
+	}
+	b.metricsRegisterer = value
+	return b
+}
+
+// Build uses the information stored in the builder to create a new transport wrapper.
+func (b *TransportWrapperBuilder) Build(ctx context.Context) (result *TransportWrapper,
+	err error) {
+	// Check parameters:
+	if b.logger == nil {
+		err = fmt.Errorf("logger is mandatory")
+		return
+	}
+	switch b.mode {
+	case ReportMode, StrictMode:
+	default:
+		err = fmt.Errorf(
+			"mode %d isn't valid, it should be report or strict",
+			int(b.mode),
+		)
+		return
+	}
+
+	// Load the specifications:
+	var specification *specification
+	if len(b.documents) > 0 {
+		specification, err = loadSpecification(b.documents)
+	} else {
+		specification, err = defaultSpecification()
+	}
+	if err != nil {
+		return
+	}
+
+	// Register the metrics:
+	var violationCount *prometheus.CounterVec
+	if b.metricsSubsystem != "" {
+		violationCount = prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Subsystem: b.metricsSubsystem,
+				Name:      "contract_violation_count",
+				Help:      "Number of violations of the specifications.",
+			},
+			violationCountLabelNames,
+		)
+		err = b.metricsRegisterer.Register(violationCount)
+		if err != nil {
+			registered, ok := err.(prometheus.AlreadyRegisteredError)
+			if ok {
+				violationCount = registered.ExistingCollector.(*prometheus.CounterVec)
+				err = nil
+			} else {
+				return
+			}
+		}
+	}
+
+	// Create and populate the object:
+	result = &TransportWrapper{
+		logger:         b.logger,
+		mode:           b.mode,
+		specification:  specification,
+		unknownFields:  b.unknownFields,
+		violationCount: violationCount,
+	}
+
+	return
+}
+
+// Wrap creates a new round tripper that wraps the given one and validates requests and responses.
+func (w *TransportWrapper) Wrap(transport http.RoundTripper) http.RoundTripper {
+	return &roundTripper{
+		owner:     w,
+		transport: transport,
+	}
+}
+
+// Mode returns the mode of the wrapper.
+func (w *TransportWrapper) Mode() Mode {
+	return w.mode
+}
+
+// Close releases all the resources used by the wrapper.
+func (w *TransportWrapper) Close() error {
+	return nil
+}
+
+// RoundTrip is the implementation of the round tripper interface.
+func (t *roundTripper) RoundTrip(request *http.Request) (response *http.Response, err error) {
+	ctx := request.Context()
+
+	// Validate the request:
+	template, variables := t.owner.specification.find(request.URL.Path)
+	var operation *operation
+	var messages []string
+	switch {
+	case template == nil:
+		messages = []string{"path isn't defined in the specifications"}
+	default:
+		operation = template.operations[request.Method]
+		if operation == nil {
+			messages = []string{"method isn't defined in the specifications"}
+		} else {
+			messages, err = t.validateRequest(request, template, operation, variables)
+			if err != nil {
+				return
+			}
+		}
+	}
+	err = t.handle(ctx, request, RequestDirection, messages)
+	if err != nil {
+		return
+	}
+
+	// Send the request:
+	response, err = t.transport.RoundTrip(request)
+	if err != nil || operation == nil {
+		return
+	}
+
+	// Validate the response:
+	messages, err = t.validateResponse(response, template, operation)
+	if err != nil {
+		response.Body.Close()
+		response = nil
+		return
+	}
+	err = t.handle(ctx, request, ResponseDirection, messages)
+	if err != nil {
+		response.Body.Close()
+		response = nil
+	}
+	return
+}
+
+// validateRequest checks the parameters and the body of the request.
+func (t *roundTripper) validateRequest(request *http.Request, template *template,
+	operation *operation, variables map[string]string) (messages []string, err error) {
+	checker := &validator{
+		template:      template,
+		unknownFields: t.owner.unknownFields,
+	}
+
+	// Check the parameters:
+	query := request.URL.Query()
+	for _, parameter := range operation.Parameters {
+		switch parameter.In {
+		case "path":
+			value, ok := variables[parameter.Name]
+			if ok {
+				checker.validateText("path parameter '"+parameter.Name+"'", value,
+					parameter.Schema)
+			}
+		case "query":
+			values, ok := query[parameter.Name]
+			if !ok {
+				if parameter.Required {
+					checker.report("query parameter '%s' is required but it is missing",
+						parameter.Name)
+				}
+				continue
+			}
+			for _, value := range values {
+				checker.validateText("query parameter '"+parameter.Name+"'", value,
+					parameter.Schema)
+			}
+		}
+	}
+
+	// Check the body:
+	body, err := readRequestBody(request)
+	if err != nil {
+		return
+	}
+	switch {
+	case operation.RequestBody == nil:
+		if len(body) > 0 {
+			checker.report("body isn't expected")
+		}
+	case len(body) == 0:
+		if operation.RequestBody.Required {
+			checker.report("body is required but it is missing")
+		}
+	case isJSON(request.Header):
+		schema := jsonSchema(operation.RequestBody.Content)
+		if schema != nil {
+			checker.validateJSON(body, schema)
+		}
+	}
+
+	messages = checker.violations
+	return
+}
+
+// validateResponse checks the status code and the body of the response.
+func (t *roundTripper) validateResponse(response *http.Response, template *template,
+	operation *operation) (messages []string, err error) {
+	checker := &validator{
+		template:      template,
+		unknownFields: t.owner.unknownFields,
+	}
+
+	// Check that the status code is defined:
+	definition := operation.response(response.StatusCode)
+	if definition == nil {
+		checker.report("status code %d isn't defined", response.StatusCode)
+		messages = checker.violations
+		return
+	}
+	schema := jsonSchema(definition.Content)
+	if schema == nil || !isJSON(response.Header) {
+		return
+	}
+
+	// Read the body and replace it so that the caller can read it again:
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return
+	}
+	err = response.Body.Close()
+	if err != nil {
+		return
+	}
+	response.Body = io.NopCloser(bytes.NewReader(body))
+	if len(body) == 0 {
+		checker.report("body is required but it is missing")
+		messages = checker.violations
+		return
+	}
+
+	// Error responses are only checked to be objects, see the documentation of the builder for
+	// details:
+	if response.StatusCode >= http.StatusBadRequest {
+		var value interface{}
+		err = json.Unmarshal(body, &value)
+		if err != nil {
+			checker.report("body isn't valid JSON: %v", err)
+		} else if _, ok := value.(map[string]interface{}); !ok {
+			checker.report("body should be an object but it is %s", describe(value))
+		}
+		err = nil
+		messages = checker.violations
+		return
+	}
+
+	checker.validateJSON(body, schema)
+	messages = checker.violations
+	return
+}
+
+// validateJSON decodes the given body and validates it against the given schema.
+func (v *validator) validateJSON(body []byte, schema *schema) {
+	var value interface{}
+	err := json.Unmarshal(body, &value)
+	if err != nil {
+		v.report("body isn't valid JSON: %v", err)
+		return
+	}
+	v.validate("body", value, schema)
+}
+
+// handle logs the given violations and updates the metrics. In strict mode it returns an error
+// if there is at least one violation.
+func (t *roundTripper) handle(ctx context.Context, request *http.Request, direction string,
+	messages []string) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	service := metrics.ServiceName(request.URL.Path)
+	violations := make([]Violation, len(messages))
+	for i, message := range messages {
+		violations[i] = Violation{
+			Direction: direction,
+			Method:    request.Method,
+			Path:      request.URL.Path,
+			Message:   message,
+		}
+		t.owner.logger.Warn(ctx, "Contract violation in %s", violations[i])
+	}
+	if t.owner.violationCount != nil {
+		labels := prometheus.Labels{
+			serviceLabelName:   service,
+			directionLabelName: direction,
+		}
+		t.owner.violationCount.With(labels).Add(float64(len(violations)))
+	}
+	if t.owner.mode == StrictMode {
+		return &Error{
+			Violations: violations,
+		}
+	}
+	return nil
+}
+
+// readRequestBody returns the body of the request, making sure that it can still be sent.
+func readRequestBody(request *http.Request) (result []byte, err error) {
+	if request.Body == nil || request.Body == http.NoBody {
+		return
+	}
+	if request.GetBody != nil {
+		var reader io.ReadCloser
+		reader, err = request.GetBody()
+		if err != nil {
+			return
+		}
+		defer reader.Close()
+		result, err = io.ReadAll(reader)
+		return
+	}
+	result, err = io.ReadAll(request.Body)
+	if err != nil {
+		return
+	}
+	err = request.Body.Close()
+	if err != nil {
+		return
+	}
+	request.Body = io.NopCloser(bytes.NewReader(result))
+	return
+}
+
+// Names of the labels added to metrics:
+const (
+	serviceLabelName   = "apiservice"
+	directionLabelName = "direction"
+)
+
+// Array of labels added to the violation count metric:
+var violationCountLabelNames = []string{
+	serviceLabelName,
+	directionLabelName,
+}
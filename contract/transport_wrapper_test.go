@@ -0,0 +1,431 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the contract validation transport wrapper.
+
+package contract
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"             // nolint
+	. "github.com/onsi/gomega"                         // nolint
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+// fixedTransport returns a transport that answers all the requests with the given status code and
+// body, and that increases the given counter for each request.
+func fixedTransport(calls *int, code int, body string) http.RoundTripper {
+	return TransportFunc(func(request *http.Request) (*http.Response, error) {
+		*calls++
+		return &http.Response{
+			StatusCode: code,
+			Header: http.Header{
+				"Content-Type": []string{"application/json"},
+			},
+			Body:    io.NopCloser(strings.NewReader(body)),
+			Request: request,
+		}, nil
+	})
+}
+
+// readBody reads the complete body of the response and closes it.
+func readBody(response *http.Response) string {
+	defer response.Body.Close()
+	data, err := io.ReadAll(response.Body)
+	Expect(err).ToNot(HaveOccurred())
+	return string(data)
+}
+
+// violations extracts the messages of the violations from the given error, which should be of
+// type *Error.
+func violations(err error) []string {
+	var contractErr *Error
+	Expect(errors.As(err, &contractErr)).To(BeTrue())
+	result := make([]string, len(contractErr.Violations))
+	for i, violation := range contractErr.Violations {
+		result[i] = violation.Message
+	}
+	return result
+}
+
+var _ = Describe("Creation", func() {
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	It("Can't be created without a logger", func() {
+		wrapper, err := NewTransportWrapper().
+			Build(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(wrapper).To(BeNil())
+		message := err.Error()
+		Expect(message).To(ContainSubstring("logger"))
+		Expect(message).To(ContainSubstring("mandatory"))
+	})
+
+	It("Can be created with the defaults", func() {
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			Build(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(wrapper).ToNot(BeNil())
+		Expect(wrapper.Mode()).To(Equal(ReportMode))
+	})
+
+	It("Can't be created with an invalid mode", func() {
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			Mode(Mode(42)).
+			Build(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(wrapper).To(BeNil())
+		Expect(err.Error()).To(ContainSubstring("mode"))
+	})
+
+	It("Can't be created with a specification that isn't valid", func() {
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			Specifications([]byte("junk")).
+			Build(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(wrapper).To(BeNil())
+		Expect(err.Error()).To(ContainSubstring("OpenAPI"))
+	})
+})
+
+var _ = Describe("Strict mode", func() {
+	var ctx context.Context
+	var wrapper *TransportWrapper
+	var calls int
+
+	BeforeEach(func() {
+		var err error
+		ctx = context.Background()
+		calls = 0
+		wrapper, err = NewTransportWrapper().
+			Logger(logger).
+			Mode(StrictMode).
+			Build(ctx)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("Accepts valid request and response", func() {
+		transport := wrapper.Wrap(fixedTransport(&calls, http.StatusOK, `{
+			"kind": "Cluster",
+			"id": "123",
+			"name": "mycluster",
+			"state": "ready",
+			"creation_timestamp": "2024-01-02T03:04:05.123456Z",
+			"nodes": {
+				"compute": 3
+			}
+		}`))
+		request, err := http.NewRequest(
+			http.MethodGet,
+			"https://api.example.com/api/clusters_mgmt/v1/clusters/123",
+			nil,
+		)
+		Expect(err).ToNot(HaveOccurred())
+		response, err := transport.RoundTrip(request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(readBody(response)).To(ContainSubstring("mycluster"))
+		Expect(calls).To(Equal(1))
+	})
+
+	It("Rejects path that doesn't exist without sending it", func() {
+		transport := wrapper.Wrap(fixedTransport(&calls, http.StatusOK, `{}`))
+		request, err := http.NewRequest(
+			http.MethodGet,
+			"https://api.example.com/api/clusters_mgmt/v1/junk",
+			nil,
+		)
+		Expect(err).ToNot(HaveOccurred())
+		response, err := transport.RoundTrip(request)
+		Expect(err).To(HaveOccurred())
+		Expect(response).To(BeNil())
+		Expect(violations(err)).To(ConsistOf(ContainSubstring("path")))
+		Expect(calls).To(BeZero())
+	})
+
+	It("Rejects method that doesn't exist", func() {
+		transport := wrapper.Wrap(fixedTransport(&calls, http.StatusOK, `{}`))
+		request, err := http.NewRequest(
+			http.MethodPut,
+			"https://api.example.com/api/clusters_mgmt/v1/clusters/123",
+			nil,
+		)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = transport.RoundTrip(request)
+		Expect(err).To(HaveOccurred())
+		Expect(violations(err)).To(ConsistOf(ContainSubstring("method")))
+		Expect(calls).To(BeZero())
+	})
+
+	It("Rejects query parameter with wrong type", func() {
+		transport := wrapper.Wrap(fixedTransport(&calls, http.StatusOK, `{}`))
+		request, err := http.NewRequest(
+			http.MethodGet,
+			"https://api.example.com/api/clusters_mgmt/v1/clusters?size=junk",
+			nil,
+		)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = transport.RoundTrip(request)
+		Expect(err).To(HaveOccurred())
+		Expect(violations(err)).To(ConsistOf(
+			"query parameter 'size' should be of type integer but it is 'junk'",
+		))
+	})
+
+	It("Rejects request body that doesn't match the schema", func() {
+		transport := wrapper.Wrap(fixedTransport(&calls, http.StatusCreated, `{}`))
+		request, err := http.NewRequest(
+			http.MethodPost,
+			"https://api.example.com/api/clusters_mgmt/v1/clusters",
+			strings.NewReader(`{
+				"name": 123,
+				"region": {
+					"id": true
+				}
+			}`),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		request.Header.Set("Content-Type", "application/json")
+		_, err = transport.RoundTrip(request)
+		Expect(err).To(HaveOccurred())
+		Expect(violations(err)).To(ConsistOf(
+			"body.name should be a string but it is a number",
+			"body.region.id should be a string but it is a boolean",
+		))
+		Expect(calls).To(BeZero())
+	})
+
+	It("Sends the complete request body", func() {
+		var received string
+		transport := wrapper.Wrap(TransportFunc(
+			func(request *http.Request) (*http.Response, error) {
+				data, err := io.ReadAll(request.Body)
+				Expect(err).ToNot(HaveOccurred())
+				received = string(data)
+				return &http.Response{
+					StatusCode: http.StatusCreated,
+					Header: http.Header{
+						"Content-Type": []string{"application/json"},
+					},
+					Body:    io.NopCloser(strings.NewReader(`{"id": "123"}`)),
+					Request: request,
+				}, nil
+			},
+		))
+		request, err := http.NewRequest(
+			http.MethodPost,
+			"https://api.example.com/api/clusters_mgmt/v1/clusters",
+			strings.NewReader(`{"name": "mycluster"}`),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		response, err := transport.RoundTrip(request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(readBody(response)).To(MatchJSON(`{"id": "123"}`))
+		Expect(received).To(MatchJSON(`{"name": "mycluster"}`))
+	})
+
+	It("Rejects response body that doesn't match the schema", func() {
+		transport := wrapper.Wrap(fixedTransport(&calls, http.StatusOK, `{
+			"id": "123",
+			"state": "junk",
+			"creation_timestamp": "yesterday"
+		}`))
+		request, err := http.NewRequest(
+			http.MethodGet,
+			"https://api.example.com/api/clusters_mgmt/v1/clusters/123",
+			nil,
+		)
+		Expect(err).ToNot(HaveOccurred())
+		response, err := transport.RoundTrip(request)
+		Expect(err).To(HaveOccurred())
+		Expect(response).To(BeNil())
+		Expect(violations(err)).To(ConsistOf(
+			"body.creation_timestamp should be a date and time but it is 'yesterday'",
+			"body.state has value 'junk' which isn't one of the allowed values",
+		))
+		Expect(calls).To(Equal(1))
+	})
+
+	It("Accepts error responses sent by the servers", func() {
+		transport := wrapper.Wrap(fixedTransport(&calls, http.StatusNotFound, `{
+			"kind": "Error",
+			"id": "404",
+			"href": "/api/clusters_mgmt/v1/errors/404",
+			"code": "CLUSTERS-MGMT-404",
+			"reason": "Cluster '123' not found"
+		}`))
+		request, err := http.NewRequest(
+			http.MethodGet,
+			"https://api.example.com/api/clusters_mgmt/v1/clusters/123",
+			nil,
+		)
+		Expect(err).ToNot(HaveOccurred())
+		response, err := transport.RoundTrip(request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(http.StatusNotFound))
+		Expect(readBody(response)).To(ContainSubstring("CLUSTERS-MGMT-404"))
+	})
+
+	It("Ignores unknown fields by default", func() {
+		transport := wrapper.Wrap(fixedTransport(&calls, http.StatusOK, `{
+			"id": "123",
+			"junk": "value"
+		}`))
+		request, err := http.NewRequest(
+			http.MethodGet,
+			"https://api.example.com/api/clusters_mgmt/v1/clusters/123",
+			nil,
+		)
+		Expect(err).ToNot(HaveOccurred())
+		response, err := transport.RoundTrip(request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(readBody(response)).To(ContainSubstring("junk"))
+	})
+
+	It("Reports unknown fields if requested", func() {
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			Mode(StrictMode).
+			UnknownFields(true).
+			Build(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		transport := wrapper.Wrap(fixedTransport(&calls, http.StatusOK, `{
+			"id": "123",
+			"junk": "value"
+		}`))
+		request, err := http.NewRequest(
+			http.MethodGet,
+			"https://api.example.com/api/clusters_mgmt/v1/clusters/123",
+			nil,
+		)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = transport.RoundTrip(request)
+		Expect(err).To(HaveOccurred())
+		Expect(violations(err)).To(ConsistOf(
+			"body.junk isn't defined in the specification",
+		))
+	})
+
+	It("Uses explicitly given specifications", func() {
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			Mode(StrictMode).
+			Specifications([]byte(`{
+				"paths": {
+					"/api/my/v1/things/{id}": {
+						"get": {
+							"responses": {
+								"200": {
+									"content": {
+										"application/json": {
+											"schema": {
+												"$ref": "#/components/schemas/Thing"
+											}
+										}
+									}
+								}
+							}
+						}
+					}
+				},
+				"components": {
+					"schemas": {
+						"Thing": {
+							"type": "object",
+							"required": ["id"],
+							"properties": {
+								"id": {
+									"type": "string"
+								}
+							}
+						}
+					}
+				}
+			}`)).
+			Build(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		transport := wrapper.Wrap(fixedTransport(&calls, http.StatusOK, `{}`))
+		request, err := http.NewRequest(
+			http.MethodGet,
+			"https://api.example.com/api/my/v1/things/123",
+			nil,
+		)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = transport.RoundTrip(request)
+		Expect(err).To(HaveOccurred())
+		Expect(violations(err)).To(ConsistOf(
+			"body.id is required but it is missing",
+		))
+		Expect(err.Error()).To(Equal(
+			"contract violation: response of GET '/api/my/v1/things/123': " +
+				"body.id is required but it is missing",
+		))
+	})
+})
+
+var _ = Describe("Report mode", func() {
+	var ctx context.Context
+	var metricsServer *MetricsServer
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		metricsServer = NewMetricsServer()
+	})
+
+	AfterEach(func() {
+		metricsServer.Close()
+	})
+
+	It("Returns the response and updates the metrics", func() {
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			MetricsSubsystem("my").
+			MetricsRegisterer(metricsServer.Registry()).
+			Build(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		calls := 0
+		transport := wrapper.Wrap(fixedTransport(&calls, http.StatusOK, `{
+			"id": 123,
+			"state": "junk"
+		}`))
+		request, err := http.NewRequest(
+			http.MethodGet,
+			"https://api.example.com/api/clusters_mgmt/v1/clusters/123?junk=true",
+			nil,
+		)
+		Expect(err).ToNot(HaveOccurred())
+		response, err := transport.RoundTrip(request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(readBody(response)).To(MatchJSON(`{
+			"id": 123,
+			"state": "junk"
+		}`))
+		metrics := metricsServer.Metrics()
+		Expect(metrics).To(MatchLine(`^my_contract_violation_count\{apiservice="ocm-clusters-service",direction="response"\} 2$`))
+		Expect(metrics).ToNot(MatchLine(`^my_contract_violation_count\{.*direction="request".*$`))
+	})
+})
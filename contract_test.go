@@ -0,0 +1,106 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the contract validation support of the connection.
+
+package sdk
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/openshift-online/ocm-sdk-go/contract"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"             // nolint
+	. "github.com/onsi/gomega"                         // nolint
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Contract validation", func() {
+	var ctx context.Context
+	var server *FakeServer
+	var connection *Connection
+
+	BeforeEach(func() {
+		var err error
+
+		// Create the context:
+		ctx = context.Background()
+
+		// Create the server:
+		server, err = NewFakeServer().Build()
+		Expect(err).ToNot(HaveOccurred())
+
+		// Create the connection:
+		connection, err = NewConnectionBuilder().
+			Logger(logger).
+			URL(server.URL()).
+			Tokens(MakeTokenString("Bearer", 5*time.Minute)).
+			ContractValidation(true).
+			ContractValidationMode(contract.StrictMode).
+			BuildContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		err := connection.Close()
+		Expect(err).ToNot(HaveOccurred())
+		server.Close()
+	})
+
+	It("Accepts requests and responses that match the specification", func() {
+		// Create a cluster:
+		object, err := cmv1.NewCluster().
+			Name("mycluster").
+			Region(cmv1.NewCloudRegion().ID("us-east-1")).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		clusters := connection.ClustersMgmt().V1().Clusters()
+		addResponse, err := clusters.Add().Body(object).SendContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		id := addResponse.Body().ID()
+
+		// List and get the clusters:
+		listResponse, err := clusters.List().
+			Search("name = 'mycluster'").
+			SendContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(listResponse.Total()).To(Equal(1))
+		getResponse, err := clusters.Cluster(id).Get().SendContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(getResponse.Body().Name()).To(Equal("mycluster"))
+
+		// Errors sent by the server are also accepted:
+		getResponse, err = clusters.Cluster("junk").Get().SendContext(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(getResponse.Status()).To(Equal(http.StatusNotFound))
+	})
+
+	It("Rejects requests that don't match the specification", func() {
+		_, err := connection.Get().
+			Path("/api/clusters_mgmt/v1/clusters").
+			Parameter("page", "junk").
+			SendContext(ctx)
+		Expect(err).To(HaveOccurred())
+		var contractErr *contract.Error
+		Expect(errors.As(err, &contractErr)).To(BeTrue())
+		Expect(contractErr.Violations).To(HaveLen(1))
+		Expect(contractErr.Violations[0].Direction).To(Equal(contract.RequestDirection))
+	})
+})
@@ -0,0 +1,342 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the implementation of the differ, that compares two objects of the same
+// generated type and calculates the minimal patch that transforms one into the other.
+
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+)
+
+// DefaultIgnored contains the fields that are ignored by default, because they identify the object
+// and can't be changed with an update request.
+var DefaultIgnored = []string{
+	"kind",
+	"id",
+	"href",
+}
+
+// DifferBuilder contains the data and logic needed to create a differ. Don't create objects of
+// this type directly, use the NewDiffer function instead.
+type DifferBuilder[T any] struct {
+	marshal   func(object T, writer io.Writer) error
+	unmarshal func(source interface{}) (T, error)
+	ignored   []string
+	removals  bool
+}
+
+// Differ compares objects of one of the generated types, for example *cmv1.Cluster, and calculates
+// the changes and the minimal JSON merge patch needed to transform one into the other. The
+// comparison is done using the JSON representation generated by the marshalling functions, which
+// only contains the fields that are present according to the presence bitmap of the object.
+//
+// By default fields that are present in the original object but not in the modified one are
+// considered unchanged, because builders can't remove fields: a modified object that only
+// contains the fields to change produces the same patch than a complete copy of the original
+// with those fields changed. Use the Removals method of the builder to consider them removed
+// instead.
+//
+// Differs don't keep state between calls to the Compare method, so they can be reused and used
+// concurrently.
+type Differ[T any] struct {
+	marshal   func(object T, writer io.Writer) error
+	unmarshal func(source interface{}) (T, error)
+	ignored   [][]string
+	removals  bool
+}
+
+// NewDiffer creates a builder that can then be used to configure and create a differ. The type
+// parameter is the type of the objects that will be compared. For example, to change the number
+// of compute nodes of a cluster sending only the fields that changed:
+//
+//	differ, err := diff.NewDiffer[*cmv1.Cluster]().
+//		Marshal(cmv1.MarshalCluster).
+//		Unmarshal(cmv1.UnmarshalCluster).
+//		Build()
+//	if err != nil {
+//		return err
+//	}
+//	resource := connection.ClustersMgmt().V1().Clusters().Cluster(id)
+//	getResponse, err := resource.Get().SendContext(ctx)
+//	if err != nil {
+//		return err
+//	}
+//	original := getResponse.Body()
+//	modified, err := cmv1.NewCluster().
+//		Copy(original).
+//		Nodes(cmv1.NewClusterNodes().Copy(original.Nodes()).Compute(10)).
+//		Build()
+//	if err != nil {
+//		return err
+//	}
+//	result, err := differ.Compare(original, modified)
+//	if err != nil {
+//		return err
+//	}
+//	if result.Empty() {
+//		return nil
+//	}
+//	fmt.Print(result)
+//	body, err := result.Object()
+//	if err != nil {
+//		return err
+//	}
+//	_, err = resource.Update().Body(body).SendContext(ctx)
+func NewDiffer[T any]() *DifferBuilder[T] {
+	return &DifferBuilder[T]{
+		ignored: append([]string(nil), DefaultIgnored...),
+	}
+}
+
+// Marshal sets the function that will be used to convert the objects to JSON, for example
+// cmv1.MarshalCluster. This is mandatory.
+func (b *DifferBuilder[T]) Marshal(value func(object T, writer io.Writer) error) *DifferBuilder[T] {
+	b.marshal = value
+	return b
+}
+
+// Unmarshal sets the function that will be used to convert patches back to objects, for example
+// cmv1.UnmarshalCluster. This is only needed for the Object method of the result.
+func (b *DifferBuilder[T]) Unmarshal(value func(source interface{}) (T, error)) *DifferBuilder[T] {
+	b.unmarshal = value
+	return b
+}
+
+// Ignore adds fields that will be ignored when comparing objects, typically read only fields like
+// `creation_timestamp` or `status`. Nested fields are separated by dots, for example
+// `aws.sts.role_arn`. The `kind`, `id` and `href` fields are always ignored.
+func (b *DifferBuilder[T]) Ignore(values ...string) *DifferBuilder[T] {
+	b.ignored = append(b.ignored, values...)
+	return b
+}
+
+// Removals sets the flag that indicates if fields that are present in the original object and not
+// in the modified one should be considered removed. When enabled they will be set to null in the
+// merge patch. The default is false, which means that those fields are considered unchanged.
+func (b *DifferBuilder[T]) Removals(value bool) *DifferBuilder[T] {
+	b.removals = value
+	return b
+}
+
+// Build uses the information stored in the builder to create a new differ.
+func (b *DifferBuilder[T]) Build() (result *Differ[T], err error) {
+	// Check parameters:
+	if b.marshal == nil {
+		err = fmt.Errorf("marshal function is mandatory")
+		return
+	}
+
+	// Split the ignored paths:
+	ignored := make([][]string, len(b.ignored))
+	for i, path := range b.ignored {
+		if path == "" {
+			err = fmt.Errorf("ignored field %d is empty", i)
+			return
+		}
+		ignored[i] = strings.Split(path, ".")
+	}
+
+	// Create and populate the object:
+	result = &Differ[T]{
+		marshal:   b.marshal,
+		unmarshal: b.unmarshal,
+		ignored:   ignored,
+		removals:  b.removals,
+	}
+	return
+}
+
+// Compare calculates the changes needed to transform the original object into the modified one.
+func (d *Differ[T]) Compare(original, modified T) (result *Result[T], err error) {
+	// Convert the objects to JSON and remove the ignored fields:
+	originalData, err := d.document(original)
+	if err != nil {
+		err = fmt.Errorf("can't convert original object to JSON: %w", err)
+		return
+	}
+	modifiedData, err := d.document(modified)
+	if err != nil {
+		err = fmt.Errorf("can't convert modified object to JSON: %w", err)
+		return
+	}
+
+	// When removals aren't considered the fields that aren't present in the modified object
+	// are taken from the original one:
+	if !d.removals {
+		modifiedData, err = jsonpatch.MergePatch(originalData, modifiedData)
+		if err != nil {
+			return
+		}
+	}
+
+	// Calculate the patch and the list of changes. Note that we don't use the CreateMergePatch
+	// function of the JSON patch library because it decodes numbers as float64, and that
+	// loses precision for integers larger than 2^53.
+	originalMap, err := decodeDocument(originalData)
+	if err != nil {
+		return
+	}
+	modifiedMap, err := decodeDocument(modifiedData)
+	if err != nil {
+		return
+	}
+	patchMap := createMergePatch(originalMap, modifiedMap)
+	patch, err := json.Marshal(patchMap)
+	if err != nil {
+		return
+	}
+	changes := []Change{}
+	changes = collectChanges(changes, nil, originalMap, patchMap)
+
+	// Create and populate the object:
+	result = &Result[T]{
+		unmarshal: d.unmarshal,
+		changes:   changes,
+		patch:     patch,
+	}
+	return
+}
+
+// document converts the given object to JSON and removes the ignored fields.
+func (d *Differ[T]) document(object T) (result []byte, err error) {
+	buffer := &bytes.Buffer{}
+	err = d.marshal(object, buffer)
+	if err != nil {
+		return
+	}
+	data, err := decodeDocument(buffer.Bytes())
+	if err != nil {
+		return
+	}
+	for _, path := range d.ignored {
+		removeField(data, path)
+	}
+	result, err = json.Marshal(data)
+	return
+}
+
+// decodeDocument decodes a JSON object into a map. Numbers are decoded as json.Number, so that
+// comparing and writing them again doesn't lose precision.
+func decodeDocument(data []byte) (result map[string]interface{}, err error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	err = decoder.Decode(&result)
+	if err != nil {
+		return
+	}
+	if result == nil {
+		result = map[string]interface{}{}
+	}
+	return
+}
+
+// createMergePatch calculates the merge patch, as defined in RFC 7386, that transforms the
+// original object into the modified one.
+func createMergePatch(original, modified map[string]interface{}) map[string]interface{} {
+	result := map[string]interface{}{}
+	for name, modifiedValue := range modified {
+		originalValue, present := original[name]
+		if !present {
+			result[name] = modifiedValue
+			continue
+		}
+		originalObject, originalOk := originalValue.(map[string]interface{})
+		modifiedObject, modifiedOk := modifiedValue.(map[string]interface{})
+		if originalOk && modifiedOk {
+			nested := createMergePatch(originalObject, modifiedObject)
+			if len(nested) > 0 {
+				result[name] = nested
+			}
+			continue
+		}
+		if !reflect.DeepEqual(originalValue, modifiedValue) {
+			result[name] = modifiedValue
+		}
+	}
+	for name := range original {
+		_, present := modified[name]
+		if !present {
+			result[name] = nil
+		}
+	}
+	return result
+}
+
+// removeField removes the field with the given path from the given JSON object.
+func removeField(data map[string]interface{}, path []string) {
+	if len(path) == 1 {
+		delete(data, path[0])
+		return
+	}
+	child, ok := data[path[0]].(map[string]interface{})
+	if ok {
+		removeField(child, path[1:])
+	}
+}
+
+// collectChanges walks the given merge patch and adds to the list the changes that it makes to
+// the given original object.
+func collectChanges(changes []Change, path []string, original,
+	patch map[string]interface{}) []Change {
+	names := make([]string, 0, len(patch))
+	for name := range patch {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		current := append(append([]string(nil), path...), name)
+		newValue := patch[name]
+		oldValue, present := original[name]
+		switch {
+		case newValue == nil:
+			changes = append(changes, Change{
+				Type:     RemovedChange,
+				Path:     strings.Join(current, "."),
+				OldValue: oldValue,
+			})
+		case !present:
+			changes = append(changes, Change{
+				Type:     AddedChange,
+				Path:     strings.Join(current, "."),
+				NewValue: newValue,
+			})
+		default:
+			oldObject, oldOk := oldValue.(map[string]interface{})
+			newObject, newOk := newValue.(map[string]interface{})
+			if oldOk && newOk {
+				changes = collectChanges(changes, current, oldObject, newObject)
+				continue
+			}
+			changes = append(changes, Change{
+				Type:     ModifiedChange,
+				Path:     strings.Join(current, "."),
+				OldValue: oldValue,
+				NewValue: newValue,
+			})
+		}
+	}
+	return changes
+}
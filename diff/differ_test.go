@@ -0,0 +1,225 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the differ.
+
+package diff
+
+import (
+	"encoding/json"
+	"time"
+
+	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core" // nolint
+	. "github.com/onsi/gomega"             // nolint
+)
+
+var _ = Describe("Differ", func() {
+	var differ *Differ[*cmv1.Cluster]
+	var original *cmv1.Cluster
+
+	BeforeEach(func() {
+		var err error
+		differ, err = NewDiffer[*cmv1.Cluster]().
+			Marshal(cmv1.MarshalCluster).
+			Unmarshal(cmv1.UnmarshalCluster).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		original, err = cmv1.NewCluster().
+			ID("123").
+			HREF("/api/clusters_mgmt/v1/clusters/123").
+			Name("mycluster").
+			State(cmv1.ClusterStateReady).
+			CreationTimestamp(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)).
+			Nodes(cmv1.NewClusterNodes().Compute(3)).
+			Properties(map[string]string{
+				"owner": "alice",
+				"team":  "blue",
+			}).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("Can't be created without a marshal function", func() {
+		_, err := NewDiffer[*cmv1.Cluster]().Build()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("marshal"))
+	})
+
+	It("Finds no changes in copies", func() {
+		modified, err := cmv1.NewCluster().Copy(original).Build()
+		Expect(err).ToNot(HaveOccurred())
+		result, err := differ.Compare(original, modified)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Empty()).To(BeTrue())
+		Expect(result.String()).To(BeEmpty())
+		Expect(result.MergePatch()).To(MatchJSON(`{}`))
+	})
+
+	It("Generates a minimal patch for nested fields", func() {
+		modified, err := cmv1.NewCluster().
+			Copy(original).
+			Nodes(cmv1.NewClusterNodes().Copy(original.Nodes()).Compute(10)).
+			Properties(map[string]string{
+				"owner": "bob",
+				"team":  "blue",
+				"cost":  "low",
+			}).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		result, err := differ.Compare(original, modified)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.MergePatch()).To(MatchJSON(`{
+			"nodes": {
+				"compute": 10
+			},
+			"properties": {
+				"cost": "low",
+				"owner": "bob"
+			}
+		}`))
+		Expect(result.String()).To(Equal(
+			"~ nodes.compute: 3 -> 10\n" +
+				"+ properties.cost: \"low\"\n" +
+				"~ properties.owner: \"alice\" -> \"bob\"\n",
+		))
+		changes := result.Changes()
+		Expect(changes).To(HaveLen(3))
+		Expect(changes[0].Type).To(Equal(ModifiedChange))
+		Expect(changes[0].Path).To(Equal("nodes.compute"))
+		Expect(changes[0].OldValue).To(Equal(json.Number("3")))
+		Expect(changes[0].NewValue).To(Equal(json.Number("10")))
+	})
+
+	It("Doesn't lose precision of large integers", func() {
+		original, err := cmv1.NewCluster().
+			Nodes(cmv1.NewClusterNodes().Compute(9007199254740992)).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		modified, err := cmv1.NewCluster().
+			Nodes(cmv1.NewClusterNodes().Compute(9007199254740993)).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		result, err := differ.Compare(original, modified)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Empty()).To(BeFalse())
+		Expect(result.String()).To(Equal(
+			"~ nodes.compute: 9007199254740992 -> 9007199254740993\n",
+		))
+		Expect(string(result.MergePatch())).To(Equal(`{"nodes":{"compute":9007199254740993}}`))
+	})
+
+	It("Generates an object suitable for update requests", func() {
+		modified, err := cmv1.NewCluster().
+			Copy(original).
+			Name("yourcluster").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		result, err := differ.Compare(original, modified)
+		Expect(err).ToNot(HaveOccurred())
+		object, err := result.Object()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(object.Name()).To(Equal("yourcluster"))
+		_, ok := object.GetID()
+		Expect(ok).To(BeFalse())
+		_, ok = object.GetState()
+		Expect(ok).To(BeFalse())
+		_, ok = object.GetCreationTimestamp()
+		Expect(ok).To(BeFalse())
+	})
+
+	It("Considers fields missing in the modified object unchanged", func() {
+		modified, err := cmv1.NewCluster().
+			Name("yourcluster").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		result, err := differ.Compare(original, modified)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.MergePatch()).To(MatchJSON(`{
+			"name": "yourcluster"
+		}`))
+	})
+
+	It("Reports removals when enabled", func() {
+		differ, err := NewDiffer[*cmv1.Cluster]().
+			Marshal(cmv1.MarshalCluster).
+			Unmarshal(cmv1.UnmarshalCluster).
+			Ignore("state", "creation_timestamp").
+			Removals(true).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		modified, err := cmv1.NewCluster().
+			Name("mycluster").
+			Nodes(cmv1.NewClusterNodes().Compute(3)).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		result, err := differ.Compare(original, modified)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.MergePatch()).To(MatchJSON(`{
+			"properties": null
+		}`))
+		Expect(result.String()).To(Equal(
+			"- properties: {\"owner\":\"alice\",\"team\":\"blue\"}\n",
+		))
+		_, err = result.Object()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("properties"))
+	})
+
+	It("Ignores the configured fields", func() {
+		differ, err := NewDiffer[*cmv1.Cluster]().
+			Marshal(cmv1.MarshalCluster).
+			Ignore("nodes.compute").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		modified, err := cmv1.NewCluster().
+			Copy(original).
+			ID("456").
+			Nodes(cmv1.NewClusterNodes().Compute(10)).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		result, err := differ.Compare(original, modified)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Empty()).To(BeTrue())
+	})
+
+	It("Works with other generated types", func() {
+		differ, err := NewDiffer[*amv1.Subscription]().
+			Marshal(amv1.MarshalSubscription).
+			Unmarshal(amv1.UnmarshalSubscription).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		original, err := amv1.NewSubscription().
+			ID("123").
+			DisplayName("mysubscription").
+			Managed(false).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		modified, err := amv1.NewSubscription().
+			Copy(original).
+			Managed(true).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		result, err := differ.Compare(original, modified)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.String()).To(Equal("~ managed: false -> true\n"))
+		object, err := result.Object()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(object.Managed()).To(BeTrue())
+	})
+})
@@ -0,0 +1,143 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the types used to return the result of comparing two objects.
+
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ChangeType indicates how a field has changed.
+type ChangeType string
+
+const (
+	// AddedChange means that the field isn't present in the original object.
+	AddedChange ChangeType = "added"
+
+	// ModifiedChange means that the field is present in both objects, with different values.
+	ModifiedChange ChangeType = "modified"
+
+	// RemovedChange means that the field isn't present in the modified object. These changes are
+	// only generated when removals have been enabled in the differ.
+	RemovedChange ChangeType = "removed"
+)
+
+// Change describes the change of one field.
+type Change struct {
+	// Type indicates if the field has been added, modified or removed.
+	Type ChangeType
+
+	// Path is the path of the field, using the JSON names and with nested fields separated by
+	// dots, for example `nodes.compute`.
+	Path string
+
+	// OldValue is the value of the field in the original object, as decoded by the
+	// encoding/json package with numbers decoded as json.Number. It is nil for added fields.
+	OldValue interface{}
+
+	// NewValue is the value of the field in the modified object, as decoded by the
+	// encoding/json package with numbers decoded as json.Number. It is nil for removed fields.
+	NewValue interface{}
+}
+
+// String generates a human readable representation of the change, for example
+// `~ nodes.compute: 3 -> 10`.
+func (c Change) String() string {
+	switch c.Type {
+	case AddedChange:
+		return fmt.Sprintf("+ %s: %s", c.Path, formatValue(c.NewValue))
+	case RemovedChange:
+		return fmt.Sprintf("- %s: %s", c.Path, formatValue(c.OldValue))
+	default:
+		return fmt.Sprintf(
+			"~ %s: %s -> %s",
+			c.Path, formatValue(c.OldValue), formatValue(c.NewValue),
+		)
+	}
+}
+
+// Result contains the result of comparing two objects. Don't create objects of this type
+// directly, use the Compare method of the differ instead.
+type Result[T any] struct {
+	unmarshal func(source interface{}) (T, error)
+	changes   []Change
+	patch     []byte
+}
+
+// Empty returns true if there are no changes.
+func (r *Result[T]) Empty() bool {
+	return len(r.changes) == 0
+}
+
+// Changes returns the list of changes, sorted by path. The caller must not modify the returned
+// slice.
+func (r *Result[T]) Changes() []Change {
+	return r.changes
+}
+
+// String generates a human readable representation of the changes, one per line.
+func (r *Result[T]) String() string {
+	buffer := &strings.Builder{}
+	for _, change := range r.changes {
+		buffer.WriteString(change.String())
+		buffer.WriteString("\n")
+	}
+	return buffer.String()
+}
+
+// MergePatch returns the JSON merge patch, as described in RFC 7386, that transforms the original
+// object into the modified one. It only contains the fields that changed. It will be an empty JSON
+// object if there are no changes.
+func (r *Result[T]) MergePatch() []byte {
+	return r.patch
+}
+
+// Object returns an object that only contains the fields that changed, suitable for the body of
+// an update request. It fails if the differ was created without an unmarshal function, or if
+// there are removed fields, because objects can't represent them. In that case use the merge patch
+// instead.
+func (r *Result[T]) Object() (result T, err error) {
+	if r.unmarshal == nil {
+		err = fmt.Errorf("can't create object because the differ has no unmarshal function")
+		return
+	}
+	for _, change := range r.changes {
+		if change.Type == RemovedChange {
+			err = fmt.Errorf(
+				"can't create object because field '%s' has been removed, use the "+
+					"merge patch instead",
+				change.Path,
+			)
+			return
+		}
+	}
+	result, err = r.unmarshal(r.patch)
+	return
+}
+
+// formatValue converts the given value to JSON, or to the text generated by the fmt package if
+// that fails.
+func formatValue(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
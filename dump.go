@@ -25,29 +25,49 @@ import (
 	"io"
 	"mime"
 	"net/http"
-	"net/url"
 	"sort"
 	"strings"
 
-	jsoniter "github.com/json-iterator/go"
-
-	"github.com/openshift-online/ocm-sdk-go/helpers"
+	"github.com/openshift-online/ocm-sdk-go/internal"
 	"github.com/openshift-online/ocm-sdk-go/logging"
 )
 
 // dumpTransportWrapper is a transport wrapper that creates round trippers that dump the details of
-// the request and the responses to the log.
+// the request and the responses to the log, if the debug level is enabled, and to the HAR
+// recorder, if it has been configured.
 type dumpTransportWrapper struct {
 	logger logging.Logger
+	har    *harRecorder
 }
 
 // Wrap creates a round tripper on top of the given one that sends to the log the details of
 // requests and responses.
 func (w *dumpTransportWrapper) Wrap(transport http.RoundTripper) http.RoundTripper {
-	return &dumpRoundTripper{
-		logger: w.logger,
-		next:   transport,
+	return w.wrap(transport, false)
+}
+
+// WrapToken is like Wrap, but for the round trippers used to request tokens. The only difference is
+// that the HAR entries generated are marked as token requests.
+func (w *dumpTransportWrapper) WrapToken(transport http.RoundTripper) http.RoundTripper {
+	return w.wrap(transport, true)
+}
+
+func (w *dumpTransportWrapper) wrap(transport http.RoundTripper, token bool) http.RoundTripper {
+	result := transport
+	if w.har != nil {
+		result = &harRoundTripper{
+			recorder: w.har,
+			token:    token,
+			next:     result,
+		}
 	}
+	if w.logger.DebugEnabled() {
+		result = &dumpRoundTripper{
+			logger: w.logger,
+			next:   result,
+		}
+	}
+	return result
 }
 
 // dumpRoundTripper is a round tripper that dumps the details of the requests and the responses to
@@ -110,25 +130,6 @@ func (d *dumpRoundTripper) RoundTrip(request *http.Request) (response *http.Resp
 	return
 }
 
-const (
-	// redactionStr replaces sensitive values in output.
-	redactionStr = "***"
-)
-
-// redactFields are removed from log output when dumped.
-var redactFields = map[string]bool{
-	"access_token":      true,
-	"admin":             true,
-	"id_token":          true,
-	"refresh_token":     true,
-	"password":          true,
-	"client_secret":     true,
-	"kubeconfig":        true,
-	"ssh":               true,
-	"access_key_id":     true,
-	"secret_access_key": true,
-}
-
 // dumpRequest dumps to the log, in debug level, the details of the given HTTP request.
 func (d *dumpRoundTripper) dumpRequest(ctx context.Context, request *http.Request, body []byte) {
 	d.logger.Debug(ctx, "Request method is %s", request.Method)
@@ -214,74 +215,22 @@ func (d *dumpRoundTripper) dumpBody(ctx context.Context, header http.Header, bod
 // dumpForm sends to the log the contents of the given form data, excluding security sensitive
 // fields.
 func (d *dumpRoundTripper) dumpForm(ctx context.Context, data []byte) {
-	// Parse the form:
-	form, err := url.ParseQuery(string(data))
+	redacted, err := internal.RedactForm(data)
 	if err != nil {
 		d.dumpBytes(ctx, data)
 		return
 	}
-
-	// Redact values corresponding to security sensitive fields:
-	for name, values := range form {
-		if redactFields[name] {
-			for i := range values {
-				values[i] = redactionStr
-			}
-		}
-	}
-
-	// Get and sort the names of the fields of the form, so that the generated output will be
-	// predictable:
-	names := make([]string, 0, len(form))
-	for name := range form {
-		names = append(names, name)
-	}
-	sort.Strings(names)
-
-	// Write the names and values to the buffer while redacting the sensitive fields:
-	buffer := &bytes.Buffer{}
-	for _, name := range names {
-		key := url.QueryEscape(name)
-		values := form[name]
-		for _, value := range values {
-			var redacted string
-			if redactFields[name] {
-				redacted = redactionStr
-			} else {
-				redacted = url.QueryEscape(value)
-			}
-			if buffer.Len() > 0 {
-				buffer.WriteByte('&') // #nosec G104
-			}
-			buffer.WriteString(key)      // #nosec G104
-			buffer.WriteByte('=')        // #nosec G104
-			buffer.WriteString(redacted) // #nosec G104
-		}
-	}
-
-	// Send the redacted data to the log:
-	d.dumpBytes(ctx, buffer.Bytes())
+	d.dumpBytes(ctx, redacted)
 }
 
 // dumpJSON tries to parse the given data as a JSON document. If that works, then it dumps it
 // indented, otherwise dumps it as is.
 func (d *dumpRoundTripper) dumpJSON(ctx context.Context, data []byte) {
-	it, err := helpers.NewIterator(data)
+	redacted, err := internal.RedactJSON(data)
 	if err != nil {
 		d.logger.Debug(ctx, "%s", data)
 	} else {
-		var buf bytes.Buffer
-		str := helpers.NewStream(&buf)
-
-		// remove sensitive information
-		d.redactSensitive(it, str)
-
-		err := str.Flush()
-		if err != nil {
-			d.logger.Debug(ctx, "%s", data)
-		} else {
-			d.logger.Debug(ctx, "%s", buf.String())
-		}
+		d.logger.Debug(ctx, "%s", redacted)
 	}
 }
 
@@ -289,56 +238,3 @@ func (d *dumpRoundTripper) dumpJSON(ctx context.Context, data []byte) {
 func (d *dumpRoundTripper) dumpBytes(ctx context.Context, data []byte) {
 	d.logger.Debug(ctx, "%s", data)
 }
-
-// redactSensitive replaces sensitive fields within a response with redactionStr.
-func (d *dumpRoundTripper) redactSensitive(it *jsoniter.Iterator, str *jsoniter.Stream) {
-	switch it.WhatIsNext() {
-	case jsoniter.ObjectValue:
-		str.WriteObjectStart()
-		first := true
-		for field := it.ReadObject(); field != ""; field = it.ReadObject() {
-			if !first {
-				str.WriteMore()
-			}
-			first = false
-			str.WriteObjectField(field)
-			if v, ok := redactFields[field]; ok && v {
-				str.WriteString(redactionStr)
-				it.Skip()
-				continue
-			}
-			d.redactSensitive(it, str)
-		}
-		str.WriteObjectEnd()
-	case jsoniter.ArrayValue:
-		str.WriteArrayStart()
-		first := true
-		for it.ReadArray() {
-			if !first {
-				str.WriteMore()
-			}
-			first = false
-			d.redactSensitive(it, str)
-		}
-		str.WriteArrayEnd()
-	case jsoniter.StringValue:
-		str.WriteString(it.ReadString())
-	case jsoniter.NumberValue:
-		v := it.ReadNumber()
-		i, err := v.Int64()
-		if err == nil {
-			str.WriteInt64(i)
-			break
-		}
-		f, err := v.Float64()
-		if err == nil {
-			str.WriteFloat64(f)
-		}
-	case jsoniter.BoolValue:
-		str.WriteBool(it.ReadBool())
-	case jsoniter.NilValue:
-		str.WriteNil()
-		// Skip because no reading from it is involved
-		it.Skip()
-	}
-}
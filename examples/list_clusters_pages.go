@@ -0,0 +1,89 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This example shows how to retrieve the collection of clusters using the page iterator, which
+// sends the requests for the pages and requests some pages in advance.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/openshift-online/ocm-sdk-go/logging"
+	"github.com/openshift-online/ocm-sdk-go/paging"
+)
+
+func main() {
+	// Create a context:
+	ctx := context.Background()
+
+	// Create a logger that has the debug level enabled:
+	logger, err := logging.NewGoLoggerBuilder().
+		Debug(true).
+		Build()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Can't build logger: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Create the connection, and remember to close it:
+	token := os.Getenv("OCM_TOKEN")
+	connection, err := sdk.NewConnectionBuilder().
+		Logger(logger).
+		Tokens(token).
+		BuildContext(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Can't build connection: %v\n", err)
+		os.Exit(1)
+	}
+	defer connection.Close()
+
+	// Get the client for the resource that manages the collection of clusters:
+	collection := connection.ClustersMgmt().V1().Clusters()
+
+	// Create the iterator, using pages of ten items and requesting up to two pages in advance:
+	iterator, err := paging.NewIterator[
+		*cmv1.ClustersListRequest,
+		*cmv1.ClustersListResponse,
+	]().
+		Request(func() *cmv1.ClustersListRequest {
+			return collection.List().Search("name like 'my%'")
+		}).
+		Size(10).
+		Prefetch(2).
+		Build()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Can't build iterator: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Display the clusters:
+	err = iterator.Each(ctx, func(response *cmv1.ClustersListResponse) bool {
+		response.Items().Each(func(cluster *cmv1.Cluster) bool {
+			fmt.Printf("%s - %s - %s\n", cluster.ID(), cluster.Name(), cluster.State())
+			return true
+		})
+		return true
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Can't retrieve clusters: %v\n", err)
+		os.Exit(1)
+	}
+}
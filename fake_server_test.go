@@ -0,0 +1,279 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the fake server of the testing package.
+
+package sdk
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"             // nolint
+	. "github.com/onsi/gomega"                         // nolint
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Fake server", func() {
+	var ctx context.Context
+	var server *FakeServer
+	var connection *Connection
+
+	BeforeEach(func() {
+		var err error
+
+		// Create the context:
+		ctx = context.Background()
+
+		// Create the server:
+		server, err = NewFakeServer().Build()
+		Expect(err).ToNot(HaveOccurred())
+
+		// Create the connection:
+		connection, err = NewConnectionBuilder().
+			Logger(logger).
+			URL(server.URL()).
+			Tokens(MakeTokenString("Bearer", 5*time.Minute)).
+			BuildContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		err := connection.Close()
+		Expect(err).ToNot(HaveOccurred())
+		server.Close()
+	})
+
+	// addCluster creates a cluster with the given name and region using the API:
+	addCluster := func(name, region string) *cmv1.Cluster {
+		object, err := cmv1.NewCluster().
+			Name(name).
+			Region(cmv1.NewCloudRegion().ID(region)).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		response, err := connection.ClustersMgmt().V1().Clusters().Add().
+			Body(object).
+			SendContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Status()).To(Equal(http.StatusCreated))
+		return response.Body()
+	}
+
+	It("Creates, retrieves, updates and deletes clusters", func() {
+		// Create:
+		created := addCluster("mycluster", "us-east-1")
+		id := created.ID()
+		Expect(id).ToNot(BeEmpty())
+		Expect(created.HREF()).To(Equal("/api/clusters_mgmt/v1/clusters/" + id))
+		Expect(created.Name()).To(Equal("mycluster"))
+		Expect(created.State()).To(Equal(cmv1.ClusterStatePending))
+		Expect(created.CreationTimestamp()).ToNot(BeZero())
+
+		// Retrieve:
+		client := connection.ClustersMgmt().V1().Clusters().Cluster(id)
+		getResponse, err := client.Get().SendContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(getResponse.Body().Name()).To(Equal("mycluster"))
+		Expect(getResponse.Body().Region().ID()).To(Equal("us-east-1"))
+
+		// Update:
+		patch, err := cmv1.NewCluster().
+			ExternalID("456").
+			State(cmv1.ClusterStateReady).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		updateResponse, err := client.Update().Body(patch).SendContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(updateResponse.Body().ExternalID()).To(Equal("456"))
+		Expect(updateResponse.Body().Name()).To(Equal("mycluster"))
+		Expect(updateResponse.Body().State()).To(Equal(cmv1.ClusterStatePending))
+
+		// Delete:
+		_, err = client.Delete().SendContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		state, ok := server.ClusterState(id)
+		Expect(ok).To(BeTrue())
+		Expect(state).To(Equal(cmv1.ClusterStateUninstalling))
+		state, err = server.AdvanceCluster(id)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state).To(BeEmpty())
+		getResponse, err = client.Get().SendContext(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(getResponse.Status()).To(Equal(http.StatusNotFound))
+		Expect(getResponse.Error().Code()).To(Equal("CLUSTERS-MGMT-404"))
+	})
+
+	It("Pages the results", func() {
+		for i := 0; i < 5; i++ {
+			addCluster("mycluster", "us-east-1")
+		}
+		response, err := connection.ClustersMgmt().V1().Clusters().List().
+			Page(2).
+			Size(2).
+			SendContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Page()).To(Equal(2))
+		Expect(response.Size()).To(Equal(2))
+		Expect(response.Total()).To(Equal(5))
+		Expect(response.Items().Len()).To(Equal(2))
+		response, err = connection.ClustersMgmt().V1().Clusters().List().
+			Page(3).
+			Size(2).
+			SendContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Size()).To(Equal(1))
+		Expect(response.Total()).To(Equal(5))
+	})
+
+	It("Filters and sorts the results", func() {
+		addCluster("b", "us-east-1")
+		addCluster("c", "eu-west-1")
+		addCluster("a", "us-west-2")
+		response, err := connection.ClustersMgmt().V1().Clusters().List().
+			Search("region.id like 'us-%' and state = 'pending'").
+			Order("name desc").
+			SendContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Total()).To(Equal(2))
+		items := response.Items().Slice()
+		Expect(items).To(HaveLen(2))
+		Expect(items[0].Name()).To(Equal("b"))
+		Expect(items[1].Name()).To(Equal("a"))
+	})
+
+	It("Rejects search expressions that aren't valid", func() {
+		response, err := connection.ClustersMgmt().V1().Clusters().List().
+			Search("name = ").
+			SendContext(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(response.Status()).To(Equal(http.StatusBadRequest))
+	})
+
+	It("Moves clusters through the states", func() {
+		id := addCluster("mycluster", "us-east-1").ID()
+		state, err := server.AdvanceCluster(id)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state).To(Equal(cmv1.ClusterStateInstalling))
+		state, err = server.AdvanceCluster(id)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state).To(Equal(cmv1.ClusterStateReady))
+		_, err = server.AdvanceCluster(id)
+		Expect(err).To(HaveOccurred())
+		err = server.SetClusterState(id, cmv1.ClusterStateError)
+		Expect(err).ToNot(HaveOccurred())
+		response, err := connection.ClustersMgmt().V1().Clusters().Cluster(id).Get().
+			SendContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Body().State()).To(Equal(cmv1.ClusterStateError))
+	})
+
+	It("Moves clusters automatically after the delay", func() {
+		delayed, err := NewFakeServer().
+			ClusterStateDelay(10 * time.Millisecond).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		defer delayed.Close()
+		object, err := cmv1.NewCluster().Name("mycluster").Build()
+		Expect(err).ToNot(HaveOccurred())
+		added, err := delayed.AddCluster(object)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(added.State()).To(Equal(cmv1.ClusterStatePending))
+		Eventually(func() cmv1.ClusterState {
+			state, _ := delayed.ClusterState(added.ID())
+			return state
+		}).Should(Equal(cmv1.ClusterStateReady))
+	})
+
+	It("Preserves the identifier and state of added clusters", func() {
+		object, err := cmv1.NewCluster().
+			ID("123").
+			Name("mycluster").
+			State(cmv1.ClusterStateReady).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		_, err = server.AddCluster(object)
+		Expect(err).ToNot(HaveOccurred())
+		response, err := connection.ClustersMgmt().V1().Clusters().Cluster("123").Get().
+			SendContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Body().State()).To(Equal(cmv1.ClusterStateReady))
+	})
+
+	It("Serves the install log of clusters", func() {
+		cluster := addCluster("mycluster", "us-east-1")
+		err := server.AppendClusterLog(cluster.ID(), "install", "one", "two", "three")
+		Expect(err).ToNot(HaveOccurred())
+		client := connection.ClustersMgmt().V1().Clusters().Cluster(cluster.ID()).Logs().Install()
+		response, err := client.Get().SendContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Body().Content()).To(Equal("one\ntwo\nthree\n"))
+		response, err = client.Get().Offset(2).SendContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Body().Content()).To(Equal("three\n"))
+		response, err = client.Get().Tail(2).SendContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Body().Content()).To(Equal("two\nthree\n"))
+	})
+
+	It("Supports the accounts management resources", func() {
+		organization, err := amv1.NewOrganization().Name("myorg").Build()
+		Expect(err).ToNot(HaveOccurred())
+		organizationResponse, err := connection.AccountsMgmt().V1().Organizations().Add().
+			Body(organization).
+			SendContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		organizationID := organizationResponse.Body().ID()
+		for _, username := range []string{"alice", "bob"} {
+			account, err := amv1.NewAccount().
+				Username(username).
+				Organization(amv1.NewOrganization().ID(organizationID)).
+				Build()
+			Expect(err).ToNot(HaveOccurred())
+			_, err = connection.AccountsMgmt().V1().Accounts().Add().
+				Body(account).
+				SendContext(ctx)
+			Expect(err).ToNot(HaveOccurred())
+		}
+		response, err := connection.AccountsMgmt().V1().Accounts().List().
+			Search("username in ('bob', 'carol')").
+			SendContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Total()).To(Equal(1))
+		account := response.Items().Get(0)
+		Expect(account.Username()).To(Equal("bob"))
+		Expect(account.Organization().ID()).To(Equal(organizationID))
+		Expect(account.CreatedAt()).ToNot(BeZero())
+	})
+
+	It("Rejects requests without a valid token", func() {
+		unauthenticated, err := NewConnectionBuilder().
+			Logger(logger).
+			URL(server.URL()).
+			Tokens(MakeTokenString("Bearer", -5*time.Minute)).
+			BuildContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		defer func() {
+			err = unauthenticated.Close()
+			Expect(err).ToNot(HaveOccurred())
+		}()
+		_, err = unauthenticated.ClustersMgmt().V1().Clusters().List().SendContext(ctx)
+		Expect(err).To(HaveOccurred())
+	})
+})
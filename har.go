@@ -0,0 +1,707 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the implementation of the sink of the dump transport wrapper that writes the
+// details of HTTP requests and responses to files in HAR format.
+
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/openshift-online/ocm-sdk-go/internal"
+	"github.com/openshift-online/ocm-sdk-go/logging"
+)
+
+// harRecorder collects the HAR entries generated by the round trippers and writes them to files.
+// When the maximum number of entries is zero all the entries are written to a single file as they
+// arrive, so that they aren't kept in memory, and the file is completed when the connection is
+// closed. Otherwise a new file is written each time that number of entries is reached, and the
+// remaining entries are written when the connection is closed.
+type harRecorder struct {
+	logger     logging.Logger
+	file       string
+	maxEntries int
+	lock       *sync.Mutex
+	entries    []*harEntry
+	index      int
+	output     *os.File
+	written    int
+	closed     bool
+}
+
+// harCorrelation is the object that the recorder adds to the context of requests so that all the
+// entries generated for the same request, including retries and token requests, can be
+// correlated.
+type harCorrelation struct {
+	id       string
+	attempts int32
+	tokens   int32
+}
+
+// harCorrelationKey is the key used to store the correlation object in the context.
+type harCorrelationKey struct{}
+
+// newHARRecorder creates a recorder that writes the entries to the given file.
+func newHARRecorder(logger logging.Logger, file string, maxEntries int) (result *harRecorder,
+	err error) {
+	if logger == nil {
+		err = fmt.Errorf("logger is mandatory")
+		return
+	}
+	if file == "" {
+		err = fmt.Errorf("HAR file is mandatory")
+		return
+	}
+	if maxEntries < 0 {
+		err = fmt.Errorf(
+			"maximum number of HAR entries %d isn't valid, it should be greater or "+
+				"equal than zero",
+			maxEntries,
+		)
+		return
+	}
+	result = &harRecorder{
+		logger:     logger,
+		file:       file,
+		maxEntries: maxEntries,
+		lock:       &sync.Mutex{},
+	}
+	return
+}
+
+// Correlate creates a round tripper that adds to the context of the request the object used to
+// correlate all the entries generated for that request. It should be added before the
+// authentication and retry wrappers, so that token requests and retries will share it.
+func (r *harRecorder) Correlate(transport http.RoundTripper) http.RoundTripper {
+	return &harCorrelationRoundTripper{
+		next: transport,
+	}
+}
+
+// add adds an entry to the recorder. When there is no maximum number of entries it is written
+// immediately to the file, otherwise a new file is written if the maximum has been reached.
+func (r *harRecorder) add(ctx context.Context, entry *harEntry) {
+	var entries []*harEntry
+	var file string
+	r.lock.Lock()
+	if r.closed {
+		r.lock.Unlock()
+		return
+	}
+	if r.maxEntries == 0 {
+		err := r.stream(entry)
+		r.lock.Unlock()
+		if err != nil {
+			r.logger.Error(ctx, "Can't write HAR file '%s': %v", r.file, err)
+		}
+		return
+	}
+	r.entries = append(r.entries, entry)
+	if len(r.entries) >= r.maxEntries {
+		r.index++
+		entries = r.entries
+		file = r.rotatedFile(r.index)
+		r.entries = nil
+	}
+	r.lock.Unlock()
+	if entries != nil {
+		err := r.write(file, entries)
+		if err != nil {
+			r.logger.Error(ctx, "Can't write HAR file '%s': %v", file, err)
+		}
+	}
+}
+
+// Close writes the entries that haven't been written yet. Entries generated after this will be
+// discarded.
+func (r *harRecorder) Close() error {
+	r.lock.Lock()
+	if r.closed {
+		r.lock.Unlock()
+		return nil
+	}
+	r.closed = true
+	if r.maxEntries == 0 {
+		err := r.finish()
+		r.lock.Unlock()
+		if err != nil {
+			return fmt.Errorf("can't write HAR file '%s': %w", r.file, err)
+		}
+		return nil
+	}
+	entries := r.entries
+	r.entries = nil
+	if len(entries) == 0 {
+		r.lock.Unlock()
+		return nil
+	}
+	r.index++
+	file := r.rotatedFile(r.index)
+	r.lock.Unlock()
+	err := r.write(file, entries)
+	if err != nil {
+		return fmt.Errorf("can't write HAR file '%s': %w", file, err)
+	}
+	return nil
+}
+
+// stream writes the given entry to the end of the single file, creating it and writing the start
+// of the archive if needed. Must be called with the lock acquired.
+func (r *harRecorder) stream(entry *harEntry) error {
+	err := r.start()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(entry, "      ", "  ")
+	if err != nil {
+		return err
+	}
+	separator := "\n      "
+	if r.written > 0 {
+		separator = "," + separator
+	}
+	_, err = fmt.Fprintf(r.output, "%s%s", separator, data)
+	if err != nil {
+		return err
+	}
+	r.written++
+	return nil
+}
+
+// start creates the single file and writes the start of the archive, unless that has already been
+// done. Must be called with the lock acquired.
+func (r *harRecorder) start() error {
+	if r.output != nil {
+		return nil
+	}
+	creator, err := json.MarshalIndent(&harCreator{
+		Name:    "ocm-sdk-go",
+		Version: Version,
+	}, "    ", "  ")
+	if err != nil {
+		return err
+	}
+	output, err := os.OpenFile(r.file, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(
+		output,
+		"{\n  \"log\": {\n    \"version\": \"1.2\",\n    \"creator\": %s,\n"+
+			"    \"entries\": [",
+		creator,
+	)
+	if err != nil {
+		output.Close()
+		return err
+	}
+	r.output = output
+	return nil
+}
+
+// finish writes the end of the archive to the single file and closes it. If no entry has been
+// written the file is created with an empty list of entries. Must be called with the lock
+// acquired.
+func (r *harRecorder) finish() error {
+	err := r.start()
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprint(r.output, "\n    ]\n  }\n}\n")
+	if err != nil {
+		r.output.Close()
+		return err
+	}
+	return r.output.Close()
+}
+
+// rotatedFile calculates the name of the file for the given index. For example, if the configured
+// file is `traffic.har` the name for index 1 will be `traffic-00001.har`.
+func (r *harRecorder) rotatedFile(index int) string {
+	ext := filepath.Ext(r.file)
+	base := strings.TrimSuffix(r.file, ext)
+	return fmt.Sprintf("%s-%05d%s", base, index, ext)
+}
+
+// write writes the given entries to the given file.
+func (r *harRecorder) write(file string, entries []*harEntry) error {
+	if entries == nil {
+		entries = []*harEntry{}
+	}
+	archive := &harArchive{
+		Log: &harLog{
+			Version: "1.2",
+			Creator: &harCreator{
+				Name:    "ocm-sdk-go",
+				Version: Version,
+			},
+			Entries: entries,
+		},
+	}
+	data, err := json.MarshalIndent(archive, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, data, 0600)
+}
+
+// harCorrelationRoundTripper is the round tripper that adds the correlation object to the context of
+// requests.
+type harCorrelationRoundTripper struct {
+	next http.RoundTripper
+}
+
+// Make sure that we implement the http.RoundTripper interface:
+var _ http.RoundTripper = &harCorrelationRoundTripper{}
+
+// RoundTrip is the implementation of the http.RoundTripper interface.
+func (t *harCorrelationRoundTripper) RoundTrip(request *http.Request) (*http.Response, error) {
+	ctx := context.WithValue(request.Context(), harCorrelationKey{}, newHARCorrelation())
+	return t.next.RoundTrip(request.WithContext(ctx))
+}
+
+func newHARCorrelation() *harCorrelation {
+	return &harCorrelation{
+		id: uuid.NewString(),
+	}
+}
+
+// harRoundTripper is a round tripper that sends the details of the requests and the responses to
+// the HAR recorder.
+type harRoundTripper struct {
+	recorder *harRecorder
+	token    bool
+	next     http.RoundTripper
+}
+
+// Make sure that we implement the http.RoundTripper interface:
+var _ http.RoundTripper = &harRoundTripper{}
+
+// RoundTrip is the implementation of the http.RoundTripper interface.
+func (t *harRoundTripper) RoundTrip(request *http.Request) (response *http.Response, err error) {
+	ctx := request.Context()
+
+	// Find the correlation object. If there is no such object this request isn't related to
+	// any other, so we create a new one.
+	correlation, ok := ctx.Value(harCorrelationKey{}).(*harCorrelation)
+	if !ok {
+		correlation = newHARCorrelation()
+	}
+	var attempt int32
+	if t.token {
+		attempt = atomic.AddInt32(&correlation.tokens, 1)
+	} else {
+		attempt = atomic.AddInt32(&correlation.attempts, 1)
+	}
+
+	// Read the complete request body in memory and replace it with a reader that reads it from
+	// memory:
+	var requestBody []byte
+	if request.Body != nil {
+		requestBody, err = io.ReadAll(request.Body)
+		if err != nil {
+			return
+		}
+		err = request.Body.Close()
+		if err != nil {
+			return
+		}
+		request.Body = io.NopCloser(bytes.NewBuffer(requestBody))
+	}
+
+	// Send the request with a trace that collects the timings:
+	timer := &harTimer{
+		lock:  &sync.Mutex{},
+		start: time.Now(),
+	}
+	response, err = t.next.RoundTrip(request.WithContext(
+		httptrace.WithClientTrace(ctx, timer.trace()),
+	))
+	timer.mark(&timer.response)
+
+	// Read the complete response body in memory and replace it with a reader that reads it
+	// from memory:
+	var responseBody []byte
+	if err == nil && response.Body != nil {
+		responseBody, err = io.ReadAll(response.Body)
+		if err != nil {
+			return
+		}
+		err = response.Body.Close()
+		if err != nil {
+			return
+		}
+		response.Body = io.NopCloser(bytes.NewBuffer(responseBody))
+	}
+	timer.mark(&timer.end)
+
+	// Create and save the entry:
+	entry := &harEntry{
+		StartedDateTime: timer.start,
+		Request:         makeHARRequest(request, requestBody),
+		Response:        makeHARResponse(response, responseBody),
+		Cache:           &harCache{},
+		Timings:         timer.timings(),
+		ServerIPAddress: timer.server,
+		Connection:      timer.connection,
+		CorrelationID:   correlation.id,
+		Attempt:         int(attempt),
+		Token:           t.token,
+	}
+	entry.Time = entry.Timings.total()
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	t.recorder.add(ctx, entry)
+
+	return
+}
+
+func makeHARRequest(request *http.Request, body []byte) *harRequest {
+	result := &harRequest{
+		Method:      request.Method,
+		URL:         request.URL.String(),
+		HTTPVersion: harVersion(request.Proto),
+		Cookies:     []*harNameValue{},
+		Headers:     makeHARHeaders(request.Header),
+		QueryString: []*harNameValue{},
+		HeadersSize: -1,
+		BodySize:    len(body),
+	}
+	query := request.URL.Query()
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range query[name] {
+			result.QueryString = append(result.QueryString, &harNameValue{
+				Name:  name,
+				Value: value,
+			})
+		}
+	}
+	if body != nil {
+		contentType := request.Header.Get("Content-Type")
+		result.PostData = &harPostData{
+			MimeType: contentType,
+			Text:     internal.RedactBody(contentType, body),
+		}
+	}
+	return result
+}
+
+func makeHARResponse(response *http.Response, body []byte) *harResponse {
+	if response == nil {
+		return &harResponse{
+			Cookies:     []*harNameValue{},
+			Headers:     []*harNameValue{},
+			Content:     &harContent{},
+			HeadersSize: -1,
+			BodySize:    -1,
+		}
+	}
+	contentType := response.Header.Get("Content-Type")
+	return &harResponse{
+		Status:      response.StatusCode,
+		StatusText:  http.StatusText(response.StatusCode),
+		HTTPVersion: harVersion(response.Proto),
+		Cookies:     []*harNameValue{},
+		Headers:     makeHARHeaders(response.Header),
+		Content: &harContent{
+			Size:     len(body),
+			MimeType: contentType,
+			Text:     internal.RedactBody(contentType, body),
+		},
+		RedirectURL: response.Header.Get("Location"),
+		HeadersSize: -1,
+		BodySize:    len(body),
+	}
+}
+
+// makeHARHeaders converts the given HTTP header into a list of HAR name value pairs, sorted by name
+// and with the security sensitive values redacted.
+func makeHARHeaders(header http.Header) []*harNameValue {
+	header = internal.RedactHeader(header)
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	result := []*harNameValue{}
+	for _, name := range names {
+		for _, value := range header[name] {
+			result = append(result, &harNameValue{
+				Name:  name,
+				Value: value,
+			})
+		}
+	}
+	return result
+}
+
+// harVersion returns the given protocol version, or `HTTP/1.1` if it is empty, as is usually the
+// case for requests created by clients.
+func harVersion(proto string) string {
+	if proto == "" {
+		return "HTTP/1.1"
+	}
+	return proto
+}
+
+// harTimer collects the time of the events of a request using an HTTP client trace.
+type harTimer struct {
+	lock         *sync.Mutex
+	start        time.Time
+	gotConn      time.Time
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	wroteRequest time.Time
+	firstByte    time.Time
+	response     time.Time
+	end          time.Time
+	server       string
+	connection   string
+}
+
+// trace creates the client trace that updates the timer.
+func (t *harTimer) trace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mark(&t.gotConn)
+			t.lock.Lock()
+			defer t.lock.Unlock()
+			if info.Conn != nil {
+				if address := info.Conn.RemoteAddr(); address != nil {
+					host, _, err := net.SplitHostPort(address.String())
+					if err == nil {
+						t.server = host
+					}
+				}
+				if address := info.Conn.LocalAddr(); address != nil {
+					_, port, err := net.SplitHostPort(address.String())
+					if err == nil {
+						t.connection = port
+					}
+				}
+			}
+		},
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.mark(&t.dnsStart)
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.mark(&t.dnsDone)
+		},
+		ConnectStart: func(string, string) {
+			t.mark(&t.connectStart)
+		},
+		ConnectDone: func(string, string, error) {
+			t.mark(&t.connectDone)
+		},
+		TLSHandshakeStart: func() {
+			t.mark(&t.tlsStart)
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.mark(&t.tlsDone)
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			t.mark(&t.wroteRequest)
+		},
+		GotFirstResponseByte: func() {
+			t.mark(&t.firstByte)
+		},
+	}
+}
+
+// mark sets the given time to the current time, unless it has already been set. Note that some
+// events, like the DNS and connection ones, can happen multiple times when multiple addresses are
+// tried, and we want to keep the first one.
+func (t *harTimer) mark(field *time.Time) {
+	now := time.Now()
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if field.IsZero() {
+		*field = now
+	}
+}
+
+// timings calculates the HAR timings from the collected events. Events that didn't happen, for
+// example the DNS resolution when the connection is reused, are reported as -1. When the
+// underlying transport doesn't support client traces all the time till the response is reported
+// as waiting time.
+func (t *harTimer) timings() *harTimings {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	result := &harTimings{
+		DNS:     harDuration(t.dnsStart, t.dnsDone),
+		Connect: harDuration(t.connectStart, t.tlsDone),
+		SSL:     harDuration(t.tlsStart, t.tlsDone),
+	}
+	if t.tlsDone.IsZero() {
+		result.Connect = harDuration(t.connectStart, t.connectDone)
+	}
+	if t.gotConn.IsZero() || t.wroteRequest.IsZero() || t.firstByte.IsZero() {
+		result.Blocked = -1
+		result.Send = 0
+		result.Wait = harDuration(t.start, t.response)
+		result.Receive = harDuration(t.response, t.end)
+		return result
+	}
+	result.Blocked = harDuration(t.start, t.gotConn)
+	if result.DNS > 0 {
+		result.Blocked -= result.DNS
+	}
+	if result.Connect > 0 {
+		result.Blocked -= result.Connect
+	}
+	if result.Blocked < 0 {
+		result.Blocked = 0
+	}
+	result.Send = harDuration(t.gotConn, t.wroteRequest)
+	result.Wait = harDuration(t.wroteRequest, t.firstByte)
+	result.Receive = harDuration(t.firstByte, t.end)
+	return result
+}
+
+// harDuration returns the number of milliseconds between the given times, or -1 if any of them
+// hasn't been set.
+func harDuration(from, to time.Time) float64 {
+	if from.IsZero() || to.IsZero() {
+		return -1
+	}
+	return float64(to.Sub(from)) / float64(time.Millisecond)
+}
+
+// The following types correspond to the objects of the HAR 1.2 specification. Fields that start
+// with an underscore are custom fields, as allowed by the specification, used to correlate the
+// retries and token requests with the request that caused them.
+
+type harArchive struct {
+	Log *harLog `json:"log"`
+}
+
+type harLog struct {
+	Version string      `json:"version"`
+	Creator *harCreator `json:"creator"`
+	Entries []*harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime time.Time    `json:"startedDateTime"`
+	Time            float64      `json:"time"`
+	Request         *harRequest  `json:"request"`
+	Response        *harResponse `json:"response"`
+	Cache           *harCache    `json:"cache"`
+	Timings         *harTimings  `json:"timings"`
+	ServerIPAddress string       `json:"serverIPAddress,omitempty"`
+	Connection      string       `json:"connection,omitempty"`
+	CorrelationID   string       `json:"_correlationId"`
+	Attempt         int          `json:"_attempt"`
+	Token           bool         `json:"_token"`
+	Error           string       `json:"_error,omitempty"`
+}
+
+type harRequest struct {
+	Method      string          `json:"method"`
+	URL         string          `json:"url"`
+	HTTPVersion string          `json:"httpVersion"`
+	Cookies     []*harNameValue `json:"cookies"`
+	Headers     []*harNameValue `json:"headers"`
+	QueryString []*harNameValue `json:"queryString"`
+	PostData    *harPostData    `json:"postData,omitempty"`
+	HeadersSize int             `json:"headersSize"`
+	BodySize    int             `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int             `json:"status"`
+	StatusText  string          `json:"statusText"`
+	HTTPVersion string          `json:"httpVersion"`
+	Cookies     []*harNameValue `json:"cookies"`
+	Headers     []*harNameValue `json:"headers"`
+	Content     *harContent     `json:"content"`
+	RedirectURL string          `json:"redirectURL"`
+	HeadersSize int             `json:"headersSize"`
+	BodySize    int             `json:"bodySize"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+}
+
+type harCache struct{}
+
+type harTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	SSL     float64 `json:"ssl"`
+}
+
+// total calculates the total time of the entry, which according to the specification is the sum
+// of all the timings except the SSL one, as it is already included in the connect time.
+func (t *harTimings) total() float64 {
+	result := 0.0
+	for _, value := range []float64{t.Blocked, t.DNS, t.Connect, t.Send, t.Wait, t.Receive} {
+		if value > 0 {
+			result += value
+		}
+	}
+	return result
+}
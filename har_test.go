@@ -0,0 +1,235 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the HAR sink of the dump transport wrapper.
+
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/onsi/gomega/ghttp"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"             // nolint
+	. "github.com/onsi/gomega"                         // nolint
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("HAR", func() {
+	var ctx context.Context
+	var tmp string
+	var oidServer *ghttp.Server
+	var apiServer *ghttp.Server
+
+	BeforeEach(func() {
+		var err error
+		ctx = context.Background()
+		tmp, err = os.MkdirTemp("", "har-*")
+		Expect(err).ToNot(HaveOccurred())
+		oidServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+	})
+
+	AfterEach(func() {
+		oidServer.Close()
+		apiServer.Close()
+		err := os.RemoveAll(tmp)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	// readHAR reads the given HAR file and returns the list of entries.
+	readHAR := func(file string) []map[string]interface{} {
+		data, err := os.ReadFile(file)
+		Expect(err).ToNot(HaveOccurred())
+		var archive struct {
+			Log struct {
+				Version string                   `json:"version"`
+				Entries []map[string]interface{} `json:"entries"`
+			} `json:"log"`
+		}
+		err = json.Unmarshal(data, &archive)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(archive.Log.Version).To(Equal("1.2"))
+		return archive.Log.Entries
+	}
+
+	It("Writes token requests and retries correlated", func() {
+		// Prepare the token server:
+		accessToken := MakeTokenString("Bearer", 5*time.Minute)
+		refreshToken := MakeTokenString("Refresh", 10*time.Hour)
+		oidServer.AppendHandlers(
+			RespondWithAccessAndRefreshTokens(accessToken, refreshToken),
+		)
+
+		// Prepare the API server so that the first attempt fails and the second succeeds:
+		apiServer.AppendHandlers(
+			RespondWithJSON(http.StatusServiceUnavailable, "{}"),
+			RespondWithJSON(http.StatusOK, `{"id": "123", "password": "secret"}`),
+		)
+
+		// Create the connection:
+		file := filepath.Join(tmp, "traffic.har")
+		connection, err := NewConnectionBuilder().
+			Logger(logger).
+			URL(apiServer.URL()).
+			TokenURL(oidServer.URL()).
+			Tokens(refreshToken).
+			RetryInterval(10 * time.Millisecond).
+			HARFile(file).
+			BuildContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(connection.HARFile()).To(Equal(file))
+
+		// Send the request:
+		response, err := connection.Get().
+			Path("/api/clusters_mgmt/v1/clusters/123").
+			SendContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Status()).To(Equal(http.StatusOK))
+
+		// The entries are written as they are generated, and the file is completed when the
+		// connection is closed:
+		data, err := os.ReadFile(file)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring(`"_attempt": 2`))
+		err = connection.Close()
+		Expect(err).ToNot(HaveOccurred())
+		entries := readHAR(file)
+		Expect(entries).To(HaveLen(3))
+
+		// Check the token request:
+		token := entries[0]
+		Expect(token["_token"]).To(BeTrue())
+		Expect(token["_attempt"]).To(BeNumerically("==", 1))
+		tokenResponse := token["response"].(map[string]interface{})
+		tokenContent := tokenResponse["content"].(map[string]interface{})
+		Expect(tokenContent["text"]).ToNot(ContainSubstring(accessToken))
+		Expect(tokenContent["text"]).To(ContainSubstring(`"access_token": "***"`))
+
+		// Check the attempts:
+		first := entries[1]
+		Expect(first["_token"]).To(BeFalse())
+		Expect(first["_attempt"]).To(BeNumerically("==", 1))
+		Expect(first["response"]).To(HaveKeyWithValue("status", BeNumerically("==", 503)))
+		second := entries[2]
+		Expect(second["_token"]).To(BeFalse())
+		Expect(second["_attempt"]).To(BeNumerically("==", 2))
+		Expect(second["response"]).To(HaveKeyWithValue("status", BeNumerically("==", 200)))
+
+		// Check that all the entries are correlated:
+		Expect(token["_correlationId"]).ToNot(BeEmpty())
+		Expect(first["_correlationId"]).To(Equal(token["_correlationId"]))
+		Expect(second["_correlationId"]).To(Equal(token["_correlationId"]))
+
+		// Check the request details:
+		request := second["request"].(map[string]interface{})
+		Expect(request["method"]).To(Equal(http.MethodGet))
+		Expect(request["url"]).To(Equal(apiServer.URL() + "/api/clusters_mgmt/v1/clusters/123"))
+		Expect(request["headers"]).To(ContainElement(map[string]interface{}{
+			"name":  "Authorization",
+			"value": "***",
+		}))
+
+		// Check the response body is redacted:
+		content := second["response"].(map[string]interface{})["content"].(map[string]interface{})
+		Expect(content["text"]).To(ContainSubstring(`"id": "123"`))
+		Expect(content["text"]).ToNot(ContainSubstring("secret"))
+
+		// Check the timings:
+		timings := second["timings"].(map[string]interface{})
+		Expect(timings["send"]).To(BeNumerically(">=", 0))
+		Expect(timings["wait"]).To(BeNumerically(">=", 0))
+		Expect(timings["receive"]).To(BeNumerically(">=", 0))
+		Expect(second["time"]).To(BeNumerically(">=", 0))
+	})
+
+	It("Rotates files when the maximum number of entries is reached", func() {
+		token := MakeTokenString("Bearer", 5*time.Minute)
+		apiServer.AppendHandlers(
+			RespondWithJSON(http.StatusOK, "{}"),
+			RespondWithJSON(http.StatusOK, "{}"),
+			RespondWithJSON(http.StatusOK, "{}"),
+		)
+		file := filepath.Join(tmp, "traffic.har")
+		connection, err := NewConnectionBuilder().
+			Logger(logger).
+			URL(apiServer.URL()).
+			Tokens(token).
+			HARFile(file).
+			HARMaxEntries(2).
+			BuildContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		for i := 0; i < 3; i++ {
+			_, err = connection.Get().
+				Path("/api/clusters_mgmt/v1/clusters").
+				SendContext(ctx)
+			Expect(err).ToNot(HaveOccurred())
+		}
+		first := filepath.Join(tmp, "traffic-00001.har")
+		second := filepath.Join(tmp, "traffic-00002.har")
+		Expect(first).To(BeAnExistingFile())
+		Expect(second).ToNot(BeAnExistingFile())
+		err = connection.Close()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(readHAR(first)).To(HaveLen(2))
+		Expect(readHAR(second)).To(HaveLen(1))
+		Expect(file).ToNot(BeAnExistingFile())
+	})
+
+	It("Writes an empty file when there are no requests", func() {
+		file := filepath.Join(tmp, "traffic.har")
+		connection, err := NewConnectionBuilder().
+			Logger(logger).
+			URL(apiServer.URL()).
+			Tokens(MakeTokenString("Bearer", 5*time.Minute)).
+			HARFile(file).
+			BuildContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		err = connection.Close()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(readHAR(file)).To(BeEmpty())
+	})
+
+	It("Rejects negative maximum number of entries", func() {
+		connection, err := NewConnectionBuilder().
+			Logger(logger).
+			URL(apiServer.URL()).
+			Tokens(MakeTokenString("Bearer", 5*time.Minute)).
+			HARFile(filepath.Join(tmp, "traffic.har")).
+			HARMaxEntries(-1).
+			BuildContext(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(connection).To(BeNil())
+		Expect(err.Error()).To(ContainSubstring("-1"))
+	})
+
+	It("Is disabled by default", func() {
+		connection, err := NewConnectionBuilder().
+			Logger(logger).
+			URL(apiServer.URL()).
+			Tokens(MakeTokenString("Bearer", 5*time.Minute)).
+			BuildContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(connection.HARFile()).To(BeEmpty())
+		err = connection.Close()
+		Expect(err).ToNot(HaveOccurred())
+	})
+})
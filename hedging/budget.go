@@ -0,0 +1,67 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the implementation of the budget that limits the number of hedged requests.
+
+package hedging
+
+import (
+	"sync"
+)
+
+// budget limits the number of hedged requests to a fraction of the eligible requests. Each eligible
+// request deposits the ratio in the budget, and each hedged request withdraws one unit. The balance
+// never exceeds the burst, so that periods without slow requests don't allow unlimited hedging
+// later.
+type budget struct {
+	lock    *sync.Mutex
+	ratio   float64
+	burst   float64
+	balance float64
+}
+
+// newBudget creates a new budget with the given ratio and burst. The budget is initially full.
+func newBudget(ratio float64, burst int) *budget {
+	return &budget{
+		lock:    &sync.Mutex{},
+		ratio:   ratio,
+		burst:   float64(burst),
+		balance: float64(burst),
+	}
+}
+
+// deposit adds the ratio to the balance of the budget. It should be called once for each request
+// that is eligible for hedging.
+func (b *budget) deposit() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.balance += b.ratio
+	if b.balance > b.burst {
+		b.balance = b.burst
+	}
+}
+
+// withdraw tries to take one unit from the balance of the budget. It returns true if that was
+// possible, and false if the budget is exhausted.
+func (b *budget) withdraw() bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.balance < 1 {
+		return false
+	}
+	b.balance--
+	return true
+}
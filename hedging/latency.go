@@ -0,0 +1,90 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the implementation of the window of recent latencies used to calculate the
+// hedging delay from a percentile.
+
+package hedging
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// latencyWindow contains the most recent latencies of successful requests to a service, and
+// calculates percentiles of them. The percentile is cached and calculated again only after a
+// number of new samples have been added, so that requests don't need to sort the window.
+type latencyWindow struct {
+	lock    *sync.Mutex
+	samples []time.Duration
+	next    int
+	full    bool
+	changes int
+	cached  time.Duration
+}
+
+// newLatencyWindow creates a window that will keep the given number of samples.
+func newLatencyWindow(size int) *latencyWindow {
+	return &latencyWindow{
+		lock:    &sync.Mutex{},
+		samples: make([]time.Duration, size),
+	}
+}
+
+// add adds a sample to the window, replacing the oldest one if the window is full.
+func (w *latencyWindow) add(value time.Duration) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.samples[w.next] = value
+	w.next++
+	if w.next == len(w.samples) {
+		w.next = 0
+		w.full = true
+	}
+	w.changes++
+}
+
+// percentile returns the given percentile of the samples in the window. The second result will
+// be false if the window doesn't contain yet the given minimum number of samples.
+func (w *latencyWindow) percentile(value float64, minimum int) (result time.Duration, ok bool) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	count := w.next
+	if w.full {
+		count = len(w.samples)
+	}
+	if count < minimum || count == 0 {
+		return
+	}
+	if w.changes > 0 && (w.changes >= len(w.samples)/10 || w.cached == 0) {
+		sorted := make([]time.Duration, count)
+		copy(sorted, w.samples[:count])
+		sort.Slice(sorted, func(i, j int) bool {
+			return sorted[i] < sorted[j]
+		})
+		index := int(math.Ceil(value/100*float64(count))) - 1
+		if index < 0 {
+			index = 0
+		}
+		w.cached = sorted[index]
+		w.changes = 0
+	}
+	result = w.cached
+	ok = true
+	return
+}
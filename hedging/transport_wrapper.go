@@ -0,0 +1,545 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the implementation of a transport wrapper that hedges idempotent requests,
+// sending a second copy when the first one takes too long.
+
+package hedging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/openshift-online/ocm-sdk-go/logging"
+	"github.com/openshift-online/ocm-sdk-go/metrics"
+)
+
+// Default values:
+const (
+	// DefaultDelay is the default time that the wrapper waits for the response to the first
+	// request before sending the hedged request.
+	DefaultDelay = 1 * time.Second
+
+	// DefaultBudgetRatio is the default maximum fraction of eligible requests that will be
+	// hedged.
+	DefaultBudgetRatio = 0.1
+
+	// DefaultBudgetBurst is the default maximum number of requests that can be hedged at once
+	// when no request has been hedged for a while.
+	DefaultBudgetBurst = 10
+)
+
+// Parameters of the window of latencies used to calculate percentiles:
+const (
+	latencyWindowSize    = 1000
+	latencyWindowMinimum = 20
+)
+
+// TransportWrapperBuilder contains the data and logic needed to create a new hedging transport
+// wrapper. When a `GET` or `HEAD` request doesn't get a response in the configured delay the
+// wrapper sends a second copy of the request, uses the first successful response and cancels the
+// other request. Requests with other methods are never hedged, as they may not be idempotent.
+//
+// The delay can be fixed, or it can be calculated from a percentile of the latencies of the
+// recent successful requests to the same API service. To avoid amplifying the load of servers
+// that are already slow the number of hedged requests is limited by a budget: only a fraction of
+// the eligible requests can be hedged.
+//
+// When the metrics subsystem is set the wrapper will generate the following Prometheus metric:
+//
+//	<subsystem>_hedge_count - Number of hedging decisions.
+//
+// This metric will have the `apiservice` label and an `outcome` label with one of the following
+// values:
+//
+//	won - The hedged request was sent and its response was used.
+//	lost - The hedged request was sent but the response of the first request was used.
+//	throttled - The hedged request wasn't sent because the budget was exhausted.
+//
+// Don't create objects of this type directly; use the NewTransportWrapper function instead.
+type TransportWrapperBuilder struct {
+	logger            logging.Logger
+	delay             time.Duration
+	percentile        float64
+	budgetRatio       float64
+	budgetBurst       int
+	services          []string
+	metricsSubsystem  string
+	metricsRegisterer prometheus.Registerer
+}
+
+// TransportWrapper contains the data and logic needed to wrap an HTTP round tripper with another
+// one that hedges idempotent requests.
+type TransportWrapper struct {
+	logger       logging.Logger
+	delay        time.Duration
+	percentile   float64
+	services     map[string]bool
+	budget       *budget
+	windowsLock  *sync.Mutex
+	windowsTable map[string]*latencyWindow
+	hedgeCount   *prometheus.CounterVec
+}
+
+// roundTripper is a round tripper that hedges idempotent requests.
+type roundTripper struct {
+	owner     *TransportWrapper
+	transport http.RoundTripper
+}
+
+// attempt contains the result of sending one of the copies of a request.
+type attempt struct {
+	hedge    bool
+	response *http.Response
+	err      error
+	elapsed  time.Duration
+	cancel   context.CancelFunc
+}
+
+// Make sure that we implement the interface:
+var _ http.RoundTripper = (*roundTripper)(nil)
+
+// NewTransportWrapper creates a new builder that can then be used to configure and create a new
+// hedging transport wrapper.
+func NewTransportWrapper() *TransportWrapperBuilder {
+	return &TransportWrapperBuilder{
+		delay:             DefaultDelay,
+		budgetRatio:       DefaultBudgetRatio,
+		budgetBurst:       DefaultBudgetBurst,
+		metricsRegisterer: prometheus.DefaultRegisterer,
+	}
+}
+
+// Logger sets the logger that will be used by the wrapper and by the round trippers that it
+// creates. This is mandatory.
+func (b *TransportWrapperBuilder) Logger(value logging.Logger) *TransportWrapperBuilder {
+	b.logger = value
+	return b
+}
+
+// Delay sets the time that the wrapper waits for the response to the first request before
+// sending the hedged request. When a percentile is also set this is only used till there are
+// enough latency samples to calculate it. The default is one second.
+func (b *TransportWrapperBuilder) Delay(value time.Duration) *TransportWrapperBuilder {
+	b.delay = value
+	return b
+}
+
+// Percentile sets the percentile of the latencies of recent successful requests that will be used
+// as the delay. For example, if the value is 95 then the hedged request will be sent when the
+// first request takes longer than 95% of the recent requests to the same API service. The value
+// should be greater than zero and less than one hundred. The default is zero, which means that
+// the fixed delay set with the Delay method is always used.
+func (b *TransportWrapperBuilder) Percentile(value float64) *TransportWrapperBuilder {
+	b.percentile = value
+	return b
+}
+
+// Budget sets the maximum fraction of eligible requests that will be hedged, and the maximum
+// number of requests that can be hedged at once when no request has been hedged for a while. For
+// example, to hedge at most 5% of the requests, with bursts of at most three hedged requests:
+//
+//	wrapper, err := hedging.NewTransportWrapper().
+//		Logger(logger).
+//		Budget(0.05, 3).
+//		Build(ctx)
+//
+// The default is to hedge at most 10% of the requests, with bursts of at most ten.
+func (b *TransportWrapperBuilder) Budget(ratio float64, burst int) *TransportWrapperBuilder {
+	b.budgetRatio = ratio
+	b.budgetBurst = burst
+	return b
+}
+
+// Services adds API services whose requests will be hedged. The service names are the same used
+// for the `apiservice` label of the metrics, for example `ocm-clusters-service`. By default the
+// requests to all the services are hedged.
+func (b *TransportWrapperBuilder) Services(values ...string) *TransportWrapperBuilder {
+	b.services = append(b.services, values...)
+	return b
+}
+
+// MetricsSubsystem sets the name of the subsystem that will be used by the wrapper to register
+// metrics with Prometheus. If this isn't explicitly specified, or if it is an empty string, then
+// no metrics will be registered.
+func (b *TransportWrapperBuilder) MetricsSubsystem(value string) *TransportWrapperBuilder {
+	b.metricsSubsystem = value
+	return b
+}
+
+// MetricsRegisterer sets the Prometheus registerer that will be used to register the metrics. The
+// default is to use the default Prometheus registerer and there is usually no need to change that.
+// This is intended for unit tests, where it is convenient to have a registerer that doesn't
+// interfere with the rest of the system.
+func (b *TransportWrapperBuilder) MetricsRegisterer(
+	value prometheus.Registerer) *TransportWrapperBuilder {
+	if value == nil {
+		value = prometheus.DefaultRegisterer
+	}
+	b.metricsRegisterer = value
+	return b
+}
+
+// Build uses the information stored in the builder to create a new transport wrapper.
+func (b *TransportWrapperBuilder) Build(ctx context.Context) (result *TransportWrapper,
+	err error) {
+	// Check parameters:
+	if b.logger == nil {
+		err = fmt.Errorf("logger is mandatory")
+		return
+	}
+	if b.delay <= 0 {
+		err = fmt.Errorf(
+			"delay %s isn't valid, it should be greater than zero",
+			b.delay,
+		)
+		return
+	}
+	if b.percentile < 0 || b.percentile >= 100 {
+		err = fmt.Errorf(
+			"percentile %f isn't valid, it should be greater or equal than zero and "+
+				"less than one hundred",
+			b.percentile,
+		)
+		return
+	}
+	if b.budgetRatio <= 0 || b.budgetRatio > 1 {
+		err = fmt.Errorf(
+			"budget ratio %f isn't valid, it should be greater than zero and less or "+
+				"equal than one",
+			b.budgetRatio,
+		)
+		return
+	}
+	if b.budgetBurst <= 0 {
+		err = fmt.Errorf(
+			"budget burst %d isn't valid, it should be greater than zero",
+			b.budgetBurst,
+		)
+		return
+	}
+	var services map[string]bool
+	if len(b.services) > 0 {
+		services = make(map[string]bool, len(b.services))
+		for _, service := range b.services {
+			if service == "" {
+				err = fmt.Errorf("service name is mandatory")
+				return
+			}
+			services[service] = true
+		}
+	}
+
+	// Register the metrics:
+	var hedgeCount *prometheus.CounterVec
+	if b.metricsSubsystem != "" {
+		hedgeCount = prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Subsystem: b.metricsSubsystem,
+				Name:      "hedge_count",
+				Help:      "Number of hedging decisions.",
+			},
+			hedgeCountLabelNames,
+		)
+		err = b.metricsRegisterer.Register(hedgeCount)
+		if err != nil {
+			registered, ok := err.(prometheus.AlreadyRegisteredError)
+			if ok {
+				hedgeCount = registered.ExistingCollector.(*prometheus.CounterVec)
+				err = nil
+			} else {
+				return
+			}
+		}
+	}
+
+	// Create and populate the object:
+	result = &TransportWrapper{
+		logger:       b.logger,
+		delay:        b.delay,
+		percentile:   b.percentile,
+		services:     services,
+		budget:       newBudget(b.budgetRatio, b.budgetBurst),
+		windowsLock:  &sync.Mutex{},
+		windowsTable: map[string]*latencyWindow{},
+		hedgeCount:   hedgeCount,
+	}
+
+	return
+}
+
+// Wrap creates a new round tripper that wraps the given one and hedges idempotent requests.
+func (w *TransportWrapper) Wrap(transport http.RoundTripper) http.RoundTripper {
+	return &roundTripper{
+		owner:     w,
+		transport: transport,
+	}
+}
+
+// Delay returns the time that the wrapper will currently wait before hedging a request to the
+// given API service.
+func (w *TransportWrapper) Delay(service string) time.Duration {
+	if w.percentile == 0 {
+		return w.delay
+	}
+	result, ok := w.window(service).percentile(w.percentile, latencyWindowMinimum)
+	if !ok || result <= 0 {
+		return w.delay
+	}
+	return result
+}
+
+// Close releases all the resources used by the wrapper.
+func (w *TransportWrapper) Close() error {
+	return nil
+}
+
+// eligible checks if the given request can be hedged.
+func (w *TransportWrapper) eligible(request *http.Request, service string) bool {
+	switch request.Method {
+	case http.MethodGet, http.MethodHead:
+	default:
+		return false
+	}
+	if w.services != nil && !w.services[service] {
+		return false
+	}
+	if request.Body != nil && request.Body != http.NoBody && request.GetBody == nil {
+		return false
+	}
+	return true
+}
+
+// window returns the window of latencies of the given service, creating it if needed.
+func (w *TransportWrapper) window(service string) *latencyWindow {
+	w.windowsLock.Lock()
+	defer w.windowsLock.Unlock()
+	result, ok := w.windowsTable[service]
+	if !ok {
+		result = newLatencyWindow(latencyWindowSize)
+		w.windowsTable[service] = result
+	}
+	return result
+}
+
+// count updates the hedge count metric, if enabled.
+func (w *TransportWrapper) count(service, outcome string) {
+	if w.hedgeCount == nil {
+		return
+	}
+	labels := prometheus.Labels{
+		serviceLabelName: service,
+		outcomeLabelName: outcome,
+	}
+	w.hedgeCount.With(labels).Inc()
+}
+
+// RoundTrip is the implementation of the round tripper interface.
+func (t *roundTripper) RoundTrip(request *http.Request) (response *http.Response, err error) {
+	// Send the request directly if it can't be hedged:
+	service := metrics.ServiceName(request.URL.Path)
+	if !t.owner.eligible(request, service) {
+		response, err = t.transport.RoundTrip(request)
+		return
+	}
+	t.owner.budget.deposit()
+
+	// Send the first request:
+	ctx := request.Context()
+	results := make(chan *attempt, 2)
+	cancels := map[bool]context.CancelFunc{}
+	cancels[false] = t.send(request.Clone(ctx), false, results)
+	pending := 1
+	hedged := false
+
+	// Wait for the responses, sending the hedged request if the first one takes too long:
+	delay := t.owner.Delay(service)
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	timeout := timer.C
+	var failed *attempt
+	for pending > 0 {
+		select {
+		case <-timeout:
+			timeout = nil
+			if !t.owner.budget.withdraw() {
+				t.owner.logger.Debug(
+					ctx,
+					"Request to '%s' took more than %s but hedging budget is "+
+						"exhausted",
+					request.URL.Path, delay,
+				)
+				t.owner.count(service, throttledOutcome)
+				continue
+			}
+			hedge := request.Clone(ctx)
+			if request.GetBody != nil {
+				hedge.Body, err = request.GetBody()
+				if err != nil {
+					t.owner.logger.Warn(
+						ctx,
+						"Can't get body for hedged request to '%s': %v",
+						request.URL.Path, err,
+					)
+					err = nil
+					continue
+				}
+			}
+			t.owner.logger.Debug(
+				ctx,
+				"Request to '%s' took more than %s, sending hedged request",
+				request.URL.Path, delay,
+			)
+			cancels[true] = t.send(hedge, true, results)
+			pending++
+			hedged = true
+		case result := <-results:
+			pending--
+			if result.err == nil && result.response.StatusCode < http.StatusInternalServerError {
+				// Cancel and discard the other request, if any:
+				if failed != nil {
+					failed.discard()
+				}
+				if pending > 0 {
+					cancels[!result.hedge]()
+					go discard(results, pending)
+				}
+
+				// Remember the latency and update the metrics:
+				t.owner.window(service).add(result.elapsed)
+				if hedged {
+					if result.hedge {
+						t.owner.count(service, wonOutcome)
+					} else {
+						t.owner.count(service, lostOutcome)
+					}
+				}
+
+				// Return the response, making sure that the context of the request is
+				// only cancelled when the body is closed:
+				response = result.response
+				response.Body = &cancelBody{
+					ReadCloser: response.Body,
+					cancel:     result.cancel,
+				}
+				return
+			}
+			if failed != nil {
+				failed.discard()
+			}
+			failed = result
+
+			// If the first request fails before the delay there is no point in sending the
+			// hedged request, the retry logic will decide what to do:
+			if pending == 0 {
+				timeout = nil
+			}
+		}
+	}
+
+	// If we are here both requests failed, so return the last failure:
+	if hedged {
+		t.owner.count(service, lostOutcome)
+	}
+	response = failed.response
+	err = failed.err
+	if response != nil {
+		response.Body = &cancelBody{
+			ReadCloser: response.Body,
+			cancel:     failed.cancel,
+		}
+	} else {
+		failed.cancel()
+	}
+	return
+}
+
+// send sends a copy of the request in a separate goroutine, and writes the result to the given
+// channel. Each copy has its own context, so that it can be cancelled when the other one wins. The
+// returned function cancels that context.
+func (t *roundTripper) send(request *http.Request, hedge bool,
+	results chan<- *attempt) context.CancelFunc {
+	ctx, cancel := context.WithCancel(request.Context())
+	request = request.WithContext(ctx)
+	go func() {
+		start := time.Now()
+		response, err := t.transport.RoundTrip(request)
+		results <- &attempt{
+			hedge:    hedge,
+			response: response,
+			err:      err,
+			elapsed:  time.Since(start),
+			cancel:   cancel,
+		}
+	}()
+	return cancel
+}
+
+// discard cancels the attempt and closes the body of the response, if any.
+func (a *attempt) discard() {
+	a.cancel()
+	if a.response != nil && a.response.Body != nil {
+		_, _ = io.Copy(io.Discard, a.response.Body)
+		_ = a.response.Body.Close()
+	}
+}
+
+// discard waits for the given number of pending attempts and discards them. This is needed to
+// close the bodies of responses that arrive after they have been cancelled.
+func discard(results <-chan *attempt, pending int) {
+	for i := 0; i < pending; i++ {
+		result := <-results
+		result.discard()
+	}
+}
+
+// cancelBody is a response body that cancels the context of the request when it is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close is the implementation of the io.Closer interface.
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// Names of the labels added to metrics:
+const (
+	serviceLabelName = "apiservice"
+	outcomeLabelName = "outcome"
+)
+
+// Values of the outcome label:
+const (
+	wonOutcome       = "won"
+	lostOutcome      = "lost"
+	throttledOutcome = "throttled"
+)
+
+// Array of labels added to the hedge count metric:
+var hedgeCountLabelNames = []string{
+	serviceLabelName,
+	outcomeLabelName,
+}
@@ -0,0 +1,448 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the hedging transport wrapper.
+
+package hedging
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"             // nolint
+	. "github.com/onsi/gomega"                         // nolint
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+// slowTransport returns a transport that answers the requests with the given bodies, waiting the
+// corresponding delays. The delays and bodies are used in the order that requests arrive. Requests
+// whose context is cancelled while waiting fail with the error of the context, and increase the
+// given counter of cancelled requests.
+func slowTransport(calls, cancelled *int32, delays []time.Duration,
+	bodies []string) http.RoundTripper {
+	return TransportFunc(func(request *http.Request) (*http.Response, error) {
+		i := atomic.AddInt32(calls, 1) - 1
+		timer := time.NewTimer(delays[i])
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-request.Context().Done():
+			atomic.AddInt32(cancelled, 1)
+			return nil, request.Context().Err()
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header: http.Header{
+				"Content-Type": []string{"application/json"},
+			},
+			Body:    io.NopCloser(strings.NewReader(bodies[i])),
+			Request: request,
+		}, nil
+	})
+}
+
+// readBody reads the complete body of the response and closes it.
+func readBody(response *http.Response) string {
+	defer response.Body.Close()
+	data, err := io.ReadAll(response.Body)
+	Expect(err).ToNot(HaveOccurred())
+	return string(data)
+}
+
+var _ = Describe("Creation", func() {
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	It("Can't be created without a logger", func() {
+		wrapper, err := NewTransportWrapper().
+			Build(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(wrapper).To(BeNil())
+		message := err.Error()
+		Expect(message).To(ContainSubstring("logger"))
+		Expect(message).To(ContainSubstring("mandatory"))
+	})
+
+	It("Can be created with the defaults", func() {
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			Build(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(wrapper).ToNot(BeNil())
+		Expect(wrapper.Delay("ocm-clusters-service")).To(Equal(DefaultDelay))
+	})
+
+	It("Can't be created with zero delay", func() {
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			Delay(0).
+			Build(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(wrapper).To(BeNil())
+		message := err.Error()
+		Expect(message).To(ContainSubstring("delay"))
+		Expect(message).To(ContainSubstring("greater than zero"))
+	})
+
+	It("Can't be created with percentile of one hundred", func() {
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			Percentile(100).
+			Build(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(wrapper).To(BeNil())
+		message := err.Error()
+		Expect(message).To(ContainSubstring("percentile"))
+		Expect(message).To(ContainSubstring("less than one hundred"))
+	})
+
+	It("Can't be created with budget ratio greater than one", func() {
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			Budget(1.5, 1).
+			Build(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(wrapper).To(BeNil())
+		message := err.Error()
+		Expect(message).To(ContainSubstring("budget ratio"))
+	})
+
+	It("Can't be created with zero budget burst", func() {
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			Budget(0.1, 0).
+			Build(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(wrapper).To(BeNil())
+		message := err.Error()
+		Expect(message).To(ContainSubstring("budget burst"))
+		Expect(message).To(ContainSubstring("greater than zero"))
+	})
+
+	It("Can't be created with empty service name", func() {
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			Services("").
+			Build(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(wrapper).To(BeNil())
+		message := err.Error()
+		Expect(message).To(ContainSubstring("service"))
+		Expect(message).To(ContainSubstring("mandatory"))
+	})
+})
+
+var _ = Describe("Hedging", func() {
+	var ctx context.Context
+	var calls *int32
+	var cancelled *int32
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		calls = new(int32)
+		cancelled = new(int32)
+	})
+
+	It("Doesn't hedge fast requests", func() {
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			Delay(100 * time.Millisecond).
+			Build(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		client := &http.Client{
+			Transport: wrapper.Wrap(slowTransport(
+				calls, cancelled,
+				[]time.Duration{0},
+				[]string{`{"first":true}`},
+			)),
+		}
+		response, err := client.Get("http://api.example.com/api/clusters_mgmt/v1/clusters")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(http.StatusOK))
+		Expect(readBody(response)).To(Equal(`{"first":true}`))
+		Expect(atomic.LoadInt32(calls)).To(BeNumerically("==", 1))
+	})
+
+	It("Hedges slow requests and cancels the slow one", func() {
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			Delay(50 * time.Millisecond).
+			Build(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		client := &http.Client{
+			Transport: wrapper.Wrap(slowTransport(
+				calls, cancelled,
+				[]time.Duration{10 * time.Second, 0},
+				[]string{`{"first":true}`, `{"second":true}`},
+			)),
+		}
+		start := time.Now()
+		response, err := client.Get("http://api.example.com/api/clusters_mgmt/v1/clusters")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(time.Since(start)).To(BeNumerically("<", 1*time.Second))
+		Expect(response.StatusCode).To(Equal(http.StatusOK))
+		Expect(readBody(response)).To(Equal(`{"second":true}`))
+		Expect(atomic.LoadInt32(calls)).To(BeNumerically("==", 2))
+		Eventually(func() int32 {
+			return atomic.LoadInt32(cancelled)
+		}).Should(BeNumerically("==", 1))
+	})
+
+	It("Uses the first response if it arrives before the hedged one", func() {
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			Delay(50 * time.Millisecond).
+			Build(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		client := &http.Client{
+			Transport: wrapper.Wrap(slowTransport(
+				calls, cancelled,
+				[]time.Duration{100 * time.Millisecond, 10 * time.Second},
+				[]string{`{"first":true}`, `{"second":true}`},
+			)),
+		}
+		response, err := client.Get("http://api.example.com/api/clusters_mgmt/v1/clusters")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(readBody(response)).To(Equal(`{"first":true}`))
+		Eventually(func() int32 {
+			return atomic.LoadInt32(cancelled)
+		}).Should(BeNumerically("==", 1))
+	})
+
+	It("Doesn't hedge mutating requests", func() {
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			Delay(10 * time.Millisecond).
+			Build(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		client := &http.Client{
+			Transport: wrapper.Wrap(slowTransport(
+				calls, cancelled,
+				[]time.Duration{100 * time.Millisecond},
+				[]string{`{}`},
+			)),
+		}
+		response, err := client.Post(
+			"http://api.example.com/api/clusters_mgmt/v1/clusters",
+			"application/json",
+			strings.NewReader(`{}`),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(readBody(response)).To(Equal(`{}`))
+		Expect(atomic.LoadInt32(calls)).To(BeNumerically("==", 1))
+	})
+
+	It("Doesn't hedge requests to other services", func() {
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			Delay(10 * time.Millisecond).
+			Services("ocm-clusters-service").
+			Build(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		client := &http.Client{
+			Transport: wrapper.Wrap(slowTransport(
+				calls, cancelled,
+				[]time.Duration{100 * time.Millisecond},
+				[]string{`{}`},
+			)),
+		}
+		response, err := client.Get("http://api.example.com/api/accounts_mgmt/v1/accounts")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(readBody(response)).To(Equal(`{}`))
+		Expect(atomic.LoadInt32(calls)).To(BeNumerically("==", 1))
+	})
+
+	It("Doesn't hedge when the first request fails before the delay", func() {
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			Delay(100 * time.Millisecond).
+			Build(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		client := &http.Client{
+			Transport: wrapper.Wrap(TransportFunc(func(*http.Request) (*http.Response,
+				error) {
+				atomic.AddInt32(calls, 1)
+				return &http.Response{
+					StatusCode: http.StatusServiceUnavailable,
+					Body:       io.NopCloser(strings.NewReader(`{}`)),
+				}, nil
+			})),
+		}
+		response, err := client.Get("http://api.example.com/api/clusters_mgmt/v1/clusters")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(http.StatusServiceUnavailable))
+		Expect(readBody(response)).To(Equal(`{}`))
+		time.Sleep(200 * time.Millisecond)
+		Expect(atomic.LoadInt32(calls)).To(BeNumerically("==", 1))
+	})
+
+	It("Stops hedging when the budget is exhausted", func() {
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			Delay(10*time.Millisecond).
+			Budget(0.1, 1).
+			Build(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		client := &http.Client{
+			Transport: wrapper.Wrap(slowTransport(
+				calls, cancelled,
+				[]time.Duration{
+					100 * time.Millisecond,
+					0,
+					100 * time.Millisecond,
+				},
+				[]string{`{"first":true}`, `{"second":true}`, `{"third":true}`},
+			)),
+		}
+
+		// The first request should be hedged, using the only unit of the budget:
+		response, err := client.Get("http://api.example.com/api/clusters_mgmt/v1/clusters")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(readBody(response)).To(Equal(`{"second":true}`))
+
+		// The second shouldn't, as the budget only recovers a tenth of a unit per request:
+		response, err = client.Get("http://api.example.com/api/clusters_mgmt/v1/clusters")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(readBody(response)).To(Equal(`{"third":true}`))
+		Expect(atomic.LoadInt32(calls)).To(BeNumerically("==", 3))
+	})
+
+	It("Calculates the delay from the percentile", func() {
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			Delay(10 * time.Second).
+			Percentile(90).
+			Build(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		client := &http.Client{
+			Transport: wrapper.Wrap(JSONTransport(http.StatusOK, `{}`)),
+		}
+
+		// Till there are enough samples the fixed delay should be used:
+		Expect(wrapper.Delay("ocm-clusters-service")).To(Equal(10 * time.Second))
+
+		// Send enough fast requests:
+		for i := 0; i < 2*latencyWindowMinimum; i++ {
+			response, err := client.Get("http://api.example.com/api/clusters_mgmt/v1/clusters")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(readBody(response)).To(Equal(`{}`))
+		}
+
+		// Now the delay should be calculated from the latencies, but only for that service:
+		Expect(wrapper.Delay("ocm-clusters-service")).To(BeNumerically("<", 1*time.Second))
+		Expect(wrapper.Delay("ocm-accounts-service")).To(Equal(10 * time.Second))
+	})
+
+	It("Returns the error if both requests fail", func() {
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			Delay(10 * time.Millisecond).
+			Build(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		client := &http.Client{
+			Transport: wrapper.Wrap(TransportFunc(func(*http.Request) (*http.Response,
+				error) {
+				atomic.AddInt32(calls, 1)
+				time.Sleep(50 * time.Millisecond)
+				return &http.Response{
+					StatusCode: http.StatusInternalServerError,
+					Body:       io.NopCloser(strings.NewReader(`{}`)),
+				}, nil
+			})),
+		}
+		response, err := client.Get("http://api.example.com/api/clusters_mgmt/v1/clusters")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(http.StatusInternalServerError))
+		Expect(readBody(response)).To(Equal(`{}`))
+		Expect(atomic.LoadInt32(calls)).To(BeNumerically("==", 2))
+	})
+})
+
+var _ = Describe("Metrics", func() {
+	var ctx context.Context
+	var server *MetricsServer
+	var calls *int32
+	var cancelled *int32
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		server = NewMetricsServer()
+		calls = new(int32)
+		cancelled = new(int32)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("Generates hedge count", func() {
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			Delay(10*time.Millisecond).
+			Budget(0.1, 1).
+			MetricsSubsystem("my").
+			MetricsRegisterer(server.Registry()).
+			Build(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		client := &http.Client{
+			Transport: wrapper.Wrap(slowTransport(
+				calls, cancelled,
+				[]time.Duration{
+					100 * time.Millisecond,
+					0,
+					100 * time.Millisecond,
+				},
+				[]string{`{}`, `{}`, `{}`},
+			)),
+		}
+		for i := 0; i < 2; i++ {
+			response, err := client.Get("http://api.example.com/api/clusters_mgmt/v1/clusters")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(readBody(response)).To(Equal(`{}`))
+		}
+		metrics := server.Metrics()
+		Expect(metrics).To(MatchLine(`^my_hedge_count\{apiservice="ocm-clusters-service",outcome="won"\} 1$`))
+		Expect(metrics).To(MatchLine(`^my_hedge_count\{apiservice="ocm-clusters-service",outcome="throttled"\} 1$`))
+	})
+
+	It("Doesn't generate metrics without subsystem", func() {
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			Delay(10 * time.Millisecond).
+			MetricsRegisterer(server.Registry()).
+			Build(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		client := &http.Client{
+			Transport: wrapper.Wrap(slowTransport(
+				calls, cancelled,
+				[]time.Duration{100 * time.Millisecond, 0},
+				[]string{`{}`, `{}`},
+			)),
+		}
+		response, err := client.Get("http://api.example.com/api/clusters_mgmt/v1/clusters")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(readBody(response)).To(Equal(`{}`))
+		metrics := server.Metrics()
+		Expect(metrics).To(ConsistOf(""))
+	})
+})
@@ -0,0 +1,138 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the support for hedged requests.
+
+package sdk
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/onsi/gomega/ghttp"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"             // nolint
+	. "github.com/onsi/gomega"                         // nolint
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Hedging", func() {
+	var ctx context.Context
+	var token string
+	var apiServer *ghttp.Server
+	var metricsServer *MetricsServer
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		token = MakeTokenString("Bearer", 5*time.Minute)
+		apiServer = MakeTCPServer()
+		metricsServer = NewMetricsServer()
+	})
+
+	AfterEach(func() {
+		apiServer.Close()
+		metricsServer.Close()
+	})
+
+	// slowHandler returns a handler that waits till the request is cancelled or till the given
+	// time has passed.
+	slowHandler := func(delay time.Duration) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-time.After(delay):
+			case <-r.Context().Done():
+			}
+		}
+	}
+
+	It("Uses the response of the hedged request", func() {
+		apiServer.AppendHandlers(
+			slowHandler(10*time.Second),
+			RespondWithJSON(http.StatusOK, `{"id": "123"}`),
+		)
+
+		connection, err := NewConnectionBuilder().
+			Logger(logger).
+			URL(apiServer.URL()).
+			Tokens(token).
+			Hedging(true).
+			HedgingDelay(50 * time.Millisecond).
+			MetricsSubsystem("my").
+			MetricsRegisterer(metricsServer.Registry()).
+			BuildContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		defer func() {
+			err = connection.Close()
+			Expect(err).ToNot(HaveOccurred())
+		}()
+
+		start := time.Now()
+		response, err := connection.ClustersMgmt().V1().Clusters().Cluster("123").Get().
+			SendContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+		Expect(response.Body().ID()).To(Equal("123"))
+		Expect(apiServer.ReceivedRequests()).To(HaveLen(2))
+
+		metrics := metricsServer.Metrics()
+		Expect(metrics).To(MatchLine(`^my_hedge_count\{apiservice="ocm-clusters-service",outcome="won"\} 1$`))
+		Expect(metrics).To(MatchLine(`^my_request_count\{apiservice="ocm-clusters-service",code="200",method="GET",path="/api/clusters_mgmt/v1/clusters/-"\} 1$`))
+	})
+
+	It("Doesn't hedge mutating requests", func() {
+		apiServer.AppendHandlers(
+			ghttp.CombineHandlers(
+				slowHandler(200*time.Millisecond),
+				RespondWithJSON(http.StatusCreated, `{"id": "123"}`),
+			),
+		)
+
+		connection, err := NewConnectionBuilder().
+			Logger(logger).
+			URL(apiServer.URL()).
+			Tokens(token).
+			Hedging(true).
+			HedgingDelay(10 * time.Millisecond).
+			BuildContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		defer func() {
+			err = connection.Close()
+			Expect(err).ToNot(HaveOccurred())
+		}()
+
+		response, err := connection.Post().
+			Path("/api/clusters_mgmt/v1/clusters").
+			String(`{}`).
+			SendContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Status()).To(Equal(http.StatusCreated))
+		Expect(apiServer.ReceivedRequests()).To(HaveLen(1))
+	})
+
+	It("Can't be created with invalid budget", func() {
+		connection, err := NewConnectionBuilder().
+			Logger(logger).
+			URL(apiServer.URL()).
+			Tokens(token).
+			Hedging(true).
+			HedgingBudget(0, 1).
+			BuildContext(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(connection).To(BeNil())
+		Expect(err.Error()).To(ContainSubstring("budget ratio"))
+	})
+})
@@ -102,7 +102,11 @@ func Segments(path string) []string {
 }
 
 // PollContext repeatedly executes a task till it returns one of the given statuses and till the result
-// satisfies all the given predicates.
+// satisfies all the given predicates. The interval between attempts can be changed using the
+// options stored in the context with the WithPollOptions function. The context must have a
+// deadline, and polling stops returning the last response when the next attempt would happen
+// after it. Set the GiveUpError option to make the deadline optional and to get a *PollError
+// when the loop gives up.
 func PollContext(
 	ctx context.Context,
 	interval time.Duration,
@@ -110,53 +114,7 @@ func PollContext(
 	predicates []func(interface{}) bool,
 	task func(context.Context) (int, interface{}, error),
 ) (result interface{}, err error) {
-	// Check the deadline:
-	deadline, ok := ctx.Deadline()
-	if !ok {
-		err = fmt.Errorf("context deadline is mandatory")
-		return
-	}
-
-	// Check the interval:
-	if interval <= 0 {
-		err = fmt.Errorf("interval must be greater than zero")
-		return
-	}
-
-	// Create a cancellable context so that we can explicitly cancel it when we know that the next
-	// iteration of the loop will be after the deadline:
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
-
-	// If no expected status has been explicitly specified then add the default:
-	if len(statuses) == 0 {
-		statuses = []int{http.StatusOK}
-	}
-	for {
-		// Execute the task. If this produces an error and the status code is zero it means that
-		// there was an error like a timeout, or a low level communications problem. In that
-		// case we want to immediately stop waiting.
-		var status int
-		status, result, err = task(ctx)
-		if err != nil && status == 0 {
-			break
-		}
-
-		// Evaluate the status and the predicates:
-		statusOK := evalStatus(statuses, status)
-		predicatesOK := evalPredicates(predicates, result)
-		if statusOK && predicatesOK {
-			break
-		}
-
-		// If either the status or the predicates aren't acceptable then we need to check if we
-		// have enough time for another iteration before the deadline:
-		if time.Now().Add(interval).After(deadline) {
-			cancel()
-			break
-		}
-		time.Sleep(interval)
-	}
+	result, err = poll(ctx, interval, PollOptionsFromContext(ctx), statuses, predicates, task)
 	return
 }
 
@@ -0,0 +1,332 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the polling engine used by the PollContext function, and therefore by the
+// Poll methods of the generated clients.
+
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// PollOptions contains the options that control how the Poll methods of the generated clients
+// wait between attempts. Use the WithPollOptions function to attach them to the context passed to
+// the StartContext method. For example, to double the interval after each attempt up to one
+// minute, with a random adjustment of 10%, and to report each attempt:
+//
+//	ctx = helpers.WithPollOptions(ctx, helpers.PollOptions{
+//		Multiplier:  2,
+//		MaxInterval: time.Minute,
+//		Jitter:      0.1,
+//		OnAttempt: func(attempt *helpers.PollAttempt) {
+//			fmt.Printf("Attempt %d returned %d\n", attempt.Number, attempt.Status)
+//		},
+//	})
+//	response, err := client.Poll().
+//		Interval(5 * time.Second).
+//		Predicate(isReady).
+//		StartContext(ctx)
+type PollOptions struct {
+	// Multiplier is the factor used to increase the interval after each attempt. The default
+	// is one, which means that the interval doesn't change.
+	Multiplier float64
+
+	// MaxInterval is the maximum interval between attempts when the multiplier is greater than
+	// one. The default is zero, which means that there is no maximum.
+	MaxInterval time.Duration
+
+	// Jitter is a factor used to randomize the intervals. For example, if this is set to 0.1
+	// then a random adjustment between -10% and +10% will be done to each interval. It should
+	// be greater or equal than zero and less than one. The default is zero.
+	Jitter float64
+
+	// Timeout is the maximum total time spent polling. When the context also has a deadline
+	// the earliest of the two is used. The default is zero, which means that only the deadline
+	// of the context, if any, is used.
+	Timeout time.Duration
+
+	// OnAttempt is called after each attempt, before waiting for the next one.
+	OnAttempt func(attempt *PollAttempt)
+
+	// GiveUpError changes how the loop reports that it gave up. By default, to preserve the
+	// behaviour of previous versions, the context must have a deadline, or the Timeout option
+	// must be set, and when the next attempt would happen after it the loop returns the last
+	// response together with the error returned by the task for it, usually nil. When this is
+	// true the context doesn't need a deadline, and giving up, because of the deadline or
+	// because the context has been cancelled, is reported with a *PollError. The last response
+	// is returned as the result in both cases.
+	GiveUpError bool
+}
+
+// PollAttempt contains the details of one of the attempts of a polling loop. It is passed to the
+// OnAttempt callback.
+type PollAttempt struct {
+	// Number is the number of the attempt, starting with one.
+	Number int
+
+	// Status is the HTTP status code of the response, or zero if there is no response.
+	Status int
+
+	// Result is the response returned by the task, for example a *ClusterGetResponse.
+	Result interface{}
+
+	// Err is the error returned by the task, if any.
+	Err error
+
+	// Done is true if the result is acceptable and the loop will stop.
+	Done bool
+
+	// Elapsed is the time since the loop started.
+	Elapsed time.Duration
+
+	// Next is the time that the loop will wait before the next attempt. It is zero when this
+	// is the last attempt.
+	Next time.Duration
+}
+
+// PollError is the error returned by the polling loop when the GiveUpError option is set and it
+// gives up before getting an acceptable response, either because the deadline would be exceeded or
+// because the context has been cancelled. It carries the last response received, so that callers can still report it.
+type PollError struct {
+	// Attempts is the number of attempts made.
+	Attempts int
+
+	// Status is the HTTP status code of the last response, or zero if there was no response.
+	Status int
+
+	// Result is the last response returned by the task, or nil if there was no response. For
+	// the generated clients this will be the same type returned by the Get method, for example
+	// *ClusterGetResponse.
+	Result interface{}
+
+	// Err is the reason for giving up. It is context.DeadlineExceeded when the next attempt
+	// would happen after the deadline, and the error of the context when it has been
+	// cancelled.
+	Err error
+}
+
+// Error is the implementation of the error interface.
+func (e *PollError) Error() string {
+	return fmt.Sprintf("gave up polling after %d attempts: %v", e.Attempts, e.Err)
+}
+
+// Unwrap returns the reason for giving up, so that errors.Is(err, context.Canceled) and
+// errors.Is(err, context.DeadlineExceeded) work as expected.
+func (e *PollError) Unwrap() error {
+	return e.Err
+}
+
+// pollOptionsKey is the key used to store the poll options in the context.
+type pollOptionsKey struct{}
+
+// WithPollOptions returns a copy of the given context that contains the given polling options.
+// These options will be used by the Poll methods of the generated clients when the context is
+// passed to the StartContext method.
+func WithPollOptions(ctx context.Context, options PollOptions) context.Context {
+	return context.WithValue(ctx, pollOptionsKey{}, options)
+}
+
+// PollOptionsFromContext returns the polling options stored in the context, or the default
+// options if there are none.
+func PollOptionsFromContext(ctx context.Context) PollOptions {
+	options, _ := ctx.Value(pollOptionsKey{}).(PollOptions)
+	return options
+}
+
+// poll repeatedly executes a task till it returns one of the given statuses and the result
+// satisfies all the given predicates.
+func poll(
+	ctx context.Context,
+	interval time.Duration,
+	options PollOptions,
+	statuses []int,
+	predicates []func(interface{}) bool,
+	task func(context.Context) (int, interface{}, error),
+) (result interface{}, err error) {
+	// Check the parameters:
+	if interval <= 0 {
+		err = fmt.Errorf("interval must be greater than zero")
+		return
+	}
+	multiplier := options.Multiplier
+	if multiplier == 0 {
+		multiplier = 1
+	}
+	if multiplier < 1 {
+		err = fmt.Errorf(
+			"multiplier %f isn't valid, it should be greater or equal than one",
+			options.Multiplier,
+		)
+		return
+	}
+	if options.MaxInterval < 0 {
+		err = fmt.Errorf(
+			"maximum interval %s isn't valid, it should be greater or equal than zero",
+			options.MaxInterval,
+		)
+		return
+	}
+	if options.Jitter < 0 || options.Jitter >= 1 {
+		err = fmt.Errorf(
+			"jitter %f isn't valid, it should be greater or equal than zero and less "+
+				"than one",
+			options.Jitter,
+		)
+		return
+	}
+	if options.Timeout < 0 {
+		err = fmt.Errorf(
+			"timeout %s isn't valid, it should be greater or equal than zero",
+			options.Timeout,
+		)
+		return
+	}
+
+	// Apply the timeout, if any:
+	start := time.Now()
+	if options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.Timeout)
+		defer cancel()
+	}
+	deadline, hasDeadline := ctx.Deadline()
+	if !hasDeadline && !options.GiveUpError {
+		err = fmt.Errorf("context deadline is mandatory")
+		return
+	}
+
+	// If no expected status has been explicitly specified then add the default:
+	if len(statuses) == 0 {
+		statuses = []int{http.StatusOK}
+	}
+
+	var last interface{}
+	var lastStatus int
+	for number := 1; ; number++ {
+		// Execute the task. If this produces an error and the status code is zero it means that
+		// there was an error like a timeout, or a low level communications problem. In that
+		// case we want to immediately stop waiting.
+		status, current, taskErr := task(ctx)
+		if current != nil {
+			last = current
+			lastStatus = status
+		}
+		attempt := &PollAttempt{
+			Number:  number,
+			Status:  status,
+			Result:  current,
+			Err:     taskErr,
+			Elapsed: time.Since(start),
+		}
+		if taskErr != nil && status == 0 {
+			notify(options, attempt)
+			if !options.GiveUpError {
+				result = current
+				err = taskErr
+				return
+			}
+			result = last
+			if ctx.Err() != nil {
+				err = &PollError{
+					Attempts: number,
+					Status:   lastStatus,
+					Result:   last,
+					Err:      ctx.Err(),
+				}
+			} else {
+				err = taskErr
+			}
+			return
+		}
+
+		// Evaluate the status and the predicates:
+		if evalStatus(statuses, status) && evalPredicates(predicates, current) {
+			attempt.Done = true
+			notify(options, attempt)
+			result = current
+			err = taskErr
+			return
+		}
+
+		// If either the status or the predicates aren't acceptable then we need to check if we
+		// have enough time for another iteration before the deadline:
+		wait := pollInterval(interval, multiplier, options.MaxInterval, options.Jitter, number)
+		if hasDeadline && time.Now().Add(wait).After(deadline) {
+			notify(options, attempt)
+			if !options.GiveUpError {
+				result = current
+				err = taskErr
+				return
+			}
+			result = last
+			err = &PollError{
+				Attempts: number,
+				Status:   lastStatus,
+				Result:   last,
+				Err:      context.DeadlineExceeded,
+			}
+			return
+		}
+		attempt.Next = wait
+		notify(options, attempt)
+
+		// Wait till the next iteration, or till the context is cancelled:
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			result = last
+			if !options.GiveUpError {
+				err = ctx.Err()
+				return
+			}
+			err = &PollError{
+				Attempts: number,
+				Status:   lastStatus,
+				Result:   last,
+				Err:      ctx.Err(),
+			}
+			return
+		}
+	}
+}
+
+// pollInterval calculates the time to wait after the given attempt.
+func pollInterval(interval time.Duration, multiplier float64, maximum time.Duration,
+	jitter float64, attempt int) time.Duration {
+	result := float64(interval) * math.Pow(multiplier, float64(attempt-1))
+	if maximum > 0 && result > float64(maximum) {
+		result = float64(maximum)
+	}
+	if jitter > 0 {
+		result *= 1 + jitter*(1-2*rand.Float64())
+	}
+	return time.Duration(result)
+}
+
+// notify calls the attempt callback, if any.
+func notify(options PollOptions, attempt *PollAttempt) {
+	if options.OnAttempt != nil {
+		options.OnAttempt(attempt)
+	}
+}
@@ -0,0 +1,311 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the polling engine.
+
+package helpers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core" // nolint
+	. "github.com/onsi/gomega"             // nolint
+)
+
+// countingTask returns a task that returns the number of times that it has been called, and that
+// returns the given status.
+func countingTask(calls *int, status int) func(context.Context) (int, interface{}, error) {
+	return func(ctx context.Context) (int, interface{}, error) {
+		*calls++
+		return status, *calls, nil
+	}
+}
+
+// atLeast returns a predicate that checks that the result of a counting task is at least the
+// given number.
+func atLeast(value int) func(interface{}) bool {
+	return func(result interface{}) bool {
+		return result.(int) >= value
+	}
+}
+
+var _ = Describe("Poll", func() {
+	var ctx context.Context
+	var cancel context.CancelFunc
+
+	BeforeEach(func() {
+		ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	It("Requires a deadline by default", func() {
+		calls := 0
+		_, err := PollContext(
+			context.Background(),
+			time.Millisecond,
+			nil,
+			nil,
+			countingTask(&calls, http.StatusOK),
+		)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("deadline"))
+		Expect(calls).To(BeZero())
+	})
+
+	It("Doesn't require a deadline when the give up error is enabled", func() {
+		calls := 0
+		ctx := WithPollOptions(context.Background(), PollOptions{
+			GiveUpError: true,
+		})
+		result, err := PollContext(
+			ctx,
+			time.Millisecond,
+			nil,
+			[]func(interface{}) bool{atLeast(3)},
+			countingTask(&calls, http.StatusOK),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).To(Equal(3))
+	})
+
+	It("Rejects zero interval", func() {
+		calls := 0
+		_, err := PollContext(ctx, 0, nil, nil, countingTask(&calls, http.StatusOK))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("interval"))
+		Expect(calls).To(BeZero())
+	})
+
+	It("Rejects jitter greater than one", func() {
+		calls := 0
+		ctx = WithPollOptions(ctx, PollOptions{
+			Jitter: 2,
+		})
+		_, err := PollContext(ctx, time.Millisecond, nil, nil,
+			countingTask(&calls, http.StatusOK))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("jitter"))
+		Expect(calls).To(BeZero())
+	})
+
+	It("Waits for the expected status", func() {
+		calls := 0
+		task := func(ctx context.Context) (int, interface{}, error) {
+			calls++
+			if calls < 3 {
+				return http.StatusNotFound, calls, errors.New("not found")
+			}
+			return http.StatusOK, calls, nil
+		}
+		result, err := PollContext(ctx, time.Millisecond, nil, nil, task)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).To(Equal(3))
+	})
+
+	It("Stops immediately when there is no response", func() {
+		calls := 0
+		failure := errors.New("connection refused")
+		task := func(ctx context.Context) (int, interface{}, error) {
+			calls++
+			return 0, nil, failure
+		}
+		_, err := PollContext(ctx, time.Millisecond, nil, nil, task)
+		Expect(err).To(Equal(failure))
+		Expect(calls).To(Equal(1))
+	})
+
+	It("Increases the interval", func() {
+		var waits []time.Duration
+		calls := 0
+		ctx = WithPollOptions(ctx, PollOptions{
+			Multiplier:  2,
+			MaxInterval: 4 * time.Millisecond,
+			OnAttempt: func(attempt *PollAttempt) {
+				waits = append(waits, attempt.Next)
+			},
+		})
+		_, err := PollContext(
+			ctx,
+			time.Millisecond,
+			nil,
+			[]func(interface{}) bool{atLeast(5)},
+			countingTask(&calls, http.StatusOK),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(waits).To(Equal([]time.Duration{
+			1 * time.Millisecond,
+			2 * time.Millisecond,
+			4 * time.Millisecond,
+			4 * time.Millisecond,
+			0,
+		}))
+	})
+
+	It("Applies the jitter", func() {
+		var waits []time.Duration
+		calls := 0
+		ctx = WithPollOptions(ctx, PollOptions{
+			Jitter: 0.5,
+			OnAttempt: func(attempt *PollAttempt) {
+				if !attempt.Done {
+					waits = append(waits, attempt.Next)
+				}
+			},
+		})
+		_, err := PollContext(
+			ctx,
+			10*time.Millisecond,
+			nil,
+			[]func(interface{}) bool{atLeast(5)},
+			countingTask(&calls, http.StatusOK),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(waits).To(HaveLen(4))
+		for _, wait := range waits {
+			Expect(wait).To(BeNumerically(">=", 5*time.Millisecond))
+			Expect(wait).To(BeNumerically("<=", 15*time.Millisecond))
+		}
+	})
+
+	It("Reports each attempt", func() {
+		var attempts []*PollAttempt
+		calls := 0
+		ctx = WithPollOptions(ctx, PollOptions{
+			OnAttempt: func(attempt *PollAttempt) {
+				attempts = append(attempts, attempt)
+			},
+		})
+		_, err := PollContext(
+			ctx,
+			time.Millisecond,
+			nil,
+			[]func(interface{}) bool{atLeast(2)},
+			countingTask(&calls, http.StatusOK),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(attempts).To(HaveLen(2))
+		Expect(attempts[0].Number).To(Equal(1))
+		Expect(attempts[0].Status).To(Equal(http.StatusOK))
+		Expect(attempts[0].Result).To(Equal(1))
+		Expect(attempts[0].Done).To(BeFalse())
+		Expect(attempts[1].Number).To(Equal(2))
+		Expect(attempts[1].Done).To(BeTrue())
+	})
+
+	It("Returns the last response without error when it reaches the deadline", func() {
+		calls := 0
+		ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		result, err := PollContext(
+			ctx,
+			20*time.Millisecond,
+			nil,
+			[]func(interface{}) bool{atLeast(100)},
+			countingTask(&calls, http.StatusOK),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(calls).To(BeNumerically(">=", 1))
+		Expect(result).To(Equal(calls))
+	})
+
+	It("Returns the error of the last response when it reaches the deadline", func() {
+		failure := errors.New("not found")
+		task := func(ctx context.Context) (int, interface{}, error) {
+			return http.StatusNotFound, "last", failure
+		}
+		ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		result, err := PollContext(ctx, 20*time.Millisecond, nil, nil, task)
+		Expect(err).To(Equal(failure))
+		Expect(result).To(Equal("last"))
+	})
+
+	It("Gives up before the deadline with the last response", func() {
+		calls := 0
+		ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		ctx = WithPollOptions(ctx, PollOptions{
+			GiveUpError: true,
+		})
+		start := time.Now()
+		result, err := PollContext(
+			ctx,
+			20*time.Millisecond,
+			nil,
+			[]func(interface{}) bool{atLeast(100)},
+			countingTask(&calls, http.StatusOK),
+		)
+		Expect(time.Since(start)).To(BeNumerically("<", 50*time.Millisecond))
+		Expect(err).To(HaveOccurred())
+		Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+		var pollErr *PollError
+		Expect(errors.As(err, &pollErr)).To(BeTrue())
+		Expect(pollErr.Attempts).To(Equal(calls))
+		Expect(pollErr.Status).To(Equal(http.StatusOK))
+		Expect(pollErr.Result).To(Equal(calls))
+		Expect(result).To(Equal(calls))
+	})
+
+	It("Gives up when the timeout option expires", func() {
+		calls := 0
+		ctx := WithPollOptions(context.Background(), PollOptions{
+			Timeout:     50 * time.Millisecond,
+			GiveUpError: true,
+		})
+		_, err := PollContext(
+			ctx,
+			20*time.Millisecond,
+			nil,
+			[]func(interface{}) bool{atLeast(100)},
+			countingTask(&calls, http.StatusOK),
+		)
+		Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+		Expect(calls).To(BeNumerically(">=", 2))
+	})
+
+	It("Returns immediately when the context is cancelled", func() {
+		calls := 0
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ctx = WithPollOptions(ctx, PollOptions{
+			GiveUpError: true,
+		})
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			cancel()
+		}()
+		start := time.Now()
+		result, err := PollContext(
+			ctx,
+			time.Hour,
+			nil,
+			[]func(interface{}) bool{atLeast(100)},
+			countingTask(&calls, http.StatusOK),
+		)
+		Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+		Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+		var pollErr *PollError
+		Expect(errors.As(err, &pollErr)).To(BeTrue())
+		Expect(pollErr.Attempts).To(Equal(1))
+		Expect(result).To(Equal(1))
+	})
+})
@@ -0,0 +1,387 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the implementation of the circuit breaker that stops sending requests to
+// servers that are failing.
+
+package internal
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/openshift-online/ocm-sdk-go/logging"
+)
+
+// Default configuration of circuit breakers:
+const (
+	DefaultCircuitBreakerFailureThreshold = 5
+	DefaultCircuitBreakerSuccessThreshold = 1
+	DefaultCircuitBreakerCoolDown         = 30 * time.Second
+)
+
+// CircuitBreakerState represents the state of a circuit breaker.
+type CircuitBreakerState int
+
+// Possible states of a circuit breaker. Requests are sent normally when the breaker is closed. When
+// the number of consecutive failures reaches the threshold the breaker opens, and requests are
+// rejected without sending them till the cool down period expires. After that the breaker is half
+// open: one request at a time is sent to probe the server, and if enough probes succeed the
+// breaker is closed again. If a probe fails the breaker opens again. Note that these values are
+// also the values of the circuit breaker state metric.
+const (
+	CircuitBreakerClosed   CircuitBreakerState = 0
+	CircuitBreakerOpen     CircuitBreakerState = 1
+	CircuitBreakerHalfOpen CircuitBreakerState = 2
+)
+
+// String returns the string representation of the state.
+func (s CircuitBreakerState) String() string {
+	switch s {
+	case CircuitBreakerClosed:
+		return "closed"
+	case CircuitBreakerOpen:
+		return "open"
+	case CircuitBreakerHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(s))
+	}
+}
+
+// CircuitBreakerError is the error returned when a request isn't sent because the circuit breaker
+// of the server is open, or because it is half open and another request is already probing the
+// server. Use the errors.As function to check for it.
+type CircuitBreakerError struct {
+	// Server is the key of the server, for example `tcp:api.openshift.com`.
+	Server string
+
+	// State is the state of the circuit breaker when the request was rejected.
+	State CircuitBreakerState
+
+	// Until is the time when the breaker will allow sending requests again. It is zero when the
+	// request was rejected because another request is probing the server.
+	Until time.Time
+}
+
+// Error is the implementation of the error interface.
+func (e *CircuitBreakerError) Error() string {
+	if e.Until.IsZero() {
+		return fmt.Sprintf(
+			"circuit breaker for server '%s' is %s and another request is probing it",
+			e.Server, e.State,
+		)
+	}
+	return fmt.Sprintf(
+		"circuit breaker for server '%s' is %s, requests will be rejected till %s",
+		e.Server, e.State, e.Until.UTC().Format(time.RFC3339),
+	)
+}
+
+// circuitBreakerMetrics contains the metrics shared by all the circuit breakers of a client
+// selector.
+type circuitBreakerMetrics struct {
+	state         *prometheus.GaugeVec
+	rejectedCount *prometheus.CounterVec
+}
+
+// circuitBreaker tracks the failures of the requests sent to one server and decides when requests
+// should be rejected.
+type circuitBreaker struct {
+	logger           logging.Logger
+	server           string
+	failureThreshold int
+	successThreshold int
+	coolDown         time.Duration
+	metrics          *circuitBreakerMetrics
+
+	lock       sync.Mutex
+	state      CircuitBreakerState
+	generation uint64
+	failures   int
+	successes  int
+	until      time.Time
+	probing    bool
+}
+
+// circuitBreakerTicket is returned by the breaker when a request is allowed, and must be passed
+// back when the result of the request is recorded. It contains the generation of the breaker when
+// the request was allowed, so that results of requests that started before the last change of
+// state are ignored, and a flag that indicates if the request is the probe of a half open breaker,
+// so that only that request can finish the probe.
+type circuitBreakerTicket struct {
+	generation uint64
+	probe      bool
+}
+
+// circuitBreakerRoundTripper is a round tripper that uses a circuit breaker to decide if requests
+// should be sent.
+type circuitBreakerRoundTripper struct {
+	breaker   *circuitBreaker
+	transport http.RoundTripper
+}
+
+// Make sure that we implement the interface:
+var _ http.RoundTripper = (*circuitBreakerRoundTripper)(nil)
+
+// newCircuitBreaker creates a new closed circuit breaker for the given server.
+func newCircuitBreaker(logger logging.Logger, server string, failureThreshold,
+	successThreshold int, coolDown time.Duration,
+	metrics *circuitBreakerMetrics) *circuitBreaker {
+	result := &circuitBreaker{
+		logger:           logger,
+		server:           server,
+		failureThreshold: failureThreshold,
+		successThreshold: successThreshold,
+		coolDown:         coolDown,
+		metrics:          metrics,
+		state:            CircuitBreakerClosed,
+	}
+	if metrics != nil {
+		metrics.state.With(prometheus.Labels{
+			serverLabelName: server,
+		}).Set(float64(CircuitBreakerClosed))
+	}
+	return result
+}
+
+// wrap creates a round tripper that wraps the given one and uses this circuit breaker.
+func (b *circuitBreaker) wrap(transport http.RoundTripper) http.RoundTripper {
+	return &circuitBreakerRoundTripper{
+		breaker:   b,
+		transport: transport,
+	}
+}
+
+// RoundTrip is the implementation of the round tripper interface.
+func (t *circuitBreakerRoundTripper) RoundTrip(request *http.Request) (response *http.Response,
+	err error) {
+	ctx := request.Context()
+	ticket, err := t.breaker.allow(ctx)
+	if err != nil {
+		// Round trippers are required to close the request body even when they fail:
+		if request.Body != nil {
+			request.Body.Close()
+		}
+		return
+	}
+	response, err = t.transport.RoundTrip(request)
+	t.breaker.record(ctx, ticket, response, err)
+	return
+}
+
+// allow checks if a request can be sent, and returns an error if it can't. When it can be sent it
+// returns the ticket that must be passed to the record method with the result.
+func (b *circuitBreaker) allow(ctx context.Context) (ticket circuitBreakerTicket, err error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	switch b.state {
+	case CircuitBreakerOpen:
+		if time.Now().Before(b.until) {
+			err = b.reject(&CircuitBreakerError{
+				Server: b.server,
+				State:  b.state,
+				Until:  b.until,
+			})
+			return
+		}
+		b.transition(ctx, CircuitBreakerHalfOpen)
+		b.probing = true
+		ticket.probe = true
+	case CircuitBreakerHalfOpen:
+		if b.probing {
+			err = b.reject(&CircuitBreakerError{
+				Server: b.server,
+				State:  b.state,
+			})
+			return
+		}
+		b.probing = true
+		ticket.probe = true
+	}
+	ticket.generation = b.generation
+	return
+}
+
+// reject updates the rejected requests metric and returns the given error.
+func (b *circuitBreaker) reject(err *CircuitBreakerError) error {
+	if b.metrics != nil {
+		b.metrics.rejectedCount.With(prometheus.Labels{
+			serverLabelName: b.server,
+		}).Inc()
+	}
+	return err
+}
+
+// record updates the state of the breaker according to the result of a request. Results of
+// requests allowed before the last change of state are ignored, as they don't say anything about
+// the current state of the server, and only the probe of a half open breaker can close or open it.
+func (b *circuitBreaker) record(ctx context.Context, ticket circuitBreakerTicket,
+	response *http.Response, err error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	// Ignore requests that started before the last change of state:
+	if ticket.generation != b.generation {
+		return
+	}
+
+	// Requests that failed because the caller cancelled them don't say anything about the
+	// health of the server, but if it was the probe we still need to allow other probes:
+	if err != nil && ctx.Err() != nil {
+		if ticket.probe {
+			b.probing = false
+		}
+		return
+	}
+
+	failed := err != nil || response.StatusCode >= 500
+	switch b.state {
+	case CircuitBreakerClosed:
+		if !failed {
+			b.failures = 0
+			return
+		}
+		b.failures++
+		if b.failures >= b.failureThreshold {
+			b.open(ctx)
+		}
+	case CircuitBreakerHalfOpen:
+		if !ticket.probe {
+			return
+		}
+		b.probing = false
+		if failed {
+			b.open(ctx)
+			return
+		}
+		b.successes++
+		if b.successes >= b.successThreshold {
+			b.failures = 0
+			b.successes = 0
+			b.transition(ctx, CircuitBreakerClosed)
+		}
+	}
+}
+
+// open changes the state to open and starts the cool down period. Must be called with the lock
+// held.
+func (b *circuitBreaker) open(ctx context.Context) {
+	b.until = time.Now().Add(b.coolDown)
+	b.successes = 0
+	b.transition(ctx, CircuitBreakerOpen)
+}
+
+// transition changes the state of the breaker, writing a message to the log and updating the
+// metrics. It also starts a new generation, so that the results of requests that started before
+// are ignored. Must be called with the lock held.
+func (b *circuitBreaker) transition(ctx context.Context, state CircuitBreakerState) {
+	if state == CircuitBreakerOpen {
+		b.logger.Warn(
+			ctx,
+			"Circuit breaker for server '%s' changed from %s to %s after %d "+
+				"consecutive failures, requests will be rejected for %s",
+			b.server, b.state, state, b.failures, b.coolDown,
+		)
+	} else {
+		b.logger.Info(
+			ctx,
+			"Circuit breaker for server '%s' changed from %s to %s",
+			b.server, b.state, state,
+		)
+	}
+	b.state = state
+	b.generation++
+	if b.metrics != nil {
+		b.metrics.state.With(prometheus.Labels{
+			serverLabelName: b.server,
+		}).Set(float64(state))
+	}
+}
+
+// currentState returns the current state of the breaker.
+func (b *circuitBreaker) currentState() CircuitBreakerState {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.state
+}
+
+// registerCircuitBreakerMetrics registers the metrics used by circuit breakers.
+func registerCircuitBreakerMetrics(subsystem string,
+	registerer prometheus.Registerer) (result *circuitBreakerMetrics, err error) {
+	// Register the state metric:
+	state := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: subsystem,
+			Name:      "circuit_breaker_state",
+			Help:      "State of the circuit breaker: 0 closed, 1 open, 2 half open.",
+		},
+		circuitBreakerLabelNames,
+	)
+	err = registerer.Register(state)
+	if err != nil {
+		registered, ok := err.(prometheus.AlreadyRegisteredError)
+		if ok {
+			state = registered.ExistingCollector.(*prometheus.GaugeVec)
+			err = nil
+		} else {
+			return
+		}
+	}
+
+	// Register the rejected requests metric:
+	rejectedCount := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: subsystem,
+			Name:      "circuit_breaker_rejected_count",
+			Help:      "Number of requests rejected by the circuit breaker.",
+		},
+		circuitBreakerLabelNames,
+	)
+	err = registerer.Register(rejectedCount)
+	if err != nil {
+		registered, ok := err.(prometheus.AlreadyRegisteredError)
+		if ok {
+			rejectedCount = registered.ExistingCollector.(*prometheus.CounterVec)
+			err = nil
+		} else {
+			return
+		}
+	}
+
+	// Create and populate the object:
+	result = &circuitBreakerMetrics{
+		state:         state,
+		rejectedCount: rejectedCount,
+	}
+
+	return
+}
+
+// Names of the labels added to circuit breaker metrics:
+const (
+	serverLabelName = "server"
+)
+
+// Array of labels added to circuit breaker metrics:
+var circuitBreakerLabelNames = []string{
+	serverLabelName,
+}
@@ -0,0 +1,356 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core" // nolint
+	. "github.com/onsi/gomega"             // nolint
+)
+
+// breakerTransport is a round tripper that returns the configured status code or error, and
+// counts the requests that it receives.
+type breakerTransport struct {
+	code  int
+	err   error
+	count int
+}
+
+func (t *breakerTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	t.count++
+	if t.err != nil {
+		return nil, t.err
+	}
+	return &http.Response{
+		StatusCode: t.code,
+		Body:       io.NopCloser(strings.NewReader("{}")),
+	}, nil
+}
+
+var _ = Describe("Circuit breaker", func() {
+	var (
+		ctx       context.Context
+		transport *breakerTransport
+		breaker   *circuitBreaker
+		client    *http.Client
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		transport = &breakerTransport{
+			code: http.StatusOK,
+		}
+		breaker = newCircuitBreaker(logger, "tcp:api.example.com", 3, 2, 100*time.Millisecond, nil)
+		client = &http.Client{
+			Transport: breaker.wrap(transport),
+		}
+	})
+
+	// send sends a request and returns the error:
+	send := func() error {
+		response, err := client.Get("http://api.example.com/api")
+		if err == nil {
+			response.Body.Close()
+		}
+		return err
+	}
+
+	It("Is initially closed", func() {
+		Expect(breaker.currentState()).To(Equal(CircuitBreakerClosed))
+		Expect(send()).To(Succeed())
+	})
+
+	It("Opens after consecutive failures", func() {
+		transport.code = http.StatusServiceUnavailable
+		for i := 0; i < 3; i++ {
+			Expect(send()).To(Succeed())
+		}
+		Expect(breaker.currentState()).To(Equal(CircuitBreakerOpen))
+
+		// Next request should be rejected without calling the transport:
+		err := send()
+		Expect(err).To(HaveOccurred())
+		Expect(transport.count).To(Equal(3))
+		var breakerErr *CircuitBreakerError
+		Expect(errors.As(err, &breakerErr)).To(BeTrue())
+		Expect(breakerErr.Server).To(Equal("tcp:api.example.com"))
+		Expect(breakerErr.State).To(Equal(CircuitBreakerOpen))
+		Expect(breakerErr.Until).To(BeTemporally(">", time.Now()))
+		Expect(err.Error()).To(ContainSubstring("tcp:api.example.com"))
+	})
+
+	It("Counts errors as failures", func() {
+		transport.err = errors.New("connection refused")
+		for i := 0; i < 3; i++ {
+			Expect(send()).ToNot(Succeed())
+		}
+		Expect(breaker.currentState()).To(Equal(CircuitBreakerOpen))
+	})
+
+	It("Resets the count after a success", func() {
+		transport.code = http.StatusInternalServerError
+		Expect(send()).To(Succeed())
+		Expect(send()).To(Succeed())
+		transport.code = http.StatusOK
+		Expect(send()).To(Succeed())
+		transport.code = http.StatusInternalServerError
+		Expect(send()).To(Succeed())
+		Expect(send()).To(Succeed())
+		Expect(breaker.currentState()).To(Equal(CircuitBreakerClosed))
+	})
+
+	It("Doesn't count client errors as failures", func() {
+		transport.code = http.StatusNotFound
+		for i := 0; i < 5; i++ {
+			Expect(send()).To(Succeed())
+		}
+		Expect(breaker.currentState()).To(Equal(CircuitBreakerClosed))
+	})
+
+	It("Doesn't count cancelled requests as failures", func() {
+		cancelCtx, cancel := context.WithCancel(ctx)
+		cancel()
+		transport.err = context.Canceled
+		for i := 0; i < 5; i++ {
+			request, err := http.NewRequestWithContext(
+				cancelCtx,
+				http.MethodGet,
+				"http://api.example.com/api",
+				nil,
+			)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = breaker.wrap(transport).RoundTrip(request)
+			Expect(err).To(HaveOccurred())
+		}
+		Expect(breaker.currentState()).To(Equal(CircuitBreakerClosed))
+	})
+
+	It("Closes after the cool down if probes succeed", func() {
+		transport.code = http.StatusServiceUnavailable
+		for i := 0; i < 3; i++ {
+			Expect(send()).To(Succeed())
+		}
+		Expect(breaker.currentState()).To(Equal(CircuitBreakerOpen))
+		time.Sleep(150 * time.Millisecond)
+		transport.code = http.StatusOK
+		Expect(send()).To(Succeed())
+		Expect(breaker.currentState()).To(Equal(CircuitBreakerHalfOpen))
+		Expect(send()).To(Succeed())
+		Expect(breaker.currentState()).To(Equal(CircuitBreakerClosed))
+	})
+
+	It("Opens again if a probe fails", func() {
+		transport.code = http.StatusServiceUnavailable
+		for i := 0; i < 3; i++ {
+			Expect(send()).To(Succeed())
+		}
+		time.Sleep(150 * time.Millisecond)
+		Expect(send()).To(Succeed())
+		Expect(breaker.currentState()).To(Equal(CircuitBreakerOpen))
+		Expect(send()).ToNot(Succeed())
+	})
+
+	It("Allows only one probe at a time", func() {
+		transport.code = http.StatusServiceUnavailable
+		for i := 0; i < 3; i++ {
+			Expect(send()).To(Succeed())
+		}
+		time.Sleep(150 * time.Millisecond)
+
+		// Simulate a probe that is in progress:
+		_, err := breaker.allow(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(breaker.currentState()).To(Equal(CircuitBreakerHalfOpen))
+
+		// Other requests should be rejected:
+		err = send()
+		var breakerErr *CircuitBreakerError
+		Expect(errors.As(err, &breakerErr)).To(BeTrue())
+		Expect(breakerErr.State).To(Equal(CircuitBreakerHalfOpen))
+		Expect(breakerErr.Until.IsZero()).To(BeTrue())
+	})
+
+	It("Ignores slow requests that started before it opened", func() {
+		// Start a slow request while the breaker is closed:
+		slow, err := breaker.allow(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(slow.probe).To(BeFalse())
+
+		// Open the breaker and wait for the cool down:
+		transport.code = http.StatusServiceUnavailable
+		for i := 0; i < 3; i++ {
+			Expect(send()).To(Succeed())
+		}
+		Expect(breaker.currentState()).To(Equal(CircuitBreakerOpen))
+		time.Sleep(150 * time.Millisecond)
+
+		// Start the probe:
+		probe, err := breaker.allow(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(probe.probe).To(BeTrue())
+		Expect(breaker.currentState()).To(Equal(CircuitBreakerHalfOpen))
+
+		// The slow request succeeding shouldn't count as a probe or finish it:
+		ok := &http.Response{
+			StatusCode: http.StatusOK,
+		}
+		breaker.record(ctx, slow, ok, nil)
+		breaker.record(ctx, slow, ok, nil)
+		Expect(breaker.currentState()).To(Equal(CircuitBreakerHalfOpen))
+		_, err = breaker.allow(ctx)
+		Expect(err).To(HaveOccurred())
+
+		// The slow request being cancelled shouldn't allow another probe either:
+		cancelCtx, cancel := context.WithCancel(ctx)
+		cancel()
+		breaker.record(cancelCtx, slow, nil, context.Canceled)
+		_, err = breaker.allow(ctx)
+		Expect(err).To(HaveOccurred())
+
+		// The probe should still be able to close the breaker:
+		breaker.record(ctx, probe, ok, nil)
+		Expect(breaker.currentState()).To(Equal(CircuitBreakerHalfOpen))
+		probe, err = breaker.allow(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		breaker.record(ctx, probe, ok, nil)
+		Expect(breaker.currentState()).To(Equal(CircuitBreakerClosed))
+	})
+
+	It("Updates the metrics", func() {
+		registry := prometheus.NewPedanticRegistry()
+		metrics, err := registerCircuitBreakerMetrics("my", registry)
+		Expect(err).ToNot(HaveOccurred())
+		breaker = newCircuitBreaker(logger, "tcp:api.example.com", 1, 1, time.Minute, metrics)
+		client.Transport = breaker.wrap(transport)
+		labels := prometheus.Labels{
+			"server": "tcp:api.example.com",
+		}
+		state := metrics.state.With(labels)
+		rejected := metrics.rejectedCount.With(labels)
+		Expect(testutil.ToFloat64(state)).To(BeNumerically("==", 0))
+		transport.code = http.StatusServiceUnavailable
+		Expect(send()).To(Succeed())
+		Expect(testutil.ToFloat64(state)).To(BeNumerically("==", 1))
+		Expect(send()).ToNot(Succeed())
+		Expect(send()).ToNot(Succeed())
+		Expect(testutil.ToFloat64(rejected)).To(BeNumerically("==", 2))
+	})
+})
+
+var _ = Describe("Client selector circuit breaker", func() {
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	It("Can't be created with invalid failure threshold", func() {
+		selector, err := NewClientSelector().
+			Logger(logger).
+			CircuitBreaker(true).
+			CircuitBreakerFailureThreshold(0).
+			Build(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(selector).To(BeNil())
+		Expect(err.Error()).To(ContainSubstring("failure threshold"))
+	})
+
+	It("Can't be created with invalid cool down", func() {
+		selector, err := NewClientSelector().
+			Logger(logger).
+			CircuitBreaker(true).
+			CircuitBreakerCoolDown(0).
+			Build(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(selector).To(BeNil())
+		Expect(err.Error()).To(ContainSubstring("cool down"))
+	})
+
+	It("Keeps the state when the client is forgotten", func() {
+		// Create a server that always fails:
+		server := httptest.NewServer(http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			},
+		))
+		defer server.Close()
+		address, err := ParseServerAddress(ctx, server.URL)
+		Expect(err).ToNot(HaveOccurred())
+
+		// Create the selector:
+		selector, err := NewClientSelector().
+			Logger(logger).
+			CircuitBreaker(true).
+			CircuitBreakerFailureThreshold(2).
+			Build(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		defer selector.Close()
+
+		// Send requests till the breaker opens:
+		client, err := selector.Select(ctx, address)
+		Expect(err).ToNot(HaveOccurred())
+		for i := 0; i < 2; i++ {
+			response, err := client.Get(server.URL)
+			Expect(err).ToNot(HaveOccurred())
+			response.Body.Close()
+		}
+		Expect(selector.CircuitBreakerState(address)).To(Equal(CircuitBreakerOpen))
+
+		// Forget the client and check that the new one still rejects requests:
+		err = selector.Forget(ctx, address)
+		Expect(err).ToNot(HaveOccurred())
+		client, err = selector.Select(ctx, address)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = client.Get(server.URL)
+		var breakerErr *CircuitBreakerError
+		Expect(errors.As(err, &breakerErr)).To(BeTrue())
+	})
+
+	It("Is disabled by default", func() {
+		server := httptest.NewServer(http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			},
+		))
+		defer server.Close()
+		address, err := ParseServerAddress(ctx, server.URL)
+		Expect(err).ToNot(HaveOccurred())
+		selector, err := NewClientSelector().
+			Logger(logger).
+			Build(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		defer selector.Close()
+		client, err := selector.Select(ctx, address)
+		Expect(err).ToNot(HaveOccurred())
+		for i := 0; i < 10; i++ {
+			response, err := client.Get(server.URL)
+			Expect(err).ToNot(HaveOccurred())
+			response.Body.Close()
+		}
+		Expect(selector.CircuitBreakerState(address)).To(Equal(CircuitBreakerClosed))
+	})
+})
@@ -0,0 +1,179 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the implementation of the TLS client certificates used for mutual TLS
+// authentication.
+
+package internal
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/openshift-online/ocm-sdk-go/logging"
+)
+
+// ClientCertificate contains a TLS client certificate and its private key. When the certificate is
+// loaded from files they are checked every time that a TLS handshake needs the certificate, and
+// loaded again if they have changed. Don't create instances of this type directly, use the
+// LoadClientCertificate or ParseClientCertificate functions instead.
+type ClientCertificate struct {
+	logger   logging.Logger
+	certFile string
+	keyFile  string
+	lock     *sync.Mutex
+	current  *tls.Certificate
+	stamp    string
+	id       string
+}
+
+// LoadClientCertificate loads the client certificate and the private key from the given PEM
+// files. The files will be loaded again when they change, so that rotated certificates are used
+// for new connections without restarting the process.
+func LoadClientCertificate(ctx context.Context, logger logging.Logger,
+	certFile, keyFile string) (result *ClientCertificate, err error) {
+	if logger == nil {
+		err = fmt.Errorf("logger is mandatory")
+		return
+	}
+	if certFile == "" {
+		err = fmt.Errorf("client certificate file is mandatory")
+		return
+	}
+	if keyFile == "" {
+		err = fmt.Errorf("client key file is mandatory")
+		return
+	}
+	stamp, err := fileStamp(certFile, keyFile)
+	if err != nil {
+		return
+	}
+	certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		err = fmt.Errorf(
+			"can't load client certificate from files '%s' and '%s': %w",
+			certFile, keyFile, err,
+		)
+		return
+	}
+	logger.Debug(
+		ctx,
+		"Loaded client certificate from files '%s' and '%s'",
+		certFile, keyFile,
+	)
+	result = &ClientCertificate{
+		logger:   logger,
+		certFile: certFile,
+		keyFile:  keyFile,
+		lock:     &sync.Mutex{},
+		current:  &certificate,
+		stamp:    stamp,
+		id:       fmt.Sprintf("file:%s:%s", certFile, keyFile),
+	}
+	return
+}
+
+// ParseClientCertificate creates a client certificate from the given PEM encoded certificate and
+// private key.
+func ParseClientCertificate(certPEM, keyPEM []byte) (result *ClientCertificate, err error) {
+	certificate, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		err = fmt.Errorf("can't parse client certificate: %w", err)
+		return
+	}
+	sum := sha256.Sum256(certificate.Certificate[0])
+	result = &ClientCertificate{
+		lock:    &sync.Mutex{},
+		current: &certificate,
+		id:      "memory:" + hex.EncodeToString(sum[:8]),
+	}
+	return
+}
+
+// Files returns the names of the files that contain the certificate and the key. They will be
+// empty if the certificate was created from data in memory.
+func (c *ClientCertificate) Files() (certFile, keyFile string) {
+	certFile = c.certFile
+	keyFile = c.keyFile
+	return
+}
+
+// Get returns the current certificate, loading it again if the files have changed. If loading
+// fails the previous certificate is returned, and the load will be tried again in the next
+// handshake. The signature is compatible with the GetClientCertificate field of the tls.Config
+// type.
+func (c *ClientCertificate) Get(info *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.certFile == "" {
+		return c.current, nil
+	}
+	ctx := context.Background()
+	if info != nil && info.Context() != nil {
+		ctx = info.Context()
+	}
+	stamp, err := fileStamp(c.certFile, c.keyFile)
+	if err != nil {
+		c.logger.Warn(ctx, "Can't check client certificate files: %v", err)
+		return c.current, nil
+	}
+	if stamp == c.stamp {
+		return c.current, nil
+	}
+	certificate, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		c.logger.Warn(
+			ctx,
+			"Can't reload client certificate from files '%s' and '%s', will use the "+
+				"previous one: %v",
+			c.certFile, c.keyFile, err,
+		)
+		return c.current, nil
+	}
+	c.current = &certificate
+	c.stamp = stamp
+	c.logger.Info(
+		ctx,
+		"Reloaded client certificate from files '%s' and '%s'",
+		c.certFile, c.keyFile,
+	)
+	return c.current, nil
+}
+
+// key returns the text that identifies the certificate inside the key of the clients table.
+func (c *ClientCertificate) key() string {
+	return c.id
+}
+
+// fileStamp calculates a text that changes when any of the given files changes, using the
+// modification time and the size.
+func fileStamp(files ...string) (result string, err error) {
+	for _, file := range files {
+		var info os.FileInfo
+		info, err = os.Stat(file)
+		if err != nil {
+			err = fmt.Errorf("can't check file '%s': %w", file, err)
+			return
+		}
+		result += fmt.Sprintf("%s:%d:%d;", file, info.ModTime().UnixNano(), info.Size())
+	}
+	return
+}
@@ -0,0 +1,136 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the TLS client certificates.
+
+package internal
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core" // nolint
+	. "github.com/onsi/gomega"             // nolint
+)
+
+var _ = Describe("Client certificate", func() {
+	var ctx context.Context
+	var tmp string
+	var certFile string
+	var keyFile string
+
+	// write generates a certificate with the given name and writes it to the files, changing the
+	// modification time so that the change is detected.
+	write := func(name string, offset time.Duration) {
+		cert, key := makeClientCertificate(name)
+		Expect(os.WriteFile(certFile, cert, 0600)).To(Succeed())
+		Expect(os.WriteFile(keyFile, key, 0600)).To(Succeed())
+		stamp := time.Now().Add(offset)
+		Expect(os.Chtimes(certFile, stamp, stamp)).To(Succeed())
+		Expect(os.Chtimes(keyFile, stamp, stamp)).To(Succeed())
+	}
+
+	// commonName returns the common name of the current certificate.
+	commonName := func(cert *ClientCertificate) string {
+		current, err := cert.Get(nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(current).ToNot(BeNil())
+		Expect(current.Leaf).ToNot(BeNil())
+		return current.Leaf.Subject.CommonName
+	}
+
+	BeforeEach(func() {
+		var err error
+		ctx = context.Background()
+		tmp, err = os.MkdirTemp("", "certs-*")
+		Expect(err).ToNot(HaveOccurred())
+		certFile = filepath.Join(tmp, "tls.crt")
+		keyFile = filepath.Join(tmp, "tls.key")
+	})
+
+	AfterEach(func() {
+		err := os.RemoveAll(tmp)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("Reloads when the files change", func() {
+		write("first", 0)
+		cert, err := LoadClientCertificate(ctx, logger, certFile, keyFile)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(commonName(cert)).To(Equal("first"))
+		write("second", time.Minute)
+		Expect(commonName(cert)).To(Equal("second"))
+	})
+
+	It("Keeps the previous certificate if the new files aren't valid", func() {
+		write("first", 0)
+		cert, err := LoadClientCertificate(ctx, logger, certFile, keyFile)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.WriteFile(keyFile, []byte("junk"), 0600)).To(Succeed())
+		Expect(commonName(cert)).To(Equal("first"))
+		Expect(os.Remove(certFile)).To(Succeed())
+		Expect(commonName(cert)).To(Equal("first"))
+	})
+
+	It("Can be created from memory", func() {
+		certPEM, keyPEM := makeClientCertificate("memory")
+		cert, err := ParseClientCertificate(certPEM, keyPEM)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(commonName(cert)).To(Equal("memory"))
+		certFile, keyFile := cert.Files()
+		Expect(certFile).To(BeEmpty())
+		Expect(keyFile).To(BeEmpty())
+	})
+
+	It("Can't be created from invalid data", func() {
+		cert, err := ParseClientCertificate([]byte("junk"), []byte("junk"))
+		Expect(err).To(HaveOccurred())
+		Expect(cert).To(BeNil())
+	})
+})
+
+// makeClientCertificate generates a self signed client certificate with the given common name and
+// returns the certificate and the key in PEM format. Note that we can't use the function from the
+// testing package because that would create an import cycle.
+func makeClientCertificate(name string) (cert, key []byte) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).ToNot(HaveOccurred())
+	now := time.Now()
+	spec := x509.Certificate{
+		SerialNumber: big.NewInt(now.UnixNano()),
+		Subject: pkix.Name{
+			CommonName: name,
+		},
+		NotBefore: now.Add(-time.Minute),
+		NotAfter:  now.Add(time.Hour),
+	}
+	data, err := x509.CreateCertificate(rand.Reader, &spec, &spec, &privateKey.PublicKey, privateKey)
+	Expect(err).ToNot(HaveOccurred())
+	keyData, err := x509.MarshalECPrivateKey(privateKey)
+	Expect(err).ToNot(HaveOccurred())
+	cert = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: data})
+	key = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyData})
+	return
+}
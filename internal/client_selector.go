@@ -29,7 +29,9 @@ import (
 	"net/http/cookiejar"
 	"os"
 	"sync"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"golang.org/x/net/http2"
 
 	"github.com/openshift-online/ocm-sdk-go/logging"
@@ -38,11 +40,27 @@ import (
 // ClientSelectorBuilder contains the information and logic needed to create an HTTP client
 // selector. Don't create instances of this type directly, use the NewClientSelector function.
 type ClientSelectorBuilder struct {
-	logger            logging.Logger
-	trustedCAs        []interface{}
-	insecure          bool
-	disableKeepAlives bool
-	transportWrappers []func(http.RoundTripper) http.RoundTripper
+	logger                  logging.Logger
+	trustedCAs              []interface{}
+	insecure                bool
+	disableKeepAlives       bool
+	transportWrappers       []func(http.RoundTripper) http.RoundTripper
+	proxy                   *Proxy
+	serverProxies           map[string]*Proxy
+	clientCertificate       *ClientCertificate
+	serverCertificates      map[string]*ClientCertificate
+	dialTimeout             time.Duration
+	tlsHandshakeTimeout     time.Duration
+	responseHeaderTimeout   time.Duration
+	idleConnTimeout         time.Duration
+	http2ReadIdleTimeout    time.Duration
+	http2PingTimeout        time.Duration
+	breakerEnabled          bool
+	breakerFailureThreshold int
+	breakerSuccessThreshold int
+	breakerCoolDown         time.Duration
+	metricsSubsystem        string
+	metricsRegisterer       prometheus.Registerer
 }
 
 // ClientSelector contains the information needed to create select the HTTP client to use to connect
@@ -54,14 +72,48 @@ type ClientSelector struct {
 	disableKeepAlives bool
 	transportWrappers []func(http.RoundTripper) http.RoundTripper
 	cookieJar         http.CookieJar
+	proxy             *Proxy
+	serverProxies     map[string]*Proxy
+	clientCert        *ClientCertificate
+	serverCerts       map[string]*ClientCertificate
 	clientsMutex      *sync.Mutex
-	clientsTable      map[string]*http.Client
+
+	clientsTable map[string]*http.Client
+
+	// Timeouts:
+	dialTimeout           time.Duration
+	tlsHandshakeTimeout   time.Duration
+	responseHeaderTimeout time.Duration
+	idleConnTimeout       time.Duration
+	http2ReadIdleTimeout  time.Duration
+	http2PingTimeout      time.Duration
+
+	// Circuit breakers are indexed by the network and host name or socket of the server, see
+	// the breakerKey method, and they aren't discarded when the clients are forgotten, so that
+	// the state is preserved.
+	breakerEnabled          bool
+	breakerFailureThreshold int
+	breakerSuccessThreshold int
+	breakerCoolDown         time.Duration
+	breakerMetrics          *circuitBreakerMetrics
+	breakersTable           map[string]*circuitBreaker
 }
 
 // NewClientSelector creates a builder that can then be used to configure and create an HTTP client
 // selector.
 func NewClientSelector() *ClientSelectorBuilder {
-	return &ClientSelectorBuilder{}
+	return &ClientSelectorBuilder{
+		dialTimeout:             DefaultDialTimeout,
+		tlsHandshakeTimeout:     DefaultTLSHandshakeTimeout,
+		responseHeaderTimeout:   DefaultResponseHeaderTimeout,
+		idleConnTimeout:         DefaultIdleConnTimeout,
+		http2ReadIdleTimeout:    DefaultHTTP2ReadIdleTimeout,
+		http2PingTimeout:        DefaultHTTP2PingTimeout,
+		breakerFailureThreshold: DefaultCircuitBreakerFailureThreshold,
+		breakerSuccessThreshold: DefaultCircuitBreakerSuccessThreshold,
+		breakerCoolDown:         DefaultCircuitBreakerCoolDown,
+		metricsRegisterer:       prometheus.DefaultRegisterer,
+	}
 }
 
 // Logger sets the logger that will be used by the selector and by the created HTTP clients to write
@@ -127,6 +179,140 @@ func (b *ClientSelectorBuilder) TransportWrappers(
 	return b
 }
 
+// Proxy sets the proxy that will be used to connect to the servers that don't have a specific
+// proxy set with the ServerProxy method. If this isn't explicitly specified then the proxy will be
+// selected using the `HTTP_PROXY`, `HTTPS_PROXY` and `NO_PROXY` environment variables. Connections
+// to Unix sockets never use a proxy.
+func (b *ClientSelectorBuilder) Proxy(value *Proxy) *ClientSelectorBuilder {
+	b.proxy = value
+	return b
+}
+
+// ServerProxy sets the proxy that will be used to connect to the server with the given URL. The
+// URL should be exactly the same text that is used to create the server address with the
+// ParseServerAddress function.
+func (b *ClientSelectorBuilder) ServerProxy(url string, value *Proxy) *ClientSelectorBuilder {
+	if b.serverProxies == nil {
+		b.serverProxies = map[string]*Proxy{}
+	}
+	b.serverProxies[url] = value
+	return b
+}
+
+// ClientCertificate sets the TLS client certificate that will be presented to the servers that
+// don't have a specific certificate set with the ServerClientCertificate method. The default is to
+// not present any client certificate.
+func (b *ClientSelectorBuilder) ClientCertificate(
+	value *ClientCertificate) *ClientSelectorBuilder {
+	b.clientCertificate = value
+	return b
+}
+
+// ServerClientCertificate sets the TLS client certificate that will be presented to the server
+// with the given URL. The URL should be exactly the same text that is used to create the server
+// address with the ParseServerAddress function.
+func (b *ClientSelectorBuilder) ServerClientCertificate(url string,
+	value *ClientCertificate) *ClientSelectorBuilder {
+	if b.serverCertificates == nil {
+		b.serverCertificates = map[string]*ClientCertificate{}
+	}
+	b.serverCertificates[url] = value
+	return b
+}
+
+// DialTimeout sets the maximum time that the clients will wait for a network connection to be
+// established. Zero means no timeout. The default is thirty seconds.
+func (b *ClientSelectorBuilder) DialTimeout(value time.Duration) *ClientSelectorBuilder {
+	b.dialTimeout = value
+	return b
+}
+
+// TLSHandshakeTimeout sets the maximum time that the clients will wait for the TLS handshake to
+// complete. Zero means no timeout. The default is ten seconds.
+func (b *ClientSelectorBuilder) TLSHandshakeTimeout(value time.Duration) *ClientSelectorBuilder {
+	b.tlsHandshakeTimeout = value
+	return b
+}
+
+// ResponseHeaderTimeout sets the maximum time that the clients will wait for the response headers
+// after sending the request. This doesn't include the time to read the response body. Zero means
+// no timeout, and that is the default.
+func (b *ClientSelectorBuilder) ResponseHeaderTimeout(value time.Duration) *ClientSelectorBuilder {
+	b.responseHeaderTimeout = value
+	return b
+}
+
+// IdleConnTimeout sets the maximum time that idle connections will be kept open. Zero means no
+// limit. The default is ninety seconds.
+func (b *ClientSelectorBuilder) IdleConnTimeout(value time.Duration) *ClientSelectorBuilder {
+	b.idleConnTimeout = value
+	return b
+}
+
+// HTTP2ReadIdleTimeout sets the time after which a health check using a ping frame will be sent
+// if no frame has been received in an HTTP/2 connection. Zero disables the health checks. The
+// default is thirty seconds.
+func (b *ClientSelectorBuilder) HTTP2ReadIdleTimeout(value time.Duration) *ClientSelectorBuilder {
+	b.http2ReadIdleTimeout = value
+	return b
+}
+
+// HTTP2PingTimeout sets the time after which an HTTP/2 connection will be closed if the response
+// to a health check ping isn't received. The default is fifteen seconds.
+func (b *ClientSelectorBuilder) HTTP2PingTimeout(value time.Duration) *ClientSelectorBuilder {
+	b.http2PingTimeout = value
+	return b
+}
+
+// CircuitBreaker enables or disables the circuit breakers. When enabled each server will have its
+// own circuit breaker, and requests to servers that have failed repeatedly will be rejected with a
+// CircuitBreakerError without sending them. The default is disabled.
+func (b *ClientSelectorBuilder) CircuitBreaker(flag bool) *ClientSelectorBuilder {
+	b.breakerEnabled = flag
+	return b
+}
+
+// CircuitBreakerFailureThreshold sets the number of consecutive failed requests that will open the
+// circuit breaker of a server. Requests fail when they don't get a response or when the response
+// has a 5xx status code. The default is five.
+func (b *ClientSelectorBuilder) CircuitBreakerFailureThreshold(value int) *ClientSelectorBuilder {
+	b.breakerFailureThreshold = value
+	return b
+}
+
+// CircuitBreakerSuccessThreshold sets the number of consecutive successful probe requests needed
+// to close a half open circuit breaker. The default is one.
+func (b *ClientSelectorBuilder) CircuitBreakerSuccessThreshold(value int) *ClientSelectorBuilder {
+	b.breakerSuccessThreshold = value
+	return b
+}
+
+// CircuitBreakerCoolDown sets the time that an open circuit breaker will reject requests before
+// allowing a probe request. The default is thirty seconds.
+func (b *ClientSelectorBuilder) CircuitBreakerCoolDown(value time.Duration) *ClientSelectorBuilder {
+	b.breakerCoolDown = value
+	return b
+}
+
+// MetricsSubsystem sets the name of the subsystem that will be used to register the circuit
+// breaker metrics with Prometheus. If this isn't explicitly specified, or if it is an empty string,
+// then no metrics will be registered.
+func (b *ClientSelectorBuilder) MetricsSubsystem(value string) *ClientSelectorBuilder {
+	b.metricsSubsystem = value
+	return b
+}
+
+// MetricsRegisterer sets the Prometheus registerer that will be used to register the metrics. The
+// default is to use the default Prometheus registerer.
+func (b *ClientSelectorBuilder) MetricsRegisterer(
+	value prometheus.Registerer) *ClientSelectorBuilder {
+	if value == nil {
+		value = prometheus.DefaultRegisterer
+	}
+	b.metricsRegisterer = value
+	return b
+}
+
 // Build uses the information stored in the builder to create a new HTTP client selector.
 func (b *ClientSelectorBuilder) Build(ctx context.Context) (result *ClientSelector, err error) {
 	// Check parameters:
@@ -134,6 +320,67 @@ func (b *ClientSelectorBuilder) Build(ctx context.Context) (result *ClientSelect
 		err = fmt.Errorf("logger is mandatory")
 		return
 	}
+	timeouts := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"dial timeout", b.dialTimeout},
+		{"TLS handshake timeout", b.tlsHandshakeTimeout},
+		{"response header timeout", b.responseHeaderTimeout},
+		{"idle connection timeout", b.idleConnTimeout},
+		{"HTTP/2 read idle timeout", b.http2ReadIdleTimeout},
+	}
+	for _, timeout := range timeouts {
+		if timeout.value < 0 {
+			err = fmt.Errorf(
+				"%s %s isn't valid, it should be greater or equal than zero",
+				timeout.name, timeout.value,
+			)
+			return
+		}
+	}
+	if b.http2PingTimeout <= 0 {
+		err = fmt.Errorf(
+			"HTTP/2 ping timeout %s isn't valid, it should be greater than zero",
+			b.http2PingTimeout,
+		)
+		return
+	}
+	if b.breakerFailureThreshold <= 0 {
+		err = fmt.Errorf(
+			"circuit breaker failure threshold %d isn't valid, it should be greater "+
+				"than zero",
+			b.breakerFailureThreshold,
+		)
+		return
+	}
+	if b.breakerSuccessThreshold <= 0 {
+		err = fmt.Errorf(
+			"circuit breaker success threshold %d isn't valid, it should be greater "+
+				"than zero",
+			b.breakerSuccessThreshold,
+		)
+		return
+	}
+	if b.breakerCoolDown <= 0 {
+		err = fmt.Errorf(
+			"circuit breaker cool down %s isn't valid, it should be greater than zero",
+			b.breakerCoolDown,
+		)
+		return
+	}
+
+	// Register the circuit breaker metrics:
+	var breakerMetrics *circuitBreakerMetrics
+	if b.breakerEnabled && b.metricsSubsystem != "" {
+		breakerMetrics, err = registerCircuitBreakerMetrics(
+			b.metricsSubsystem,
+			b.metricsRegisterer,
+		)
+		if err != nil {
+			return
+		}
+	}
 
 	// Create the cookie jar:
 	cookieJar, err := b.createCookieJar()
@@ -142,11 +389,23 @@ func (b *ClientSelectorBuilder) Build(ctx context.Context) (result *ClientSelect
 	}
 
 	// Load trusted CAs:
-	trustedCAs, err := b.loadTrustedCAs(ctx)
+	trustedCAs, err := LoadTrustedCAs(ctx, b.logger, b.trustedCAs...)
 	if err != nil {
 		return
 	}
 
+	// Copy the server proxies, so that changes in the builder don't affect the selector:
+	serverProxies := make(map[string]*Proxy, len(b.serverProxies))
+	for url, proxy := range b.serverProxies {
+		serverProxies[url] = proxy
+	}
+
+	// Copy the server client certificates, for the same reason:
+	serverCerts := make(map[string]*ClientCertificate, len(b.serverCertificates))
+	for url, cert := range b.serverCertificates {
+		serverCerts[url] = cert
+	}
+
 	// Create and populate the object:
 	result = &ClientSelector{
 		logger:            b.logger,
@@ -155,29 +414,50 @@ func (b *ClientSelectorBuilder) Build(ctx context.Context) (result *ClientSelect
 		disableKeepAlives: b.disableKeepAlives,
 		transportWrappers: b.transportWrappers,
 		cookieJar:         cookieJar,
+		proxy:             b.proxy,
+		serverProxies:     serverProxies,
+		clientCert:        b.clientCertificate,
+		serverCerts:       serverCerts,
 		clientsMutex:      &sync.Mutex{},
 		clientsTable:      map[string]*http.Client{},
+
+		dialTimeout:           b.dialTimeout,
+		tlsHandshakeTimeout:   b.tlsHandshakeTimeout,
+		responseHeaderTimeout: b.responseHeaderTimeout,
+		idleConnTimeout:       b.idleConnTimeout,
+		http2ReadIdleTimeout:  b.http2ReadIdleTimeout,
+		http2PingTimeout:      b.http2PingTimeout,
+
+		breakerEnabled:          b.breakerEnabled,
+		breakerFailureThreshold: b.breakerFailureThreshold,
+		breakerSuccessThreshold: b.breakerSuccessThreshold,
+		breakerCoolDown:         b.breakerCoolDown,
+		breakerMetrics:          breakerMetrics,
+		breakersTable:           map[string]*circuitBreaker{},
 	}
 
 	return
 }
 
-func (b *ClientSelectorBuilder) loadTrustedCAs(ctx context.Context) (result *x509.CertPool,
-	err error) {
+// LoadTrustedCAs creates a certificate pool containing the system certificate authorities and the
+// ones from the given sources. Each source can be a certificate pool, which replaces the
+// certificates loaded so far, or the name of a file containing PEM encoded certificates.
+func LoadTrustedCAs(ctx context.Context, logger logging.Logger,
+	sources ...interface{}) (result *x509.CertPool, err error) {
 	result, err = loadSystemCAs()
 	if err != nil {
 		return
 	}
-	for _, ca := range b.trustedCAs {
+	for _, ca := range sources {
 		switch source := ca.(type) {
 		case *x509.CertPool:
-			b.logger.Debug(
+			logger.Debug(
 				ctx,
 				"Default trusted CA certificates have been explicitly replaced",
 			)
 			result = source
 		case string:
-			b.logger.Debug(
+			logger.Debug(
 				ctx,
 				"Loading trusted CA certificates from file '%s'",
 				source,
@@ -232,7 +512,7 @@ func (s *ClientSelector) Select(ctx context.Context, address *ServerAddress) (cl
 		return
 	}
 	s.logger.Debug(ctx, "Client for key '%s' doesn't exist, will create it", key)
-	client, err = s.create(ctx, address)
+	client, err = s.create(ctx, key, address)
 	if err != nil {
 		return
 	}
@@ -278,21 +558,77 @@ func (s *ClientSelector) key(address *ServerAddress) string {
 	//      rh-api.app-sre-prod-04.i5h0.p1.openshiftapps.com, not sso.redhat.com
 	//
 	// To avoid this we add the host name or socket path as a suffix to the key.
+	//
+	// Servers that use different proxies or different client certificates also need different
+	// clients, so those are added as well.
 	key := address.Network
 	switch address.Network {
 	case UnixNetwork:
 		key = fmt.Sprintf("%s:%s", key, address.Socket)
 	case TCPNetwork:
 		key = fmt.Sprintf("%s:%s", key, address.Host)
+		proxy := s.selectProxy(address)
+		if proxy != nil {
+			key = fmt.Sprintf("%s|proxy:%s", key, proxy.key())
+		}
+	}
+	cert := s.selectClientCertificate(address)
+	if cert != nil {
+		key = fmt.Sprintf("%s|cert:%s", key, cert.key())
 	}
 	return key
 }
 
+// breakerKey calculates from the given server address the key that is used to store circuit
+// breakers in the table. It is also used as the name of the server in the metrics and in the
+// errors of the breakers, so it only contains the network and the host name or socket, for example
+// `tcp:api.openshift.com`. It doesn't contain the proxy or client certificate details that are
+// part of the key of the clients table, as those would leak into the metrics and would increase
+// the number of label values each time that they change.
+func (s *ClientSelector) breakerKey(address *ServerAddress) string {
+	if address.Network == UnixNetwork {
+		return address.Network + ":" + address.Socket
+	}
+	return address.Network + ":" + address.Host
+}
+
+// selectClientCertificate returns the client certificate that should be presented to the given
+// server address, or nil if no certificate should be presented.
+func (s *ClientSelector) selectClientCertificate(address *ServerAddress) *ClientCertificate {
+	cert, ok := s.serverCerts[address.Text]
+	if ok {
+		return cert
+	}
+	return s.clientCert
+}
+
+// selectProxy returns the explicitly configured proxy that should be used to connect to the given
+// server address, or nil if there is no explicitly configured proxy.
+func (s *ClientSelector) selectProxy(address *ServerAddress) *Proxy {
+	proxy, ok := s.serverProxies[address.Text]
+	if ok {
+		return proxy
+	}
+	return s.proxy
+}
+
+// CircuitBreakerState returns the state of the circuit breaker for the given server address. The
+// result will always be closed if circuit breakers aren't enabled.
+func (s *ClientSelector) CircuitBreakerState(address *ServerAddress) CircuitBreakerState {
+	s.clientsMutex.Lock()
+	breaker, ok := s.breakersTable[s.breakerKey(address)]
+	s.clientsMutex.Unlock()
+	if !ok {
+		return CircuitBreakerClosed
+	}
+	return breaker.currentState()
+}
+
 // create creates a new HTTP client to use to connect to the given address.
-func (s *ClientSelector) create(ctx context.Context, address *ServerAddress) (result *http.Client,
-	err error) {
+func (s *ClientSelector) create(ctx context.Context, key string,
+	address *ServerAddress) (result *http.Client, err error) {
 	// Create the transport:
-	transport, err := s.createTransport(ctx, address)
+	transport, err := s.createTransport(ctx, key, address)
 	if err != nil {
 		return
 	}
@@ -318,7 +654,7 @@ func (s *ClientSelector) create(ctx context.Context, address *ServerAddress) (re
 }
 
 // createTransport creates a new HTTP transport to use to connect to the given server address.
-func (s *ClientSelector) createTransport(ctx context.Context,
+func (s *ClientSelector) createTransport(ctx context.Context, key string,
 	address *ServerAddress) (result http.RoundTripper, err error) {
 	// Prepare the TLS configuration:
 	// #nosec 402
@@ -329,63 +665,147 @@ func (s *ClientSelector) createTransport(ctx context.Context,
 		RootCAs:            s.trustedCAs,
 	}
 
+	// Present the client certificate, if any. Note that the certificate is retrieved in each
+	// handshake so that it can be reloaded when it changes.
+	cert := s.selectClientCertificate(address)
+	if cert != nil {
+		config.GetClientCertificate = cert.Get
+	}
+
+	// Prepare the dialer, that is used for TCP and Unix sockets:
+	dialer := &net.Dialer{
+		Timeout: s.dialTimeout,
+	}
+
 	// Create the transport:
 	if address.Protocol != H2CProtocol {
 		// Create a regular transport. Note that this does support HTTP/2 with TLS, but
 		// not h2c:
 		transport := &http.Transport{
-			TLSClientConfig:    config,
-			Proxy:              http.ProxyFromEnvironment,
-			DisableKeepAlives:  s.disableKeepAlives,
-			DisableCompression: false,
-			ForceAttemptHTTP2:  true,
+			TLSClientConfig:       config,
+			Proxy:                 http.ProxyFromEnvironment,
+			DialContext:           dialer.DialContext,
+			DisableKeepAlives:     s.disableKeepAlives,
+			DisableCompression:    false,
+			ForceAttemptHTTP2:     true,
+			TLSHandshakeTimeout:   s.tlsHandshakeTimeout,
+			ResponseHeaderTimeout: s.responseHeaderTimeout,
+			IdleConnTimeout:       s.idleConnTimeout,
+		}
+
+		// Use the explicitly configured proxy, if any:
+		proxy := s.selectProxy(address)
+		if proxy != nil {
+			s.logger.Debug(
+				ctx,
+				"Client for key '%s' will use proxy '%s'",
+				key, proxy.URL(),
+			)
+			transport.Proxy = proxy.Select
 		}
 
 		// In order to use Unix sockets we need to explicitly set dialers that use `unix` as
 		// network and the socket file as address, otherwise the HTTP client will always use
-		// `tcp` as the network and the host name from the request as the address:
+		// `tcp` as the network and the host name from the request as the address. Note that
+		// proxies need to be disabled in that case, as otherwise the transport would send
+		// the requests in proxy format to the socket. The TLS handshake timeout of the
+		// transport doesn't apply when the TLS dialer is replaced, so we need to add it to
+		// the dial timeout.
 		if address.Network == UnixNetwork {
+			transport.Proxy = nil
 			transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn,
 				error) {
-				dialer := net.Dialer{}
 				return dialer.DialContext(ctx, UnixNetwork, address.Socket)
 			}
+			tlsDialer := &net.Dialer{}
+			if s.dialTimeout > 0 && s.tlsHandshakeTimeout > 0 {
+				tlsDialer.Timeout = s.dialTimeout + s.tlsHandshakeTimeout
+			}
 			transport.DialTLSContext = func(ctx context.Context, _, _ string) (net.Conn,
 				error) {
 				// Append server name manually for TLS with sockets
 				config.ServerName = address.Host
 				dialer := tls.Dialer{
-					Config: config,
+					NetDialer: tlsDialer,
+					Config:    config,
 				}
 				return dialer.DialContext(ctx, UnixNetwork, address.Socket)
 			}
 		}
 
+		// Configure the HTTP/2 health checks. Note that this also enables HTTP/2 for the
+		// transport, like the ForceAttemptHTTP2 flag.
+		var http2Transport *http2.Transport
+		http2Transport, err = http2.ConfigureTransports(transport)
+		if err != nil {
+			return
+		}
+		http2Transport.ReadIdleTimeout = s.http2ReadIdleTimeout
+		http2Transport.PingTimeout = s.http2PingTimeout
+
 		// Prepare the result:
 		result = transport
 	} else {
-		// In order to use h2c we need to tell the transport to allow the `http` scheme:
+		// In order to use h2c we need to tell the transport to allow the `http` scheme. Note
+		// that this transport doesn't support proxies.
+		if address.Network == TCPNetwork && s.selectProxy(address) != nil {
+			s.logger.Warn(
+				ctx,
+				"Proxy will be ignored for key '%s' because h2c doesn't support proxies",
+				key,
+			)
+		}
+		// Note that the HTTP/2 transport doesn't support the idle connection timeout when it
+		// isn't created from a regular transport, so it will not be applied to h2c.
 		transport := &http2.Transport{
 			AllowHTTP:          true,
 			DisableCompression: false,
+			ReadIdleTimeout:    s.http2ReadIdleTimeout,
+			PingTimeout:        s.http2PingTimeout,
 		}
 
 		// We also need to ignore TLS configuration when dialing, and explicitly set the
 		// network and socket when using Unix sockets:
 		if address.Network == UnixNetwork {
 			transport.DialTLSContext = func(ctx context.Context, _, _ string, cfg *tls.Config) (net.Conn, error) {
-				var d net.Dialer
-				return d.DialContext(ctx, UnixNetwork, address.Socket)
+				return dialer.DialContext(ctx, UnixNetwork, address.Socket)
 			}
 		} else {
 			transport.DialTLSContext = func(ctx context.Context, network, addr string, cfg *tls.Config) (net.Conn, error) {
-				var d net.Dialer
-				return d.DialContext(ctx, network, addr)
+				return dialer.DialContext(ctx, network, addr)
 			}
 		}
 
-		// Prepare the result:
+		// Prepare the result. The HTTP/2 transport doesn't support the response header
+		// timeout, so we need to implement it with a round tripper.
 		result = transport
+		if s.responseHeaderTimeout > 0 {
+			result = &responseHeaderTimeoutRoundTripper{
+				timeout: s.responseHeaderTimeout,
+				next:    transport,
+			}
+		}
+	}
+
+	// The circuit breaker wraps directly the transport, so that it sees each retry as a separate
+	// request, and so that once it is open retries are rejected inmediately. Note that the
+	// breaker is reused if it already exists, because the client may have been forgotten and
+	// created again.
+	if s.breakerEnabled {
+		server := s.breakerKey(address)
+		breaker, ok := s.breakersTable[server]
+		if !ok {
+			breaker = newCircuitBreaker(
+				s.logger,
+				server,
+				s.breakerFailureThreshold,
+				s.breakerSuccessThreshold,
+				s.breakerCoolDown,
+				s.breakerMetrics,
+			)
+			s.breakersTable[server] = breaker
+		}
+		result = breaker.wrap(result)
 	}
 
 	// Transport wrappers are stored in the order that the round trippers that they create
@@ -400,9 +820,26 @@ func (s *ClientSelector) createTransport(ctx context.Context,
 // TrustedCAs sets returns the certificate pool that contains the certificate authorities that are
 // trusted by the HTTP clients.
 func (s *ClientSelector) TrustedCAs() *x509.CertPool {
+	s.clientsMutex.Lock()
+	defer s.clientsMutex.Unlock()
 	return s.trustedCAs
 }
 
+// SetTrustedCAs replaces the certificate pool that contains the certificate authorities that are
+// trusted by the HTTP clients. Existing clients are discarded so that new ones using the new pool
+// will be created when needed. Requests that are in progress will continue using the old clients,
+// and their connections will be closed when they are no longer in use.
+func (s *ClientSelector) SetTrustedCAs(ctx context.Context, value *x509.CertPool) {
+	s.clientsMutex.Lock()
+	defer s.clientsMutex.Unlock()
+	s.trustedCAs = value
+	for key, client := range s.clientsTable {
+		delete(s.clientsTable, key)
+		client.CloseIdleConnections()
+	}
+	s.logger.Debug(ctx, "Replaced trusted CAs and discarded existing clients")
+}
+
 // Insecure returns the flag that indicates if insecure communication with the server is enabled.
 func (s *ClientSelector) Insecure() bool {
 	return s.insecure
@@ -415,6 +852,8 @@ func (s *ClientSelector) DisableKeepAlives() bool {
 
 // Close closes all the connections used by all the clients created by the selector.
 func (s *ClientSelector) Close() error {
+	s.clientsMutex.Lock()
+	defer s.clientsMutex.Unlock()
 	for _, client := range s.clientsTable {
 		client.CloseIdleConnections()
 	}
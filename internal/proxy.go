@@ -0,0 +1,114 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the implementation of the explicitly configured outbound proxies.
+
+package internal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"strings"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// Proxy contains the configuration of an outbound proxy. Don't create instances of this type
+// directly, use the ParseProxy function instead.
+type Proxy struct {
+	url      *neturl.URL
+	noProxy  []string
+	function func(*neturl.URL) (*neturl.URL, error)
+}
+
+// ParseProxy parses the given proxy URL and creates the corresponding proxy configuration. The
+// scheme of the URL can be `http`, `https` or `socks5`. The user name and password, if present in
+// the URL, are used to authenticate to the proxy.
+//
+// The no proxy values have the same syntax and semantics than the `NO_PROXY` environment
+// variable: each value can be a host name, a domain name starting with a dot, an IP address, an IP
+// address range in CIDR notation or an asterisk, optionally followed by a port number. Requests for
+// hosts that match any of these values are sent directly, without the proxy. Requests for
+// `localhost` and loopback addresses are always sent directly.
+func ParseProxy(text string, noProxy ...string) (result *Proxy, err error) {
+	parsed, err := neturl.Parse(text)
+	if err != nil {
+		err = fmt.Errorf("can't parse proxy URL: %w", err)
+		return
+	}
+	switch parsed.Scheme {
+	case "http", "https", "socks5":
+	default:
+		err = fmt.Errorf(
+			"scheme '%s' of proxy URL '%s' isn't supported, it should be 'http', "+
+				"'https' or 'socks5'",
+			parsed.Scheme, parsed.Redacted(),
+		)
+		return
+	}
+	if parsed.Hostname() == "" {
+		err = fmt.Errorf("proxy URL '%s' doesn't contain a host name", parsed.Redacted())
+		return
+	}
+	values := make([]string, 0, len(noProxy))
+	for _, value := range noProxy {
+		value = strings.TrimSpace(value)
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+	config := &httpproxy.Config{
+		HTTPProxy:  parsed.String(),
+		HTTPSProxy: parsed.String(),
+		NoProxy:    strings.Join(values, ","),
+	}
+	result = &Proxy{
+		url:      parsed,
+		noProxy:  values,
+		function: config.ProxyFunc(),
+	}
+	return
+}
+
+// URL returns the URL of the proxy, with the password redacted.
+func (p *Proxy) URL() string {
+	return p.url.Redacted()
+}
+
+// NoProxy returns the values that define the hosts that are connected directly.
+func (p *Proxy) NoProxy() []string {
+	result := make([]string, len(p.noProxy))
+	copy(result, p.noProxy)
+	return result
+}
+
+// Select returns the URL of the proxy that should be used for the given request, or nil if the
+// request should be sent directly. The signature is compatible with the Proxy field of the
+// http.Transport type.
+func (p *Proxy) Select(request *http.Request) (*neturl.URL, error) {
+	return p.function(request.URL)
+}
+
+// key returns the text that identifies the proxy inside the key of the clients table. It uses a
+// hash of the complete URL, so that proxies that differ only in the credentials get different
+// clients without putting the password in the key.
+func (p *Proxy) key() string {
+	sum := sha256.Sum256([]byte(p.url.String()))
+	return hex.EncodeToString(sum[:]) + "|" + strings.Join(p.noProxy, ",")
+}
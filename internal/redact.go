@@ -0,0 +1,224 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains functions that remove security sensitive data from request and response
+// bodies before they are written to the log or to other places.
+
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/openshift-online/ocm-sdk-go/helpers"
+)
+
+// RedactionString is the text that replaces sensitive values.
+const RedactionString = "***"
+
+// redactFields are the names of the fields whose values are replaced by the redaction string.
+var redactFields = map[string]bool{
+	"access_token":      true,
+	"admin":             true,
+	"id_token":          true,
+	"refresh_token":     true,
+	"password":          true,
+	"client_secret":     true,
+	"kubeconfig":        true,
+	"ssh":               true,
+	"access_key_id":     true,
+	"secret_access_key": true,
+}
+
+// redactHeaders are the names of the HTTP headers whose values are replaced by the redaction
+// string.
+var redactHeaders = map[string]bool{
+	"Authorization": true,
+	"Cookie":        true,
+	"Set-Cookie":    true,
+}
+
+// RedactField returns true if the value of the JSON or form field with the given name should be
+// redacted.
+func RedactField(name string) bool {
+	return redactFields[name]
+}
+
+// RedactJSON parses the given JSON document and returns a copy, indented with two spaces by the
+// stream created by helpers.NewStream, where the values of the sensitive fields have been replaced
+// by the redaction string.
+func RedactJSON(data []byte) (result []byte, err error) {
+	it, err := helpers.NewIterator(data)
+	if err != nil {
+		return
+	}
+	var buffer bytes.Buffer
+	str := helpers.NewStream(&buffer)
+	redactJSON(it, str)
+	err = str.Flush()
+	if err != nil {
+		return
+	}
+	result = buffer.Bytes()
+	return
+}
+
+// RedactForm parses the given URL encoded form and returns a copy where the values of the
+// sensitive fields have been replaced by the redaction string. The fields of the result are sorted
+// by name, so that the output is predictable.
+func RedactForm(data []byte) (result []byte, err error) {
+	// Parse the form:
+	form, err := url.ParseQuery(string(data))
+	if err != nil {
+		return
+	}
+
+	// Get and sort the names of the fields of the form, so that the generated output will be
+	// predictable:
+	names := make([]string, 0, len(form))
+	for name := range form {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Write the names and values to the buffer while redacting the sensitive fields:
+	buffer := &bytes.Buffer{}
+	for _, name := range names {
+		key := url.QueryEscape(name)
+		values := form[name]
+		for _, value := range values {
+			var redacted string
+			if redactFields[name] {
+				redacted = RedactionString
+			} else {
+				redacted = url.QueryEscape(value)
+			}
+			if buffer.Len() > 0 {
+				buffer.WriteByte('&') // #nosec G104
+			}
+			buffer.WriteString(key)      // #nosec G104
+			buffer.WriteByte('=')        // #nosec G104
+			buffer.WriteString(redacted) // #nosec G104
+		}
+	}
+	result = buffer.Bytes()
+	return
+}
+
+// RedactHeader returns a copy of the given header where the values of the security sensitive
+// headers have been replaced by the redaction string.
+func RedactHeader(header http.Header) http.Header {
+	if len(header) == 0 {
+		return nil
+	}
+	result := header.Clone()
+	for name, values := range result {
+		if !redactHeaders[http.CanonicalHeaderKey(name)] {
+			continue
+		}
+		for i := range values {
+			values[i] = RedactionString
+		}
+	}
+	return result
+}
+
+// RedactBody returns a copy of the given body where the values of the security sensitive fields
+// have been redacted. URL encoded forms are detected using the given content type, and any body
+// that is a valid JSON document is redacted as JSON regardless of the content type, as servers
+// and clients don't always set it correctly. Bodies of any other type, for example plain text
+// that may contain a bare token, can't be redacted reliably, so they are replaced by the
+// redaction string.
+func RedactBody(contentType string, body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "application/x-www-form-urlencoded" {
+		redacted, err := RedactForm(body)
+		if err == nil {
+			return string(redacted)
+		}
+		return RedactionString
+	}
+	if json.Valid(body) {
+		redacted, err := RedactJSON(body)
+		if err == nil {
+			return string(redacted)
+		}
+	}
+	return RedactionString
+}
+
+// redactJSON copies the value from the iterator to the stream, replacing sensitive fields with the
+// redaction string.
+func redactJSON(it *jsoniter.Iterator, str *jsoniter.Stream) {
+	switch it.WhatIsNext() {
+	case jsoniter.ObjectValue:
+		str.WriteObjectStart()
+		first := true
+		for field := it.ReadObject(); field != ""; field = it.ReadObject() {
+			if !first {
+				str.WriteMore()
+			}
+			first = false
+			str.WriteObjectField(field)
+			if v, ok := redactFields[field]; ok && v {
+				str.WriteString(RedactionString)
+				it.Skip()
+				continue
+			}
+			redactJSON(it, str)
+		}
+		str.WriteObjectEnd()
+	case jsoniter.ArrayValue:
+		str.WriteArrayStart()
+		first := true
+		for it.ReadArray() {
+			if !first {
+				str.WriteMore()
+			}
+			first = false
+			redactJSON(it, str)
+		}
+		str.WriteArrayEnd()
+	case jsoniter.StringValue:
+		str.WriteString(it.ReadString())
+	case jsoniter.NumberValue:
+		v := it.ReadNumber()
+		i, err := v.Int64()
+		if err == nil {
+			str.WriteInt64(i)
+			break
+		}
+		f, err := v.Float64()
+		if err == nil {
+			str.WriteFloat64(f)
+		}
+	case jsoniter.BoolValue:
+		str.WriteBool(it.ReadBool())
+	case jsoniter.NilValue:
+		str.WriteNil()
+		// Skip because no reading from it is involved
+		it.Skip()
+	}
+}
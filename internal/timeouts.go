@@ -0,0 +1,117 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the default values of the transport timeouts, and the round tripper that
+// implements the response header timeout for transports that don't support it.
+
+package internal
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Default values of the transport timeouts. They are the same than the ones used by the default
+// transport of the Go HTTP library, except the HTTP/2 ones, that the library disables by default.
+const (
+	DefaultDialTimeout           = 30 * time.Second
+	DefaultTLSHandshakeTimeout   = 10 * time.Second
+	DefaultResponseHeaderTimeout = 0
+	DefaultIdleConnTimeout       = 90 * time.Second
+	DefaultHTTP2ReadIdleTimeout  = 30 * time.Second
+	DefaultHTTP2PingTimeout      = 15 * time.Second
+)
+
+// ResponseHeaderTimeoutError is the error returned when the response headers aren't received
+// in the time configured for the transport.
+type ResponseHeaderTimeoutError struct {
+	Duration time.Duration
+}
+
+// Error is the implementation of the error interface.
+func (e *ResponseHeaderTimeoutError) Error() string {
+	return fmt.Sprintf("timeout awaiting response headers after %s", e.Duration)
+}
+
+// Timeout returns true, so that the error is considered a timeout by the retry logic.
+func (e *ResponseHeaderTimeoutError) Timeout() bool {
+	return true
+}
+
+// responseHeaderTimeoutRoundTripper cancels requests when the response headers aren't received in
+// the configured time. This is intended for the h2c transport, that doesn't support this timeout.
+type responseHeaderTimeoutRoundTripper struct {
+	timeout time.Duration
+	next    http.RoundTripper
+}
+
+// Make sure that we implement the interface:
+var _ http.RoundTripper = (*responseHeaderTimeoutRoundTripper)(nil)
+
+// RoundTrip is the implementation of the round tripper interface.
+func (t *responseHeaderTimeoutRoundTripper) RoundTrip(
+	request *http.Request) (response *http.Response, err error) {
+	// Note that we can't use a context with a deadline, as that would also apply to the reading
+	// of the body, so we use a timer that is stopped as soon as the response headers are
+	// received instead.
+	ctx, cancel := context.WithCancel(request.Context())
+	timer := time.AfterFunc(t.timeout, cancel)
+	response, err = t.next.RoundTrip(request.WithContext(ctx))
+	if !timer.Stop() {
+		if response != nil {
+			response.Body.Close()
+			response = nil
+		}
+		err = &ResponseHeaderTimeoutError{
+			Duration: t.timeout,
+		}
+		cancel()
+		return
+	}
+	if err != nil {
+		cancel()
+		return
+	}
+	response.Body = &cancelBody{
+		ReadCloser: response.Body,
+		cancel:     cancel,
+	}
+	return
+}
+
+// CloseIdleConnections closes the idle connections of the wrapped transport.
+func (t *responseHeaderTimeoutRoundTripper) CloseIdleConnections() {
+	closer, ok := t.next.(interface{ CloseIdleConnections() })
+	if ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+// cancelBody is a response body that cancels the context of the request when it is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close is the implementation of the io.Closer interface.
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -234,9 +234,14 @@ func (b *FlagBuilder) Build(ctx context.Context) (result *Flag, err error) {
 		}
 	}
 
-	// Create and populate the flag:
+	// Create and populate the flag. Note that the logger includes the names of the flag and the
+	// process as fields, so there is no need to add them to the messages.
 	result = &Flag{
-		logger:        b.logger,
+		logger: logging.With(
+			b.logger,
+			logging.FlagField, b.name,
+			logging.ProcessField, b.process,
+		),
 		handle:        b.handle,
 		name:          b.name,
 		process:       b.process,
@@ -313,8 +318,8 @@ func (f *Flag) check() {
 	if err != nil {
 		f.logger.Error(
 			ctx,
-			"Process '%s' can't get current time for flag '%s': %v",
-			f.process, f.name, err,
+			"Can't get current time: %v",
+			err,
 		)
 		f.lower(ctx)
 		f.schedule(ctx, f.retryInterval)
@@ -326,8 +331,8 @@ func (f *Flag) check() {
 	if err != nil {
 		f.logger.Error(
 			ctx,
-			"Process '%s' can't load state for flag '%s': %v",
-			f.process, f.name, err,
+			"Can't load state: %v",
+			err,
 		)
 		f.lower(ctx)
 		f.schedule(ctx, f.retryInterval)
@@ -341,8 +346,8 @@ func (f *Flag) check() {
 		if err != nil {
 			f.logger.Error(
 				ctx,
-				"Process '%s' can't create initial state for flag: %v",
-				f.process, f.name, err,
+				"Can't create initial state: %v",
+				err,
 			)
 			f.lower(ctx)
 			f.schedule(ctx, f.retryInterval)
@@ -351,19 +356,13 @@ func (f *Flag) check() {
 		if !created {
 			f.logger.Debug(
 				ctx,
-				"Process '%s' found a conflict when trying to create the initial "+
-					"state for flag '%s'",
-				f.process, f.name,
+				"Found a conflict when trying to create the initial state",
 			)
 			f.lower(ctx)
 			f.schedule(ctx, f.checkInterval)
 			return
 		}
-		f.logger.Info(
-			ctx,
-			"Process '%s' successfully created initial state for flag '%s'",
-			f.process, f.name,
-		)
+		f.logger.Info(ctx, "Successfully created initial state")
 		f.raise(ctx)
 		f.schedule(ctx, f.checkInterval)
 		return
@@ -378,8 +377,8 @@ func (f *Flag) check() {
 		if err != nil {
 			f.logger.Error(
 				ctx,
-				"Process '%s' can't update the timestamp for flag '%s': %v",
-				f.process, f.name, err,
+				"Can't update the timestamp: %v",
+				err,
 			)
 			f.lower(ctx)
 			f.schedule(ctx, f.retryInterval)
@@ -388,19 +387,13 @@ func (f *Flag) check() {
 		if !updated {
 			f.logger.Info(
 				ctx,
-				"Process '%s' found a conflict when trying to update the "+
-					"timestamp for flag '%s'",
-				f.process, f.name,
+				"Found a conflict when trying to update the timestamp",
 			)
 			f.lower(ctx)
 			f.schedule(ctx, f.checkInterval)
 			return
 		}
-		f.logger.Debug(
-			ctx,
-			"Process '%s' successfully updated the timestamp for flag '%s'",
-			f.process, f.name,
-		)
+		f.logger.Debug(ctx, "Successfully updated the timestamp")
 		f.raise(ctx)
 		f.schedule(ctx, f.checkInterval)
 		return
@@ -412,18 +405,17 @@ func (f *Flag) check() {
 	if excess > 0 {
 		f.logger.Info(
 			ctx,
-			"Process '%s' detected that flag '%s' is currently held by process '%s' "+
-				"but it should have been renewed %s ago, will try to get hold "+
-				"of it",
-			f.process, f.name, holder, excess,
+			"Flag is currently held by process '%s' but it should have been "+
+				"renewed %s ago, will try to get hold of it",
+			holder, excess,
 		)
 		var updated bool
 		updated, err = f.updateHolder(ctx, version, now)
 		if err != nil {
 			f.logger.Error(
 				ctx,
-				"Process '%s' can't update holder for flag '%s': %v",
-				f.process, f.name,
+				"Can't update holder: %v",
+				err,
 			)
 			f.lower(ctx)
 			f.schedule(ctx, f.retryInterval)
@@ -432,19 +424,13 @@ func (f *Flag) check() {
 		if !updated {
 			f.logger.Info(
 				ctx,
-				"Process '%s' found a conflict when trying to update the holder "+
-					"for flag '%s'",
-				f.process, f.name,
+				"Found a conflict when trying to update the holder",
 			)
 			f.lower(ctx)
 			f.schedule(ctx, f.checkInterval)
 			return
 		}
-		f.logger.Debug(
-			ctx,
-			"Process '%s' successfully updated holder for flag '%s'",
-			f.process, f.name,
-		)
+		f.logger.Debug(ctx, "Successfully updated holder")
 		f.raise(ctx)
 		f.schedule(ctx, f.checkInterval)
 		return
@@ -454,9 +440,8 @@ func (f *Flag) check() {
 	// do is check again later:
 	f.logger.Debug(
 		ctx,
-		"Process '%s' found that flag '%s' is currently held by process '%s' and it "+
-			"should be renewed in %s",
-		f.process, f.name, holder, -excess,
+		"Flag is currently held by process '%s' and it should be renewed in %s",
+		holder, -excess,
 	)
 	f.lower(ctx)
 	f.schedule(ctx, f.checkInterval)
@@ -473,7 +458,7 @@ func (f *Flag) schedule(ctx context.Context, d time.Duration) {
 	d += delta
 
 	// Reset the timer:
-	f.logger.Debug(ctx, "Process '%s' will check flag '%s' in %s", f.process, f.name, d)
+	f.logger.Debug(ctx, "Will check flag in %s", d)
 	f.timer.Reset(d)
 }
 
@@ -645,11 +630,7 @@ func (f *Flag) updateHolder(ctx context.Context, version int64, timestamp time.T
 func (f *Flag) raise(ctx context.Context) {
 	old := atomic.SwapInt32(&f.value, 1)
 	if old == 0 {
-		f.logger.Debug(
-			ctx,
-			"Process '%s' is now holding flag '%s'",
-			f.process, f.name,
-		)
+		f.logger.Debug(ctx, "Process is now holding the flag")
 	}
 	if f.stateMetric != nil {
 		f.stateMetric.WithLabelValues(f.name, f.process).Set(1)
@@ -660,11 +641,7 @@ func (f *Flag) raise(ctx context.Context) {
 func (f *Flag) lower(ctx context.Context) {
 	old := atomic.SwapInt32(&f.value, 0)
 	if old == 1 {
-		f.logger.Debug(
-			ctx,
-			"Process '%s' is no longer holding flag '%s'",
-			f.process, f.name,
-		)
+		f.logger.Debug(ctx, "Process is no longer holding the flag")
 	}
 	if f.stateMetric != nil {
 		f.stateMetric.WithLabelValues(f.name, f.process).Set(0)
@@ -0,0 +1,172 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the support for structured key/value fields in log messages.
+
+package logging
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FieldLogger is an optional interface that loggers can implement in order to support structured
+// key/value fields. When the logger used by the SDK implements this interface the SDK will pass
+// values like the HTTP method, the URL path, the response status or the retry attempt as fields
+// instead of formatting them into the text of the message.
+type FieldLogger interface {
+	Logger
+
+	// With returns a logger that adds the given fields to all the messages that it sends to the
+	// log. The fields are given as alternating keys and values, where the keys are strings. For
+	// example:
+	//
+	//	logger.With("method", "GET", "status", 200).Info(ctx, "Request sent")
+	With(keyvals ...interface{}) Logger
+}
+
+// Names of the fields used by the SDK:
+const (
+	AttemptField = "attempt"
+	FlagField    = "flag"
+	HostField    = "host"
+	MethodField  = "method"
+	PathField    = "path"
+	ProcessField = "process"
+	StatusField  = "status"
+)
+
+// With returns a logger that adds the given fields to all the messages. If the given logger
+// implements the FieldLogger interface its With method will be used. Otherwise the result will be
+// a logger that appends the fields to the text of the messages, in `key=value` format, so that the
+// information isn't lost.
+func With(logger Logger, keyvals ...interface{}) Logger {
+	if len(keyvals) == 0 {
+		return logger
+	}
+	fieldLogger, ok := logger.(FieldLogger)
+	if ok {
+		return fieldLogger.With(keyvals...)
+	}
+	return &textFieldLogger{
+		logger: logger,
+		fields: keyvals,
+	}
+}
+
+// textFieldLogger is the logger that adds the fields to the text of the messages, used for loggers
+// that don't implement the FieldLogger interface. Note that the fields are formatted only when a
+// message is actually written, as most of the times the logger is created but not used.
+type textFieldLogger struct {
+	logger Logger
+	fields []interface{}
+}
+
+// Make sure that we implement the interface:
+var _ FieldLogger = (*textFieldLogger)(nil)
+
+// DebugEnabled is part of the implementation of the Logger interface.
+func (l *textFieldLogger) DebugEnabled() bool {
+	return l.logger.DebugEnabled()
+}
+
+// InfoEnabled is part of the implementation of the Logger interface.
+func (l *textFieldLogger) InfoEnabled() bool {
+	return l.logger.InfoEnabled()
+}
+
+// WarnEnabled is part of the implementation of the Logger interface.
+func (l *textFieldLogger) WarnEnabled() bool {
+	return l.logger.WarnEnabled()
+}
+
+// ErrorEnabled is part of the implementation of the Logger interface.
+func (l *textFieldLogger) ErrorEnabled() bool {
+	return l.logger.ErrorEnabled()
+}
+
+// Debug is part of the implementation of the Logger interface.
+func (l *textFieldLogger) Debug(ctx context.Context, format string, args ...interface{}) {
+	if l.logger.DebugEnabled() {
+		l.logger.Debug(ctx, "%s %s", fmt.Sprintf(format, args...), formatFields(l.fields))
+	}
+}
+
+// Info is part of the implementation of the Logger interface.
+func (l *textFieldLogger) Info(ctx context.Context, format string, args ...interface{}) {
+	if l.logger.InfoEnabled() {
+		l.logger.Info(ctx, "%s %s", fmt.Sprintf(format, args...), formatFields(l.fields))
+	}
+}
+
+// Warn is part of the implementation of the Logger interface.
+func (l *textFieldLogger) Warn(ctx context.Context, format string, args ...interface{}) {
+	if l.logger.WarnEnabled() {
+		l.logger.Warn(ctx, "%s %s", fmt.Sprintf(format, args...), formatFields(l.fields))
+	}
+}
+
+// Error is part of the implementation of the Logger interface.
+func (l *textFieldLogger) Error(ctx context.Context, format string, args ...interface{}) {
+	if l.logger.ErrorEnabled() {
+		l.logger.Error(ctx, "%s %s", fmt.Sprintf(format, args...), formatFields(l.fields))
+	}
+}
+
+// Fatal is part of the implementation of the Logger interface.
+func (l *textFieldLogger) Fatal(ctx context.Context, format string, args ...interface{}) {
+	l.logger.Fatal(ctx, "%s %s", fmt.Sprintf(format, args...), formatFields(l.fields))
+}
+
+// With is the implementation of the FieldLogger interface.
+func (l *textFieldLogger) With(keyvals ...interface{}) Logger {
+	if len(keyvals) == 0 {
+		return l
+	}
+	fields := make([]interface{}, 0, len(l.fields)+len(keyvals))
+	fields = append(fields, l.fields...)
+	fields = append(fields, keyvals...)
+	return &textFieldLogger{
+		logger: l.logger,
+		fields: fields,
+	}
+}
+
+// formatFields generates the text representation of the given fields, for example
+// `method=GET status=200`. Values that contain spaces or quotes are quoted. If the number of
+// items is odd the last key will have the `!MISSING` value.
+func formatFields(keyvals []interface{}) string {
+	buffer := &strings.Builder{}
+	for i := 0; i < len(keyvals); i += 2 {
+		if i > 0 {
+			buffer.WriteString(" ")
+		}
+		key := fmt.Sprintf("%v", keyvals[i])
+		value := "!MISSING"
+		if i+1 < len(keyvals) {
+			value = fmt.Sprintf("%v", keyvals[i+1])
+		}
+		if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+			value = strconv.Quote(value)
+		}
+		buffer.WriteString(key)
+		buffer.WriteString("=")
+		buffer.WriteString(value)
+	}
+	return buffer.String()
+}
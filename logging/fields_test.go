@@ -0,0 +1,94 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"  // nolint
+	. "github.com/onsi/ginkgo/v2/dsl/table" // nolint
+	. "github.com/onsi/gomega"              // nolint
+)
+
+var _ = Describe("Fields", func() {
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	DescribeTable("Text format",
+		func(keyvals []interface{}, text string) {
+			Expect(formatFields(keyvals)).To(Equal(text))
+		},
+		Entry("Empty", nil, ""),
+		Entry("One", []interface{}{"method", "GET"}, "method=GET"),
+		Entry("Two", []interface{}{"method", "GET", "status", 200}, "method=GET status=200"),
+		Entry("Spaces", []interface{}{"flag", "my flag"}, `flag="my flag"`),
+		Entry("Empty value", []interface{}{"path", ""}, `path=""`),
+		Entry("Missing value", []interface{}{"path"}, "path=!MISSING"),
+	)
+
+	It("Appends fields to messages of loggers that don't support them", func() {
+		buffer := &bytes.Buffer{}
+		logger, err := NewStdLoggerBuilder().
+			Streams(buffer, buffer).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		fieldLogger := With(logger, MethodField, "GET", PathField, "/api")
+		fieldLogger = With(fieldLogger, StatusField, 503)
+		fieldLogger.Info(ctx, "Request failed after %d attempts", 3)
+		Expect(buffer.String()).To(Equal(
+			"Request failed after 3 attempts method=GET path=/api status=503\n",
+		))
+	})
+
+	It("Doesn't format fields when the level is disabled", func() {
+		buffer := &bytes.Buffer{}
+		logger, err := NewStdLoggerBuilder().
+			Streams(buffer, buffer).
+			Debug(false).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		With(logger, MethodField, "GET").Debug(ctx, "Hello")
+		Expect(buffer.Len()).To(BeZero())
+	})
+
+	It("Returns the same logger if there are no fields", func() {
+		logger, err := NewStdLoggerBuilder().Build()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(With(logger)).To(BeIdenticalTo(logger))
+	})
+
+	It("Uses the fields support of the logger if available", func() {
+		buffer := &bytes.Buffer{}
+		logger, err := NewSlogLoggerBuilder().
+			Handler(slog.NewJSONHandler(buffer, nil)).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		With(logger, MethodField, "GET", StatusField, 200).Info(ctx, "Request sent")
+		var record map[string]interface{}
+		err = json.Unmarshal(buffer.Bytes(), &record)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(record).To(HaveKeyWithValue("msg", "Request sent"))
+		Expect(record).To(HaveKeyWithValue("method", "GET"))
+		Expect(record).To(HaveKeyWithValue("status", BeNumerically("==", 200)))
+	})
+})
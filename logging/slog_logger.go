@@ -0,0 +1,163 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains a logger that uses the structured logging `log/slog` package.
+
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"runtime"
+	"time"
+)
+
+// SlogLoggerBuilder contains the configuration and logic needed to build a logger that uses the
+// `log/slog` package. Don't create instances of this type directly, use the NewSlogLoggerBuilder
+// function instead.
+type SlogLoggerBuilder struct {
+	handler slog.Handler
+}
+
+// SlogLogger is a logger that uses the `log/slog` package. It implements the FieldLogger interface,
+// so the fields passed by the SDK will be sent to the handler as attributes of the records.
+type SlogLogger struct {
+	logger *slog.Logger
+}
+
+// Make sure that we implement the interface:
+var _ FieldLogger = (*SlogLogger)(nil)
+
+// NewSlogLoggerBuilder creates a builder that knows how to build a logger that uses the `log/slog`
+// package. By default these loggers will use the handler of the default `slog` logger.
+func NewSlogLoggerBuilder() *SlogLoggerBuilder {
+	return &SlogLoggerBuilder{}
+}
+
+// Handler sets the `slog` handler that will be used to process the log records. For example, to
+// write the messages to the standard output in JSON format:
+//
+//	logger, err := logging.NewSlogLoggerBuilder().
+//		Handler(slog.NewJSONHandler(os.Stdout, nil)).
+//		Build()
+//
+// The levels enabled are also determined by the handler.
+func (b *SlogLoggerBuilder) Handler(value slog.Handler) *SlogLoggerBuilder {
+	b.handler = value
+	return b
+}
+
+// Build creates a new logger using the configuration stored in the builder.
+func (b *SlogLoggerBuilder) Build() (logger *SlogLogger, err error) {
+	handler := b.handler
+	if handler == nil {
+		handler = slog.Default().Handler()
+	}
+	logger = &SlogLogger{
+		logger: slog.New(handler),
+	}
+	return
+}
+
+// DebugEnabled returns true iff the debug level is enabled.
+func (l *SlogLogger) DebugEnabled() bool {
+	return l.logger.Enabled(context.Background(), slog.LevelDebug)
+}
+
+// InfoEnabled returns true iff the information level is enabled.
+func (l *SlogLogger) InfoEnabled() bool {
+	return l.logger.Enabled(context.Background(), slog.LevelInfo)
+}
+
+// WarnEnabled returns true iff the warning level is enabled.
+func (l *SlogLogger) WarnEnabled() bool {
+	return l.logger.Enabled(context.Background(), slog.LevelWarn)
+}
+
+// ErrorEnabled returns true iff the error level is enabled.
+func (l *SlogLogger) ErrorEnabled() bool {
+	return l.logger.Enabled(context.Background(), slog.LevelError)
+}
+
+// Debug sends to the log a debug message formatted using the fmt.Sprintf function and the given
+// format and arguments.
+func (l *SlogLogger) Debug(ctx context.Context, format string, args ...interface{}) {
+	l.log(ctx, slog.LevelDebug, format, args)
+}
+
+// Info sends to the log an information message formatted using the fmt.Sprintf function and the
+// given format and arguments.
+func (l *SlogLogger) Info(ctx context.Context, format string, args ...interface{}) {
+	l.log(ctx, slog.LevelInfo, format, args)
+}
+
+// Warn sends to the log a warning message formatted using the fmt.Sprintf function and the given
+// format and arguments.
+func (l *SlogLogger) Warn(ctx context.Context, format string, args ...interface{}) {
+	l.log(ctx, slog.LevelWarn, format, args)
+}
+
+// Error sends to the log an error message formatted using the fmt.Sprintf function and the given
+// format and arguments.
+func (l *SlogLogger) Error(ctx context.Context, format string, args ...interface{}) {
+	l.log(ctx, slog.LevelError, format, args)
+}
+
+// Fatal sends to the log an error message formatted using the fmt.Sprintf function and the given
+// format and arguments. After that it will os.Exit(1). This level is always enabled.
+func (l *SlogLogger) Fatal(ctx context.Context, format string, args ...interface{}) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var pcs [1]uintptr
+	runtime.Callers(2, pcs[:])
+	record := slog.NewRecord(time.Now(), slog.LevelError, fmt.Sprintf(format, args...), pcs[0])
+	_ = l.logger.Handler().Handle(ctx, record)
+	os.Exit(1)
+}
+
+// With returns a logger that adds the given fields as attributes to all the records.
+func (l *SlogLogger) With(keyvals ...interface{}) Logger {
+	return &SlogLogger{
+		logger: l.logger.With(keyvals...),
+	}
+}
+
+// Slog returns the underlying `slog` logger.
+func (l *SlogLogger) Slog() *slog.Logger {
+	return l.logger
+}
+
+func (l *SlogLogger) log(ctx context.Context, level slog.Level, format string,
+	args []interface{}) {
+	// The context is optional for the SDK, but handlers may not accept nil:
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !l.logger.Enabled(ctx, level) {
+		return
+	}
+
+	// The record is created explicitly instead of using the methods of the logger so that the
+	// source is the caller of the Debug, Info, Warn or Error methods and not this file. That
+	// means skipping the frames of runtime.Callers, this method and the method that called it.
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:])
+	record := slog.NewRecord(time.Now(), level, fmt.Sprintf(format, args...), pcs[0])
+	_ = l.logger.Handler().Handle(ctx, record)
+}
@@ -0,0 +1,124 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core" // nolint
+	. "github.com/onsi/gomega"             // nolint
+)
+
+var _ = Describe("Slog logger", func() {
+	var ctx context.Context
+	var buffer *bytes.Buffer
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		buffer = &bytes.Buffer{}
+	})
+
+	// parse parses the JSON records written to the buffer:
+	parse := func() []map[string]interface{} {
+		var result []map[string]interface{}
+		decoder := json.NewDecoder(buffer)
+		for decoder.More() {
+			var record map[string]interface{}
+			err := decoder.Decode(&record)
+			Expect(err).ToNot(HaveOccurred())
+			result = append(result, record)
+		}
+		return result
+	}
+
+	It("Can be created without a handler", func() {
+		logger, err := NewSlogLoggerBuilder().Build()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(logger.Slog()).ToNot(BeNil())
+	})
+
+	It("Uses the levels of the handler", func() {
+		logger, err := NewSlogLoggerBuilder().
+			Handler(slog.NewJSONHandler(buffer, &slog.HandlerOptions{
+				Level: slog.LevelWarn,
+			})).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(logger.DebugEnabled()).To(BeFalse())
+		Expect(logger.InfoEnabled()).To(BeFalse())
+		Expect(logger.WarnEnabled()).To(BeTrue())
+		Expect(logger.ErrorEnabled()).To(BeTrue())
+		logger.Info(ctx, "Ignored")
+		logger.Warn(ctx, "Written")
+		records := parse()
+		Expect(records).To(HaveLen(1))
+		Expect(records[0]).To(HaveKeyWithValue("msg", "Written"))
+		Expect(records[0]).To(HaveKeyWithValue("level", "WARN"))
+	})
+
+	It("Formats the message", func() {
+		logger, err := NewSlogLoggerBuilder().
+			Handler(slog.NewJSONHandler(buffer, nil)).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		logger.Error(ctx, "Can't get %s: %v", "tokens", "timeout")
+		records := parse()
+		Expect(records).To(HaveLen(1))
+		Expect(records[0]).To(HaveKeyWithValue("msg", "Can't get tokens: timeout"))
+		Expect(records[0]).To(HaveKeyWithValue("level", "ERROR"))
+	})
+
+	It("Reports the source of the caller", func() {
+		logger, err := NewSlogLoggerBuilder().
+			Handler(slog.NewJSONHandler(buffer, &slog.HandlerOptions{
+				AddSource: true,
+			})).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		logger.Info(ctx, "Hello")
+		records := parse()
+		Expect(records).To(HaveLen(1))
+		Expect(records[0]).To(HaveKey("source"))
+		source := records[0]["source"].(map[string]interface{})
+		Expect(source["file"]).To(HaveSuffix("/slog_logger_test.go"))
+		Expect(source["function"]).To(ContainSubstring("logging.init"))
+	})
+
+	It("Accepts nil context", func() {
+		logger, err := NewSlogLoggerBuilder().
+			Handler(slog.NewJSONHandler(buffer, nil)).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		logger.Info(nil, "Hello") // nolint
+		Expect(parse()).To(HaveLen(1))
+	})
+
+	It("Adds fields as attributes", func() {
+		logger, err := NewSlogLoggerBuilder().
+			Handler(slog.NewJSONHandler(buffer, nil)).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		With(logger.With(FlagField, "my_flag"), ProcessField, "my_process").Info(ctx, "Hello")
+		records := parse()
+		Expect(records).To(HaveLen(1))
+		Expect(records[0]).To(HaveKeyWithValue("flag", "my_flag"))
+		Expect(records[0]).To(HaveKeyWithValue("process", "my_process"))
+	})
+})
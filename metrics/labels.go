@@ -23,6 +23,22 @@ import (
 	"strings"
 )
 
+// ServiceName calculates the name of the API service that handles the given URL path, for example
+// `ocm-clusters-service` for `/api/clusters_mgmt/v1/clusters`. This is the value of the
+// `apiservice` label of the metrics. The result will be an empty string if the path doesn't
+// correspond to an API service.
+func ServiceName(path string) string {
+	return serviceLabel(path)
+}
+
+// PathTemplate calculates the template of the given URL path, replacing the segments that
+// correspond to object identifiers with a dash. For example, for `/api/clusters_mgmt/v1/clusters/123`
+// the result will be `/api/clusters_mgmt/v1/clusters/-`. This is the value of the `path` label of
+// the metrics. The result will be `/-` if the path isn't part of the API.
+func PathTemplate(path string) string {
+	return pathLabel(pathRoot, path)
+}
+
 // serviceLabel calculates the `service` for the given URL path.
 func serviceLabel(path string) string {
 	if !strings.HasPrefix(path, "/api/") {
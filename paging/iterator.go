@@ -0,0 +1,265 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the implementation of an iterator that knows how to walk all the pages of
+// the collections returned by the generated list requests.
+
+package paging
+
+import (
+	"context"
+	"fmt"
+)
+
+// Default configuration:
+const (
+	DefaultSize     = 100
+	DefaultPrefetch = 0
+)
+
+// Request is the interface implemented by all the generated list requests, for example
+// clustersmgmt/v1.ClustersListRequest. The Q type parameter is the type of the request itself
+// and the S type parameter is the type of the response.
+type Request[Q any, S any] interface {
+	Page(value int) Q
+	Size(value int) Q
+	SendContext(ctx context.Context) (S, error)
+}
+
+// Response is the interface implemented by all the generated list responses, for example
+// clustersmgmt/v1.ClustersListResponse.
+type Response interface {
+	Page() int
+	Size() int
+	Total() int
+}
+
+// IteratorBuilder contains the data and logic needed to create a page iterator. Don't create
+// objects of this type directly, use the NewIterator function instead.
+type IteratorBuilder[Q Request[Q, S], S Response] struct {
+	request  func() Q
+	size     int
+	prefetch int
+}
+
+// Iterator knows how to walk all the pages of a collection, sending one list request per page.
+// Iterators don't keep state between calls to the Each method, so they can be reused and used
+// concurrently.
+type Iterator[Q Request[Q, S], S Response] struct {
+	request  func() Q
+	size     int
+	prefetch int
+}
+
+// result is used to send the result of fetching a page from the goroutine that sends the request
+// to the goroutine that calls the callback.
+type result[S Response] struct {
+	response S
+	err      error
+}
+
+// NewIterator creates a builder that can then be used to configure and create a page iterator.
+// The type parameters are the types of the list request and response. For example, to create an
+// iterator for the list of clusters:
+//
+//	collection := connection.ClustersMgmt().V1().Clusters()
+//	iterator, err := paging.NewIterator[
+//		*cmv1.ClustersListRequest,
+//		*cmv1.ClustersListResponse,
+//	]().
+//		Request(func() *cmv1.ClustersListRequest {
+//			return collection.List().Search("managed = 't'")
+//		}).
+//		Size(100).
+//		Prefetch(2).
+//		Build()
+//	if err != nil {
+//		return err
+//	}
+//	err = iterator.Each(ctx, func(response *cmv1.ClustersListResponse) bool {
+//		response.Items().Each(func(cluster *cmv1.Cluster) bool {
+//			fmt.Println(cluster.ID())
+//			return true
+//		})
+//		return true
+//	})
+func NewIterator[Q Request[Q, S], S Response]() *IteratorBuilder[Q, S] {
+	return &IteratorBuilder[Q, S]{
+		size:     DefaultSize,
+		prefetch: DefaultPrefetch,
+	}
+}
+
+// Request sets the function that will be called to create the list request for each page. The
+// function must return a new request each time that it is called, because the iterator will
+// change the page number and size, and because when prefetching is enabled multiple requests may
+// be sent concurrently. The method that creates the list request of a collection, for example
+// `connection.ClustersMgmt().V1().Clusters().List`, is usually a good choice. Use a closure when
+// the request needs additional parameters, like the search criteria. This is mandatory.
+func (b *IteratorBuilder[Q, S]) Request(value func() Q) *IteratorBuilder[Q, S] {
+	b.request = value
+	return b
+}
+
+// Size sets the number of items that will be requested for each page. The default is 100.
+func (b *IteratorBuilder[Q, S]) Size(value int) *IteratorBuilder[Q, S] {
+	b.size = value
+	return b
+}
+
+// Prefetch sets the number of pages that will be requested concurrently in advance while the
+// current page is being processed. The default is zero, which means that pages will be requested
+// one after the other, only when the previous one has been processed.
+func (b *IteratorBuilder[Q, S]) Prefetch(value int) *IteratorBuilder[Q, S] {
+	b.prefetch = value
+	return b
+}
+
+// Build uses the information stored in the builder to create a new page iterator.
+func (b *IteratorBuilder[Q, S]) Build() (result *Iterator[Q, S], err error) {
+	// Check parameters:
+	if b.request == nil {
+		err = fmt.Errorf("request function is mandatory")
+		return
+	}
+	if b.size <= 0 {
+		err = fmt.Errorf(
+			"page size %d isn't valid, it should be greater than zero",
+			b.size,
+		)
+		return
+	}
+	if b.prefetch < 0 {
+		err = fmt.Errorf(
+			"prefetch %d isn't valid, it should be greater or equal than zero",
+			b.prefetch,
+		)
+		return
+	}
+
+	// Create and populate the object:
+	result = &Iterator[Q, S]{
+		request:  b.request,
+		size:     b.size,
+		prefetch: b.prefetch,
+	}
+
+	return
+}
+
+// Each sends the list requests needed to retrieve all the pages of the collection and calls the
+// given function for each of them, in order. The iteration stops when the last page has been
+// processed, when the function returns false, when a request fails or when the context is
+// cancelled. Requests that are still in progress when the iteration stops are cancelled. The
+// returned error will be nil if the iteration stopped because the last page was reached or
+// because the function returned false.
+func (i *Iterator[Q, S]) Each(ctx context.Context, callback func(response S) bool) error {
+	// Create a context that we can cancel when the iteration finishes, so that pending requests
+	// for prefetched pages are abandoned:
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// The first page is always requested alone, because we need it to know the total number of
+	// items, and therefore how many pages we can safely request in advance.
+	first, err := i.fetch(ctx, 1)
+	if err != nil {
+		return err
+	}
+	received := first.Size()
+	if !callback(first) || i.last(first, received) {
+		return nil
+	}
+
+	// Servers may return less items than requested when the requested page size is larger than
+	// the maximum that they support, so when the total is known we use the size of the first page
+	// to calculate how many pages there are:
+	limit := 0
+	total := first.Total()
+	if total > 0 {
+		size := first.Size()
+		if size > i.size {
+			size = i.size
+		}
+		limit = (total + size - 1) / size
+	}
+
+	// Process the rest of the pages, keeping in flight at most the number of requests given by
+	// the prefetch parameter plus one for the page that will be processed next:
+	var pending []chan result[S]
+	next := 2
+	for {
+		for len(pending) <= i.prefetch && (limit == 0 || next <= limit) {
+			pending = append(pending, i.start(ctx, next))
+			next++
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+		var current result[S]
+		select {
+		case current = <-pending[0]:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		pending = pending[1:]
+		if current.err != nil {
+			return current.err
+		}
+		received += current.response.Size()
+		if !callback(current.response) || i.last(current.response, received) {
+			return nil
+		}
+	}
+}
+
+// start starts a goroutine that fetches the given page and returns the channel where the result
+// will be written. The channel is buffered so that the goroutine can always finish even if nobody
+// reads the result.
+func (i *Iterator[Q, S]) start(ctx context.Context, page int) chan result[S] {
+	channel := make(chan result[S], 1)
+	go func() {
+		response, err := i.fetch(ctx, page)
+		channel <- result[S]{
+			response: response,
+			err:      err,
+		}
+	}()
+	return channel
+}
+
+// fetch sends the request for the given page and waits for the response.
+func (i *Iterator[Q, S]) fetch(ctx context.Context, page int) (response S, err error) {
+	request := i.request()
+	request.Page(page)
+	request.Size(i.size)
+	response, err = request.SendContext(ctx)
+	if err != nil {
+		err = fmt.Errorf("can't fetch page %d: %w", page, err)
+	}
+	return
+}
+
+// last checks if the given response is the last page of the collection. The received parameter
+// is the total number of items received so far, including the ones in the given response. When
+// the server returns the total number of items that is what decides, because the server may
+// return pages smaller than requested. Otherwise an empty or short page is the last one.
+func (i *Iterator[Q, S]) last(response S, received int) bool {
+	total := response.Total()
+	if total > 0 {
+		return received >= total || response.Size() == 0
+	}
+	return response.Size() < i.size
+}
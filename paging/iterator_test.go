@@ -0,0 +1,386 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the page iterator.
+
+package paging
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"             // nolint
+	. "github.com/onsi/gomega"                         // nolint
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+// clustersServer is a fake transport that returns pages of a collection of clusters.
+type clustersServer struct {
+	count     int
+	withTotal bool
+	maxSize   int
+	delay     time.Duration
+	failPage  int
+	lock      sync.Mutex
+	pages     []int
+	active    int32
+	maxActive int32
+}
+
+func (s *clustersServer) RoundTrip(request *http.Request) (response *http.Response, err error) {
+	active := atomic.AddInt32(&s.active, 1)
+	defer atomic.AddInt32(&s.active, -1)
+	for {
+		current := atomic.LoadInt32(&s.maxActive)
+		if active <= current || atomic.CompareAndSwapInt32(&s.maxActive, current, active) {
+			break
+		}
+	}
+	query := request.URL.Query()
+	page, err := strconv.Atoi(query.Get("page"))
+	Expect(err).ToNot(HaveOccurred())
+	size, err := strconv.Atoi(query.Get("size"))
+	Expect(err).ToNot(HaveOccurred())
+	if s.maxSize > 0 && size > s.maxSize {
+		size = s.maxSize
+	}
+	s.lock.Lock()
+	s.pages = append(s.pages, page)
+	s.lock.Unlock()
+	if s.delay > 0 {
+		select {
+		case <-time.After(s.delay):
+		case <-request.Context().Done():
+			err = request.Context().Err()
+			return
+		}
+	}
+	if page == s.failPage {
+		response = &http.Response{
+			StatusCode: http.StatusInternalServerError,
+			Header: http.Header{
+				"Content-Type": []string{"application/json"},
+			},
+			Body: io.NopCloser(strings.NewReader(`{
+				"kind": "Error",
+				"id": "500",
+				"reason": "Server failed"
+			}`)),
+		}
+		return
+	}
+	items := []interface{}{}
+	for i := (page - 1) * size; i < page*size && i < s.count; i++ {
+		items = append(items, map[string]interface{}{
+			"kind": "Cluster",
+			"id":   strconv.Itoa(i),
+		})
+	}
+	body := map[string]interface{}{
+		"kind":  "ClusterList",
+		"page":  page,
+		"size":  len(items),
+		"items": items,
+	}
+	if s.withTotal {
+		body["total"] = s.count
+	}
+	data, err := json.Marshal(body)
+	Expect(err).ToNot(HaveOccurred())
+	response = &http.Response{
+		StatusCode: http.StatusOK,
+		Header: http.Header{
+			"Content-Type": []string{"application/json"},
+		},
+		Body: io.NopCloser(strings.NewReader(string(data))),
+	}
+	return
+}
+
+func (s *clustersServer) requested() []int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	result := make([]int, len(s.pages))
+	copy(result, s.pages)
+	return result
+}
+
+var _ = Describe("Iterator", func() {
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	// collect iterates all the pages and returns the identifiers of the clusters:
+	collect := func(iterator *Iterator[*cmv1.ClustersListRequest, *cmv1.ClustersListResponse]) (
+		ids []string, err error) {
+		err = iterator.Each(ctx, func(response *cmv1.ClustersListResponse) bool {
+			response.Items().Each(func(cluster *cmv1.Cluster) bool {
+				ids = append(ids, cluster.ID())
+				return true
+			})
+			return true
+		})
+		return
+	}
+
+	// expected returns the identifiers that should be returned for a collection of the given size:
+	expected := func(count int) []string {
+		result := make([]string, count)
+		for i := 0; i < count; i++ {
+			result[i] = strconv.Itoa(i)
+		}
+		return result
+	}
+
+	Describe("Creation", func() {
+		It("Can't be created without a request function", func() {
+			iterator, err := NewIterator[*cmv1.ClustersListRequest, *cmv1.ClustersListResponse]().
+				Build()
+			Expect(err).To(HaveOccurred())
+			Expect(iterator).To(BeNil())
+			Expect(err.Error()).To(ContainSubstring("mandatory"))
+		})
+
+		It("Can't be created with zero size", func() {
+			client := cmv1.NewClustersClient(&clustersServer{}, "/api/clusters_mgmt/v1/clusters")
+			iterator, err := NewIterator[*cmv1.ClustersListRequest, *cmv1.ClustersListResponse]().
+				Request(client.List).
+				Size(0).
+				Build()
+			Expect(err).To(HaveOccurred())
+			Expect(iterator).To(BeNil())
+			Expect(err.Error()).To(ContainSubstring("greater than zero"))
+		})
+
+		It("Can't be created with negative prefetch", func() {
+			client := cmv1.NewClustersClient(&clustersServer{}, "/api/clusters_mgmt/v1/clusters")
+			iterator, err := NewIterator[*cmv1.ClustersListRequest, *cmv1.ClustersListResponse]().
+				Request(client.List).
+				Prefetch(-1).
+				Build()
+			Expect(err).To(HaveOccurred())
+			Expect(iterator).To(BeNil())
+			Expect(err.Error()).To(ContainSubstring("greater or equal than zero"))
+		})
+	})
+
+	It("Returns all the items when total is known", func() {
+		server := &clustersServer{
+			count:     250,
+			withTotal: true,
+		}
+		client := cmv1.NewClustersClient(server, "/api/clusters_mgmt/v1/clusters")
+		iterator, err := NewIterator[*cmv1.ClustersListRequest, *cmv1.ClustersListResponse]().
+			Request(client.List).
+			Size(100).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		ids, err := collect(iterator)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ids).To(Equal(expected(250)))
+		Expect(server.requested()).To(Equal([]int{1, 2, 3}))
+	})
+
+	It("Returns all the items when total isn't known", func() {
+		server := &clustersServer{
+			count: 200,
+		}
+		client := cmv1.NewClustersClient(server, "/api/clusters_mgmt/v1/clusters")
+		iterator, err := NewIterator[*cmv1.ClustersListRequest, *cmv1.ClustersListResponse]().
+			Request(client.List).
+			Size(100).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		ids, err := collect(iterator)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ids).To(Equal(expected(200)))
+		Expect(server.requested()).To(Equal([]int{1, 2, 3}))
+	})
+
+	It("Returns all the items when the server caps the page size", func() {
+		server := &clustersServer{
+			count:     250,
+			withTotal: true,
+			maxSize:   50,
+		}
+		client := cmv1.NewClustersClient(server, "/api/clusters_mgmt/v1/clusters")
+		iterator, err := NewIterator[*cmv1.ClustersListRequest, *cmv1.ClustersListResponse]().
+			Request(client.List).
+			Size(100).
+			Prefetch(2).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		ids, err := collect(iterator)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ids).To(Equal(expected(250)))
+		Expect(server.requested()).To(ConsistOf(1, 2, 3, 4, 5))
+	})
+
+	It("Works with an empty collection", func() {
+		server := &clustersServer{
+			withTotal: true,
+		}
+		client := cmv1.NewClustersClient(server, "/api/clusters_mgmt/v1/clusters")
+		iterator, err := NewIterator[*cmv1.ClustersListRequest, *cmv1.ClustersListResponse]().
+			Request(client.List).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		ids, err := collect(iterator)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ids).To(BeEmpty())
+		Expect(server.requested()).To(Equal([]int{1}))
+	})
+
+	It("Preserves the parameters added by the request function", func() {
+		var searches []string
+		var lock sync.Mutex
+		server := &clustersServer{
+			count:     20,
+			withTotal: true,
+		}
+		transport := TransportFunc(func(request *http.Request) (*http.Response, error) {
+			lock.Lock()
+			searches = append(searches, request.URL.Query().Get("search"))
+			lock.Unlock()
+			return server.RoundTrip(request)
+		})
+		client := cmv1.NewClustersClient(transport, "/api/clusters_mgmt/v1/clusters")
+		iterator, err := NewIterator[*cmv1.ClustersListRequest, *cmv1.ClustersListResponse]().
+			Request(func() *cmv1.ClustersListRequest {
+				return client.List().Search("name like 'my%'")
+			}).
+			Size(10).
+			Prefetch(1).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		ids, err := collect(iterator)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ids).To(Equal(expected(20)))
+		Expect(searches).To(Equal([]string{
+			"name like 'my%'",
+			"name like 'my%'",
+		}))
+	})
+
+	It("Stops when the callback returns false", func() {
+		server := &clustersServer{
+			count:     1000,
+			withTotal: true,
+		}
+		client := cmv1.NewClustersClient(server, "/api/clusters_mgmt/v1/clusters")
+		iterator, err := NewIterator[*cmv1.ClustersListRequest, *cmv1.ClustersListResponse]().
+			Request(client.List).
+			Size(10).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		count := 0
+		err = iterator.Each(ctx, func(response *cmv1.ClustersListResponse) bool {
+			count++
+			return count < 2
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(count).To(Equal(2))
+		Expect(server.requested()).To(Equal([]int{1, 2}))
+	})
+
+	It("Returns the error of the failed page", func() {
+		server := &clustersServer{
+			count:     1000,
+			withTotal: true,
+			failPage:  3,
+		}
+		client := cmv1.NewClustersClient(server, "/api/clusters_mgmt/v1/clusters")
+		iterator, err := NewIterator[*cmv1.ClustersListRequest, *cmv1.ClustersListResponse]().
+			Request(client.List).
+			Size(10).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		ids, err := collect(iterator)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("page 3"))
+		Expect(err.Error()).To(ContainSubstring("Server failed"))
+		Expect(ids).To(Equal(expected(20)))
+	})
+
+	It("Prefetches pages concurrently", func() {
+		server := &clustersServer{
+			count:     100,
+			withTotal: true,
+			delay:     50 * time.Millisecond,
+		}
+		client := cmv1.NewClustersClient(server, "/api/clusters_mgmt/v1/clusters")
+		iterator, err := NewIterator[*cmv1.ClustersListRequest, *cmv1.ClustersListResponse]().
+			Request(client.List).
+			Size(10).
+			Prefetch(3).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		ids, err := collect(iterator)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ids).To(Equal(expected(100)))
+		Expect(server.requested()).To(ConsistOf(1, 2, 3, 4, 5, 6, 7, 8, 9, 10))
+		Expect(atomic.LoadInt32(&server.maxActive)).To(BeNumerically(">", 1))
+		Expect(atomic.LoadInt32(&server.maxActive)).To(BeNumerically("<=", 4))
+	})
+
+	It("Doesn't prefetch when disabled", func() {
+		server := &clustersServer{
+			count:     50,
+			withTotal: true,
+			delay:     10 * time.Millisecond,
+		}
+		client := cmv1.NewClustersClient(server, "/api/clusters_mgmt/v1/clusters")
+		iterator, err := NewIterator[*cmv1.ClustersListRequest, *cmv1.ClustersListResponse]().
+			Request(client.List).
+			Size(10).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		_, err = collect(iterator)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(atomic.LoadInt32(&server.maxActive)).To(BeNumerically("==", 1))
+	})
+
+	It("Stops when the context is cancelled", func() {
+		server := &clustersServer{
+			count:     1000,
+			withTotal: true,
+			delay:     time.Minute,
+		}
+		client := cmv1.NewClustersClient(server, "/api/clusters_mgmt/v1/clusters")
+		iterator, err := NewIterator[*cmv1.ClustersListRequest, *cmv1.ClustersListResponse]().
+			Request(client.List).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		_, err = collect(iterator)
+		Expect(err).To(HaveOccurred())
+		Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+	})
+})
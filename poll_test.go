@@ -0,0 +1,129 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the poll methods of the generated clients.
+
+package sdk
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/openshift-online/ocm-sdk-go/helpers"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"             // nolint
+	. "github.com/onsi/gomega"                         // nolint
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Poll", func() {
+	var ctx context.Context
+	var server *FakeServer
+	var connection *Connection
+	var id string
+
+	BeforeEach(func() {
+		var err error
+
+		// Create the context:
+		ctx = context.Background()
+
+		// Create the server and a cluster that will be ready after two transitions:
+		server, err = NewFakeServer().
+			ClusterStateDelay(100 * time.Millisecond).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		object, err := cmv1.NewCluster().Name("mycluster").Build()
+		Expect(err).ToNot(HaveOccurred())
+		object, err = server.AddCluster(object)
+		Expect(err).ToNot(HaveOccurred())
+		id = object.ID()
+
+		// Create the connection:
+		connection, err = NewConnectionBuilder().
+			Logger(logger).
+			URL(server.URL()).
+			Tokens(MakeTokenString("Bearer", 5*time.Minute)).
+			BuildContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		err := connection.Close()
+		Expect(err).ToNot(HaveOccurred())
+		server.Close()
+	})
+
+	// isReady checks if the cluster is ready:
+	isReady := func(response *cmv1.ClusterGetResponse) bool {
+		return response.Body().State() == cmv1.ClusterStateReady
+	}
+
+	It("Waits without a deadline and reports the attempts", func() {
+		var states []cmv1.ClusterState
+		ctx = helpers.WithPollOptions(ctx, helpers.PollOptions{
+			Multiplier:  1.5,
+			Jitter:      0.1,
+			GiveUpError: true,
+			OnAttempt: func(attempt *helpers.PollAttempt) {
+				response := attempt.Result.(*cmv1.ClusterGetResponse)
+				states = append(states, response.Body().State())
+			},
+		})
+		response, err := connection.ClustersMgmt().V1().Clusters().Cluster(id).Poll().
+			Interval(5 * time.Millisecond).
+			Predicate(isReady).
+			StartContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Body().State()).To(Equal(cmv1.ClusterStateReady))
+		Expect(states).To(ContainElement(cmv1.ClusterStateInstalling))
+		Expect(states[len(states)-1]).To(Equal(cmv1.ClusterStateReady))
+	})
+
+	It("Returns the last response without error when it reaches the deadline", func() {
+		ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		response, err := connection.ClustersMgmt().V1().Clusters().Cluster(id).Poll().
+			Interval(5 * time.Millisecond).
+			Predicate(isReady).
+			StartContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response).ToNot(BeNil())
+		Expect(response.Body().State()).ToNot(Equal(cmv1.ClusterStateReady))
+	})
+
+	It("Returns the last response with the give up error", func() {
+		ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		ctx = helpers.WithPollOptions(ctx, helpers.PollOptions{
+			GiveUpError: true,
+		})
+		response, err := connection.ClustersMgmt().V1().Clusters().Cluster(id).Poll().
+			Interval(5 * time.Millisecond).
+			Predicate(isReady).
+			StartContext(ctx)
+		Expect(err).To(HaveOccurred())
+		var pollErr *helpers.PollError
+		Expect(errors.As(err, &pollErr)).To(BeTrue())
+		last, ok := pollErr.Result.(*cmv1.ClusterGetResponse)
+		Expect(ok).To(BeTrue())
+		Expect(last.Body().State()).ToNot(Equal(cmv1.ClusterStateReady))
+		Expect(response).ToNot(BeNil())
+		Expect(response.Body().State()).To(Equal(last.Body().State()))
+	})
+})
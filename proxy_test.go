@@ -0,0 +1,557 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the support for outbound proxies.
+
+package sdk
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/onsi/gomega/ghttp"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"             // nolint
+	. "github.com/onsi/gomega"                         // nolint
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Proxy", func() {
+	var ctx context.Context
+	var token string
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		token = MakeTokenString("Bearer", 5*time.Minute)
+	})
+
+	// basicAuth calculates the value of the proxy authorization header for the given user and
+	// password.
+	basicAuth := func(user, password string) string {
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+password))
+	}
+
+	// proxyURL returns the URL of the given server, when used as a proxy.
+	proxyURL := func(server *ghttp.Server) string {
+		return "http://" + server.Addr()
+	}
+
+	Describe("HTTP", func() {
+		var proxyServer *ghttp.Server
+
+		BeforeEach(func() {
+			proxyServer = MakeTCPServer()
+		})
+
+		AfterEach(func() {
+			proxyServer.Close()
+		})
+
+		It("Sends requests via the proxy with authentication", func() {
+			// Prepare the proxy so that it checks the target and the authentication:
+			proxyServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters"),
+					ghttp.VerifyHeaderKV(
+						"Proxy-Authorization",
+						basicAuth("myuser", "mypassword"),
+					),
+					func(w http.ResponseWriter, r *http.Request) {
+						Expect(r.Host).To(Equal("api.example.com"))
+					},
+					RespondWithJSON(http.StatusOK, "{}"),
+				),
+			)
+
+			// Create the connection:
+			connection, err := NewConnectionBuilder().
+				Logger(logger).
+				URL("http://api.example.com").
+				Tokens(token).
+				Proxy("http://myuser:mypassword@" + proxyServer.Addr()).
+				BuildContext(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			defer func() {
+				err = connection.Close()
+				Expect(err).ToNot(HaveOccurred())
+			}()
+			Expect(connection.Proxy()).To(Equal(
+				"http://myuser:mypassword@" + proxyServer.Addr(),
+			))
+
+			// Send the request:
+			response, err := connection.Get().
+				Path("/api/clusters_mgmt/v1/clusters").
+				SendContext(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.Status()).To(Equal(http.StatusOK))
+		})
+
+		It("Uses a different proxy for the token URL", func() {
+			// Prepare the token proxy:
+			tokenProxy := MakeTCPServer()
+			defer tokenProxy.Close()
+			tokenProxy.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodPost, "/token"),
+					func(w http.ResponseWriter, r *http.Request) {
+						Expect(r.Host).To(Equal("sso.example.com"))
+					},
+					RespondWithAccessAndRefreshTokens(token, ""),
+				),
+			)
+
+			// Prepare the API proxy:
+			proxyServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters"),
+					ghttp.VerifyHeaderKV("Authorization", "Bearer "+token),
+					RespondWithJSON(http.StatusOK, "{}"),
+				),
+			)
+
+			// Create the connection:
+			connection, err := NewConnectionBuilder().
+				Logger(logger).
+				URL("http://api.example.com").
+				TokenURL("http://sso.example.com/token").
+				Client("myclient", "mysecret").
+				Proxy(proxyURL(proxyServer)).
+				TokenURLProxy(proxyURL(tokenProxy)).
+				BuildContext(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			defer func() {
+				err = connection.Close()
+				Expect(err).ToNot(HaveOccurred())
+			}()
+
+			// Send the request:
+			_, err = connection.Get().
+				Path("/api/clusters_mgmt/v1/clusters").
+				SendContext(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(tokenProxy.ReceivedRequests()).To(HaveLen(1))
+			Expect(proxyServer.ReceivedRequests()).To(HaveLen(1))
+		})
+
+		It("Uses a different proxy for an alternative URL", func() {
+			// Prepare the proxies:
+			clustersProxy := MakeTCPServer()
+			defer clustersProxy.Close()
+			clustersProxy.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters"),
+					func(w http.ResponseWriter, r *http.Request) {
+						Expect(r.Host).To(Equal("clusters.example.com"))
+					},
+					RespondWithJSON(http.StatusOK, "{}"),
+				),
+			)
+			proxyServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/accounts"),
+					func(w http.ResponseWriter, r *http.Request) {
+						Expect(r.Host).To(Equal("api.example.com"))
+					},
+					RespondWithJSON(http.StatusOK, "{}"),
+				),
+			)
+
+			// Create the connection:
+			connection, err := NewConnectionBuilder().
+				Logger(logger).
+				URL("http://api.example.com").
+				AlternativeURL("/api/clusters_mgmt", "http://clusters.example.com").
+				Tokens(token).
+				Proxy(proxyURL(proxyServer)).
+				AlternativeURLProxy("/api/clusters_mgmt", proxyURL(clustersProxy)).
+				BuildContext(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			defer func() {
+				err = connection.Close()
+				Expect(err).ToNot(HaveOccurred())
+			}()
+
+			// Send the requests:
+			_, err = connection.Get().
+				Path("/api/clusters_mgmt/v1/clusters").
+				SendContext(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = connection.Get().
+				Path("/api/accounts_mgmt/v1/accounts").
+				SendContext(ctx)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("Connects directly to loopback addresses", func() {
+			// Prepare the API server, that will be reached directly because it uses a
+			// loopback address:
+			apiServer := MakeTCPServer()
+			defer apiServer.Close()
+			apiServer.AppendHandlers(
+				RespondWithJSON(http.StatusOK, "{}"),
+			)
+
+			// Create the connection:
+			connection, err := NewConnectionBuilder().
+				Logger(logger).
+				URL(apiServer.URL()).
+				Tokens(token).
+				Proxy(proxyURL(proxyServer)).
+				NoProxy("api.example.com").
+				BuildContext(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			defer func() {
+				err = connection.Close()
+				Expect(err).ToNot(HaveOccurred())
+			}()
+			Expect(connection.NoProxy()).To(Equal([]string{"api.example.com"}))
+
+			// Send the request:
+			_, err = connection.Get().
+				Path("/api/clusters_mgmt/v1/clusters").
+				SendContext(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(proxyServer.ReceivedRequests()).To(BeEmpty())
+			Expect(apiServer.ReceivedRequests()).To(HaveLen(1))
+		})
+
+		It("Can be loaded from the configuration", func() {
+			// Prepare the proxies:
+			clustersProxy := MakeTCPServer()
+			defer clustersProxy.Close()
+			clustersProxy.AppendHandlers(
+				RespondWithJSON(http.StatusOK, "{}"),
+			)
+			proxyServer.AppendHandlers(
+				RespondWithJSON(http.StatusOK, "{}"),
+			)
+
+			// Create the connection:
+			config := fmt.Sprintf(
+				"url: http://api.example.com\n"+
+					"alternative_urls:\n"+
+					"  /api/clusters_mgmt: http://clusters.example.com\n"+
+					"proxy: %s\n"+
+					"no_proxy:\n"+
+					"- .internal.example.com\n"+
+					"alternative_url_proxies:\n"+
+					"  /api/clusters_mgmt: %s\n",
+				proxyURL(proxyServer), proxyURL(clustersProxy),
+			)
+			connection, err := NewConnectionBuilder().
+				Logger(logger).
+				Tokens(token).
+				Load(strings.NewReader(config)).
+				BuildContext(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			defer func() {
+				err = connection.Close()
+				Expect(err).ToNot(HaveOccurred())
+			}()
+			Expect(connection.Proxy()).To(Equal(proxyURL(proxyServer)))
+			Expect(connection.NoProxy()).To(Equal([]string{".internal.example.com"}))
+
+			// Send the requests:
+			_, err = connection.Get().
+				Path("/api/clusters_mgmt/v1/clusters").
+				SendContext(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = connection.Get().
+				Path("/api/accounts_mgmt/v1/accounts").
+				SendContext(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(clustersProxy.ReceivedRequests()).To(HaveLen(1))
+			Expect(proxyServer.ReceivedRequests()).To(HaveLen(1))
+		})
+	})
+
+	Describe("SOCKS5", func() {
+		var apiServer *ghttp.Server
+		var socksServer *socks5Server
+
+		BeforeEach(func() {
+			apiServer = MakeTCPServer()
+			socksServer = newSOCKS5Server(apiServer.Addr(), "myuser", "mypassword")
+		})
+
+		AfterEach(func() {
+			socksServer.close()
+			apiServer.Close()
+		})
+
+		It("Sends requests via the proxy with authentication", func() {
+			apiServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters"),
+					func(w http.ResponseWriter, r *http.Request) {
+						Expect(r.Host).To(Equal("api.example.com"))
+					},
+					RespondWithJSON(http.StatusOK, "{}"),
+				),
+			)
+			connection, err := NewConnectionBuilder().
+				Logger(logger).
+				URL("http://api.example.com").
+				Tokens(token).
+				Proxy("socks5://myuser:mypassword@" + socksServer.addr()).
+				BuildContext(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			defer func() {
+				err = connection.Close()
+				Expect(err).ToNot(HaveOccurred())
+			}()
+			_, err = connection.Get().
+				Path("/api/clusters_mgmt/v1/clusters").
+				SendContext(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(socksServer.targets()).To(Equal([]string{"api.example.com:80"}))
+		})
+
+		It("Fails with wrong credentials", func() {
+			connection, err := NewConnectionBuilder().
+				Logger(logger).
+				URL("http://api.example.com").
+				Tokens(token).
+				RetryLimit(0).
+				Proxy("socks5://myuser:junk@" + socksServer.addr()).
+				BuildContext(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			defer func() {
+				err = connection.Close()
+				Expect(err).ToNot(HaveOccurred())
+			}()
+			_, err = connection.Get().
+				Path("/api/clusters_mgmt/v1/clusters").
+				SendContext(ctx)
+			Expect(err).To(HaveOccurred())
+			Expect(socksServer.targets()).To(BeEmpty())
+		})
+	})
+
+	It("Isn't used for Unix sockets", func() {
+		apiServer, apiSocket := MakeUnixServer()
+		defer func() {
+			apiServer.Close()
+			err := os.RemoveAll(filepath.Dir(apiSocket))
+			Expect(err).ToNot(HaveOccurred())
+		}()
+		apiServer.AppendHandlers(
+			RespondWithJSON(http.StatusOK, "{}"),
+		)
+		connection, err := NewConnectionBuilder().
+			Logger(logger).
+			URL("unix://api.example.com" + apiSocket).
+			Tokens(token).
+			RetryLimit(0).
+			Proxy("http://junk.example.com:3128").
+			BuildContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		defer func() {
+			err = connection.Close()
+			Expect(err).ToNot(HaveOccurred())
+		}()
+		_, err = connection.Get().
+			Path("/api/clusters_mgmt/v1/clusters").
+			SendContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("Can't be created with unsupported scheme", func() {
+		connection, err := NewConnectionBuilder().
+			Logger(logger).
+			Tokens(token).
+			Proxy("ftp://my.proxy.com").
+			BuildContext(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(connection).To(BeNil())
+		Expect(err.Error()).To(ContainSubstring("ftp"))
+	})
+
+	It("Can't be created with proxy for unknown prefix", func() {
+		connection, err := NewConnectionBuilder().
+			Logger(logger).
+			Tokens(token).
+			AlternativeURLProxy("/api/clusters_mgmt", "http://my.proxy.com").
+			BuildContext(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(connection).To(BeNil())
+		Expect(err.Error()).To(ContainSubstring("/api/clusters_mgmt"))
+	})
+})
+
+// socks5Server is a minimal SOCKS5 server that supports user name and password authentication and
+// the connect command, and that connects all the requests to a fixed target address, regardless
+// of the requested one.
+type socks5Server struct {
+	listener  net.Listener
+	target    string
+	user      string
+	password  string
+	lock      sync.Mutex
+	requested []string
+}
+
+func newSOCKS5Server(target, user, password string) *socks5Server {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).ToNot(HaveOccurred())
+	server := &socks5Server{
+		listener: listener,
+		target:   target,
+		user:     user,
+		password: password,
+	}
+	go server.serve()
+	return server
+}
+
+func (s *socks5Server) addr() string {
+	return s.listener.Addr().String()
+}
+
+func (s *socks5Server) targets() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]string{}, s.requested...)
+}
+
+func (s *socks5Server) close() {
+	s.listener.Close()
+}
+
+func (s *socks5Server) serve() {
+	defer GinkgoRecover()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *socks5Server) handle(conn net.Conn) {
+	defer conn.Close()
+
+	// Negotiate the authentication method, only user name and password is supported:
+	header := make([]byte, 2)
+	_, err := io.ReadFull(conn, header)
+	if err != nil || header[0] != 5 {
+		return
+	}
+	methods := make([]byte, header[1])
+	_, err = io.ReadFull(conn, methods)
+	if err != nil {
+		return
+	}
+	_, err = conn.Write([]byte{5, 2})
+	if err != nil {
+		return
+	}
+
+	// Check the user name and password:
+	version := make([]byte, 2)
+	_, err = io.ReadFull(conn, version)
+	if err != nil {
+		return
+	}
+	user := make([]byte, version[1])
+	_, err = io.ReadFull(conn, user)
+	if err != nil {
+		return
+	}
+	length := make([]byte, 1)
+	_, err = io.ReadFull(conn, length)
+	if err != nil {
+		return
+	}
+	password := make([]byte, length[0])
+	_, err = io.ReadFull(conn, password)
+	if err != nil {
+		return
+	}
+	if string(user) != s.user || string(password) != s.password {
+		_, _ = conn.Write([]byte{1, 1})
+		return
+	}
+	_, err = conn.Write([]byte{1, 0})
+	if err != nil {
+		return
+	}
+
+	// Read the connect request:
+	request := make([]byte, 4)
+	_, err = io.ReadFull(conn, request)
+	if err != nil || request[1] != 1 {
+		return
+	}
+	var host string
+	switch request[3] {
+	case 1:
+		ip := make([]byte, 4)
+		_, err = io.ReadFull(conn, ip)
+		host = net.IP(ip).String()
+	case 3:
+		_, err = io.ReadFull(conn, length)
+		if err == nil {
+			name := make([]byte, length[0])
+			_, err = io.ReadFull(conn, name)
+			host = string(name)
+		}
+	default:
+		return
+	}
+	if err != nil {
+		return
+	}
+	port := make([]byte, 2)
+	_, err = io.ReadFull(conn, port)
+	if err != nil {
+		return
+	}
+	s.lock.Lock()
+	s.requested = append(
+		s.requested,
+		net.JoinHostPort(host, fmt.Sprintf("%d", binary.BigEndian.Uint16(port))),
+	)
+	s.lock.Unlock()
+
+	// Connect to the target and copy the data in both directions:
+	target, err := net.Dial("tcp", s.target)
+	if err != nil {
+		_, _ = conn.Write([]byte{5, 1, 0, 1, 0, 0, 0, 0, 0, 0})
+		return
+	}
+	defer target.Close()
+	_, err = conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+	if err != nil {
+		return
+	}
+	go func() {
+		_, _ = io.Copy(target, conn)
+	}()
+	_, _ = io.Copy(conn, target)
+}
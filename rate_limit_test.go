@@ -0,0 +1,100 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the client side rate limits.
+
+package sdk
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"             // nolint
+	. "github.com/onsi/gomega"                         // nolint
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Rate limit", func() {
+	var ctx context.Context
+	var token string
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		token = MakeTokenString("Bearer", 15*time.Minute)
+	})
+
+	It("Can't be created with empty service name", func() {
+		connection, err := NewConnectionBuilder().
+			Logger(logger).
+			Tokens(token).
+			ServiceRateLimit("", 1, 1).
+			BuildContext(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(connection).To(BeNil())
+		message := err.Error()
+		Expect(message).To(ContainSubstring("service"))
+		Expect(message).To(ContainSubstring("mandatory"))
+	})
+
+	It("Can't be created with invalid rate", func() {
+		connection, err := NewConnectionBuilder().
+			Logger(logger).
+			Tokens(token).
+			RateLimit(-1, 1).
+			BuildContext(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(connection).To(BeNil())
+		Expect(err.Error()).To(ContainSubstring("rate"))
+	})
+
+	It("Limits the rate of requests", func() {
+		// Create the connection:
+		metricsServer := NewMetricsServer()
+		defer metricsServer.Close()
+		connection, err := NewConnectionBuilder().
+			Logger(logger).
+			Tokens(token).
+			TransportWrapper(func(_ http.RoundTripper) http.RoundTripper {
+				return JSONTransport(http.StatusOK, `{}`)
+			}).
+			RateLimit(100, 10).
+			ServiceRateLimit("ocm-clusters-service", 10, 1).
+			MetricsSubsystem("my").
+			MetricsRegisterer(metricsServer.Registry()).
+			BuildContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		defer func() {
+			err = connection.Close()
+			Expect(err).ToNot(HaveOccurred())
+		}()
+
+		// Send the requests:
+		start := time.Now()
+		for i := 0; i < 3; i++ {
+			_, err = connection.Get().
+				Path("/api/clusters_mgmt/v1/clusters").
+				SendContext(ctx)
+			Expect(err).ToNot(HaveOccurred())
+		}
+		elapsed := time.Since(start)
+		Expect(elapsed).To(BeNumerically("~", 200*time.Millisecond, 100*time.Millisecond))
+
+		// Check the metrics:
+		metrics := metricsServer.Metrics()
+		Expect(metrics).To(MatchLine(`^my_rate_limit_wait_duration_count\{apiservice="ocm-clusters-service"\} 3$`))
+	})
+})
@@ -0,0 +1,361 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the implementation of a transport wrapper that limits the rate of requests
+// sent to the API services.
+
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/openshift-online/ocm-sdk-go/logging"
+	"github.com/openshift-online/ocm-sdk-go/metrics"
+)
+
+// TransportWrapperBuilder contains the data and logic needed to create a new rate limiting
+// transport wrapper. The limits are implemented using token buckets: each request takes a token
+// from the bucket, and when the bucket is empty the request waits till the bucket is refilled or
+// till the context of the request is done.
+//
+// There can be a global limit, that applies to all the requests, and limits for specific API
+// services. When a request is sent to a service that has its own limit then it needs to satisfy
+// both the global limit and the limit of the service. The names of the services are the same used
+// for the `apiservice` label of the metrics, for example `ocm-clusters-service` or
+// `ocm-accounts-service`.
+//
+// When the metrics subsystem is set the wrapper will generate the following Prometheus metrics:
+//
+//	<subsystem>_rate_limit_wait_duration_sum - Total time waiting for rate limits, in seconds.
+//	<subsystem>_rate_limit_wait_duration_count - Total number of requests measured.
+//	<subsystem>_rate_limit_wait_duration_bucket - Number of requests organized in buckets.
+//
+// These metrics will have the `apiservice` label.
+//
+// Don't create objects of this type directly; use the NewTransportWrapper function instead.
+type TransportWrapperBuilder struct {
+	logger            logging.Logger
+	limit             *limit
+	services          map[string]limit
+	metricsSubsystem  string
+	metricsRegisterer prometheus.Registerer
+}
+
+// TransportWrapper contains the data and logic needed to wrap an HTTP round tripper with another
+// one that limits the rate of requests.
+type TransportWrapper struct {
+	logger       logging.Logger
+	global       *bucket
+	services     map[string]*bucket
+	waitDuration *prometheus.HistogramVec
+}
+
+// roundTripper is a round tripper that limits the rate of requests.
+type roundTripper struct {
+	owner     *TransportWrapper
+	transport http.RoundTripper
+}
+
+// limit contains the configuration of a limit.
+type limit struct {
+	rate  float64
+	burst int
+}
+
+// Make sure that we implement the interface:
+var _ http.RoundTripper = (*roundTripper)(nil)
+
+// NewTransportWrapper creates a new builder that can then be used to configure and create a new
+// rate limiting transport wrapper.
+func NewTransportWrapper() *TransportWrapperBuilder {
+	return &TransportWrapperBuilder{
+		services:          map[string]limit{},
+		metricsRegisterer: prometheus.DefaultRegisterer,
+	}
+}
+
+// Logger sets the logger that will be used by the wrapper and by the round trippers that it
+// creates. This is mandatory.
+func (b *TransportWrapperBuilder) Logger(value logging.Logger) *TransportWrapperBuilder {
+	b.logger = value
+	return b
+}
+
+// Limit sets the global limit, that applies to all the requests. The rate is the number of
+// requests per second and the burst is the maximum number of requests that can be sent at once
+// when no request has been sent for a while. For example, to send at most five requests per
+// second, with bursts of at most ten requests:
+//
+//	wrapper, err := ratelimit.NewTransportWrapper().
+//		Logger(logger).
+//		Limit(5, 10).
+//		Build(ctx)
+//
+// By default there is no global limit.
+func (b *TransportWrapperBuilder) Limit(rate float64, burst int) *TransportWrapperBuilder {
+	b.limit = &limit{
+		rate:  rate,
+		burst: burst,
+	}
+	return b
+}
+
+// ServiceLimit sets the limit for the requests sent to the given API service, for example
+// `ocm-clusters-service`. The meaning of the rate and burst parameters is the same than for the
+// Limit method. By default services don't have their own limits.
+func (b *TransportWrapperBuilder) ServiceLimit(service string, rate float64,
+	burst int) *TransportWrapperBuilder {
+	b.services[service] = limit{
+		rate:  rate,
+		burst: burst,
+	}
+	return b
+}
+
+// MetricsSubsystem sets the name of the subsystem that will be used by the wrapper to register
+// metrics with Prometheus. If this isn't explicitly specified, or if it is an empty string, then
+// no metrics will be registered.
+func (b *TransportWrapperBuilder) MetricsSubsystem(value string) *TransportWrapperBuilder {
+	b.metricsSubsystem = value
+	return b
+}
+
+// MetricsRegisterer sets the Prometheus registerer that will be used to register the metrics. The
+// default is to use the default Prometheus registerer and there is usually no need to change that.
+// This is intended for unit tests, where it is convenient to have a registerer that doesn't
+// interfere with the rest of the system.
+func (b *TransportWrapperBuilder) MetricsRegisterer(
+	value prometheus.Registerer) *TransportWrapperBuilder {
+	if value == nil {
+		value = prometheus.DefaultRegisterer
+	}
+	b.metricsRegisterer = value
+	return b
+}
+
+// Build uses the information stored in the builder to create a new transport wrapper.
+func (b *TransportWrapperBuilder) Build(ctx context.Context) (result *TransportWrapper,
+	err error) {
+	// Check parameters:
+	if b.logger == nil {
+		err = fmt.Errorf("logger is mandatory")
+		return
+	}
+	if b.limit != nil {
+		err = b.limit.check("global")
+		if err != nil {
+			return
+		}
+	}
+	for service, limit := range b.services {
+		if service == "" {
+			err = fmt.Errorf("service name is mandatory")
+			return
+		}
+		err = limit.check(fmt.Sprintf("service '%s'", service))
+		if err != nil {
+			return
+		}
+	}
+
+	// Create the buckets:
+	var global *bucket
+	if b.limit != nil {
+		global = newBucket(b.limit.rate, b.limit.burst)
+	}
+	services := make(map[string]*bucket, len(b.services))
+	for service, limit := range b.services {
+		services[service] = newBucket(limit.rate, limit.burst)
+	}
+
+	// Register the metrics:
+	var waitDuration *prometheus.HistogramVec
+	if b.metricsSubsystem != "" {
+		waitDuration = prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Subsystem: b.metricsSubsystem,
+				Name:      "rate_limit_wait_duration",
+				Help:      "Time waiting for client side rate limits in seconds.",
+				Buckets: []float64{
+					0.1,
+					1.0,
+					10.0,
+					30.0,
+				},
+			},
+			waitDurationLabelNames,
+		)
+		err = b.metricsRegisterer.Register(waitDuration)
+		if err != nil {
+			registered, ok := err.(prometheus.AlreadyRegisteredError)
+			if ok {
+				waitDuration = registered.ExistingCollector.(*prometheus.HistogramVec)
+				err = nil
+			} else {
+				return
+			}
+		}
+	}
+
+	// Create and populate the object:
+	result = &TransportWrapper{
+		logger:       b.logger,
+		global:       global,
+		services:     services,
+		waitDuration: waitDuration,
+	}
+
+	return
+}
+
+// check verifies that the limit is valid. The name is used to construct the error message.
+func (l limit) check(name string) error {
+	if l.rate <= 0 {
+		return fmt.Errorf(
+			"rate %f of %s limit isn't valid, it should be greater than zero",
+			l.rate, name,
+		)
+	}
+	if l.burst <= 0 {
+		return fmt.Errorf(
+			"burst %d of %s limit isn't valid, it should be greater than zero",
+			l.burst, name,
+		)
+	}
+	return nil
+}
+
+// Wrap creates a new round tripper that wraps the given one and implements the rate limits.
+func (w *TransportWrapper) Wrap(transport http.RoundTripper) http.RoundTripper {
+	return &roundTripper{
+		owner:     w,
+		transport: transport,
+	}
+}
+
+// Wait waits till the limits allow sending a request to the given service, or till the context is
+// done. This is intended for code that sends requests without using the round trippers created by
+// this wrapper but still wants to respect the limits. It returns an error if the context is done
+// before the request can be sent, or if the deadline of the context would be exceeded.
+func (w *TransportWrapper) Wait(ctx context.Context, service string) error {
+	// Take a token from each of the buckets that apply, and wait the longest of the delays:
+	buckets := w.buckets(service)
+	if len(buckets) == 0 {
+		return nil
+	}
+	start := time.Now()
+	var delay time.Duration
+	for _, bucket := range buckets {
+		value := bucket.take(start)
+		if value > delay {
+			delay = value
+		}
+	}
+	if delay > 0 {
+		err := w.sleep(ctx, service, start, delay)
+		if err != nil {
+			for _, bucket := range buckets {
+				bucket.give(time.Now())
+			}
+			return err
+		}
+	}
+
+	// Update the metrics:
+	if w.waitDuration != nil {
+		labels := prometheus.Labels{
+			serviceLabelName: service,
+		}
+		w.waitDuration.With(labels).Observe(time.Since(start).Seconds())
+	}
+
+	return nil
+}
+
+// Close releases all the resources used by the wrapper.
+func (w *TransportWrapper) Close() error {
+	return nil
+}
+
+// buckets returns the buckets that apply to the given service.
+func (w *TransportWrapper) buckets(service string) []*bucket {
+	var result []*bucket
+	if w.global != nil {
+		result = append(result, w.global)
+	}
+	bucket, ok := w.services[service]
+	if ok {
+		result = append(result, bucket)
+	}
+	return result
+}
+
+// sleep waits the given delay, or till the context is done.
+func (w *TransportWrapper) sleep(ctx context.Context, service string, start time.Time,
+	delay time.Duration) error {
+	// Don't wait if we already know that the deadline would be exceeded:
+	deadline, ok := ctx.Deadline()
+	if ok && start.Add(delay).After(deadline) {
+		return fmt.Errorf(
+			"waiting %s for rate limit of service '%s' would exceed the deadline: %w",
+			delay, service, context.DeadlineExceeded,
+		)
+	}
+
+	// Wait:
+	w.logger.Debug(ctx, "Waiting %s for rate limit of service '%s'", delay, service)
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf(
+			"can't wait for rate limit of service '%s': %w",
+			service, ctx.Err(),
+		)
+	}
+}
+
+// RoundTrip is the implementation of the round tripper interface.
+func (t *roundTripper) RoundTrip(request *http.Request) (response *http.Response, err error) {
+	service := metrics.ServiceName(request.URL.Path)
+	err = t.owner.Wait(request.Context(), service)
+	if err != nil {
+		// Round trippers are required to close the request body even when they fail:
+		if request.Body != nil {
+			request.Body.Close()
+		}
+		return
+	}
+	response, err = t.transport.RoundTrip(request)
+	return
+}
+
+// Names of the labels added to metrics:
+const (
+	serviceLabelName = "apiservice"
+)
+
+// Array of labels added to the wait duration metric:
+var waitDurationLabelNames = []string{
+	serviceLabelName,
+}
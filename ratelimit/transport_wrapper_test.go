@@ -0,0 +1,319 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the rate limiting transport wrapper.
+
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"             // nolint
+	. "github.com/onsi/gomega"                         // nolint
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Creation", func() {
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	It("Can't be created without a logger", func() {
+		wrapper, err := NewTransportWrapper().
+			Limit(1, 1).
+			Build(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(wrapper).To(BeNil())
+		message := err.Error()
+		Expect(message).To(ContainSubstring("logger"))
+		Expect(message).To(ContainSubstring("mandatory"))
+	})
+
+	It("Can be created without limits", func() {
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			Build(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(wrapper).ToNot(BeNil())
+	})
+
+	It("Can't be created with zero rate", func() {
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			Limit(0, 1).
+			Build(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(wrapper).To(BeNil())
+		message := err.Error()
+		Expect(message).To(ContainSubstring("rate"))
+		Expect(message).To(ContainSubstring("global"))
+		Expect(message).To(ContainSubstring("greater than zero"))
+	})
+
+	It("Can't be created with zero burst", func() {
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			ServiceLimit("ocm-clusters-service", 1, 0).
+			Build(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(wrapper).To(BeNil())
+		message := err.Error()
+		Expect(message).To(ContainSubstring("burst"))
+		Expect(message).To(ContainSubstring("ocm-clusters-service"))
+		Expect(message).To(ContainSubstring("greater than zero"))
+	})
+
+	It("Can't be created with empty service name", func() {
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			ServiceLimit("", 1, 1).
+			Build(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(wrapper).To(BeNil())
+		message := err.Error()
+		Expect(message).To(ContainSubstring("service"))
+		Expect(message).To(ContainSubstring("mandatory"))
+	})
+})
+
+var _ = Describe("Limits", func() {
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	// send sends a GET request for the given path using the given client, and returns the time
+	// that it took:
+	send := func(client *http.Client, path string) time.Duration {
+		start := time.Now()
+		response, err := client.Get("http://api.example.com" + path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(http.StatusOK))
+		return time.Since(start)
+	}
+
+	It("Doesn't wait while the burst isn't exhausted", func() {
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			Limit(1, 3).
+			Build(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		client := &http.Client{
+			Transport: wrapper.Wrap(JSONTransport(http.StatusOK, `{}`)),
+		}
+		for i := 0; i < 3; i++ {
+			elapsed := send(client, "/api/clusters_mgmt/v1/clusters")
+			Expect(elapsed).To(BeNumerically("<", 50*time.Millisecond))
+		}
+	})
+
+	It("Waits when the burst is exhausted", func() {
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			Limit(10, 1).
+			Build(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		client := &http.Client{
+			Transport: wrapper.Wrap(JSONTransport(http.StatusOK, `{}`)),
+		}
+		start := time.Now()
+		for i := 0; i < 4; i++ {
+			send(client, "/api/clusters_mgmt/v1/clusters")
+		}
+		elapsed := time.Since(start)
+		Expect(elapsed).To(BeNumerically("~", 300*time.Millisecond, 100*time.Millisecond))
+	})
+
+	It("Applies service limit only to that service", func() {
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			ServiceLimit("ocm-clusters-service", 5, 1).
+			Build(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		client := &http.Client{
+			Transport: wrapper.Wrap(JSONTransport(http.StatusOK, `{}`)),
+		}
+
+		// Exhaust the limit of the clusters service:
+		send(client, "/api/clusters_mgmt/v1/clusters")
+
+		// Requests for other services shouldn't wait:
+		for i := 0; i < 3; i++ {
+			elapsed := send(client, "/api/accounts_mgmt/v1/accounts")
+			Expect(elapsed).To(BeNumerically("<", 50*time.Millisecond))
+		}
+
+		// But requests for the clusters service should:
+		elapsed := send(client, "/api/clusters_mgmt/v1/clusters")
+		Expect(elapsed).To(BeNumerically("~", 200*time.Millisecond, 100*time.Millisecond))
+	})
+
+	It("Applies both global and service limits", func() {
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			Limit(5, 1).
+			ServiceLimit("ocm-clusters-service", 100, 10).
+			Build(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		client := &http.Client{
+			Transport: wrapper.Wrap(JSONTransport(http.StatusOK, `{}`)),
+		}
+		send(client, "/api/clusters_mgmt/v1/clusters")
+		elapsed := send(client, "/api/clusters_mgmt/v1/clusters")
+		Expect(elapsed).To(BeNumerically("~", 200*time.Millisecond, 100*time.Millisecond))
+	})
+
+	It("Stops waiting when the context is cancelled", func() {
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			Limit(0.1, 1).
+			Build(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		client := &http.Client{
+			Transport: wrapper.Wrap(JSONTransport(http.StatusOK, `{}`)),
+		}
+		send(client, "/api/clusters_mgmt/v1/clusters")
+
+		// The next request would need to wait ten seconds, so it should fail when the
+		// context is cancelled:
+		cancelCtx, cancel := context.WithCancel(ctx)
+		time.AfterFunc(100*time.Millisecond, cancel)
+		request, err := http.NewRequestWithContext(
+			cancelCtx,
+			http.MethodGet,
+			"http://api.example.com/api/clusters_mgmt/v1/clusters",
+			nil,
+		)
+		Expect(err).ToNot(HaveOccurred())
+		start := time.Now()
+		_, err = client.Do(request)
+		elapsed := time.Since(start)
+		Expect(err).To(HaveOccurred())
+		Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+		Expect(elapsed).To(BeNumerically("~", 100*time.Millisecond, 50*time.Millisecond))
+	})
+
+	It("Fails inmediately if the deadline would be exceeded", func() {
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			Limit(0.1, 1).
+			Build(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		client := &http.Client{
+			Transport: wrapper.Wrap(JSONTransport(http.StatusOK, `{}`)),
+		}
+		send(client, "/api/clusters_mgmt/v1/clusters")
+		timeoutCtx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		request, err := http.NewRequestWithContext(
+			timeoutCtx,
+			http.MethodGet,
+			"http://api.example.com/api/clusters_mgmt/v1/clusters",
+			nil,
+		)
+		Expect(err).ToNot(HaveOccurred())
+		start := time.Now()
+		_, err = client.Do(request)
+		elapsed := time.Since(start)
+		Expect(err).To(HaveOccurred())
+		Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("ocm-clusters-service"))
+		Expect(elapsed).To(BeNumerically("<", 100*time.Millisecond))
+	})
+
+	It("Returns the token when it doesn't wait", func() {
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			Limit(5, 1).
+			Build(ctx)
+		Expect(err).ToNot(HaveOccurred())
+
+		// Take the only token:
+		err = wrapper.Wait(ctx, "")
+		Expect(err).ToNot(HaveOccurred())
+
+		// Try to wait with a context that is already cancelled, this should fail and return
+		// the token, so that the next wait will only need to wait for one token:
+		cancelCtx, cancel := context.WithCancel(ctx)
+		cancel()
+		err = wrapper.Wait(cancelCtx, "")
+		Expect(err).To(HaveOccurred())
+		start := time.Now()
+		err = wrapper.Wait(ctx, "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(time.Since(start)).To(BeNumerically("~", 200*time.Millisecond, 100*time.Millisecond))
+	})
+})
+
+var _ = Describe("Metrics", func() {
+	var ctx context.Context
+	var server *MetricsServer
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		server = NewMetricsServer()
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("Generates wait duration", func() {
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			Limit(10, 1).
+			MetricsSubsystem("my").
+			MetricsRegisterer(server.Registry()).
+			Build(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		client := &http.Client{
+			Transport: wrapper.Wrap(JSONTransport(http.StatusOK, `{}`)),
+		}
+		for i := 0; i < 2; i++ {
+			response, err := client.Get("http://api.example.com/api/clusters_mgmt/v1/clusters")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.StatusCode).To(Equal(http.StatusOK))
+		}
+		metrics := server.Metrics()
+		Expect(metrics).To(MatchLine(`^my_rate_limit_wait_duration_bucket\{apiservice="ocm-clusters-service",le="0.1"\} 1$`))
+		Expect(metrics).To(MatchLine(`^my_rate_limit_wait_duration_bucket\{apiservice="ocm-clusters-service",le="1"\} 2$`))
+		Expect(metrics).To(MatchLine(`^my_rate_limit_wait_duration_count\{apiservice="ocm-clusters-service"\} 2$`))
+		Expect(metrics).To(MatchLine(`^my_rate_limit_wait_duration_sum\{apiservice="ocm-clusters-service"\} .*$`))
+	})
+
+	It("Doesn't generate metrics without subsystem", func() {
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			Limit(10, 1).
+			MetricsRegisterer(server.Registry()).
+			Build(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		client := &http.Client{
+			Transport: wrapper.Wrap(JSONTransport(http.StatusOK, `{}`)),
+		}
+		response, err := client.Get("http://api.example.com/api/clusters_mgmt/v1/clusters")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(http.StatusOK))
+		metrics := server.Metrics()
+		Expect(metrics).To(ConsistOf(""))
+	})
+})
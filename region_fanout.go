@@ -0,0 +1,279 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the implementation of the fan-out object that runs the same list request
+// against all the regions and merges the results.
+
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/openshift-online/ocm-sdk-go/logging"
+)
+
+// DefaultFanOutWorkers is the default number of regions that are queried concurrently.
+const DefaultFanOutWorkers = 4
+
+// FanOutBuilder contains the data and logic needed to create a fan-out object. Don't create
+// objects of this type directly, use the NewFanOut function instead.
+type FanOutBuilder[T any] struct {
+	logger   logging.Logger
+	resolver *RegionResolver
+	regions  []string
+	workers  int
+	list     func(ctx context.Context, connection *Connection) ([]T, error)
+}
+
+// FanOut runs the same list function against the connections of multiple regions, concurrently,
+// and merges the results. Don't create objects of this type directly, use the NewFanOut function
+// instead.
+type FanOut[T any] struct {
+	logger   logging.Logger
+	resolver *RegionResolver
+	regions  []string
+	workers  int
+	list     func(ctx context.Context, connection *Connection) ([]T, error)
+}
+
+// RegionItem is an item returned by a fan-out, annotated with the name of the region where it
+// was found.
+type RegionItem[T any] struct {
+	Region string
+	Item   T
+}
+
+// FanOutResult contains the merged results of a fan-out.
+type FanOutResult[T any] struct {
+	// Items contains the items returned by all the regions. Items of the same region are
+	// together and in the order returned by the list function, and regions are sorted by name.
+	Items []RegionItem[T]
+
+	// Regions contains the sorted names of the regions that were queried successfully.
+	Regions []string
+
+	// Errors contains the errors of the regions that failed, indexed by region name.
+	Errors map[string]error
+}
+
+// NewFanOut creates a builder that can then be used to configure and create a fan-out object. The
+// type parameter is the type of the items returned by the list function. For example, to list all
+// the clusters in all the regions, using a page iterator to retrieve all the pages:
+//
+//	fanOut, err := sdk.NewFanOut[*cmv1.Cluster]().
+//		Logger(logger).
+//		Resolver(resolver).
+//		Workers(4).
+//		List(func(ctx context.Context, connection *sdk.Connection) ([]*cmv1.Cluster, error) {
+//			var items []*cmv1.Cluster
+//			collection := connection.ClustersMgmt().V1().Clusters()
+//			iterator, err := paging.NewIterator[
+//				*cmv1.ClustersListRequest,
+//				*cmv1.ClustersListResponse,
+//			]().
+//				Request(collection.List).
+//				Build()
+//			if err != nil {
+//				return nil, err
+//			}
+//			err = iterator.Each(ctx, func(response *cmv1.ClustersListResponse) bool {
+//				items = append(items, response.Items().Slice()...)
+//				return true
+//			})
+//			return items, err
+//		}).
+//		Build()
+//	if err != nil {
+//		return err
+//	}
+//	result, err := fanOut.Run(ctx)
+//	if err != nil {
+//		return err
+//	}
+//	for _, item := range result.Items {
+//		fmt.Printf("%s %s\n", item.Region, item.Item.ID())
+//	}
+//	for region, err := range result.Errors {
+//		fmt.Printf("%s failed: %v\n", region, err)
+//	}
+func NewFanOut[T any]() *FanOutBuilder[T] {
+	return &FanOutBuilder[T]{
+		workers: DefaultFanOutWorkers,
+	}
+}
+
+// Logger sets the logger that will be used to send messages to the log. This is mandatory.
+func (b *FanOutBuilder[T]) Logger(value logging.Logger) *FanOutBuilder[T] {
+	b.logger = value
+	return b
+}
+
+// Resolver sets the region resolver that will be used to discover the regions and to create the
+// regional connections. This is mandatory.
+func (b *FanOutBuilder[T]) Resolver(value *RegionResolver) *FanOutBuilder[T] {
+	b.resolver = value
+	return b
+}
+
+// Regions sets the names of the regions that will be queried. Repeated names are queried only
+// once. The default is to query all the regions returned by the resolver.
+func (b *FanOutBuilder[T]) Regions(values ...string) *FanOutBuilder[T] {
+	b.regions = append(b.regions, values...)
+	return b
+}
+
+// Workers sets the maximum number of regions that will be queried concurrently. The default is
+// four.
+func (b *FanOutBuilder[T]) Workers(value int) *FanOutBuilder[T] {
+	b.workers = value
+	return b
+}
+
+// List sets the function that will be called for each region, with the connection for that
+// region, to retrieve the items. The function will be called concurrently for different regions.
+// This is mandatory.
+func (b *FanOutBuilder[T]) List(
+	value func(ctx context.Context, connection *Connection) ([]T, error)) *FanOutBuilder[T] {
+	b.list = value
+	return b
+}
+
+// Build uses the information stored in the builder to create a new fan-out object.
+func (b *FanOutBuilder[T]) Build() (result *FanOut[T], err error) {
+	// Check parameters:
+	if b.logger == nil {
+		err = fmt.Errorf("logger is mandatory")
+		return
+	}
+	if b.resolver == nil {
+		err = fmt.Errorf("resolver is mandatory")
+		return
+	}
+	if b.list == nil {
+		err = fmt.Errorf("list function is mandatory")
+		return
+	}
+	if b.workers <= 0 {
+		err = fmt.Errorf(
+			"number of workers %d isn't valid, it should be greater than zero",
+			b.workers,
+		)
+		return
+	}
+
+	// Copy and deduplicate the regions, so that changes to the builder don't affect the object
+	// and so that each region is queried only once:
+	var regions []string
+	if len(b.regions) > 0 {
+		seen := make(map[string]bool, len(b.regions))
+		regions = make([]string, 0, len(b.regions))
+		for _, region := range b.regions {
+			if !seen[region] {
+				seen[region] = true
+				regions = append(regions, region)
+			}
+		}
+		sort.Strings(regions)
+	}
+
+	// Create and populate the object:
+	result = &FanOut[T]{
+		logger:   b.logger,
+		resolver: b.resolver,
+		regions:  regions,
+		workers:  b.workers,
+		list:     b.list,
+	}
+	return
+}
+
+// Run calls the list function for all the regions and merges the results. Failures of individual
+// regions don't stop the process, they are reported in the Errors field of the result. The
+// returned error will only be non nil if the regions can't be discovered.
+func (f *FanOut[T]) Run(ctx context.Context) (result *FanOutResult[T], err error) {
+	// Get the names of the regions:
+	regions := f.regions
+	if regions == nil {
+		regions, err = f.resolver.Names(ctx)
+		if err != nil {
+			return
+		}
+	}
+
+	// Start the workers, but not more than regions:
+	workers := f.workers
+	if workers > len(regions) {
+		workers = len(regions)
+	}
+	queue := make(chan int, len(regions))
+	for i := range regions {
+		queue <- i
+	}
+	close(queue)
+	items := make([][]T, len(regions))
+	errs := make([]error, len(regions))
+	wg := &sync.WaitGroup{}
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for j := range queue {
+				items[j], errs[j] = f.query(ctx, regions[j])
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Merge the results:
+	result = &FanOutResult[T]{
+		Regions: []string{},
+		Errors:  map[string]error{},
+	}
+	for i, region := range regions {
+		if errs[i] != nil {
+			f.logger.Warn(ctx, "Can't query region '%s': %v", region, errs[i])
+			result.Errors[region] = errs[i]
+			continue
+		}
+		result.Regions = append(result.Regions, region)
+		for _, item := range items[i] {
+			result.Items = append(result.Items, RegionItem[T]{
+				Region: region,
+				Item:   item,
+			})
+		}
+	}
+	return
+}
+
+// query calls the list function for one region.
+func (f *FanOut[T]) query(ctx context.Context, region string) (items []T, err error) {
+	err = ctx.Err()
+	if err != nil {
+		return
+	}
+	connection, err := f.resolver.Connection(ctx, region)
+	if err != nil {
+		return
+	}
+	defer connection.Close()
+	f.logger.Debug(ctx, "Querying region '%s' using URL '%s'", region, connection.URL())
+	items, err = f.list(ctx, connection)
+	return
+}
@@ -0,0 +1,258 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the region fan-out.
+
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/onsi/gomega/ghttp"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"             // nolint
+	. "github.com/onsi/gomega"                         // nolint
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Region fan-out", func() {
+	var ctx context.Context
+	var globalServer *ghttp.Server
+	var usServer *ghttp.Server
+	var euServer *ghttp.Server
+	var connection *Connection
+	var resolver *RegionResolver
+
+	// listClusters is a list function that returns the clusters of the first page.
+	listClusters := func(ctx context.Context, connection *Connection) ([]*cmv1.Cluster, error) {
+		response, err := connection.ClustersMgmt().V1().Clusters().List().SendContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return response.Items().Slice(), nil
+	}
+
+	BeforeEach(func() {
+		var err error
+		ctx = context.Background()
+		globalServer = MakeTCPServer()
+		usServer = MakeTCPServer()
+		euServer = MakeTCPServer()
+		globalServer.AppendHandlers(
+			RespondWithJSON(http.StatusOK, fmt.Sprintf(`{
+				"aws.us-east-1": {
+					"url": "%s"
+				},
+				"aws.eu-west-1": {
+					"url": "%s"
+				}
+			}`, usServer.URL(), euServer.URL())),
+		)
+		connection, err = NewConnectionBuilder().
+			Logger(logger).
+			URL(globalServer.URL()).
+			Tokens(MakeTokenString("Bearer", 5*time.Minute)).
+			BuildContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		resolver, err = NewRegionResolver().
+			Logger(logger).
+			Connection(connection).
+			URL(globalServer.URL() + "/static/ocm-shards.json").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		err := connection.Close()
+		Expect(err).ToNot(HaveOccurred())
+		globalServer.Close()
+		usServer.Close()
+		euServer.Close()
+	})
+
+	It("Can't be created without a list function", func() {
+		fanOut, err := NewFanOut[*cmv1.Cluster]().
+			Logger(logger).
+			Resolver(resolver).
+			Build()
+		Expect(err).To(HaveOccurred())
+		Expect(fanOut).To(BeNil())
+		Expect(err.Error()).To(ContainSubstring("list function"))
+	})
+
+	It("Can't be created with zero workers", func() {
+		fanOut, err := NewFanOut[*cmv1.Cluster]().
+			Logger(logger).
+			Resolver(resolver).
+			List(listClusters).
+			Workers(0).
+			Build()
+		Expect(err).To(HaveOccurred())
+		Expect(fanOut).To(BeNil())
+		Expect(err.Error()).To(ContainSubstring("0"))
+	})
+
+	It("Merges the results of all the regions", func() {
+		usServer.AppendHandlers(
+			RespondWithJSON(http.StatusOK, `{
+				"kind": "ClusterList",
+				"page": 1,
+				"size": 2,
+				"total": 2,
+				"items": [
+					{ "kind": "Cluster", "id": "us1" },
+					{ "kind": "Cluster", "id": "us2" }
+				]
+			}`),
+		)
+		euServer.AppendHandlers(
+			RespondWithJSON(http.StatusOK, `{
+				"kind": "ClusterList",
+				"page": 1,
+				"size": 1,
+				"total": 1,
+				"items": [
+					{ "kind": "Cluster", "id": "eu1" }
+				]
+			}`),
+		)
+		fanOut, err := NewFanOut[*cmv1.Cluster]().
+			Logger(logger).
+			Resolver(resolver).
+			List(listClusters).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		result, err := fanOut.Run(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Errors).To(BeEmpty())
+		Expect(result.Regions).To(Equal([]string{"aws.eu-west-1", "aws.us-east-1"}))
+		Expect(result.Items).To(HaveLen(3))
+		Expect(result.Items[0].Region).To(Equal("aws.eu-west-1"))
+		Expect(result.Items[0].Item.ID()).To(Equal("eu1"))
+		Expect(result.Items[1].Region).To(Equal("aws.us-east-1"))
+		Expect(result.Items[1].Item.ID()).To(Equal("us1"))
+		Expect(result.Items[2].Region).To(Equal("aws.us-east-1"))
+		Expect(result.Items[2].Item.ID()).To(Equal("us2"))
+	})
+
+	It("Reports failures per region", func() {
+		usServer.AppendHandlers(
+			RespondWithJSON(http.StatusOK, `{
+				"kind": "ClusterList",
+				"page": 1,
+				"size": 1,
+				"total": 1,
+				"items": [
+					{ "kind": "Cluster", "id": "us1" }
+				]
+			}`),
+		)
+		euServer.AppendHandlers(
+			RespondWithJSON(http.StatusForbidden, `{
+				"kind": "Error",
+				"id": "403",
+				"reason": "Forbidden"
+			}`),
+		)
+		fanOut, err := NewFanOut[*cmv1.Cluster]().
+			Logger(logger).
+			Resolver(resolver).
+			List(listClusters).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		result, err := fanOut.Run(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Regions).To(Equal([]string{"aws.us-east-1"}))
+		Expect(result.Items).To(HaveLen(1))
+		Expect(result.Items[0].Item.ID()).To(Equal("us1"))
+		Expect(result.Errors).To(HaveLen(1))
+		Expect(result.Errors).To(HaveKey("aws.eu-west-1"))
+	})
+
+	It("Queries only the selected regions", func() {
+		var queried []string
+		fanOut, err := NewFanOut[string]().
+			Logger(logger).
+			Resolver(resolver).
+			Regions("aws.us-east-1").
+			List(func(ctx context.Context, connection *Connection) ([]string, error) {
+				queried = append(queried, connection.Region())
+				return []string{connection.URL()}, nil
+			}).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		result, err := fanOut.Run(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(queried).To(Equal([]string{"aws.us-east-1"}))
+		Expect(result.Items).To(Equal([]RegionItem[string]{{
+			Region: "aws.us-east-1",
+			Item:   usServer.URL(),
+		}}))
+	})
+
+	It("Queries repeated regions only once", func() {
+		var lock sync.Mutex
+		var queried []string
+		fanOut, err := NewFanOut[string]().
+			Logger(logger).
+			Resolver(resolver).
+			Regions("aws.us-east-1", "aws.us-east-1").
+			Regions("aws.us-east-1").
+			List(func(ctx context.Context, connection *Connection) ([]string, error) {
+				lock.Lock()
+				queried = append(queried, connection.Region())
+				lock.Unlock()
+				return []string{connection.URL()}, nil
+			}).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		result, err := fanOut.Run(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(queried).To(Equal([]string{"aws.us-east-1"}))
+		Expect(result.Regions).To(Equal([]string{"aws.us-east-1"}))
+		Expect(result.Items).To(HaveLen(1))
+	})
+
+	It("Honours the number of workers", func() {
+		var active, maxActive int32
+		fanOut, err := NewFanOut[string]().
+			Logger(logger).
+			Resolver(resolver).
+			Workers(1).
+			List(func(ctx context.Context, connection *Connection) ([]string, error) {
+				current := atomic.AddInt32(&active, 1)
+				defer atomic.AddInt32(&active, -1)
+				if current > atomic.LoadInt32(&maxActive) {
+					atomic.StoreInt32(&maxActive, current)
+				}
+				time.Sleep(10 * time.Millisecond)
+				return nil, nil
+			}).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		result, err := fanOut.Run(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Regions).To(HaveLen(2))
+		Expect(maxActive).To(BeNumerically("==", 1))
+	})
+})
@@ -0,0 +1,311 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the implementation of the region resolver, that discovers the regional
+// endpoints using the connection and creates connections for those regions.
+
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/openshift-online/ocm-sdk-go/internal"
+	"github.com/openshift-online/ocm-sdk-go/logging"
+)
+
+// DefaultRegionTTL is the default time that the region resolver keeps the discovered regions before
+// fetching them again.
+const DefaultRegionTTL = 1 * time.Hour
+
+// RegionResolverBuilder contains the data and logic needed to create a region resolver. Don't
+// create instances of this type directly, use the NewRegionResolver function instead.
+type RegionResolverBuilder struct {
+	logger     logging.Logger
+	connection *Connection
+	url        string
+	ttl        time.Duration
+}
+
+// RegionResolver discovers the regional endpoints fetching the `ocm-shards.json` document using the
+// transport of a connection, so that it uses the same trusted CAs, retries, metrics, etc. The
+// results are cached for a configurable time.
+type RegionResolver struct {
+	logger     logging.Logger
+	connection *Connection
+	url        string
+	ttl        time.Duration
+	lock       *sync.Mutex
+	regions    map[string]Region
+	expiry     time.Time
+}
+
+// NewRegionResolver creates a builder that can then be used to configure and create a region
+// resolver.
+func NewRegionResolver() *RegionResolverBuilder {
+	return &RegionResolverBuilder{
+		ttl: DefaultRegionTTL,
+	}
+}
+
+// Logger sets the logger that the resolver will use to send messages to the log. This is
+// mandatory.
+func (b *RegionResolverBuilder) Logger(value logging.Logger) *RegionResolverBuilder {
+	b.logger = value
+	return b
+}
+
+// Connection sets the connection that will be used to fetch the regions and that will be used as
+// the base for the regional connections. This is mandatory.
+func (b *RegionResolverBuilder) Connection(value *Connection) *RegionResolverBuilder {
+	b.connection = value
+	return b
+}
+
+// URL sets the URL of the region discovery document. This is intended for tests or for local
+// replacements of the real service. The default is calculated from the URL of the connection using
+// the DetermineRegionDiscoveryUrl function.
+func (b *RegionResolverBuilder) URL(value string) *RegionResolverBuilder {
+	b.url = value
+	return b
+}
+
+// TTL sets the time that the discovered regions will be cached. A value of zero means that they
+// will be fetched every time they are needed. The default is one hour.
+func (b *RegionResolverBuilder) TTL(value time.Duration) *RegionResolverBuilder {
+	b.ttl = value
+	return b
+}
+
+// Build uses the data stored in the builder to create a new region resolver.
+func (b *RegionResolverBuilder) Build() (result *RegionResolver, err error) {
+	// Check parameters:
+	if b.logger == nil {
+		err = fmt.Errorf("logger is mandatory")
+		return
+	}
+	if b.connection == nil {
+		err = fmt.Errorf("connection is mandatory")
+		return
+	}
+	if b.ttl < 0 {
+		err = fmt.Errorf(
+			"region TTL %s isn't valid, it should be greater or equal than zero",
+			b.ttl,
+		)
+		return
+	}
+
+	// Calculate the discovery URL:
+	url := b.url
+	if url == "" {
+		url, err = DetermineRegionDiscoveryUrl(b.connection.URL())
+		if err != nil {
+			err = fmt.Errorf("can't determine region discovery URL: %w", err)
+			return
+		}
+	}
+
+	// Create and populate the object:
+	result = &RegionResolver{
+		logger:     b.logger,
+		connection: b.connection,
+		url:        url,
+		ttl:        b.ttl,
+		lock:       &sync.Mutex{},
+	}
+	return
+}
+
+// URL returns the URL of the region discovery document.
+func (r *RegionResolver) URL() string {
+	return r.url
+}
+
+// TTL returns the time that the discovered regions are cached.
+func (r *RegionResolver) TTL() time.Duration {
+	return r.ttl
+}
+
+// Regions returns the regions indexed by name. The result is cached, so the document will only be
+// fetched again when the TTL expires or when the Invalidate method is called. The caller must not
+// modify the returned map.
+func (r *RegionResolver) Regions(ctx context.Context) (result map[string]Region, err error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.regions != nil && time.Now().Before(r.expiry) {
+		result = r.regions
+		return
+	}
+	result, err = r.fetch(ctx)
+	if err != nil {
+		return
+	}
+	r.regions = result
+	r.expiry = time.Now().Add(r.ttl)
+	return
+}
+
+// Names returns the sorted names of the regions.
+func (r *RegionResolver) Names(ctx context.Context) (result []string, err error) {
+	regions, err := r.Regions(ctx)
+	if err != nil {
+		return
+	}
+	result = make([]string, 0, len(regions))
+	for name := range regions {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return
+}
+
+// Region returns the details of the region with the given name.
+func (r *RegionResolver) Region(ctx context.Context, name string) (result Region, err error) {
+	regions, err := r.Regions(ctx)
+	if err != nil {
+		return
+	}
+	result, ok := regions[name]
+	if !ok {
+		err = fmt.Errorf("can't find region '%s'", name)
+	}
+	return
+}
+
+// Connection returns a connection for the region with the given name. The regional connection
+// shares the credentials, transports and the rest of the configuration with the connection used
+// by the resolver, the only difference is the base URL. Closing the regional connection doesn't
+// close the original one, and closing the original connection makes all the regional connections
+// unusable.
+func (r *RegionResolver) Connection(ctx context.Context, name string) (result *Connection,
+	err error) {
+	region, err := r.Region(ctx, name)
+	if err != nil {
+		return
+	}
+	result, err = r.connection.regional(ctx, name, region.URL)
+	return
+}
+
+// Invalidate discards the cached regions, so that they will be fetched again the next time that
+// they are needed.
+func (r *RegionResolver) Invalidate() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.regions = nil
+}
+
+// fetch fetches the region discovery document.
+func (r *RegionResolver) fetch(ctx context.Context) (result map[string]Region, err error) {
+	err = r.connection.checkClosed()
+	if err != nil {
+		return
+	}
+	parsed, err := neturl.Parse(r.url)
+	if err != nil {
+		return
+	}
+	server, err := internal.ParseServerAddress(ctx, r.url)
+	if err != nil {
+		return
+	}
+
+	// When using Unix sockets the path of the URL may be the name of the socket, and then it
+	// can't be used as the path of the request:
+	target := *server.URL
+	if server.Network != internal.UnixNetwork || parsed.Query().Has("socket") {
+		target.Path = parsed.Path
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return
+	}
+	request.Header.Set("Accept", "application/json")
+	if r.connection.agent != "" {
+		request.Header.Set("User-Agent", r.connection.agent)
+	}
+	client, err := r.connection.clientSelector.Select(ctx, server)
+	if err != nil {
+		return
+	}
+	r.logger.Debug(ctx, "Fetching regions from '%s'", r.url)
+	response, err := client.Do(request)
+	if err != nil {
+		err = fmt.Errorf("can't retrieve regions from '%s': %w", r.url, err)
+		return
+	}
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return
+	}
+	if response.StatusCode != http.StatusOK {
+		err = fmt.Errorf(
+			"can't retrieve regions from '%s', server responded with code %d",
+			r.url, response.StatusCode,
+		)
+		return
+	}
+	err = json.Unmarshal(body, &result)
+	if err != nil {
+		err = fmt.Errorf("can't decode regions from '%s': %w", r.url, err)
+		return
+	}
+	return
+}
+
+// regional creates a copy of the connection that uses the given base URL instead of the original
+// one. The copy shares all the other resources with the original connection.
+func (c *Connection) regional(ctx context.Context, region, base string) (result *Connection,
+	err error) {
+	err = c.checkClosed()
+	if err != nil {
+		return
+	}
+	address, err := internal.ParseServerAddress(ctx, base)
+	if err != nil {
+		err = fmt.Errorf("can't parse URL '%s' of region '%s': %w", base, region, err)
+		return
+	}
+	current := c.currentURLTable()
+	urlTable := make([]urlTableEntry, len(current))
+	copy(urlTable, current)
+	for i := range urlTable {
+		if urlTable[i].prefix == "" {
+			urlTable[i].url = address
+		}
+	}
+	parent := c
+	if c.parent != nil {
+		parent = c.parent
+	}
+	clone := *c
+	clone.urlTable = urlTable
+	clone.urlTableMutex = &sync.RWMutex{}
+	clone.reloader = nil
+	clone.region = region
+	clone.parent = parent
+	result = &clone
+	return
+}
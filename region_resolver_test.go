@@ -0,0 +1,255 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the region resolver.
+
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/onsi/gomega/ghttp"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"             // nolint
+	. "github.com/onsi/gomega"                         // nolint
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Region resolver", func() {
+	var ctx context.Context
+	var token string
+	var globalServer *ghttp.Server
+	var regionalServer *ghttp.Server
+	var connection *Connection
+
+	// respondWithRegions creates a handler that returns the regions document and checks that
+	// the request contains the token of the connection.
+	respondWithRegions := func() http.HandlerFunc {
+		return ghttp.CombineHandlers(
+			ghttp.VerifyRequest(http.MethodGet, "/static/ocm-shards.json"),
+			ghttp.VerifyHeaderKV("Authorization", "Bearer "+token),
+			RespondWithJSON(http.StatusOK, fmt.Sprintf(`{
+				"aws.us-east-1": {
+					"url": "%s",
+					"aws": ["us-east-1"]
+				}
+			}`, regionalServer.URL())),
+		)
+	}
+
+	BeforeEach(func() {
+		var err error
+		ctx = context.Background()
+		token = MakeTokenString("Bearer", 5*time.Minute)
+		globalServer = MakeTCPServer()
+		regionalServer = MakeTCPServer()
+		connection, err = NewConnectionBuilder().
+			Logger(logger).
+			URL(globalServer.URL()).
+			Tokens(token).
+			BuildContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		err := connection.Close()
+		Expect(err).ToNot(HaveOccurred())
+		globalServer.Close()
+		regionalServer.Close()
+	})
+
+	It("Can't be created without a connection", func() {
+		resolver, err := NewRegionResolver().
+			Logger(logger).
+			Build()
+		Expect(err).To(HaveOccurred())
+		Expect(resolver).To(BeNil())
+		Expect(err.Error()).To(ContainSubstring("connection"))
+	})
+
+	It("Calculates the default discovery URL", func() {
+		resolver, err := NewRegionResolver().
+			Logger(logger).
+			Connection(connection).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resolver.URL()).To(Equal("https://api.openshift.com/static/ocm-shards.json"))
+		Expect(resolver.TTL()).To(Equal(DefaultRegionTTL))
+	})
+
+	It("Caches the regions", func() {
+		globalServer.AppendHandlers(respondWithRegions())
+		resolver, err := NewRegionResolver().
+			Logger(logger).
+			Connection(connection).
+			URL(globalServer.URL() + "/static/ocm-shards.json").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		for i := 0; i < 2; i++ {
+			region, err := resolver.Region(ctx, "aws.us-east-1")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(region.URL).To(Equal(regionalServer.URL()))
+			Expect(region.AWS).To(ConsistOf("us-east-1"))
+		}
+		Expect(globalServer.ReceivedRequests()).To(HaveLen(1))
+	})
+
+	It("Fetches the regions again when the TTL expires", func() {
+		globalServer.AppendHandlers(respondWithRegions(), respondWithRegions())
+		resolver, err := NewRegionResolver().
+			Logger(logger).
+			Connection(connection).
+			URL(globalServer.URL() + "/static/ocm-shards.json").
+			TTL(0).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		names, err := resolver.Names(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(names).To(Equal([]string{"aws.us-east-1"}))
+		names, err = resolver.Names(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(names).To(Equal([]string{"aws.us-east-1"}))
+		Expect(globalServer.ReceivedRequests()).To(HaveLen(2))
+	})
+
+	It("Fails if the region doesn't exist", func() {
+		globalServer.AppendHandlers(respondWithRegions())
+		resolver, err := NewRegionResolver().
+			Logger(logger).
+			Connection(connection).
+			URL(globalServer.URL() + "/static/ocm-shards.json").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		_, err = resolver.Region(ctx, "junk")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("junk"))
+	})
+
+	It("Fails if the server returns an error", func() {
+		globalServer.AppendHandlers(
+			RespondWithJSON(http.StatusNotFound, "{}"),
+		)
+		resolver, err := NewRegionResolver().
+			Logger(logger).
+			Connection(connection).
+			URL(globalServer.URL() + "/static/ocm-shards.json").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		_, err = resolver.Regions(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("404"))
+	})
+
+	It("Creates regional connections that share credentials", func() {
+		globalServer.AppendHandlers(respondWithRegions())
+		regionalServer.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters"),
+				ghttp.VerifyHeaderKV("Authorization", "Bearer "+token),
+				RespondWithJSON(http.StatusOK, "{}"),
+			),
+		)
+		resolver, err := NewRegionResolver().
+			Logger(logger).
+			Connection(connection).
+			URL(globalServer.URL() + "/static/ocm-shards.json").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		regional, err := resolver.Connection(ctx, "aws.us-east-1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(regional.URL()).To(Equal(regionalServer.URL()))
+		Expect(regional.Region()).To(Equal("aws.us-east-1"))
+		Expect(connection.URL()).To(Equal(globalServer.URL()))
+		response, err := regional.Get().
+			Path("/api/clusters_mgmt/v1/clusters").
+			SendContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Status()).To(Equal(http.StatusOK))
+
+		// Closing the regional connection doesn't close the original one:
+		err = regional.Close()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(connection.checkClosed()).To(Succeed())
+	})
+
+	It("Makes regional connections unusable when the original is closed", func() {
+		globalServer.AppendHandlers(respondWithRegions())
+		other, err := NewConnectionBuilder().
+			Logger(logger).
+			URL(globalServer.URL()).
+			Tokens(token).
+			BuildContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		resolver, err := NewRegionResolver().
+			Logger(logger).
+			Connection(other).
+			URL(globalServer.URL() + "/static/ocm-shards.json").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		regional, err := resolver.Connection(ctx, "aws.us-east-1")
+		Expect(err).ToNot(HaveOccurred())
+		err = other.Close()
+		Expect(err).ToNot(HaveOccurred())
+		_, err = regional.Get().
+			Path("/api/clusters_mgmt/v1/clusters").
+			SendContext(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("closed"))
+	})
+
+	It("Builds a connection for the region", func() {
+		globalServer.AppendHandlers(respondWithRegions())
+		regionalServer.AppendHandlers(
+			RespondWithJSON(http.StatusOK, "{}"),
+		)
+		regional, err := NewConnectionBuilder().
+			Logger(logger).
+			URL(globalServer.URL()).
+			Tokens(token).
+			Region("aws.us-east-1").
+			RegionDiscoveryURL(globalServer.URL() + "/static/ocm-shards.json").
+			BuildContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		defer func() {
+			err = regional.Close()
+			Expect(err).ToNot(HaveOccurred())
+		}()
+		Expect(regional.Region()).To(Equal("aws.us-east-1"))
+		Expect(regional.URL()).To(Equal(regionalServer.URL()))
+		_, err = regional.Get().
+			Path("/api/clusters_mgmt/v1/clusters").
+			SendContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(regionalServer.ReceivedRequests()).To(HaveLen(1))
+	})
+
+	It("Fails to build a connection for an unknown region", func() {
+		globalServer.AppendHandlers(respondWithRegions())
+		regional, err := NewConnectionBuilder().
+			Logger(logger).
+			URL(globalServer.URL()).
+			Tokens(token).
+			Region("junk").
+			RegionDiscoveryURL(globalServer.URL() + "/static/ocm-shards.json").
+			BuildContext(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(regional).To(BeNil())
+		Expect(err.Error()).To(ContainSubstring("junk"))
+	})
+})
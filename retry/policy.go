@@ -0,0 +1,316 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the policy that decides which requests can be retried.
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/http2"
+
+	"github.com/openshift-online/ocm-sdk-go/logging"
+)
+
+// DefaultIdempotencyHeader is the name of the header that clients can add to requests to indicate
+// that they can be safely retried even if the method isn't idempotent.
+const DefaultIdempotencyHeader = "Idempotency-Key"
+
+// Policy decides if the result of an attempt to send a request should be retried, and how much to
+// wait before the next attempt. The request, response and error are the ones of the last attempt,
+// and the attempt number starts with one. Note that when the error isn't nil the response will be
+// nil.
+//
+// The returned delay is optional. When it is zero the transport wrapper will calculate it using the
+// configured interval and jitter factor, or the value of the `Retry-After` header if the server
+// sent it. The retry limit and the elapsed time limit of the transport wrapper are always applied,
+// regardless of what the policy returns.
+type Policy interface {
+	Retry(request *http.Request, response *http.Response, err error,
+		attempt int) (retry bool, delay time.Duration)
+}
+
+// PolicyFunc is a function that implements the Policy interface.
+type PolicyFunc func(request *http.Request, response *http.Response, err error,
+	attempt int) (retry bool, delay time.Duration)
+
+// Retry is the implementation of the Policy interface.
+func (f PolicyFunc) Retry(request *http.Request, response *http.Response, err error,
+	attempt int) (retry bool, delay time.Duration) {
+	return f(request, response, err, attempt)
+}
+
+// DefaultPolicyBuilder contains the data and logic needed to create the default retry policy.
+type DefaultPolicyBuilder struct {
+	logger            logging.Logger
+	methods           []string
+	idempotencyHeader string
+}
+
+// DefaultPolicy is the retry policy used when no other is explicitly configured. It retries
+// requests that failed because the connection was closed or reset, or because the HTTP/2 stream
+// was refused or had a protocol error. It also retries responses with status codes 429 and 503,
+// because in those cases the server didn't process the request. Other 5xx responses are retried
+// only for idempotent methods, GET, HEAD and OPTIONS by default, and for requests that contain an
+// idempotency key header, because in those cases the server may have partially processed the
+// request.
+type DefaultPolicy struct {
+	logger            logging.Logger
+	methods           map[string]bool
+	idempotencyHeader string
+}
+
+// Make sure that we implement the interface:
+var _ Policy = (*DefaultPolicy)(nil)
+
+// NewDefaultPolicy creates a builder that can then be used to configure and create the default
+// retry policy.
+func NewDefaultPolicy() *DefaultPolicyBuilder {
+	return &DefaultPolicyBuilder{
+		methods: []string{
+			http.MethodGet,
+			http.MethodHead,
+			http.MethodOptions,
+		},
+		idempotencyHeader: DefaultIdempotencyHeader,
+	}
+}
+
+// Logger sets the logger that the policy will use to explain why requests are retried.
+func (b *DefaultPolicyBuilder) Logger(value logging.Logger) *DefaultPolicyBuilder {
+	b.logger = value
+	return b
+}
+
+// IdempotentMethods adds HTTP methods that will be considered idempotent, so that requests using
+// them will be retried when the server responds with any 5xx status code. For example, to also
+// retry PUT and DELETE requests:
+//
+//	policy, err := retry.NewDefaultPolicy().
+//		Logger(logger).
+//		IdempotentMethods(http.MethodPut, http.MethodDelete).
+//		Build()
+//
+// The GET, HEAD and OPTIONS methods are always considered idempotent.
+func (b *DefaultPolicyBuilder) IdempotentMethods(values ...string) *DefaultPolicyBuilder {
+	b.methods = append(b.methods, values...)
+	return b
+}
+
+// IdempotencyHeader sets the name of the header that indicates that a request can be retried
+// regardless of its method. Requests that contain this header with a non empty value will be
+// retried when the server responds with any 5xx status code. The default is `Idempotency-Key`. Set
+// it to an empty string to disable this behaviour.
+func (b *DefaultPolicyBuilder) IdempotencyHeader(value string) *DefaultPolicyBuilder {
+	b.idempotencyHeader = value
+	return b
+}
+
+// Build uses the information stored in the builder to create the default retry policy.
+func (b *DefaultPolicyBuilder) Build() (result *DefaultPolicy, err error) {
+	// Check parameters:
+	if b.logger == nil {
+		err = fmt.Errorf("logger is mandatory")
+		return
+	}
+
+	// Create and populate the object:
+	methods := map[string]bool{}
+	for _, method := range b.methods {
+		methods[strings.ToUpper(method)] = true
+	}
+	result = &DefaultPolicy{
+		logger:            b.logger,
+		methods:           methods,
+		idempotencyHeader: b.idempotencyHeader,
+	}
+
+	return
+}
+
+// Retry is the implementation of the Policy interface.
+func (p *DefaultPolicy) Retry(request *http.Request, response *http.Response, err error,
+	attempt int) (retry bool, delay time.Duration) {
+	ctx := request.Context()
+	logger := logging.With(
+		p.logger,
+		logging.MethodField, request.Method,
+		logging.HostField, request.URL.Host,
+		logging.PathField, request.URL.Path,
+		logging.AttemptField, attempt,
+	)
+	if err != nil {
+		retry = p.retryError(ctx, logger, request, err)
+	} else {
+		retry = p.retryResponse(ctx, logger, request, response)
+	}
+	return
+}
+
+func (p *DefaultPolicy) retryError(ctx context.Context, logger logging.Logger,
+	request *http.Request, err error) bool {
+	// If the context is done then there is no point in trying again:
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	// Check the errors that indicate that the connection was lost. The server may or may not
+	// have processed the request, but we retry them anyhow because that is what happens in
+	// practice when a server closes idle connections or when a load balancer drops them.
+	switch {
+	case errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF):
+		logger.Warn(
+			ctx,
+			"Request failed with EOF, will try again: %v",
+			err,
+		)
+		return true
+	case errors.Is(err, syscall.ECONNRESET):
+		logger.Warn(
+			ctx,
+			"Request failed with connection reset by peer, will try again: %v",
+			err,
+		)
+		return true
+	}
+
+	// Check the HTTP/2 error codes that indicate that the server didn't process the request:
+	code, ok := http2Code(err)
+	if ok {
+		switch code {
+		case http2.ErrCodeProtocol:
+			logger.Warn(
+				ctx,
+				"Request failed with protocol error, will try again: %v",
+				err,
+			)
+			return true
+		case http2.ErrCodeRefusedStream:
+			logger.Warn(
+				ctx,
+				"Request failed with refused stream, will try again: %v",
+				err,
+			)
+			return true
+		}
+	}
+
+	// Timeouts are retried only for idempotent requests, because the server may have
+	// processed the request before the timeout expired:
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() && p.idempotent(request) {
+		logger.Warn(
+			ctx,
+			"Request timed out, will try again: %v",
+			err,
+		)
+		return true
+	}
+
+	// For any other error we just report it to the caller:
+	return false
+}
+
+func (p *DefaultPolicy) retryResponse(ctx context.Context, logger logging.Logger,
+	request *http.Request, response *http.Response) bool {
+	code := response.StatusCode
+	switch {
+	case code == http.StatusServiceUnavailable || code == http.StatusTooManyRequests:
+		// For 429 and 503 we know that the server didn't process the request, so we
+		// can safely retry regardless of the method.
+		logging.With(logger, logging.StatusField, code).Warn(
+			ctx,
+			"Request failed with code %d, will try again",
+			code,
+		)
+		return true
+	case code >= 500 && p.idempotent(request):
+		// For any other 5xx status code we can't be sure if the server processed
+		// the request, so we retry only requests that don't have side effects, or
+		// that the server can deduplicate using the idempotency key.
+		logging.With(logger, logging.StatusField, code).Warn(
+			ctx,
+			"Request failed with code %d, will try again",
+			code,
+		)
+		return true
+	default:
+		// For any other status code we can't be sure if the server processed the
+		// request, so we just return the result to the caller.
+		return false
+	}
+}
+
+// idempotent checks if the given request can be sent multiple times without additional side
+// effects.
+func (p *DefaultPolicy) idempotent(request *http.Request) bool {
+	if p.methods[request.Method] {
+		return true
+	}
+	if p.idempotencyHeader != "" && request.Header.Get(p.idempotencyHeader) != "" {
+		return true
+	}
+	return false
+}
+
+// http2Code extracts the HTTP/2 error code from the given error. Note that the HTTP/2
+// implementation bundled with the `net/http` package uses its own private copies of the error
+// types, so for those errors the code can only be extracted from the text of the message.
+func http2Code(err error) (code http2.ErrCode, ok bool) {
+	var streamErr http2.StreamError
+	if errors.As(err, &streamErr) {
+		code = streamErr.Code
+		ok = true
+		return
+	}
+	var connErr http2.ConnectionError
+	if errors.As(err, &connErr) {
+		code = http2.ErrCode(connErr)
+		ok = true
+		return
+	}
+	var goAwayErr http2.GoAwayError
+	if errors.As(err, &goAwayErr) {
+		code = goAwayErr.ErrCode
+		ok = true
+		return
+	}
+	message := err.Error()
+	for _, candidate := range bundledCodes {
+		if strings.Contains(message, candidate.String()) {
+			code = candidate
+			ok = true
+			return
+		}
+	}
+	return
+}
+
+// bundledCodes are the HTTP/2 error codes that we try to find in the text of the errors returned
+// by the HTTP/2 implementation bundled with the `net/http` package.
+var bundledCodes = []http2.ErrCode{
+	http2.ErrCodeProtocol,
+	http2.ErrCodeRefusedStream,
+}
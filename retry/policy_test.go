@@ -0,0 +1,360 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the retry policies.
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/http2"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"             // nolint
+	. "github.com/onsi/ginkgo/v2/dsl/table"            // nolint
+	. "github.com/onsi/gomega"                         // nolint
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Default policy", func() {
+	var policy *DefaultPolicy
+
+	BeforeEach(func() {
+		var err error
+		policy, err = NewDefaultPolicy().
+			Logger(logger).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	// makeRequest creates a request with the given method and headers:
+	makeRequest := func(method string, headers ...string) *http.Request {
+		request, err := http.NewRequest(method, "http://api.example.com/mypath", nil)
+		Expect(err).ToNot(HaveOccurred())
+		for i := 0; i < len(headers); i += 2 {
+			request.Header.Set(headers[i], headers[i+1])
+		}
+		return request
+	}
+
+	// makeResponse creates a response with the given status code:
+	makeResponse := func(code int) *http.Response {
+		return &http.Response{
+			StatusCode: code,
+		}
+	}
+
+	It("Can't be created without a logger", func() {
+		policy, err := NewDefaultPolicy().Build()
+		Expect(err).To(HaveOccurred())
+		Expect(policy).To(BeNil())
+		message := err.Error()
+		Expect(message).To(ContainSubstring("logger"))
+		Expect(message).To(ContainSubstring("mandatory"))
+	})
+
+	DescribeTable(
+		"Errors",
+		func(err error, expected bool) {
+			retry, delay := policy.Retry(makeRequest(http.MethodPost), nil, err, 1)
+			Expect(retry).To(Equal(expected))
+			Expect(delay).To(BeZero())
+		},
+		Entry(
+			"EOF",
+			io.EOF,
+			true,
+		),
+		Entry(
+			"Unexpected EOF",
+			fmt.Errorf("can't read body: %w", io.ErrUnexpectedEOF),
+			true,
+		),
+		Entry(
+			"Connection reset",
+			&net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError(
+				"read", syscall.ECONNRESET,
+			)},
+			true,
+		),
+		Entry(
+			"Connection refused",
+			&net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError(
+				"connect", syscall.ECONNREFUSED,
+			)},
+			false,
+		),
+		Entry(
+			"Stream protocol error",
+			fmt.Errorf("can't read: %w", http2.StreamError{
+				StreamID: 1,
+				Code:     http2.ErrCodeProtocol,
+			}),
+			true,
+		),
+		Entry(
+			"Refused stream",
+			fmt.Errorf("can't read: %w", http2.StreamError{
+				StreamID: 1,
+				Code:     http2.ErrCodeRefusedStream,
+			}),
+			true,
+		),
+		Entry(
+			"Stream cancelled",
+			fmt.Errorf("can't read: %w", http2.StreamError{
+				StreamID: 1,
+				Code:     http2.ErrCodeCancel,
+			}),
+			false,
+		),
+		Entry(
+			"Connection protocol error",
+			http2.ConnectionError(http2.ErrCodeProtocol),
+			true,
+		),
+		Entry(
+			"Protocol error from bundled implementation",
+			errors.New("http2: server sent GOAWAY and closed the connection; ErrCode=PROTOCOL_ERROR"),
+			true,
+		),
+		Entry(
+			"Context cancelled",
+			fmt.Errorf("can't send: %w", context.Canceled),
+			false,
+		),
+		Entry(
+			"Other",
+			errors.New("junk"),
+			false,
+		),
+	)
+
+	DescribeTable(
+		"Timeouts",
+		func(method string, expected bool) {
+			err := &net.OpError{Op: "read", Net: "tcp", Err: os.ErrDeadlineExceeded}
+			retry, _ := policy.Retry(makeRequest(method), nil, err, 1)
+			Expect(retry).To(Equal(expected))
+		},
+		Entry("GET", http.MethodGet, true),
+		Entry("POST", http.MethodPost, false),
+	)
+
+	DescribeTable(
+		"Responses",
+		func(request *http.Request, code int, expected bool) {
+			retry, delay := policy.Retry(request, makeResponse(code), nil, 1)
+			Expect(retry).To(Equal(expected))
+			Expect(delay).To(BeZero())
+		},
+		Entry("GET 200", makeRequest(http.MethodGet), http.StatusOK, false),
+		Entry("GET 404", makeRequest(http.MethodGet), http.StatusNotFound, false),
+		Entry("GET 500", makeRequest(http.MethodGet), http.StatusInternalServerError, true),
+		Entry("HEAD 502", makeRequest(http.MethodHead), http.StatusBadGateway, true),
+		Entry("POST 429", makeRequest(http.MethodPost), http.StatusTooManyRequests, true),
+		Entry("POST 503", makeRequest(http.MethodPost), http.StatusServiceUnavailable, true),
+		Entry("POST 500", makeRequest(http.MethodPost), http.StatusInternalServerError, false),
+		Entry("PUT 500", makeRequest(http.MethodPut), http.StatusInternalServerError, false),
+		Entry("DELETE 500", makeRequest(http.MethodDelete), http.StatusInternalServerError, false),
+		Entry(
+			"POST 500 with idempotency key",
+			makeRequest(http.MethodPost, "Idempotency-Key", "123"),
+			http.StatusInternalServerError,
+			true,
+		),
+		Entry(
+			"POST 500 with empty idempotency key",
+			makeRequest(http.MethodPost, "Idempotency-Key", ""),
+			http.StatusInternalServerError,
+			false,
+		),
+	)
+
+	It("Retries additional idempotent methods", func() {
+		policy, err := NewDefaultPolicy().
+			Logger(logger).
+			IdempotentMethods(http.MethodPut, "delete").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		response := makeResponse(http.StatusInternalServerError)
+		retry, _ := policy.Retry(makeRequest(http.MethodPut), response, nil, 1)
+		Expect(retry).To(BeTrue())
+		retry, _ = policy.Retry(makeRequest(http.MethodDelete), response, nil, 1)
+		Expect(retry).To(BeTrue())
+		retry, _ = policy.Retry(makeRequest(http.MethodPatch), response, nil, 1)
+		Expect(retry).To(BeFalse())
+	})
+
+	It("Honours custom idempotency header", func() {
+		policy, err := NewDefaultPolicy().
+			Logger(logger).
+			IdempotencyHeader("X-Request-Id").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		response := makeResponse(http.StatusInternalServerError)
+		request := makeRequest(http.MethodPost, "X-Request-Id", "123")
+		retry, _ := policy.Retry(request, response, nil, 1)
+		Expect(retry).To(BeTrue())
+		request = makeRequest(http.MethodPost, "Idempotency-Key", "123")
+		retry, _ = policy.Retry(request, response, nil, 1)
+		Expect(retry).To(BeFalse())
+	})
+
+	It("Can disable idempotency header", func() {
+		policy, err := NewDefaultPolicy().
+			Logger(logger).
+			IdempotencyHeader("").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		response := makeResponse(http.StatusInternalServerError)
+		request := makeRequest(http.MethodPost, "Idempotency-Key", "123")
+		retry, _ := policy.Retry(request, response, nil, 1)
+		Expect(retry).To(BeFalse())
+	})
+})
+
+var _ = Describe("Policy", func() {
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	It("Is used by default with the logger of the wrapper", func() {
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			Build(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(wrapper.Policy()).To(BeAssignableToTypeOf(&DefaultPolicy{}))
+	})
+
+	It("Receives the details of each attempt", func() {
+		// Create a policy that remembers what it received:
+		var attempts []int
+		var codes []int
+		policy := PolicyFunc(func(request *http.Request, response *http.Response, err error,
+			attempt int) (retry bool, delay time.Duration) {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(request.Method).To(Equal(http.MethodPut))
+			attempts = append(attempts, attempt)
+			codes = append(codes, response.StatusCode)
+			retry = response.StatusCode >= 500
+			delay = time.Millisecond
+			return
+		})
+
+		// Create the wrapper with an interval that is much longer than the delay returned by
+		// the policy, so that we can check that the delay is honoured:
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			Interval(time.Minute).
+			Policy(policy).
+			Build(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(wrapper.Policy()).ToNot(BeNil())
+		transport := CombineTransports(
+			TextTransport(http.StatusInternalServerError, "ko"),
+			TextTransport(http.StatusBadGateway, "ko"),
+			JSONTransport(http.StatusOK, `{ "ok": true }`),
+		)
+		client := &http.Client{
+			Transport: wrapper.Wrap(transport),
+		}
+
+		// Send the request:
+		request, err := http.NewRequest(
+			http.MethodPut,
+			"http://api.example.com/mypath",
+			strings.NewReader(`{}`),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		start := time.Now()
+		response, err := client.Do(request)
+		elapsed := time.Since(start)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(http.StatusOK))
+		Expect(elapsed).To(BeNumerically("<", time.Second))
+
+		// Check what the policy received:
+		Expect(attempts).To(Equal([]int{1, 2}))
+		Expect(codes).To(Equal([]int{
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+		}))
+	})
+
+	It("Can prevent retries", func() {
+		policy := PolicyFunc(func(request *http.Request, response *http.Response, err error,
+			attempt int) (retry bool, delay time.Duration) {
+			return
+		})
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			Policy(policy).
+			Build(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		transport := CombineTransports(
+			TextTransport(http.StatusServiceUnavailable, "ko"),
+			JSONTransport(http.StatusOK, `{ "ok": true }`),
+		)
+		client := &http.Client{
+			Transport: wrapper.Wrap(transport),
+		}
+		response, err := client.Get("http://api.example.com/mypath")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(http.StatusServiceUnavailable))
+	})
+
+	It("Uses the wrapper interval when the policy doesn't return a delay", func() {
+		policy := PolicyFunc(func(request *http.Request, response *http.Response, err error,
+			attempt int) (retry bool, delay time.Duration) {
+			retry = response.StatusCode >= 500
+			return
+		})
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			Interval(100 * time.Millisecond).
+			Jitter(0).
+			Policy(policy).
+			Build(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		transport := CombineTransports(
+			TextTransport(http.StatusInternalServerError, "ko"),
+			JSONTransport(http.StatusOK, `{ "ok": true }`),
+		)
+		client := &http.Client{
+			Transport: wrapper.Wrap(transport),
+		}
+		request, err := http.NewRequest(http.MethodPost, "http://api.example.com/mypath", nil)
+		Expect(err).ToNot(HaveOccurred())
+		start := time.Now()
+		response, err := client.Do(request)
+		elapsed := time.Since(start)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(http.StatusOK))
+		Expect(elapsed).To(BeNumerically("~", 100*time.Millisecond, 50*time.Millisecond))
+	})
+})
@@ -23,7 +23,9 @@ import (
 	"bytes"
 	"context"
 	"io"
+	"math"
 	"math/rand"
+	"strconv"
 	"strings"
 
 	"fmt"
@@ -35,36 +37,47 @@ import (
 
 // Default configuration:
 const (
-	DefaultLimit    = 2
-	DefaultInterval = 1 * time.Second
-	DefaultJitter   = 0.2
+	DefaultLimit           = 2
+	DefaultInterval        = 1 * time.Second
+	DefaultJitter          = 0.2
+	DefaultRetryAfterLimit = 1 * time.Minute
+	DefaultElapsedLimit    = 0
 )
 
 // TransportWrapperBuilder contains the data and logic needed to create a new retry transport
 // wrapper.
 type TransportWrapperBuilder struct {
-	logger   logging.Logger
-	limit    int
-	interval time.Duration
-	jitter   float64
+	logger          logging.Logger
+	limit           int
+	interval        time.Duration
+	jitter          float64
+	retryAfterLimit time.Duration
+	elapsedLimit    time.Duration
+	policy          Policy
 }
 
 // TransportWrapper contains the data and logic needed to wrap an HTTP round tripper with another
 // one that adds retry capability.
 type TransportWrapper struct {
-	logger   logging.Logger
-	limit    int
-	interval time.Duration
-	jitter   float64
+	logger          logging.Logger
+	limit           int
+	interval        time.Duration
+	jitter          float64
+	retryAfterLimit time.Duration
+	elapsedLimit    time.Duration
+	policy          Policy
 }
 
 // roundTripper is a round tripper that adds retry logic.
 type roundTripper struct {
-	logger    logging.Logger
-	limit     int
-	interval  time.Duration
-	jitter    float64
-	transport http.RoundTripper
+	logger          logging.Logger
+	limit           int
+	interval        time.Duration
+	jitter          float64
+	retryAfterLimit time.Duration
+	elapsedLimit    time.Duration
+	policy          Policy
+	transport       http.RoundTripper
 }
 
 // Make sure that we implement the interface:
@@ -74,9 +87,11 @@ var _ http.RoundTripper = (*roundTripper)(nil)
 // retry round tripper.
 func NewTransportWrapper() *TransportWrapperBuilder {
 	return &TransportWrapperBuilder{
-		limit:    DefaultLimit,
-		interval: DefaultInterval,
-		jitter:   DefaultJitter,
+		limit:           DefaultLimit,
+		interval:        DefaultInterval,
+		jitter:          DefaultJitter,
+		retryAfterLimit: DefaultRetryAfterLimit,
+		elapsedLimit:    DefaultElapsedLimit,
 	}
 }
 
@@ -112,6 +127,32 @@ func (b *TransportWrapperBuilder) Jitter(value float64) *TransportWrapperBuilder
 	return b
 }
 
+// RetryAfterLimit sets the maximum time to wait before a retry when the server explicitly requests
+// it using the `Retry-After` header. Servers usually send that header with 429 and 503 responses,
+// and when it is present its value is used instead of the interval calculated from the interval and
+// jitter factor. Larger values will be reduced to this limit. The default value is one minute.
+func (b *TransportWrapperBuilder) RetryAfterLimit(value time.Duration) *TransportWrapperBuilder {
+	b.retryAfterLimit = value
+	return b
+}
+
+// ElapsedLimit sets the maximum total time that can be spent sending a request, including all the
+// retries and the waits between them. When the next wait would exceed this limit no more retries
+// are performed and the result of the last attempt is returned. The default value is zero, which
+// means that there is no limit, other than the retry limit and the deadline of the context.
+func (b *TransportWrapperBuilder) ElapsedLimit(value time.Duration) *TransportWrapperBuilder {
+	b.elapsedLimit = value
+	return b
+}
+
+// Policy sets the policy that decides which requests will be retried and how much to wait before
+// each retry. The default is to use the policy created by the NewDefaultPolicy function, with the
+// logger of the transport wrapper.
+func (b *TransportWrapperBuilder) Policy(value Policy) *TransportWrapperBuilder {
+	b.policy = value
+	return b
+}
+
 // Build uses the information stored in the builder to create a new transport wrapper.
 func (b *TransportWrapperBuilder) Build(ctx context.Context) (result *TransportWrapper, err error) {
 	// Check parameters:
@@ -140,13 +181,41 @@ func (b *TransportWrapperBuilder) Build(ctx context.Context) (result *TransportW
 		)
 		return
 	}
+	if b.retryAfterLimit < 0 {
+		err = fmt.Errorf(
+			"retry after limit %s isn't valid, it should be greater or equal than zero",
+			b.retryAfterLimit,
+		)
+		return
+	}
+	if b.elapsedLimit < 0 {
+		err = fmt.Errorf(
+			"elapsed limit %s isn't valid, it should be greater or equal than zero",
+			b.elapsedLimit,
+		)
+		return
+	}
+
+	// Create the default policy if needed:
+	policy := b.policy
+	if policy == nil {
+		policy, err = NewDefaultPolicy().
+			Logger(b.logger).
+			Build()
+		if err != nil {
+			return
+		}
+	}
 
 	// Create and populate the object:
 	result = &TransportWrapper{
-		logger:   b.logger,
-		limit:    b.limit,
-		interval: b.interval,
-		jitter:   b.jitter,
+		logger:          b.logger,
+		limit:           b.limit,
+		interval:        b.interval,
+		jitter:          b.jitter,
+		retryAfterLimit: b.retryAfterLimit,
+		elapsedLimit:    b.elapsedLimit,
+		policy:          policy,
 	}
 
 	return
@@ -155,11 +224,14 @@ func (b *TransportWrapperBuilder) Build(ctx context.Context) (result *TransportW
 // Wrap creates a new round tripper that wraps the given one and implements the retry logic.
 func (w *TransportWrapper) Wrap(transport http.RoundTripper) http.RoundTripper {
 	return &roundTripper{
-		logger:    w.logger,
-		limit:     w.limit,
-		interval:  w.interval,
-		jitter:    w.jitter,
-		transport: transport,
+		logger:          w.logger,
+		limit:           w.limit,
+		interval:        w.interval,
+		jitter:          w.jitter,
+		retryAfterLimit: w.retryAfterLimit,
+		elapsedLimit:    w.elapsedLimit,
+		policy:          w.policy,
+		transport:       transport,
 	}
 }
 
@@ -178,6 +250,22 @@ func (w *TransportWrapper) Jitter() float64 {
 	return w.jitter
 }
 
+// RetryAfterLimit returns the maximum time to wait when the server sends the `Retry-After` header.
+func (w *TransportWrapper) RetryAfterLimit() time.Duration {
+	return w.retryAfterLimit
+}
+
+// ElapsedLimit returns the maximum total time that can be spent sending a request, including
+// retries. Zero means that there is no limit.
+func (w *TransportWrapper) ElapsedLimit() time.Duration {
+	return w.elapsedLimit
+}
+
+// Policy returns the policy that decides which requests will be retried.
+func (w *TransportWrapper) Policy() Policy {
+	return w.policy
+}
+
 // Close releases all the resources used by the wrapper.
 func (w *TransportWrapper) Close() error {
 	return nil
@@ -204,13 +292,9 @@ func (t *roundTripper) RoundTrip(request *http.Request) (response *http.Response
 	}
 
 	// Try to send the request till it succeeds or else the retry limit is exceeded:
+	start := time.Now()
 	attempt := 0
 	for {
-		// If this is not the first attempt then we should wait:
-		if attempt > 0 {
-			t.sleep(ctx, attempt)
-		}
-
 		// Each time that we retry the request we need to rewind the request body:
 		if bodyCopy != nil {
 			request.Body = io.NopCloser(bytes.NewBuffer(bodyCopy))
@@ -222,102 +306,91 @@ func (t *roundTripper) RoundTrip(request *http.Request) (response *http.Response
 		if attempt > t.limit {
 			return
 		}
+		logger := logging.With(
+			t.logger,
+			logging.MethodField, request.Method,
+			logging.HostField, request.URL.Host,
+			logging.PathField, request.URL.Path,
+			logging.AttemptField, attempt,
+		)
 
-		// Handle errors without HTTP response:
-		if err != nil {
-			message := err.Error()
-			switch {
-			case strings.Contains(message, "EOF"):
-				t.logger.Warn(
-					ctx,
-					"Request for method %s and URL '%s' failed with EOF, "+
-						"will try again: %v",
-					request.Method, request.URL, err,
-				)
-				continue
-			case strings.Contains(message, "connection reset by peer"):
-				t.logger.Warn(
-					ctx,
-					"Request for method %s and URL '%s' failed with connection "+
-						"reset by peer, will try again: %v",
-					request.Method, request.URL, err,
-				)
-				continue
-			case strings.Contains(message, "PROTOCOL_ERROR"):
-				t.logger.Warn(
-					ctx,
-					"Request for method %s and URL '%s' failed with protocol error, "+
-						"will try again: %v",
-					request.Method, request.URL, err,
-				)
-				continue
-			case strings.Contains(message, "REFUSED_STREAM"):
-				t.logger.Warn(
-					ctx,
-					"Request for method %s and URL '%s' failed with refused stream, "+
-						"will try again: %v",
-					request.Method, request.URL, err,
-				)
-				continue
-			default:
-				// For any other error we just report it to the caller:
+		// Check if the result can be retried:
+		retry, delay := t.policy.Retry(request, response, err, attempt)
+		if !retry {
+			if err != nil {
 				err = fmt.Errorf("can't send request: %w", err)
-				return
 			}
+			return
 		}
 
-		// Handle HTTP responses with error codes:
-		method := request.Method
-		code := response.StatusCode
-		switch {
-		case code == http.StatusServiceUnavailable || code == http.StatusTooManyRequests:
-			// For 429 and 503 we know that the server didn't process the request, so we
-			// can safely retry regardless of the method.
-			t.logger.Warn(
+		// Calculate how much we need to wait before the next attempt, unless the policy
+		// already did it, and check that it doesn't exceed the total time allowed. Note
+		// that if it does we return the result of the last attempt, so the response body
+		// must not be closed before this check.
+		if delay <= 0 {
+			delay = t.delay(ctx, logger, attempt, response)
+		}
+		if t.elapsedLimit > 0 && time.Since(start)+delay > t.elapsedLimit {
+			logger.Warn(
 				ctx,
-				"Request for method %s and URL '%s' failed with code %d, "+
-					"will try again",
-				request.Method, request.URL, code,
+				"Request will not be retried because waiting %s would exceed "+
+					"the elapsed time limit of %s",
+				delay, t.elapsedLimit,
 			)
-			err = response.Body.Close()
 			if err != nil {
-				t.logger.Error(
-					ctx,
-					"Failed to close response body for method '%s' and URL '%s'",
-					request.Method, request.URL,
-				)
+				err = fmt.Errorf("can't send request: %w", err)
 			}
-			continue
-		case code >= 500 && method == http.MethodGet:
-			// For any other 5xx status code we can't be sure if the server processed
-			// the request, so we retry only GET requests, as those don't have side
-			// effects.
-			t.logger.Warn(
-				ctx,
-				"Request for method %s and URL '%s' failed with code %d, "+
-					"will try again",
-				request.Method, request.URL, code,
-			)
-			err = response.Body.Close()
-			if err != nil {
-				t.logger.Error(
-					ctx,
-					"Failed to close response body for method '%s' and URL '%s'",
-					request.Method, request.URL,
-				)
+			return
+		}
+
+		// Discard the response of the failed attempt:
+		if response != nil {
+			closeErr := response.Body.Close()
+			if closeErr != nil {
+				logger.Error(ctx, "Failed to close response body: %v", closeErr)
 			}
-			continue
-		default:
-			// For any other status code we can't be sure if the server processed the
-			// request, so we just return the result to the caller.
+			response = nil
+		}
+
+		// Wait before the next attempt, unless the context is done:
+		err = t.sleep(ctx, logger, delay)
+		if err != nil {
+			err = fmt.Errorf("can't send request: %w", err)
 			return
 		}
 	}
 }
 
-// sleep calculates a retry interval taking into account the configured interval and jitter factor
-// and then waits that time.
-func (t *roundTripper) sleep(ctx context.Context, attempt int) {
+// delay calculates the time to wait before the next attempt. If the response contains the
+// `Retry-After` header then its value is used, limited by the configured maximum. Otherwise the
+// interval is calculated taking into account the configured interval and jitter factor.
+func (t *roundTripper) delay(ctx context.Context, logger logging.Logger, attempt int,
+	response *http.Response) time.Duration {
+	// Honour the delay requested by the server, if any:
+	if response != nil {
+		value := response.Header.Get("Retry-After")
+		if value != "" {
+			interval, ok := parseRetryAfter(value, time.Now())
+			if ok {
+				if interval > t.retryAfterLimit {
+					logger.Debug(
+						ctx,
+						"Server requested to wait %s before next attempt, "+
+							"will wait only %s",
+						interval, t.retryAfterLimit,
+					)
+					interval = t.retryAfterLimit
+				}
+				return interval
+			}
+			logger.Debug(
+				ctx,
+				"Ignoring invalid 'Retry-After' header value '%s'",
+				value,
+			)
+		}
+	}
+
 	// Start with the configured interval:
 	interval := t.interval
 
@@ -330,7 +403,49 @@ func (t *roundTripper) sleep(ctx context.Context, attempt int) {
 	delta := time.Duration(float64(interval) * factor)
 	interval += delta
 
-	// Go sleep for a while:
-	t.logger.Debug(ctx, "Wating %s before next attempt", interval)
-	time.Sleep(interval)
+	return interval
+}
+
+// sleep waits the given time, or till the context is done. It returns the error of the context
+// if it is done before the time expires.
+func (t *roundTripper) sleep(ctx context.Context, logger logging.Logger,
+	interval time.Duration) error {
+	logger.Debug(ctx, "Wating %s before next attempt", interval)
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// parseRetryAfter parses the value of the `Retry-After` header, which can be a number of seconds
+// or an HTTP date, and returns the corresponding time to wait. Dates in the past result in zero.
+func parseRetryAfter(value string, now time.Time) (result time.Duration, ok bool) {
+	value = strings.TrimSpace(value)
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err == nil {
+		if seconds < 0 {
+			return
+		}
+		if seconds > int64(math.MaxInt64/time.Second) {
+			result = math.MaxInt64
+		} else {
+			result = time.Duration(seconds) * time.Second
+		}
+		ok = true
+		return
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return
+	}
+	result = date.Sub(now)
+	if result < 0 {
+		result = 0
+	}
+	ok = true
+	return
 }
@@ -21,7 +21,9 @@ package retry
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"io"
+	"math"
 	"net"
 	"net/http"
 	"net/url"
@@ -31,6 +33,7 @@ import (
 	"golang.org/x/net/http2"
 
 	. "github.com/onsi/ginkgo/v2/dsl/core"             // nolint
+	. "github.com/onsi/ginkgo/v2/dsl/table"            // nolint
 	. "github.com/onsi/gomega"                         // nolint
 	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
 )
@@ -159,6 +162,41 @@ var _ = Describe("Creation", func() {
 		Expect(message).To(ContainSubstring("between zero and one"))
 	})
 
+	It("Can't be created with negative retry after limit", func() {
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			RetryAfterLimit(-1 * time.Second).
+			Build(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(wrapper).To(BeNil())
+		message := err.Error()
+		Expect(message).To(ContainSubstring("retry after limit"))
+		Expect(message).To(ContainSubstring("-1s"))
+		Expect(message).To(ContainSubstring("greater or equal than zero"))
+	})
+
+	It("Can't be created with negative elapsed limit", func() {
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			ElapsedLimit(-1 * time.Second).
+			Build(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(wrapper).To(BeNil())
+		message := err.Error()
+		Expect(message).To(ContainSubstring("elapsed limit"))
+		Expect(message).To(ContainSubstring("-1s"))
+		Expect(message).To(ContainSubstring("greater or equal than zero"))
+	})
+
+	It("Uses the default limits", func() {
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			Build(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(wrapper.RetryAfterLimit()).To(Equal(DefaultRetryAfterLimit))
+		Expect(wrapper.ElapsedLimit()).To(BeZero())
+	})
+
 	It("Can't be created with jitter greater than one", func() {
 		wrapper, err := NewTransportWrapper().
 			Logger(logger).
@@ -877,3 +915,218 @@ func Serve(conn net.Conn, handler http.HandlerFunc) {
 		Handler: handler,
 	})
 }
+
+var _ = Describe("Retry after", func() {
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	// retryAfterTransport creates a transport that returns the given status code and retry after
+	// header:
+	retryAfterTransport := func(code int, value string) http.RoundTripper {
+		return TransportFunc(func(request *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: code,
+				Header: http.Header{
+					"Content-Type": []string{"plain/text"},
+					"Retry-After":  []string{value},
+				},
+				Body: io.NopCloser(strings.NewReader("ko")),
+			}, nil
+		})
+	}
+
+	DescribeTable(
+		"Parsing",
+		func(value string, expected time.Duration, valid bool) {
+			now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+			actual, ok := parseRetryAfter(value, now)
+			Expect(ok).To(Equal(valid))
+			Expect(actual).To(Equal(expected))
+		},
+		Entry("Seconds", "120", 120*time.Second, true),
+		Entry("Zero seconds", "0", time.Duration(0), true),
+		Entry("Seconds with spaces", " 3 ", 3*time.Second, true),
+		Entry("Negative seconds", "-1", time.Duration(0), false),
+		Entry("Huge seconds", "99999999999999999", time.Duration(math.MaxInt64), true),
+		Entry("Date", "Tue, 02 Jan 2024 03:04:15 GMT", 10*time.Second, true),
+		Entry("Date in the past", "Tue, 02 Jan 2024 03:04:00 GMT", time.Duration(0), true),
+		Entry("Junk", "junk", time.Duration(0), false),
+	)
+
+	It("Honours the delay requested by the server", func() {
+		transport := CombineTransports(
+			retryAfterTransport(http.StatusTooManyRequests, "1"),
+			JSONTransport(http.StatusOK, `{ "ok": true }`),
+		)
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			Interval(10 * time.Millisecond).
+			Jitter(0).
+			Build(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		client := &http.Client{
+			Transport: wrapper.Wrap(transport),
+		}
+		start := time.Now()
+		response, err := client.Get("http://api.example.com/mypath")
+		elapsed := time.Since(start)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(http.StatusOK))
+		Expect(elapsed).To(BeNumerically("~", time.Second, 200*time.Millisecond))
+	})
+
+	It("Honours date requested by the server", func() {
+		date := time.Now().Add(2 * time.Second).UTC().Format(http.TimeFormat)
+		transport := CombineTransports(
+			retryAfterTransport(http.StatusServiceUnavailable, date),
+			JSONTransport(http.StatusOK, `{ "ok": true }`),
+		)
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			Interval(10 * time.Millisecond).
+			Jitter(0).
+			Build(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		client := &http.Client{
+			Transport: wrapper.Wrap(transport),
+		}
+		start := time.Now()
+		response, err := client.Get("http://api.example.com/mypath")
+		elapsed := time.Since(start)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(http.StatusOK))
+		Expect(elapsed).To(BeNumerically(">", 500*time.Millisecond))
+		Expect(elapsed).To(BeNumerically("<", 2500*time.Millisecond))
+	})
+
+	It("Limits the delay requested by the server", func() {
+		transport := CombineTransports(
+			retryAfterTransport(http.StatusTooManyRequests, "3600"),
+			JSONTransport(http.StatusOK, `{ "ok": true }`),
+		)
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			RetryAfterLimit(100 * time.Millisecond).
+			Build(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		client := &http.Client{
+			Transport: wrapper.Wrap(transport),
+		}
+		start := time.Now()
+		response, err := client.Get("http://api.example.com/mypath")
+		elapsed := time.Since(start)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(http.StatusOK))
+		Expect(elapsed).To(BeNumerically("~", 100*time.Millisecond, 50*time.Millisecond))
+	})
+
+	It("Ignores invalid value", func() {
+		transport := CombineTransports(
+			retryAfterTransport(http.StatusTooManyRequests, "junk"),
+			JSONTransport(http.StatusOK, `{ "ok": true }`),
+		)
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			Interval(100 * time.Millisecond).
+			Jitter(0).
+			Build(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		client := &http.Client{
+			Transport: wrapper.Wrap(transport),
+		}
+		start := time.Now()
+		response, err := client.Get("http://api.example.com/mypath")
+		elapsed := time.Since(start)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(http.StatusOK))
+		Expect(elapsed).To(BeNumerically("~", 100*time.Millisecond, 50*time.Millisecond))
+	})
+})
+
+var _ = Describe("Cancellation", func() {
+	It("Stops waiting when the context is cancelled", func() {
+		// Create a transport that always fails, and a wrapper with a long interval:
+		transport := TextTransport(http.StatusServiceUnavailable, "ko")
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			Interval(time.Minute).
+			Build(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		client := &http.Client{
+			Transport: wrapper.Wrap(transport),
+		}
+
+		// Send the request with a context that will be cancelled soon:
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		request, err := http.NewRequestWithContext(
+			ctx,
+			http.MethodGet,
+			"http://api.example.com/mypath",
+			nil,
+		)
+		Expect(err).ToNot(HaveOccurred())
+		start := time.Now()
+		response, err := client.Do(request)
+		elapsed := time.Since(start)
+		Expect(err).To(HaveOccurred())
+		Expect(response).To(BeNil())
+		Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+		Expect(elapsed).To(BeNumerically("<", time.Second))
+	})
+})
+
+var _ = Describe("Elapsed limit", func() {
+	It("Returns last response when the limit would be exceeded", func() {
+		// Create a transport that fails the first time, and a wrapper with an interval
+		// that is longer than the elapsed limit:
+		transport := CombineTransports(
+			TextTransport(http.StatusServiceUnavailable, "ko"),
+			JSONTransport(http.StatusOK, `{ "ok": true }`),
+		)
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			Interval(time.Second).
+			Jitter(0).
+			ElapsedLimit(500 * time.Millisecond).
+			Build(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		client := &http.Client{
+			Transport: wrapper.Wrap(transport),
+		}
+
+		// Send the request and verify that the first response is returned without waiting:
+		start := time.Now()
+		response, err := client.Get("http://api.example.com/mypath")
+		elapsed := time.Since(start)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(http.StatusServiceUnavailable))
+		body, err := io.ReadAll(response.Body)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(body)).To(Equal("ko"))
+		Expect(elapsed).To(BeNumerically("<", 100*time.Millisecond))
+	})
+
+	It("Retries while within the limit", func() {
+		transport := CombineTransports(
+			TextTransport(http.StatusServiceUnavailable, "ko"),
+			JSONTransport(http.StatusOK, `{ "ok": true }`),
+		)
+		wrapper, err := NewTransportWrapper().
+			Logger(logger).
+			Interval(100 * time.Millisecond).
+			Jitter(0).
+			ElapsedLimit(time.Second).
+			Build(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		client := &http.Client{
+			Transport: wrapper.Wrap(transport),
+		}
+		response, err := client.Get("http://api.example.com/mypath")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(http.StatusOK))
+	})
+})
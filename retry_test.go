@@ -20,10 +20,13 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/openshift-online/ocm-sdk-go/logging"
+	"github.com/openshift-online/ocm-sdk-go/retry"
 
 	. "github.com/onsi/ginkgo/v2/dsl/core"             // nolint
 	. "github.com/onsi/gomega"                         // nolint
@@ -110,6 +113,42 @@ var _ = Describe("Retry", func() {
 		})
 	})
 
+	It("Limits the delay requested by the server", func() {
+		// Create a connection with a transport wrapper that returns 429 with a very large
+		// `Retry-After` for the first request and 200 for the second.
+		connection, err := NewConnectionBuilder().
+			Logger(logger).
+			Tokens(token).
+			TransportWrapper(func(_ http.RoundTripper) http.RoundTripper {
+				return CombineTransports(
+					TransportFunc(func(*http.Request) (*http.Response, error) {
+						return &http.Response{
+							StatusCode: http.StatusTooManyRequests,
+							Header: http.Header{
+								"Content-Type": []string{"application/json"},
+								"Retry-After":  []string{"3600"},
+							},
+							Body: io.NopCloser(strings.NewReader("{}")),
+						}, nil
+					}),
+					JSONTransport(http.StatusOK, "{}"),
+				)
+			}).
+			RetryAfterLimit(10 * time.Millisecond).
+			RetryElapsedLimit(time.Minute).
+			BuildContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(connection.RetryAfterLimit()).To(Equal(10 * time.Millisecond))
+		Expect(connection.RetryElapsedLimit()).To(Equal(time.Minute))
+
+		// Send the request:
+		start := time.Now()
+		response, err := connection.Get().Path("/mypath").Send()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Status()).To(Equal(http.StatusOK))
+		Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+	})
+
 	Describe("Delete", func() {
 		It("Retries for protocol error", func() {
 			// Create a connection with a transport wrapper that returns an error for
@@ -206,6 +245,91 @@ var _ = Describe("Retry", func() {
 			Expect(err).ToNot(HaveOccurred())
 			Expect(response).ToNot(BeNil())
 		})
+
+		It("Retries for 500 with idempotency key", func() {
+			// Create a connection with a transport wrapper that returns 500 for the
+			// first request and 200 for the second.
+			connection, err := NewConnectionBuilder().
+				Logger(logger).
+				Tokens(token).
+				TransportWrapper(func(_ http.RoundTripper) http.RoundTripper {
+					return CombineTransports(
+						JSONTransport(http.StatusInternalServerError, "{}"),
+						JSONTransport(http.StatusOK, "{}"),
+					)
+				}).
+				RetryInterval(10 * time.Millisecond).
+				BuildContext(ctx)
+			Expect(err).ToNot(HaveOccurred())
+
+			// Send the request:
+			response, err := connection.Post().
+				Path("/mypath").
+				Header("Idempotency-Key", "123").
+				String(`{}`).
+				Send()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.Status()).To(Equal(http.StatusOK))
+		})
+
+		It("Doesn't retry for 500 without idempotency key", func() {
+			// Create a connection with a transport wrapper that returns 500 for the
+			// first request and 200 for the second.
+			connection, err := NewConnectionBuilder().
+				Logger(logger).
+				Tokens(token).
+				TransportWrapper(func(_ http.RoundTripper) http.RoundTripper {
+					return CombineTransports(
+						JSONTransport(http.StatusInternalServerError, "{}"),
+						JSONTransport(http.StatusOK, "{}"),
+					)
+				}).
+				RetryInterval(10 * time.Millisecond).
+				BuildContext(ctx)
+			Expect(err).ToNot(HaveOccurred())
+
+			// Send the request:
+			response, err := connection.Post().
+				Path("/mypath").
+				String(`{}`).
+				Send()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.Status()).To(Equal(http.StatusInternalServerError))
+		})
+	})
+
+	It("Uses the configured policy", func() {
+		// Create a policy that retries PUT requests:
+		policy, err := retry.NewDefaultPolicy().
+			Logger(logger).
+			IdempotentMethods(http.MethodPut).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+
+		// Create a connection with a transport wrapper that returns 500 for the first
+		// request and 200 for the second.
+		connection, err := NewConnectionBuilder().
+			Logger(logger).
+			Tokens(token).
+			TransportWrapper(func(_ http.RoundTripper) http.RoundTripper {
+				return CombineTransports(
+					JSONTransport(http.StatusInternalServerError, "{}"),
+					JSONTransport(http.StatusOK, "{}"),
+				)
+			}).
+			RetryInterval(10 * time.Millisecond).
+			RetryPolicy(policy).
+			BuildContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(connection.RetryPolicy()).To(BeIdenticalTo(policy))
+
+		// Send the request:
+		response, err := connection.Put().
+			Path("/mypath").
+			String(`{}`).
+			Send()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Status()).To(Equal(http.StatusOK))
 	})
 
 	It("Writes error to the debug log", func() {
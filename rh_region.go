@@ -14,6 +14,9 @@ type Region struct {
 	GCP []string
 }
 
+// GetRhRegions fetches the regions from the region discovery document that corresponds to the given
+// URL. Note that this uses the default HTTP client, without custom trusted CAs, timeouts or
+// caching. Consider using a RegionResolver instead, which uses the transport of a connection.
 func GetRhRegions(ocmServiceUrl string) (map[string]Region, error) {
 	var regions map[string]Region
 	url, err := DetermineRegionDiscoveryUrl(ocmServiceUrl)
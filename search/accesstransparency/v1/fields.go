@@ -0,0 +1,91 @@
+/*
+Copyright (c) 2020 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// IMPORTANT: This file has been generated automatically, refrain from modifying it manually as all
+// your changes will be lost when the file is generated again.
+
+package v1 // github.com/openshift-online/ocm-sdk-go/search/accesstransparency/v1
+
+import (
+	"github.com/openshift-online/ocm-sdk-go/search"
+)
+
+// AccessRequest contains the names of the fields of the 'AccessRequest' type that can be used
+// in search expressions.
+var AccessRequest = accessRequestFields{
+	ClusterID:             "cluster_id",
+	CreatedAt:             "created_at",
+	Deadline:              "deadline",
+	DeadlineAt:            "deadline_at",
+	Duration:              "duration",
+	ID:                    "id",
+	InternalSupportCaseID: "internal_support_case_id",
+	Justification:         "justification",
+	OrganizationID:        "organization_id",
+	RequestedBy:           "requested_by",
+	Status: accessRequestStatusFields{
+		ExpiresAt: "status.expires_at",
+		State:     "status.state",
+	},
+	SubscriptionID: "subscription_id",
+	SupportCaseID:  "support_case_id",
+	UpdatedAt:      "updated_at",
+}
+
+// Decision contains the names of the fields of the 'Decision' type that can be used
+// in search expressions.
+var Decision = decisionFields{
+	CreatedAt:     "created_at",
+	DecidedBy:     "decided_by",
+	Decision:      "decision",
+	ID:            "id",
+	Justification: "justification",
+	UpdatedAt:     "updated_at",
+}
+
+// accessRequestFields is the type of the AccessRequest variable.
+type accessRequestFields struct {
+	ClusterID             search.Field
+	CreatedAt             search.Field
+	Deadline              search.Field
+	DeadlineAt            search.Field
+	Duration              search.Field
+	ID                    search.Field
+	InternalSupportCaseID search.Field
+	Justification         search.Field
+	OrganizationID        search.Field
+	RequestedBy           search.Field
+	Status                accessRequestStatusFields
+	SubscriptionID        search.Field
+	SupportCaseID         search.Field
+	UpdatedAt             search.Field
+}
+
+// accessRequestStatusFields contains the names of the fields of the 'status' object.
+type accessRequestStatusFields struct {
+	ExpiresAt search.Field
+	State     search.Field
+}
+
+// decisionFields is the type of the Decision variable.
+type decisionFields struct {
+	CreatedAt     search.Field
+	DecidedBy     search.Field
+	Decision      search.Field
+	ID            search.Field
+	Justification search.Field
+	UpdatedAt     search.Field
+}
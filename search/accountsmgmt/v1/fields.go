@@ -0,0 +1,798 @@
+/*
+Copyright (c) 2020 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// IMPORTANT: This file has been generated automatically, refrain from modifying it manually as all
+// your changes will be lost when the file is generated again.
+
+package v1 // github.com/openshift-online/ocm-sdk-go/search/accountsmgmt/v1
+
+import (
+	"github.com/openshift-online/ocm-sdk-go/search"
+)
+
+// Account contains the names of the fields of the 'Account' type that can be used
+// in search expressions.
+var Account = accountFields{
+	BanCode:        "ban_code",
+	BanDescription: "ban_description",
+	Banned:         "banned",
+	CreatedAt:      "created_at",
+	Email:          "email",
+	FirstName:      "first_name",
+	ID:             "id",
+	LastName:       "last_name",
+	Organization: accountOrganizationFields{
+		CreatedAt:    "organization.created_at",
+		EbsAccountID: "organization.ebs_account_id",
+		ExternalID:   "organization.external_id",
+		ID:           "organization.id",
+		Name:         "organization.name",
+		UpdatedAt:    "organization.updated_at",
+	},
+	RHITAccountID:  "rhit_account_id",
+	RHITWebUserID:  "rhit_web_user_id",
+	ServiceAccount: "service_account",
+	UpdatedAt:      "updated_at",
+	Username:       "username",
+}
+
+// Capability contains the names of the fields of the 'Capability' type that can be used
+// in search expressions.
+var Capability = capabilityFields{
+	Inherited: "inherited",
+	Name:      "name",
+	Value:     "value",
+}
+
+// CloudResource contains the names of the fields of the 'CloudResource' type that can be used
+// in search expressions.
+var CloudResource = cloudResourceFields{
+	Active:         "active",
+	CPUCores:       "cpu_cores",
+	Category:       "category",
+	CategoryPretty: "category_pretty",
+	CloudProvider:  "cloud_provider",
+	CreatedAt:      "created_at",
+	GenericName:    "generic_name",
+	ID:             "id",
+	Memory:         "memory",
+	MemoryPretty:   "memory_pretty",
+	NamePretty:     "name_pretty",
+	ResourceType:   "resource_type",
+	SizePretty:     "size_pretty",
+	UpdatedAt:      "updated_at",
+}
+
+// DefaultCapability contains the names of the fields of the 'DefaultCapability' type that can be used
+// in search expressions.
+var DefaultCapability = defaultCapabilityFields{
+	Name:  "name",
+	Value: "value",
+}
+
+// DeletedSubscription contains the names of the fields of the 'DeletedSubscription' type that can be used
+// in search expressions.
+var DeletedSubscription = deletedSubscriptionFields{
+	BillingExpirationDate:     "billing_expiration_date",
+	BillingMarketplaceAccount: "billing_marketplace_account",
+	CPUTotal:                  "cpu_total",
+	CloudAccountID:            "cloud_account_id",
+	CloudProviderID:           "cloud_provider_id",
+	ClusterBillingModel:       "cluster_billing_model",
+	ClusterID:                 "cluster_id",
+	ConsoleURL:                "console_url",
+	ConsumerUUID:              "consumer_uuid",
+	CreatedAt:                 "created_at",
+	CreatorID:                 "creator_id",
+	DisplayName:               "display_name",
+	ExternalClusterID:         "external_cluster_id",
+	ID:                        "id",
+	LastReconcileDate:         "last_reconcile_date",
+	LastReleasedAt:            "last_released_at",
+	LastTelemetryDate:         "last_telemetry_date",
+	Managed:                   "managed",
+	Metrics:                   "metrics",
+	OrganizationID:            "organization_id",
+	PlanID:                    "plan_id",
+	ProductBundle:             "product_bundle",
+	Provenance:                "provenance",
+	QueryTimestamp:            "query_timestamp",
+	RegionID:                  "region_id",
+	Released:                  "released",
+	ServiceLevel:              "service_level",
+	SocketTotal:               "socket_total",
+	Status:                    "status",
+	SupportLevel:              "support_level",
+	SystemUnits:               "system_units",
+	TrialEndDate:              "trial_end_date",
+	Usage:                     "usage",
+}
+
+// Label contains the names of the fields of the 'Label' type that can be used
+// in search expressions.
+var Label = labelFields{
+	AccountID:      "account_id",
+	CreatedAt:      "created_at",
+	ID:             "id",
+	Internal:       "internal",
+	Key:            "key",
+	ManagedBy:      "managed_by",
+	OrganizationID: "organization_id",
+	SubscriptionID: "subscription_id",
+	Type:           "type",
+	UpdatedAt:      "updated_at",
+	Value:          "value",
+}
+
+// Organization contains the names of the fields of the 'Organization' type that can be used
+// in search expressions.
+var Organization = organizationFields{
+	CreatedAt:    "created_at",
+	EbsAccountID: "ebs_account_id",
+	ExternalID:   "external_id",
+	ID:           "id",
+	Name:         "name",
+	UpdatedAt:    "updated_at",
+}
+
+// QuotaCost contains the names of the fields of the 'QuotaCost' type that can be used
+// in search expressions.
+var QuotaCost = quotaCostFields{
+	Allowed:        "allowed",
+	Consumed:       "consumed",
+	OrganizationID: "organization_id",
+	QuotaID:        "quota_id",
+	Version:        "version",
+}
+
+// RegistryCredential contains the names of the fields of the 'RegistryCredential' type that can be used
+// in search expressions.
+var RegistryCredential = registryCredentialFields{
+	Account: registryCredentialAccountFields{
+		BanCode:        "account.ban_code",
+		BanDescription: "account.ban_description",
+		Banned:         "account.banned",
+		CreatedAt:      "account.created_at",
+		Email:          "account.email",
+		FirstName:      "account.first_name",
+		ID:             "account.id",
+		LastName:       "account.last_name",
+		Organization: registryCredentialAccountOrganizationFields{
+			CreatedAt:    "account.organization.created_at",
+			EbsAccountID: "account.organization.ebs_account_id",
+			ExternalID:   "account.organization.external_id",
+			ID:           "account.organization.id",
+			Name:         "account.organization.name",
+			UpdatedAt:    "account.organization.updated_at",
+		},
+		RHITAccountID:  "account.rhit_account_id",
+		RHITWebUserID:  "account.rhit_web_user_id",
+		ServiceAccount: "account.service_account",
+		UpdatedAt:      "account.updated_at",
+		Username:       "account.username",
+	},
+	CreatedAt:          "created_at",
+	ExternalResourceID: "external_resource_id",
+	ID:                 "id",
+	Registry: registryCredentialRegistryFields{
+		CloudAlias: "registry.cloud_alias",
+		CreatedAt:  "registry.created_at",
+		ID:         "registry.id",
+		Name:       "registry.name",
+		OrgName:    "registry.org_name",
+		TeamName:   "registry.team_name",
+		Type:       "registry.type",
+		URL:        "registry.url",
+		UpdatedAt:  "registry.updated_at",
+	},
+	Token:     "token",
+	UpdatedAt: "updated_at",
+	Username:  "username",
+}
+
+// ResourceQuota contains the names of the fields of the 'ResourceQuota' type that can be used
+// in search expressions.
+var ResourceQuota = resourceQuotaFields{
+	CreatedAt:      "created_at",
+	ID:             "id",
+	OrganizationID: "organization_id",
+	SKU:            "sku",
+	SKUCount:       "sku_count",
+	Type:           "type",
+	UpdatedAt:      "updated_at",
+}
+
+// Role contains the names of the fields of the 'Role' type that can be used
+// in search expressions.
+var Role = roleFields{
+	ID:   "id",
+	Name: "name",
+}
+
+// RoleBinding contains the names of the fields of the 'RoleBinding' type that can be used
+// in search expressions.
+var RoleBinding = roleBindingFields{
+	Account: roleBindingAccountFields{
+		BanCode:        "account.ban_code",
+		BanDescription: "account.ban_description",
+		Banned:         "account.banned",
+		CreatedAt:      "account.created_at",
+		Email:          "account.email",
+		FirstName:      "account.first_name",
+		ID:             "account.id",
+		LastName:       "account.last_name",
+		Organization: roleBindingAccountOrganizationFields{
+			CreatedAt:    "account.organization.created_at",
+			EbsAccountID: "account.organization.ebs_account_id",
+			ExternalID:   "account.organization.external_id",
+			ID:           "account.organization.id",
+			Name:         "account.organization.name",
+			UpdatedAt:    "account.organization.updated_at",
+		},
+		RHITAccountID:  "account.rhit_account_id",
+		RHITWebUserID:  "account.rhit_web_user_id",
+		ServiceAccount: "account.service_account",
+		UpdatedAt:      "account.updated_at",
+		Username:       "account.username",
+	},
+	AccountID:     "account_id",
+	ConfigManaged: "config_managed",
+	CreatedAt:     "created_at",
+	ID:            "id",
+	ManagedBy:     "managed_by",
+	Organization: roleBindingOrganizationFields{
+		CreatedAt:    "organization.created_at",
+		EbsAccountID: "organization.ebs_account_id",
+		ExternalID:   "organization.external_id",
+		ID:           "organization.id",
+		Name:         "organization.name",
+		UpdatedAt:    "organization.updated_at",
+	},
+	OrganizationID: "organization_id",
+	Role: roleBindingRoleFields{
+		ID:   "role.id",
+		Name: "role.name",
+	},
+	RoleID: "role_id",
+	Subscription: roleBindingSubscriptionFields{
+		BillingMarketplaceAccount: "subscription.billing_marketplace_account",
+		CPUTotal:                  "subscription.cpu_total",
+		CloudAccountID:            "subscription.cloud_account_id",
+		CloudProviderID:           "subscription.cloud_provider_id",
+		ClusterBillingModel:       "subscription.cluster_billing_model",
+		ClusterID:                 "subscription.cluster_id",
+		ConsoleURL:                "subscription.console_url",
+		ConsumerUUID:              "subscription.consumer_uuid",
+		CreatedAt:                 "subscription.created_at",
+		Creator: roleBindingSubscriptionCreatorFields{
+			BanCode:        "subscription.creator.ban_code",
+			BanDescription: "subscription.creator.ban_description",
+			Banned:         "subscription.creator.banned",
+			CreatedAt:      "subscription.creator.created_at",
+			Email:          "subscription.creator.email",
+			FirstName:      "subscription.creator.first_name",
+			ID:             "subscription.creator.id",
+			LastName:       "subscription.creator.last_name",
+			RHITAccountID:  "subscription.creator.rhit_account_id",
+			RHITWebUserID:  "subscription.creator.rhit_web_user_id",
+			ServiceAccount: "subscription.creator.service_account",
+			UpdatedAt:      "subscription.creator.updated_at",
+			Username:       "subscription.creator.username",
+		},
+		DisplayName:       "subscription.display_name",
+		ExternalClusterID: "subscription.external_cluster_id",
+		ID:                "subscription.id",
+		LastReconcileDate: "subscription.last_reconcile_date",
+		LastReleasedAt:    "subscription.last_released_at",
+		LastTelemetryDate: "subscription.last_telemetry_date",
+		Managed:           "subscription.managed",
+		OrganizationID:    "subscription.organization_id",
+		Plan: roleBindingSubscriptionPlanFields{
+			Category: "subscription.plan.category",
+			ID:       "subscription.plan.id",
+			Name:     "subscription.plan.name",
+			Type:     "subscription.plan.type",
+		},
+		ProductBundle: "subscription.product_bundle",
+		Provenance:    "subscription.provenance",
+		RegionID:      "subscription.region_id",
+		Released:      "subscription.released",
+		ServiceLevel:  "subscription.service_level",
+		SocketTotal:   "subscription.socket_total",
+		Status:        "subscription.status",
+		SupportLevel:  "subscription.support_level",
+		SystemUnits:   "subscription.system_units",
+		TrialEndDate:  "subscription.trial_end_date",
+		UpdatedAt:     "subscription.updated_at",
+		Usage:         "subscription.usage",
+	},
+	SubscriptionID: "subscription_id",
+	Type:           "type",
+	UpdatedAt:      "updated_at",
+}
+
+// SkuRule contains the names of the fields of the 'SkuRule' type that can be used
+// in search expressions.
+var SkuRule = skuRuleFields{
+	Allowed: "allowed",
+	ID:      "id",
+	QuotaID: "quota_id",
+	SKU:     "sku",
+}
+
+// Subscription contains the names of the fields of the 'Subscription' type that can be used
+// in search expressions.
+var Subscription = subscriptionFields{
+	BillingMarketplaceAccount: "billing_marketplace_account",
+	CPUTotal:                  "cpu_total",
+	CloudAccountID:            "cloud_account_id",
+	CloudProviderID:           "cloud_provider_id",
+	ClusterBillingModel:       "cluster_billing_model",
+	ClusterID:                 "cluster_id",
+	ConsoleURL:                "console_url",
+	ConsumerUUID:              "consumer_uuid",
+	CreatedAt:                 "created_at",
+	Creator: subscriptionCreatorFields{
+		BanCode:        "creator.ban_code",
+		BanDescription: "creator.ban_description",
+		Banned:         "creator.banned",
+		CreatedAt:      "creator.created_at",
+		Email:          "creator.email",
+		FirstName:      "creator.first_name",
+		ID:             "creator.id",
+		LastName:       "creator.last_name",
+		Organization: subscriptionCreatorOrganizationFields{
+			CreatedAt:    "creator.organization.created_at",
+			EbsAccountID: "creator.organization.ebs_account_id",
+			ExternalID:   "creator.organization.external_id",
+			ID:           "creator.organization.id",
+			Name:         "creator.organization.name",
+			UpdatedAt:    "creator.organization.updated_at",
+		},
+		RHITAccountID:  "creator.rhit_account_id",
+		RHITWebUserID:  "creator.rhit_web_user_id",
+		ServiceAccount: "creator.service_account",
+		UpdatedAt:      "creator.updated_at",
+		Username:       "creator.username",
+	},
+	DisplayName:       "display_name",
+	ExternalClusterID: "external_cluster_id",
+	ID:                "id",
+	LastReconcileDate: "last_reconcile_date",
+	LastReleasedAt:    "last_released_at",
+	LastTelemetryDate: "last_telemetry_date",
+	Managed:           "managed",
+	OrganizationID:    "organization_id",
+	Plan: subscriptionPlanFields{
+		Category: "plan.category",
+		ID:       "plan.id",
+		Name:     "plan.name",
+		Type:     "plan.type",
+	},
+	ProductBundle: "product_bundle",
+	Provenance:    "provenance",
+	RegionID:      "region_id",
+	Released:      "released",
+	ServiceLevel:  "service_level",
+	SocketTotal:   "socket_total",
+	Status:        "status",
+	SupportLevel:  "support_level",
+	SystemUnits:   "system_units",
+	TrialEndDate:  "trial_end_date",
+	UpdatedAt:     "updated_at",
+	Usage:         "usage",
+}
+
+// accountFields is the type of the Account variable.
+type accountFields struct {
+	BanCode        search.Field
+	BanDescription search.Field
+	Banned         search.Field
+	CreatedAt      search.Field
+	Email          search.Field
+	FirstName      search.Field
+	ID             search.Field
+	LastName       search.Field
+	Organization   accountOrganizationFields
+	RHITAccountID  search.Field
+	RHITWebUserID  search.Field
+	ServiceAccount search.Field
+	UpdatedAt      search.Field
+	Username       search.Field
+}
+
+// accountOrganizationFields contains the names of the fields of the 'organization' object.
+type accountOrganizationFields struct {
+	CreatedAt    search.Field
+	EbsAccountID search.Field
+	ExternalID   search.Field
+	ID           search.Field
+	Name         search.Field
+	UpdatedAt    search.Field
+}
+
+// capabilityFields is the type of the Capability variable.
+type capabilityFields struct {
+	Inherited search.Field
+	Name      search.Field
+	Value     search.Field
+}
+
+// cloudResourceFields is the type of the CloudResource variable.
+type cloudResourceFields struct {
+	Active         search.Field
+	CPUCores       search.Field
+	Category       search.Field
+	CategoryPretty search.Field
+	CloudProvider  search.Field
+	CreatedAt      search.Field
+	GenericName    search.Field
+	ID             search.Field
+	Memory         search.Field
+	MemoryPretty   search.Field
+	NamePretty     search.Field
+	ResourceType   search.Field
+	SizePretty     search.Field
+	UpdatedAt      search.Field
+}
+
+// defaultCapabilityFields is the type of the DefaultCapability variable.
+type defaultCapabilityFields struct {
+	Name  search.Field
+	Value search.Field
+}
+
+// deletedSubscriptionFields is the type of the DeletedSubscription variable.
+type deletedSubscriptionFields struct {
+	BillingExpirationDate     search.Field
+	BillingMarketplaceAccount search.Field
+	CPUTotal                  search.Field
+	CloudAccountID            search.Field
+	CloudProviderID           search.Field
+	ClusterBillingModel       search.Field
+	ClusterID                 search.Field
+	ConsoleURL                search.Field
+	ConsumerUUID              search.Field
+	CreatedAt                 search.Field
+	CreatorID                 search.Field
+	DisplayName               search.Field
+	ExternalClusterID         search.Field
+	ID                        search.Field
+	LastReconcileDate         search.Field
+	LastReleasedAt            search.Field
+	LastTelemetryDate         search.Field
+	Managed                   search.Field
+	Metrics                   search.Field
+	OrganizationID            search.Field
+	PlanID                    search.Field
+	ProductBundle             search.Field
+	Provenance                search.Field
+	QueryTimestamp            search.Field
+	RegionID                  search.Field
+	Released                  search.Field
+	ServiceLevel              search.Field
+	SocketTotal               search.Field
+	Status                    search.Field
+	SupportLevel              search.Field
+	SystemUnits               search.Field
+	TrialEndDate              search.Field
+	Usage                     search.Field
+}
+
+// labelFields is the type of the Label variable.
+type labelFields struct {
+	AccountID      search.Field
+	CreatedAt      search.Field
+	ID             search.Field
+	Internal       search.Field
+	Key            search.Field
+	ManagedBy      search.Field
+	OrganizationID search.Field
+	SubscriptionID search.Field
+	Type           search.Field
+	UpdatedAt      search.Field
+	Value          search.Field
+}
+
+// organizationFields is the type of the Organization variable.
+type organizationFields struct {
+	CreatedAt    search.Field
+	EbsAccountID search.Field
+	ExternalID   search.Field
+	ID           search.Field
+	Name         search.Field
+	UpdatedAt    search.Field
+}
+
+// quotaCostFields is the type of the QuotaCost variable.
+type quotaCostFields struct {
+	Allowed        search.Field
+	Consumed       search.Field
+	OrganizationID search.Field
+	QuotaID        search.Field
+	Version        search.Field
+}
+
+// registryCredentialFields is the type of the RegistryCredential variable.
+type registryCredentialFields struct {
+	Account            registryCredentialAccountFields
+	CreatedAt          search.Field
+	ExternalResourceID search.Field
+	ID                 search.Field
+	Registry           registryCredentialRegistryFields
+	Token              search.Field
+	UpdatedAt          search.Field
+	Username           search.Field
+}
+
+// registryCredentialAccountFields contains the names of the fields of the 'account' object.
+type registryCredentialAccountFields struct {
+	BanCode        search.Field
+	BanDescription search.Field
+	Banned         search.Field
+	CreatedAt      search.Field
+	Email          search.Field
+	FirstName      search.Field
+	ID             search.Field
+	LastName       search.Field
+	Organization   registryCredentialAccountOrganizationFields
+	RHITAccountID  search.Field
+	RHITWebUserID  search.Field
+	ServiceAccount search.Field
+	UpdatedAt      search.Field
+	Username       search.Field
+}
+
+// registryCredentialAccountOrganizationFields contains the names of the fields of the 'account.organization' object.
+type registryCredentialAccountOrganizationFields struct {
+	CreatedAt    search.Field
+	EbsAccountID search.Field
+	ExternalID   search.Field
+	ID           search.Field
+	Name         search.Field
+	UpdatedAt    search.Field
+}
+
+// registryCredentialRegistryFields contains the names of the fields of the 'registry' object.
+type registryCredentialRegistryFields struct {
+	CloudAlias search.Field
+	CreatedAt  search.Field
+	ID         search.Field
+	Name       search.Field
+	OrgName    search.Field
+	TeamName   search.Field
+	Type       search.Field
+	URL        search.Field
+	UpdatedAt  search.Field
+}
+
+// resourceQuotaFields is the type of the ResourceQuota variable.
+type resourceQuotaFields struct {
+	CreatedAt      search.Field
+	ID             search.Field
+	OrganizationID search.Field
+	SKU            search.Field
+	SKUCount       search.Field
+	Type           search.Field
+	UpdatedAt      search.Field
+}
+
+// roleFields is the type of the Role variable.
+type roleFields struct {
+	ID   search.Field
+	Name search.Field
+}
+
+// roleBindingFields is the type of the RoleBinding variable.
+type roleBindingFields struct {
+	Account        roleBindingAccountFields
+	AccountID      search.Field
+	ConfigManaged  search.Field
+	CreatedAt      search.Field
+	ID             search.Field
+	ManagedBy      search.Field
+	Organization   roleBindingOrganizationFields
+	OrganizationID search.Field
+	Role           roleBindingRoleFields
+	RoleID         search.Field
+	Subscription   roleBindingSubscriptionFields
+	SubscriptionID search.Field
+	Type           search.Field
+	UpdatedAt      search.Field
+}
+
+// roleBindingAccountFields contains the names of the fields of the 'account' object.
+type roleBindingAccountFields struct {
+	BanCode        search.Field
+	BanDescription search.Field
+	Banned         search.Field
+	CreatedAt      search.Field
+	Email          search.Field
+	FirstName      search.Field
+	ID             search.Field
+	LastName       search.Field
+	Organization   roleBindingAccountOrganizationFields
+	RHITAccountID  search.Field
+	RHITWebUserID  search.Field
+	ServiceAccount search.Field
+	UpdatedAt      search.Field
+	Username       search.Field
+}
+
+// roleBindingAccountOrganizationFields contains the names of the fields of the 'account.organization' object.
+type roleBindingAccountOrganizationFields struct {
+	CreatedAt    search.Field
+	EbsAccountID search.Field
+	ExternalID   search.Field
+	ID           search.Field
+	Name         search.Field
+	UpdatedAt    search.Field
+}
+
+// roleBindingOrganizationFields contains the names of the fields of the 'organization' object.
+type roleBindingOrganizationFields struct {
+	CreatedAt    search.Field
+	EbsAccountID search.Field
+	ExternalID   search.Field
+	ID           search.Field
+	Name         search.Field
+	UpdatedAt    search.Field
+}
+
+// roleBindingRoleFields contains the names of the fields of the 'role' object.
+type roleBindingRoleFields struct {
+	ID   search.Field
+	Name search.Field
+}
+
+// roleBindingSubscriptionFields contains the names of the fields of the 'subscription' object.
+type roleBindingSubscriptionFields struct {
+	BillingMarketplaceAccount search.Field
+	CPUTotal                  search.Field
+	CloudAccountID            search.Field
+	CloudProviderID           search.Field
+	ClusterBillingModel       search.Field
+	ClusterID                 search.Field
+	ConsoleURL                search.Field
+	ConsumerUUID              search.Field
+	CreatedAt                 search.Field
+	Creator                   roleBindingSubscriptionCreatorFields
+	DisplayName               search.Field
+	ExternalClusterID         search.Field
+	ID                        search.Field
+	LastReconcileDate         search.Field
+	LastReleasedAt            search.Field
+	LastTelemetryDate         search.Field
+	Managed                   search.Field
+	OrganizationID            search.Field
+	Plan                      roleBindingSubscriptionPlanFields
+	ProductBundle             search.Field
+	Provenance                search.Field
+	RegionID                  search.Field
+	Released                  search.Field
+	ServiceLevel              search.Field
+	SocketTotal               search.Field
+	Status                    search.Field
+	SupportLevel              search.Field
+	SystemUnits               search.Field
+	TrialEndDate              search.Field
+	UpdatedAt                 search.Field
+	Usage                     search.Field
+}
+
+// roleBindingSubscriptionCreatorFields contains the names of the fields of the 'subscription.creator' object.
+type roleBindingSubscriptionCreatorFields struct {
+	BanCode        search.Field
+	BanDescription search.Field
+	Banned         search.Field
+	CreatedAt      search.Field
+	Email          search.Field
+	FirstName      search.Field
+	ID             search.Field
+	LastName       search.Field
+	RHITAccountID  search.Field
+	RHITWebUserID  search.Field
+	ServiceAccount search.Field
+	UpdatedAt      search.Field
+	Username       search.Field
+}
+
+// roleBindingSubscriptionPlanFields contains the names of the fields of the 'subscription.plan' object.
+type roleBindingSubscriptionPlanFields struct {
+	Category search.Field
+	ID       search.Field
+	Name     search.Field
+	Type     search.Field
+}
+
+// skuRuleFields is the type of the SkuRule variable.
+type skuRuleFields struct {
+	Allowed search.Field
+	ID      search.Field
+	QuotaID search.Field
+	SKU     search.Field
+}
+
+// subscriptionFields is the type of the Subscription variable.
+type subscriptionFields struct {
+	BillingMarketplaceAccount search.Field
+	CPUTotal                  search.Field
+	CloudAccountID            search.Field
+	CloudProviderID           search.Field
+	ClusterBillingModel       search.Field
+	ClusterID                 search.Field
+	ConsoleURL                search.Field
+	ConsumerUUID              search.Field
+	CreatedAt                 search.Field
+	Creator                   subscriptionCreatorFields
+	DisplayName               search.Field
+	ExternalClusterID         search.Field
+	ID                        search.Field
+	LastReconcileDate         search.Field
+	LastReleasedAt            search.Field
+	LastTelemetryDate         search.Field
+	Managed                   search.Field
+	OrganizationID            search.Field
+	Plan                      subscriptionPlanFields
+	ProductBundle             search.Field
+	Provenance                search.Field
+	RegionID                  search.Field
+	Released                  search.Field
+	ServiceLevel              search.Field
+	SocketTotal               search.Field
+	Status                    search.Field
+	SupportLevel              search.Field
+	SystemUnits               search.Field
+	TrialEndDate              search.Field
+	UpdatedAt                 search.Field
+	Usage                     search.Field
+}
+
+// subscriptionCreatorFields contains the names of the fields of the 'creator' object.
+type subscriptionCreatorFields struct {
+	BanCode        search.Field
+	BanDescription search.Field
+	Banned         search.Field
+	CreatedAt      search.Field
+	Email          search.Field
+	FirstName      search.Field
+	ID             search.Field
+	LastName       search.Field
+	Organization   subscriptionCreatorOrganizationFields
+	RHITAccountID  search.Field
+	RHITWebUserID  search.Field
+	ServiceAccount search.Field
+	UpdatedAt      search.Field
+	Username       search.Field
+}
+
+// subscriptionCreatorOrganizationFields contains the names of the fields of the 'creator.organization' object.
+type subscriptionCreatorOrganizationFields struct {
+	CreatedAt    search.Field
+	EbsAccountID search.Field
+	ExternalID   search.Field
+	ID           search.Field
+	Name         search.Field
+	UpdatedAt    search.Field
+}
+
+// subscriptionPlanFields contains the names of the fields of the 'plan' object.
+type subscriptionPlanFields struct {
+	Category search.Field
+	ID       search.Field
+	Name     search.Field
+	Type     search.Field
+}
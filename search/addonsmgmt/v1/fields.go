@@ -0,0 +1,147 @@
+/*
+Copyright (c) 2020 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// IMPORTANT: This file has been generated automatically, refrain from modifying it manually as all
+// your changes will be lost when the file is generated again.
+
+package v1 // github.com/openshift-online/ocm-sdk-go/search/addonsmgmt/v1
+
+import (
+	"github.com/openshift-online/ocm-sdk-go/search"
+)
+
+// Addon contains the names of the fields of the 'Addon' type that can be used
+// in search expressions.
+var Addon = addonFields{
+	Description:          "description",
+	DocsLink:             "docs_link",
+	Enabled:              "enabled",
+	HasExternalResources: "has_external_resources",
+	Hidden:               "hidden",
+	ID:                   "id",
+	Icon:                 "icon",
+	InstallMode:          "install_mode",
+	Label:                "label",
+	ManagedService:       "managed_service",
+	Name:                 "name",
+	OperatorName:         "operator_name",
+	ResourceCost:         "resource_cost",
+	ResourceName:         "resource_name",
+	TargetNamespace:      "target_namespace",
+	Version: addonVersion2Fields{
+		Channel: "version.channel",
+		Enabled: "version.enabled",
+		ID:      "version.id",
+		MetricsFederation: addonVersionMetricsFederationFields{
+			Namespace: "version.metrics_federation.namespace",
+			PortName:  "version.metrics_federation.port_name",
+		},
+		MonitoringStack: addonVersionMonitoringStackFields{
+			Enabled: "version.monitoring_stack.enabled",
+		},
+		PackageImage:        "version.package_image",
+		PullSecretName:      "version.pull_secret_name",
+		SourceImage:         "version.source_image",
+		UpgradePlansCreated: "version.upgrade_plans_created",
+	},
+}
+
+// AddonVersion contains the names of the fields of the 'AddonVersion' type that can be used
+// in search expressions.
+var AddonVersion = addonVersionFields{
+	Channel: "channel",
+	Enabled: "enabled",
+	ID:      "id",
+	MetricsFederation: addonVersionMetricsFederation2Fields{
+		Namespace: "metrics_federation.namespace",
+		PortName:  "metrics_federation.port_name",
+	},
+	MonitoringStack: addonVersionMonitoringStack2Fields{
+		Enabled: "monitoring_stack.enabled",
+	},
+	PackageImage:        "package_image",
+	PullSecretName:      "pull_secret_name",
+	SourceImage:         "source_image",
+	UpgradePlansCreated: "upgrade_plans_created",
+}
+
+// addonFields is the type of the Addon variable.
+type addonFields struct {
+	Description          search.Field
+	DocsLink             search.Field
+	Enabled              search.Field
+	HasExternalResources search.Field
+	Hidden               search.Field
+	ID                   search.Field
+	Icon                 search.Field
+	InstallMode          search.Field
+	Label                search.Field
+	ManagedService       search.Field
+	Name                 search.Field
+	OperatorName         search.Field
+	ResourceCost         search.Field
+	ResourceName         search.Field
+	TargetNamespace      search.Field
+	Version              addonVersion2Fields
+}
+
+// addonVersion2Fields contains the names of the fields of the 'version' object.
+type addonVersion2Fields struct {
+	Channel             search.Field
+	Enabled             search.Field
+	ID                  search.Field
+	MetricsFederation   addonVersionMetricsFederationFields
+	MonitoringStack     addonVersionMonitoringStackFields
+	PackageImage        search.Field
+	PullSecretName      search.Field
+	SourceImage         search.Field
+	UpgradePlansCreated search.Field
+}
+
+// addonVersionMetricsFederationFields contains the names of the fields of the 'version.metrics_federation' object.
+type addonVersionMetricsFederationFields struct {
+	Namespace search.Field
+	PortName  search.Field
+}
+
+// addonVersionMonitoringStackFields contains the names of the fields of the 'version.monitoring_stack' object.
+type addonVersionMonitoringStackFields struct {
+	Enabled search.Field
+}
+
+// addonVersionFields is the type of the AddonVersion variable.
+type addonVersionFields struct {
+	Channel             search.Field
+	Enabled             search.Field
+	ID                  search.Field
+	MetricsFederation   addonVersionMetricsFederation2Fields
+	MonitoringStack     addonVersionMonitoringStack2Fields
+	PackageImage        search.Field
+	PullSecretName      search.Field
+	SourceImage         search.Field
+	UpgradePlansCreated search.Field
+}
+
+// addonVersionMetricsFederation2Fields contains the names of the fields of the 'metrics_federation' object.
+type addonVersionMetricsFederation2Fields struct {
+	Namespace search.Field
+	PortName  search.Field
+}
+
+// addonVersionMonitoringStack2Fields contains the names of the fields of the 'monitoring_stack' object.
+type addonVersionMonitoringStack2Fields struct {
+	Enabled search.Field
+}
@@ -0,0 +1,2413 @@
+/*
+Copyright (c) 2020 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// IMPORTANT: This file has been generated automatically, refrain from modifying it manually as all
+// your changes will be lost when the file is generated again.
+
+package v1 // github.com/openshift-online/ocm-sdk-go/search/clustersmgmt/v1
+
+import (
+	"github.com/openshift-online/ocm-sdk-go/search"
+)
+
+// AWSInfrastructureAccessRole contains the names of the fields of the 'AWSInfrastructureAccessRole' type that can be used
+// in search expressions.
+var AWSInfrastructureAccessRole = awsInfrastructureAccessRoleFields{
+	Description: "description",
+	DisplayName: "display_name",
+	ID:          "id",
+	State:       "state",
+}
+
+// AWSInfrastructureAccessRoleGrant contains the names of the fields of the 'AWSInfrastructureAccessRoleGrant' type that can be used
+// in search expressions.
+var AWSInfrastructureAccessRoleGrant = awsInfrastructureAccessRoleGrantFields{
+	ConsoleURL: "console_url",
+	ID:         "id",
+	Role: awsInfrastructureAccessRoleGrantRoleFields{
+		Description: "role.description",
+		DisplayName: "role.display_name",
+		ID:          "role.id",
+		State:       "role.state",
+	},
+	State:            "state",
+	StateDescription: "state_description",
+	UserARN:          "user_arn",
+}
+
+// AWSSTSPolicy contains the names of the fields of the 'AWSSTSPolicy' type that can be used
+// in search expressions.
+var AWSSTSPolicy = awsstsPolicyFields{
+	ARN:     "arn",
+	Details: "details",
+	ID:      "id",
+	Type:    "type",
+}
+
+// AddOn contains the names of the fields of the 'AddOn' type that can be used
+// in search expressions.
+var AddOn = addOnFields{
+	Config: addOnConfigFields{
+		ID: "config.id",
+	},
+	Description:          "description",
+	DocsLink:             "docs_link",
+	Enabled:              "enabled",
+	HasExternalResources: "has_external_resources",
+	Hidden:               "hidden",
+	ID:                   "id",
+	Icon:                 "icon",
+	InstallMode:          "install_mode",
+	Label:                "label",
+	ManagedService:       "managed_service",
+	Name:                 "name",
+	OperatorName:         "operator_name",
+	ResourceCost:         "resource_cost",
+	ResourceName:         "resource_name",
+	TargetNamespace:      "target_namespace",
+	Version: addOnVersion2Fields{
+		Channel: "version.channel",
+		Config: addOnVersionConfigFields{
+			ID: "version.config.id",
+		},
+		Enabled:        "version.enabled",
+		ID:             "version.id",
+		PackageImage:   "version.package_image",
+		PullSecretName: "version.pull_secret_name",
+		SourceImage:    "version.source_image",
+	},
+}
+
+// AddOnInstallation contains the names of the fields of the 'AddOnInstallation' type that can be used
+// in search expressions.
+var AddOnInstallation = addOnInstallationFields{
+	Addon: addOnInstallationAddonFields{
+		Config: addOnInstallationAddonConfigFields{
+			ID: "addon.config.id",
+		},
+		Description:          "addon.description",
+		DocsLink:             "addon.docs_link",
+		Enabled:              "addon.enabled",
+		HasExternalResources: "addon.has_external_resources",
+		Hidden:               "addon.hidden",
+		ID:                   "addon.id",
+		Icon:                 "addon.icon",
+		InstallMode:          "addon.install_mode",
+		Label:                "addon.label",
+		ManagedService:       "addon.managed_service",
+		Name:                 "addon.name",
+		OperatorName:         "addon.operator_name",
+		ResourceCost:         "addon.resource_cost",
+		ResourceName:         "addon.resource_name",
+		TargetNamespace:      "addon.target_namespace",
+		Version: addOnInstallationAddonVersionFields{
+			Channel:        "addon.version.channel",
+			Enabled:        "addon.version.enabled",
+			ID:             "addon.version.id",
+			PackageImage:   "addon.version.package_image",
+			PullSecretName: "addon.version.pull_secret_name",
+			SourceImage:    "addon.version.source_image",
+		},
+	},
+	AddonVersion: addOnInstallationAddonVersion2Fields{
+		Channel: "addon_version.channel",
+		Config: addOnInstallationAddonVersionConfigFields{
+			ID: "addon_version.config.id",
+		},
+		Enabled:        "addon_version.enabled",
+		ID:             "addon_version.id",
+		PackageImage:   "addon_version.package_image",
+		PullSecretName: "addon_version.pull_secret_name",
+		SourceImage:    "addon_version.source_image",
+	},
+	Billing: addOnInstallationBillingFields{
+		BillingMarketplaceAccount: "billing.billing_marketplace_account",
+		BillingModel:              "billing.billing_model",
+		ID:                        "billing.id",
+	},
+	CreationTimestamp: "creation_timestamp",
+	ID:                "id",
+	OperatorVersion:   "operator_version",
+	State:             "state",
+	StateDescription:  "state_description",
+	UpdatedTimestamp:  "updated_timestamp",
+}
+
+// AddOnVersion contains the names of the fields of the 'AddOnVersion' type that can be used
+// in search expressions.
+var AddOnVersion = addOnVersionFields{
+	Channel: "channel",
+	Config: addOnVersionConfig2Fields{
+		ID: "config.id",
+	},
+	Enabled:        "enabled",
+	ID:             "id",
+	PackageImage:   "package_image",
+	PullSecretName: "pull_secret_name",
+	SourceImage:    "source_image",
+}
+
+// BreakGlassCredential contains the names of the fields of the 'BreakGlassCredential' type that can be used
+// in search expressions.
+var BreakGlassCredential = breakGlassCredentialFields{
+	ExpirationTimestamp: "expiration_timestamp",
+	ID:                  "id",
+	Kubeconfig:          "kubeconfig",
+	RevocationTimestamp: "revocation_timestamp",
+	Status:              "status",
+	Username:            "username",
+}
+
+// CloudProvider contains the names of the fields of the 'CloudProvider' type that can be used
+// in search expressions.
+var CloudProvider = cloudProviderFields{
+	DisplayName: "display_name",
+	ID:          "id",
+	Name:        "name",
+}
+
+// Cluster contains the names of the fields of the 'Cluster' type that can be used
+// in search expressions.
+var Cluster = clusterFields{
+	API: clusterAPIFields{
+		Listening: "api.listening",
+		URL:       "api.url",
+	},
+	AWS: clusterAWSFields{
+		AccessKeyID: "aws.access_key_id",
+		AccountID:   "aws.account_id",
+		AuditLog: clusterAWSAuditLogFields{
+			RoleARN: "aws.audit_log.role_arn",
+		},
+		BillingAccountID:      "aws.billing_account_id",
+		Ec2MetadataHTTPTokens: "aws.ec2_metadata_http_tokens",
+		EtcdEncryption: clusterAWSEtcdEncryptionFields{
+			KMSKeyARN: "aws.etcd_encryption.kms_key_arn",
+		},
+		KMSKeyARN:                "aws.kms_key_arn",
+		PrivateHostedZoneID:      "aws.private_hosted_zone_id",
+		PrivateHostedZoneRoleARN: "aws.private_hosted_zone_role_arn",
+		PrivateLink:              "aws.private_link",
+		STS: clusterAWSSTSFields{
+			AutoMode:           "aws.sts.auto_mode",
+			Enabled:            "aws.sts.enabled",
+			ExternalID:         "aws.sts.external_id",
+			ManagedPolicies:    "aws.sts.managed_policies",
+			OIDCEndpointURL:    "aws.sts.oidc_endpoint_url",
+			OperatorRolePrefix: "aws.sts.operator_role_prefix",
+			PermissionBoundary: "aws.sts.permission_boundary",
+			RoleARN:            "aws.sts.role_arn",
+			SupportRoleARN:     "aws.sts.support_role_arn",
+		},
+		SecretAccessKey: "aws.secret_access_key",
+	},
+	AdditionalTrustBundle: "additional_trust_bundle",
+	Autoscaler: clusterAutoscalerFields{
+		BalanceSimilarNodeGroups:    "autoscaler.balance_similar_node_groups",
+		ID:                          "autoscaler.id",
+		IgnoreDaemonsetsUtilization: "autoscaler.ignore_daemonsets_utilization",
+		LogVerbosity:                "autoscaler.log_verbosity",
+		MaxNodeProvisionTime:        "autoscaler.max_node_provision_time",
+		MaxPodGracePeriod:           "autoscaler.max_pod_grace_period",
+		PodPriorityThreshold:        "autoscaler.pod_priority_threshold",
+		ResourceLimits: clusterAutoscalerResourceLimitsFields{
+			MaxNodesTotal: "autoscaler.resource_limits.max_nodes_total",
+		},
+		ScaleDown: clusterAutoscalerScaleDownFields{
+			DelayAfterAdd:        "autoscaler.scale_down.delay_after_add",
+			DelayAfterDelete:     "autoscaler.scale_down.delay_after_delete",
+			DelayAfterFailure:    "autoscaler.scale_down.delay_after_failure",
+			Enabled:              "autoscaler.scale_down.enabled",
+			UnneededTime:         "autoscaler.scale_down.unneeded_time",
+			UtilizationThreshold: "autoscaler.scale_down.utilization_threshold",
+		},
+		SkipNodesWithLocalStorage: "autoscaler.skip_nodes_with_local_storage",
+	},
+	Azure: clusterAzureFields{
+		ManagedResourceGroupName:       "azure.managed_resource_group_name",
+		NetworkSecurityGroupResourceID: "azure.network_security_group_resource_id",
+		ResourceGroupName:              "azure.resource_group_name",
+		ResourceName:                   "azure.resource_name",
+		SubnetResourceID:               "azure.subnet_resource_id",
+		SubscriptionID:                 "azure.subscription_id",
+		TenantID:                       "azure.tenant_id",
+	},
+	BillingModel: "billing_model",
+	ByoOIDC: clusterByoOIDCFields{
+		Enabled: "byo_oidc.enabled",
+	},
+	CCS: clusterCCSFields{
+		DisableScpChecks: "ccs.disable_scp_checks",
+		Enabled:          "ccs.enabled",
+		ID:               "ccs.id",
+	},
+	CloudProvider: clusterCloudProviderFields{
+		DisplayName: "cloud_provider.display_name",
+		ID:          "cloud_provider.id",
+		Name:        "cloud_provider.name",
+	},
+	Console: clusterConsoleFields{
+		URL: "console.url",
+	},
+	CreationTimestamp: "creation_timestamp",
+	DNS: clusterDNSFields{
+		BaseDomain: "dns.base_domain",
+	},
+	DeleteProtection: clusterDeleteProtectionFields{
+		Enabled: "delete_protection.enabled",
+	},
+	DisableUserWorkloadMonitoring: "disable_user_workload_monitoring",
+	DomainPrefix:                  "domain_prefix",
+	EtcdEncryption:                "etcd_encryption",
+	ExpirationTimestamp:           "expiration_timestamp",
+	ExternalAuthConfig: clusterExternalAuthConfigFields{
+		Enabled: "external_auth_config.enabled",
+	},
+	ExternalID: "external_id",
+	FIPS:       "fips",
+	Flavour: clusterFlavourFields{
+		AWS: clusterFlavourAWSFields{
+			ComputeInstanceType: "flavour.aws.compute_instance_type",
+			InfraInstanceType:   "flavour.aws.infra_instance_type",
+			MasterInstanceType:  "flavour.aws.master_instance_type",
+		},
+		GCP: clusterFlavourGCPFields{
+			ComputeInstanceType: "flavour.gcp.compute_instance_type",
+			InfraInstanceType:   "flavour.gcp.infra_instance_type",
+			MasterInstanceType:  "flavour.gcp.master_instance_type",
+		},
+		ID:   "flavour.id",
+		Name: "flavour.name",
+		Network: clusterFlavourNetworkFields{
+			HostPrefix:  "flavour.network.host_prefix",
+			MachineCIDR: "flavour.network.machine_cidr",
+			PodCIDR:     "flavour.network.pod_cidr",
+			ServiceCIDR: "flavour.network.service_cidr",
+			Type:        "flavour.network.type",
+		},
+		Nodes: clusterFlavourNodesFields{
+			Master: "flavour.nodes.master",
+		},
+	},
+	GCP: clusterGCPFields{
+		AuthProviderX509CertURL: "gcp.auth_provider_x509_cert_url",
+		AuthUri:                 "gcp.auth_uri",
+		Authentication: clusterGCPAuthenticationFields{
+			ID: "gcp.authentication.id",
+		},
+		ClientEmail:       "gcp.client_email",
+		ClientID:          "gcp.client_id",
+		ClientX509CertURL: "gcp.client_x509_cert_url",
+		PrivateKey:        "gcp.private_key",
+		PrivateKeyID:      "gcp.private_key_id",
+		ProjectID:         "gcp.project_id",
+		Security: clusterGCPSecurityFields{
+			SecureBoot: "gcp.security.secure_boot",
+		},
+		TokenUri: "gcp.token_uri",
+		Type:     "gcp.type",
+	},
+	GCPEncryptionKey: clusterGCPEncryptionKeyFields{
+		KMSKeyServiceAccount: "gcp_encryption_key.kms_key_service_account",
+		KeyLocation:          "gcp_encryption_key.key_location",
+		KeyName:              "gcp_encryption_key.key_name",
+		KeyRing:              "gcp_encryption_key.key_ring",
+	},
+	GCPNetwork: clusterGCPNetworkFields{
+		ComputeSubnet:      "gcp_network.compute_subnet",
+		ControlPlaneSubnet: "gcp_network.control_plane_subnet",
+		VPCName:            "gcp_network.vpc_name",
+		VPCProjectID:       "gcp_network.vpc_project_id",
+	},
+	HealthState: "health_state",
+	Htpasswd: clusterHtpasswdFields{
+		Password: "htpasswd.password",
+		Username: "htpasswd.username",
+	},
+	Hypershift: clusterHypershiftFields{
+		Enabled: "hypershift.enabled",
+	},
+	ID:      "id",
+	InfraID: "infra_id",
+	KubeletConfig: clusterKubeletConfigFields{
+		ID:           "kubelet_config.id",
+		Name:         "kubelet_config.name",
+		PodPidsLimit: "kubelet_config.pod_pids_limit",
+	},
+	LoadBalancerQuota: "load_balancer_quota",
+	Managed:           "managed",
+	ManagedService: clusterManagedServiceFields{
+		Enabled: "managed_service.enabled",
+	},
+	MultiAZ:          "multi_az",
+	MultiArchEnabled: "multi_arch_enabled",
+	Name:             "name",
+	Network: clusterNetworkFields{
+		HostPrefix:  "network.host_prefix",
+		MachineCIDR: "network.machine_cidr",
+		PodCIDR:     "network.pod_cidr",
+		ServiceCIDR: "network.service_cidr",
+		Type:        "network.type",
+	},
+	NodeDrainGracePeriod: clusterNodeDrainGracePeriodFields{
+		Unit:  "node_drain_grace_period.unit",
+		Value: "node_drain_grace_period.value",
+	},
+	Nodes: clusterNodesFields{
+		AutoscaleCompute: clusterNodesAutoscaleComputeFields{
+			ID:          "nodes.autoscale_compute.id",
+			MaxReplicas: "nodes.autoscale_compute.max_replicas",
+			MinReplicas: "nodes.autoscale_compute.min_replicas",
+		},
+		Compute: "nodes.compute",
+		ComputeMachineType: clusterNodesComputeMachineTypeFields{
+			Architecture: "nodes.compute_machine_type.architecture",
+			CCSOnly:      "nodes.compute_machine_type.ccs_only",
+			Category:     "nodes.compute_machine_type.category",
+			GenericName:  "nodes.compute_machine_type.generic_name",
+			ID:           "nodes.compute_machine_type.id",
+			Name:         "nodes.compute_machine_type.name",
+			Size:         "nodes.compute_machine_type.size",
+		},
+		Infra: "nodes.infra",
+		InfraMachineType: clusterNodesInfraMachineTypeFields{
+			Architecture: "nodes.infra_machine_type.architecture",
+			CCSOnly:      "nodes.infra_machine_type.ccs_only",
+			Category:     "nodes.infra_machine_type.category",
+			GenericName:  "nodes.infra_machine_type.generic_name",
+			ID:           "nodes.infra_machine_type.id",
+			Name:         "nodes.infra_machine_type.name",
+			Size:         "nodes.infra_machine_type.size",
+		},
+		Master: "nodes.master",
+		MasterMachineType: clusterNodesMasterMachineTypeFields{
+			Architecture: "nodes.master_machine_type.architecture",
+			CCSOnly:      "nodes.master_machine_type.ccs_only",
+			Category:     "nodes.master_machine_type.category",
+			GenericName:  "nodes.master_machine_type.generic_name",
+			ID:           "nodes.master_machine_type.id",
+			Name:         "nodes.master_machine_type.name",
+			Size:         "nodes.master_machine_type.size",
+		},
+		Total: "nodes.total",
+	},
+	OpenshiftVersion: "openshift_version",
+	Product: clusterProductFields{
+		ID:   "product.id",
+		Name: "product.name",
+	},
+	ProvisionShard: clusterProvisionShardFields{
+		AWSAccountOperatorConfig: clusterProvisionShardAWSAccountOperatorConfigFields{
+			ID:         "provision_shard.aws_account_operator_config.id",
+			Kubeconfig: "provision_shard.aws_account_operator_config.kubeconfig",
+			Server:     "provision_shard.aws_account_operator_config.server",
+			Topology:   "provision_shard.aws_account_operator_config.topology",
+		},
+		AWSBaseDomain: "provision_shard.aws_base_domain",
+		CloudProvider: clusterProvisionShardCloudProviderFields{
+			DisplayName: "provision_shard.cloud_provider.display_name",
+			ID:          "provision_shard.cloud_provider.id",
+			Name:        "provision_shard.cloud_provider.name",
+		},
+		CreationTimestamp: "provision_shard.creation_timestamp",
+		GCPBaseDomain:     "provision_shard.gcp_base_domain",
+		GCPProjectOperator: clusterProvisionShardGCPProjectOperatorFields{
+			ID:         "provision_shard.gcp_project_operator.id",
+			Kubeconfig: "provision_shard.gcp_project_operator.kubeconfig",
+			Server:     "provision_shard.gcp_project_operator.server",
+			Topology:   "provision_shard.gcp_project_operator.topology",
+		},
+		HiveConfig: clusterProvisionShardHiveConfigFields{
+			ID:         "provision_shard.hive_config.id",
+			Kubeconfig: "provision_shard.hive_config.kubeconfig",
+			Server:     "provision_shard.hive_config.server",
+			Topology:   "provision_shard.hive_config.topology",
+		},
+		HypershiftConfig: clusterProvisionShardHypershiftConfigFields{
+			ID:         "provision_shard.hypershift_config.id",
+			Kubeconfig: "provision_shard.hypershift_config.kubeconfig",
+			Server:     "provision_shard.hypershift_config.server",
+			Topology:   "provision_shard.hypershift_config.topology",
+		},
+		ID:                  "provision_shard.id",
+		LastUpdateTimestamp: "provision_shard.last_update_timestamp",
+		ManagementCluster:   "provision_shard.management_cluster",
+		Region: clusterProvisionShardRegionFields{
+			CCSOnly:            "provision_shard.region.ccs_only",
+			DisplayName:        "provision_shard.region.display_name",
+			Enabled:            "provision_shard.region.enabled",
+			Govcloud:           "provision_shard.region.govcloud",
+			ID:                 "provision_shard.region.id",
+			KMSLocationID:      "provision_shard.region.kms_location_id",
+			KMSLocationName:    "provision_shard.region.kms_location_name",
+			Name:               "provision_shard.region.name",
+			SupportsHypershift: "provision_shard.region.supports_hypershift",
+			SupportsMultiAZ:    "provision_shard.region.supports_multi_az",
+		},
+		Status: "provision_shard.status",
+	},
+	Proxy: clusterProxyFields{
+		HTTPProxy:  "proxy.http_proxy",
+		HTTPSProxy: "proxy.https_proxy",
+		NoProxy:    "proxy.no_proxy",
+	},
+	Region: clusterRegionFields{
+		CCSOnly: "region.ccs_only",
+		CloudProvider: clusterRegionCloudProviderFields{
+			DisplayName: "region.cloud_provider.display_name",
+			ID:          "region.cloud_provider.id",
+			Name:        "region.cloud_provider.name",
+		},
+		DisplayName:        "region.display_name",
+		Enabled:            "region.enabled",
+		Govcloud:           "region.govcloud",
+		ID:                 "region.id",
+		KMSLocationID:      "region.kms_location_id",
+		KMSLocationName:    "region.kms_location_name",
+		Name:               "region.name",
+		SupportsHypershift: "region.supports_hypershift",
+		SupportsMultiAZ:    "region.supports_multi_az",
+	},
+	State: "state",
+	Status: clusterStatusFields{
+		ConfigurationMode:         "status.configuration_mode",
+		CurrentCompute:            "status.current_compute",
+		DNSReady:                  "status.dns_ready",
+		Description:               "status.description",
+		ID:                        "status.id",
+		LimitedSupportReasonCount: "status.limited_support_reason_count",
+		OIDCReady:                 "status.oidc_ready",
+		ProvisionErrorCode:        "status.provision_error_code",
+		ProvisionErrorMessage:     "status.provision_error_message",
+		State:                     "status.state",
+	},
+	StorageQuota: clusterStorageQuotaFields{
+		Unit:  "storage_quota.unit",
+		Value: "storage_quota.value",
+	},
+	Subscription: clusterSubscriptionFields{
+		ID: "subscription.id",
+	},
+	Version: clusterVersionFields{
+		ChannelGroup:              "version.channel_group",
+		Default:                   "version.default",
+		Enabled:                   "version.enabled",
+		EndOfLifeTimestamp:        "version.end_of_life_timestamp",
+		GCPMarketplaceEnabled:     "version.gcp_marketplace_enabled",
+		HostedControlPlaneDefault: "version.hosted_control_plane_default",
+		HostedControlPlaneEnabled: "version.hosted_control_plane_enabled",
+		ID:                        "version.id",
+		ImageOverrides: clusterVersionImageOverridesFields{
+			ID: "version.image_overrides.id",
+		},
+		RawID:        "version.raw_id",
+		ReleaseImage: "version.release_image",
+		RosaEnabled:  "version.rosa_enabled",
+	},
+}
+
+// DNSDomain contains the names of the fields of the 'DNSDomain' type that can be used
+// in search expressions.
+var DNSDomain = dnsDomainFields{
+	Cluster: dnsDomainClusterFields{
+		ID: "cluster.id",
+	},
+	ID: "id",
+	Organization: dnsDomainOrganizationFields{
+		ID: "organization.id",
+	},
+	ReservedAtTimestamp: "reserved_at_timestamp",
+	UserDefined:         "user_defined",
+}
+
+// Flavour contains the names of the fields of the 'Flavour' type that can be used
+// in search expressions.
+var Flavour = flavourFields{
+	AWS: flavourAWSFields{
+		ComputeInstanceType: "aws.compute_instance_type",
+		InfraInstanceType:   "aws.infra_instance_type",
+		InfraVolume: flavourAWSInfraVolumeFields{
+			Iops: "aws.infra_volume.iops",
+			Size: "aws.infra_volume.size",
+		},
+		MasterInstanceType: "aws.master_instance_type",
+		MasterVolume: flavourAWSMasterVolumeFields{
+			Iops: "aws.master_volume.iops",
+			Size: "aws.master_volume.size",
+		},
+		WorkerVolume: flavourAWSWorkerVolumeFields{
+			Iops: "aws.worker_volume.iops",
+			Size: "aws.worker_volume.size",
+		},
+	},
+	GCP: flavourGCPFields{
+		ComputeInstanceType: "gcp.compute_instance_type",
+		InfraInstanceType:   "gcp.infra_instance_type",
+		InfraVolume: flavourGCPInfraVolumeFields{
+			Size: "gcp.infra_volume.size",
+		},
+		MasterInstanceType: "gcp.master_instance_type",
+		MasterVolume: flavourGCPMasterVolumeFields{
+			Size: "gcp.master_volume.size",
+		},
+		WorkerVolume: flavourGCPWorkerVolumeFields{
+			Size: "gcp.worker_volume.size",
+		},
+	},
+	ID:   "id",
+	Name: "name",
+	Network: flavourNetworkFields{
+		HostPrefix:  "network.host_prefix",
+		MachineCIDR: "network.machine_cidr",
+		PodCIDR:     "network.pod_cidr",
+		ServiceCIDR: "network.service_cidr",
+		Type:        "network.type",
+	},
+	Nodes: flavourNodesFields{
+		Master: "nodes.master",
+	},
+}
+
+// MachineType contains the names of the fields of the 'MachineType' type that can be used
+// in search expressions.
+var MachineType = machineTypeFields{
+	Architecture: "architecture",
+	CCSOnly:      "ccs_only",
+	CPU: machineTypeCPUFields{
+		Unit:  "cpu.unit",
+		Value: "cpu.value",
+	},
+	Category: "category",
+	CloudProvider: machineTypeCloudProviderFields{
+		DisplayName: "cloud_provider.display_name",
+		ID:          "cloud_provider.id",
+		Name:        "cloud_provider.name",
+	},
+	GenericName: "generic_name",
+	ID:          "id",
+	Memory: machineTypeMemoryFields{
+		Unit:  "memory.unit",
+		Value: "memory.value",
+	},
+	Name: "name",
+	Size: "size",
+}
+
+// NodePool contains the names of the fields of the 'NodePool' type that can be used
+// in search expressions.
+var NodePool = nodePoolFields{
+	AWSNodePool: nodePoolAWSNodePoolFields{
+		Ec2MetadataHTTPTokens: "aws_node_pool.ec2_metadata_http_tokens",
+		ID:                    "aws_node_pool.id",
+		InstanceProfile:       "aws_node_pool.instance_profile",
+		InstanceType:          "aws_node_pool.instance_type",
+		RootVolume: nodePoolAWSNodePoolRootVolumeFields{
+			Iops: "aws_node_pool.root_volume.iops",
+			Size: "aws_node_pool.root_volume.size",
+		},
+	},
+	AutoRepair: "auto_repair",
+	Autoscaling: nodePoolAutoscalingFields{
+		ID:         "autoscaling.id",
+		MaxReplica: "autoscaling.max_replica",
+		MinReplica: "autoscaling.min_replica",
+	},
+	AvailabilityZone: "availability_zone",
+	AzureNodePool: nodePoolAzureNodePoolFields{
+		EphemeralOsDiskEnabled:   "azure_node_pool.ephemeral_os_disk_enabled",
+		OsDiskSizeGibibytes:      "azure_node_pool.os_disk_size_gibibytes",
+		OsDiskStorageAccountType: "azure_node_pool.os_disk_storage_account_type",
+		ResourceName:             "azure_node_pool.resource_name",
+		VmSize:                   "azure_node_pool.vm_size",
+	},
+	ID: "id",
+	ManagementUpgrade: nodePoolManagementUpgradeFields{
+		ID:             "management_upgrade.id",
+		MaxSurge:       "management_upgrade.max_surge",
+		MaxUnavailable: "management_upgrade.max_unavailable",
+		Type:           "management_upgrade.type",
+	},
+	NodeDrainGracePeriod: nodePoolNodeDrainGracePeriodFields{
+		Unit:  "node_drain_grace_period.unit",
+		Value: "node_drain_grace_period.value",
+	},
+	Replicas: "replicas",
+	Status: nodePoolStatusFields{
+		CurrentReplicas: "status.current_replicas",
+		ID:              "status.id",
+		Message:         "status.message",
+	},
+	Subnet: "subnet",
+	Version: nodePoolVersionFields{
+		ChannelGroup:              "version.channel_group",
+		Default:                   "version.default",
+		Enabled:                   "version.enabled",
+		EndOfLifeTimestamp:        "version.end_of_life_timestamp",
+		GCPMarketplaceEnabled:     "version.gcp_marketplace_enabled",
+		HostedControlPlaneDefault: "version.hosted_control_plane_default",
+		HostedControlPlaneEnabled: "version.hosted_control_plane_enabled",
+		ID:                        "version.id",
+		ImageOverrides: nodePoolVersionImageOverridesFields{
+			ID: "version.image_overrides.id",
+		},
+		RawID:        "version.raw_id",
+		ReleaseImage: "version.release_image",
+		RosaEnabled:  "version.rosa_enabled",
+	},
+}
+
+// PendingDeleteCluster contains the names of the fields of the 'PendingDeleteCluster' type that can be used
+// in search expressions.
+var PendingDeleteCluster = pendingDeleteClusterFields{
+	BestEffort: "best_effort",
+	Cluster: pendingDeleteClusterClusterFields{
+		API: pendingDeleteClusterClusterAPIFields{
+			Listening: "cluster.api.listening",
+			URL:       "cluster.api.url",
+		},
+		AWS: pendingDeleteClusterClusterAWSFields{
+			AccessKeyID:              "cluster.aws.access_key_id",
+			AccountID:                "cluster.aws.account_id",
+			BillingAccountID:         "cluster.aws.billing_account_id",
+			Ec2MetadataHTTPTokens:    "cluster.aws.ec2_metadata_http_tokens",
+			KMSKeyARN:                "cluster.aws.kms_key_arn",
+			PrivateHostedZoneID:      "cluster.aws.private_hosted_zone_id",
+			PrivateHostedZoneRoleARN: "cluster.aws.private_hosted_zone_role_arn",
+			PrivateLink:              "cluster.aws.private_link",
+			SecretAccessKey:          "cluster.aws.secret_access_key",
+		},
+		AdditionalTrustBundle: "cluster.additional_trust_bundle",
+		Autoscaler: pendingDeleteClusterClusterAutoscalerFields{
+			BalanceSimilarNodeGroups:    "cluster.autoscaler.balance_similar_node_groups",
+			ID:                          "cluster.autoscaler.id",
+			IgnoreDaemonsetsUtilization: "cluster.autoscaler.ignore_daemonsets_utilization",
+			LogVerbosity:                "cluster.autoscaler.log_verbosity",
+			MaxNodeProvisionTime:        "cluster.autoscaler.max_node_provision_time",
+			MaxPodGracePeriod:           "cluster.autoscaler.max_pod_grace_period",
+			PodPriorityThreshold:        "cluster.autoscaler.pod_priority_threshold",
+			SkipNodesWithLocalStorage:   "cluster.autoscaler.skip_nodes_with_local_storage",
+		},
+		Azure: pendingDeleteClusterClusterAzureFields{
+			ManagedResourceGroupName:       "cluster.azure.managed_resource_group_name",
+			NetworkSecurityGroupResourceID: "cluster.azure.network_security_group_resource_id",
+			ResourceGroupName:              "cluster.azure.resource_group_name",
+			ResourceName:                   "cluster.azure.resource_name",
+			SubnetResourceID:               "cluster.azure.subnet_resource_id",
+			SubscriptionID:                 "cluster.azure.subscription_id",
+			TenantID:                       "cluster.azure.tenant_id",
+		},
+		BillingModel: "cluster.billing_model",
+		ByoOIDC: pendingDeleteClusterClusterByoOIDCFields{
+			Enabled: "cluster.byo_oidc.enabled",
+		},
+		CCS: pendingDeleteClusterClusterCCSFields{
+			DisableScpChecks: "cluster.ccs.disable_scp_checks",
+			Enabled:          "cluster.ccs.enabled",
+			ID:               "cluster.ccs.id",
+		},
+		CloudProvider: pendingDeleteClusterClusterCloudProviderFields{
+			DisplayName: "cluster.cloud_provider.display_name",
+			ID:          "cluster.cloud_provider.id",
+			Name:        "cluster.cloud_provider.name",
+		},
+		Console: pendingDeleteClusterClusterConsoleFields{
+			URL: "cluster.console.url",
+		},
+		CreationTimestamp: "cluster.creation_timestamp",
+		DNS: pendingDeleteClusterClusterDNSFields{
+			BaseDomain: "cluster.dns.base_domain",
+		},
+		DeleteProtection: pendingDeleteClusterClusterDeleteProtectionFields{
+			Enabled: "cluster.delete_protection.enabled",
+		},
+		DisableUserWorkloadMonitoring: "cluster.disable_user_workload_monitoring",
+		DomainPrefix:                  "cluster.domain_prefix",
+		EtcdEncryption:                "cluster.etcd_encryption",
+		ExpirationTimestamp:           "cluster.expiration_timestamp",
+		ExternalAuthConfig: pendingDeleteClusterClusterExternalAuthConfigFields{
+			Enabled: "cluster.external_auth_config.enabled",
+		},
+		ExternalID: "cluster.external_id",
+		FIPS:       "cluster.fips",
+		Flavour: pendingDeleteClusterClusterFlavourFields{
+			ID:   "cluster.flavour.id",
+			Name: "cluster.flavour.name",
+		},
+		GCP: pendingDeleteClusterClusterGCPFields{
+			AuthProviderX509CertURL: "cluster.gcp.auth_provider_x509_cert_url",
+			AuthUri:                 "cluster.gcp.auth_uri",
+			ClientEmail:             "cluster.gcp.client_email",
+			ClientID:                "cluster.gcp.client_id",
+			ClientX509CertURL:       "cluster.gcp.client_x509_cert_url",
+			PrivateKey:              "cluster.gcp.private_key",
+			PrivateKeyID:            "cluster.gcp.private_key_id",
+			ProjectID:               "cluster.gcp.project_id",
+			TokenUri:                "cluster.gcp.token_uri",
+			Type:                    "cluster.gcp.type",
+		},
+		GCPEncryptionKey: pendingDeleteClusterClusterGCPEncryptionKeyFields{
+			KMSKeyServiceAccount: "cluster.gcp_encryption_key.kms_key_service_account",
+			KeyLocation:          "cluster.gcp_encryption_key.key_location",
+			KeyName:              "cluster.gcp_encryption_key.key_name",
+			KeyRing:              "cluster.gcp_encryption_key.key_ring",
+		},
+		GCPNetwork: pendingDeleteClusterClusterGCPNetworkFields{
+			ComputeSubnet:      "cluster.gcp_network.compute_subnet",
+			ControlPlaneSubnet: "cluster.gcp_network.control_plane_subnet",
+			VPCName:            "cluster.gcp_network.vpc_name",
+			VPCProjectID:       "cluster.gcp_network.vpc_project_id",
+		},
+		HealthState: "cluster.health_state",
+		Htpasswd: pendingDeleteClusterClusterHtpasswdFields{
+			Password: "cluster.htpasswd.password",
+			Username: "cluster.htpasswd.username",
+		},
+		Hypershift: pendingDeleteClusterClusterHypershiftFields{
+			Enabled: "cluster.hypershift.enabled",
+		},
+		ID:      "cluster.id",
+		InfraID: "cluster.infra_id",
+		KubeletConfig: pendingDeleteClusterClusterKubeletConfigFields{
+			ID:           "cluster.kubelet_config.id",
+			Name:         "cluster.kubelet_config.name",
+			PodPidsLimit: "cluster.kubelet_config.pod_pids_limit",
+		},
+		LoadBalancerQuota: "cluster.load_balancer_quota",
+		Managed:           "cluster.managed",
+		ManagedService: pendingDeleteClusterClusterManagedServiceFields{
+			Enabled: "cluster.managed_service.enabled",
+		},
+		MultiAZ:          "cluster.multi_az",
+		MultiArchEnabled: "cluster.multi_arch_enabled",
+		Name:             "cluster.name",
+		Network: pendingDeleteClusterClusterNetworkFields{
+			HostPrefix:  "cluster.network.host_prefix",
+			MachineCIDR: "cluster.network.machine_cidr",
+			PodCIDR:     "cluster.network.pod_cidr",
+			ServiceCIDR: "cluster.network.service_cidr",
+			Type:        "cluster.network.type",
+		},
+		NodeDrainGracePeriod: pendingDeleteClusterClusterNodeDrainGracePeriodFields{
+			Unit:  "cluster.node_drain_grace_period.unit",
+			Value: "cluster.node_drain_grace_period.value",
+		},
+		Nodes: pendingDeleteClusterClusterNodesFields{
+			Compute: "cluster.nodes.compute",
+			Infra:   "cluster.nodes.infra",
+			Master:  "cluster.nodes.master",
+			Total:   "cluster.nodes.total",
+		},
+		OpenshiftVersion: "cluster.openshift_version",
+		Product: pendingDeleteClusterClusterProductFields{
+			ID:   "cluster.product.id",
+			Name: "cluster.product.name",
+		},
+		ProvisionShard: pendingDeleteClusterClusterProvisionShardFields{
+			AWSBaseDomain:       "cluster.provision_shard.aws_base_domain",
+			CreationTimestamp:   "cluster.provision_shard.creation_timestamp",
+			GCPBaseDomain:       "cluster.provision_shard.gcp_base_domain",
+			ID:                  "cluster.provision_shard.id",
+			LastUpdateTimestamp: "cluster.provision_shard.last_update_timestamp",
+			ManagementCluster:   "cluster.provision_shard.management_cluster",
+			Status:              "cluster.provision_shard.status",
+		},
+		Proxy: pendingDeleteClusterClusterProxyFields{
+			HTTPProxy:  "cluster.proxy.http_proxy",
+			HTTPSProxy: "cluster.proxy.https_proxy",
+			NoProxy:    "cluster.proxy.no_proxy",
+		},
+		Region: pendingDeleteClusterClusterRegionFields{
+			CCSOnly:            "cluster.region.ccs_only",
+			DisplayName:        "cluster.region.display_name",
+			Enabled:            "cluster.region.enabled",
+			Govcloud:           "cluster.region.govcloud",
+			ID:                 "cluster.region.id",
+			KMSLocationID:      "cluster.region.kms_location_id",
+			KMSLocationName:    "cluster.region.kms_location_name",
+			Name:               "cluster.region.name",
+			SupportsHypershift: "cluster.region.supports_hypershift",
+			SupportsMultiAZ:    "cluster.region.supports_multi_az",
+		},
+		State: "cluster.state",
+		Status: pendingDeleteClusterClusterStatusFields{
+			ConfigurationMode:         "cluster.status.configuration_mode",
+			CurrentCompute:            "cluster.status.current_compute",
+			DNSReady:                  "cluster.status.dns_ready",
+			Description:               "cluster.status.description",
+			ID:                        "cluster.status.id",
+			LimitedSupportReasonCount: "cluster.status.limited_support_reason_count",
+			OIDCReady:                 "cluster.status.oidc_ready",
+			ProvisionErrorCode:        "cluster.status.provision_error_code",
+			ProvisionErrorMessage:     "cluster.status.provision_error_message",
+			State:                     "cluster.status.state",
+		},
+		StorageQuota: pendingDeleteClusterClusterStorageQuotaFields{
+			Unit:  "cluster.storage_quota.unit",
+			Value: "cluster.storage_quota.value",
+		},
+		Subscription: pendingDeleteClusterClusterSubscriptionFields{
+			ID: "cluster.subscription.id",
+		},
+		Version: pendingDeleteClusterClusterVersionFields{
+			ChannelGroup:              "cluster.version.channel_group",
+			Default:                   "cluster.version.default",
+			Enabled:                   "cluster.version.enabled",
+			EndOfLifeTimestamp:        "cluster.version.end_of_life_timestamp",
+			GCPMarketplaceEnabled:     "cluster.version.gcp_marketplace_enabled",
+			HostedControlPlaneDefault: "cluster.version.hosted_control_plane_default",
+			HostedControlPlaneEnabled: "cluster.version.hosted_control_plane_enabled",
+			ID:                        "cluster.version.id",
+			RawID:                     "cluster.version.raw_id",
+			ReleaseImage:              "cluster.version.release_image",
+			RosaEnabled:               "cluster.version.rosa_enabled",
+		},
+	},
+	CreationTimestamp: "creation_timestamp",
+	ID:                "id",
+}
+
+// PrivateLinkPrincipal contains the names of the fields of the 'PrivateLinkPrincipal' type that can be used
+// in search expressions.
+var PrivateLinkPrincipal = privateLinkPrincipalFields{
+	ID:        "id",
+	Principal: "principal",
+}
+
+// Product contains the names of the fields of the 'Product' type that can be used
+// in search expressions.
+var Product = productFields{
+	ID:   "id",
+	Name: "name",
+}
+
+// ProductMinimalVersion contains the names of the fields of the 'ProductMinimalVersion' type that can be used
+// in search expressions.
+var ProductMinimalVersion = productMinimalVersionFields{
+	ID:        "id",
+	RosaCli:   "rosa_cli",
+	StartDate: "start_date",
+}
+
+// ProductTechnologyPreview contains the names of the fields of the 'ProductTechnologyPreview' type that can be used
+// in search expressions.
+var ProductTechnologyPreview = productTechnologyPreviewFields{
+	AdditionalText: "additional_text",
+	EndDate:        "end_date",
+	ID:             "id",
+	StartDate:      "start_date",
+}
+
+// ProvisionShard contains the names of the fields of the 'ProvisionShard' type that can be used
+// in search expressions.
+var ProvisionShard = provisionShardFields{
+	AWSAccountOperatorConfig: provisionShardAWSAccountOperatorConfigFields{
+		ID:         "aws_account_operator_config.id",
+		Kubeconfig: "aws_account_operator_config.kubeconfig",
+		Server:     "aws_account_operator_config.server",
+		Topology:   "aws_account_operator_config.topology",
+	},
+	AWSBaseDomain: "aws_base_domain",
+	CloudProvider: provisionShardCloudProviderFields{
+		DisplayName: "cloud_provider.display_name",
+		ID:          "cloud_provider.id",
+		Name:        "cloud_provider.name",
+	},
+	CreationTimestamp: "creation_timestamp",
+	GCPBaseDomain:     "gcp_base_domain",
+	GCPProjectOperator: provisionShardGCPProjectOperatorFields{
+		ID:         "gcp_project_operator.id",
+		Kubeconfig: "gcp_project_operator.kubeconfig",
+		Server:     "gcp_project_operator.server",
+		Topology:   "gcp_project_operator.topology",
+	},
+	HiveConfig: provisionShardHiveConfigFields{
+		ID:         "hive_config.id",
+		Kubeconfig: "hive_config.kubeconfig",
+		Server:     "hive_config.server",
+		Topology:   "hive_config.topology",
+	},
+	HypershiftConfig: provisionShardHypershiftConfigFields{
+		ID:         "hypershift_config.id",
+		Kubeconfig: "hypershift_config.kubeconfig",
+		Server:     "hypershift_config.server",
+		Topology:   "hypershift_config.topology",
+	},
+	ID:                  "id",
+	LastUpdateTimestamp: "last_update_timestamp",
+	ManagementCluster:   "management_cluster",
+	Region: provisionShardRegionFields{
+		CCSOnly: "region.ccs_only",
+		CloudProvider: provisionShardRegionCloudProviderFields{
+			DisplayName: "region.cloud_provider.display_name",
+			ID:          "region.cloud_provider.id",
+			Name:        "region.cloud_provider.name",
+		},
+		DisplayName:        "region.display_name",
+		Enabled:            "region.enabled",
+		Govcloud:           "region.govcloud",
+		ID:                 "region.id",
+		KMSLocationID:      "region.kms_location_id",
+		KMSLocationName:    "region.kms_location_name",
+		Name:               "region.name",
+		SupportsHypershift: "region.supports_hypershift",
+		SupportsMultiAZ:    "region.supports_multi_az",
+	},
+	Status: "status",
+}
+
+// Version contains the names of the fields of the 'Version' type that can be used
+// in search expressions.
+var Version = versionFields{
+	ChannelGroup:              "channel_group",
+	Default:                   "default",
+	Enabled:                   "enabled",
+	EndOfLifeTimestamp:        "end_of_life_timestamp",
+	GCPMarketplaceEnabled:     "gcp_marketplace_enabled",
+	HostedControlPlaneDefault: "hosted_control_plane_default",
+	HostedControlPlaneEnabled: "hosted_control_plane_enabled",
+	ID:                        "id",
+	ImageOverrides: versionImageOverridesFields{
+		ID: "image_overrides.id",
+	},
+	RawID:        "raw_id",
+	ReleaseImage: "release_image",
+	ReleaseImages: versionReleaseImagesFields{
+		Arm64: versionReleaseImagesArm64Fields{
+			ReleaseImage: "release_images.arm64.release_image",
+		},
+		Multi: versionReleaseImagesMultiFields{
+			ReleaseImage: "release_images.multi.release_image",
+		},
+	},
+	RosaEnabled: "rosa_enabled",
+}
+
+// VersionGate contains the names of the fields of the 'VersionGate' type that can be used
+// in search expressions.
+var VersionGate = versionGateFields{
+	CreationTimestamp:  "creation_timestamp",
+	Description:        "description",
+	DocumentationURL:   "documentation_url",
+	ID:                 "id",
+	Label:              "label",
+	STSOnly:            "sts_only",
+	Value:              "value",
+	VersionRawIDPrefix: "version_raw_id_prefix",
+	WarningMessage:     "warning_message",
+}
+
+// WifConfig contains the names of the fields of the 'WifConfig' type that can be used
+// in search expressions.
+var WifConfig = wifConfigFields{
+	DisplayName: "display_name",
+	GCP: wifConfigGCPFields{
+		ImpersonatorEmail: "gcp.impersonator_email",
+		ProjectID:         "gcp.project_id",
+		ProjectNumber:     "gcp.project_number",
+		RolePrefix:        "gcp.role_prefix",
+		WorkloadIdentityPool: wifConfigGCPWorkloadIdentityPoolFields{
+			PoolID:   "gcp.workload_identity_pool.pool_id",
+			PoolName: "gcp.workload_identity_pool.pool_name",
+		},
+	},
+	ID: "id",
+	Organization: wifConfigOrganizationFields{
+		ID: "organization.id",
+	},
+}
+
+// awsInfrastructureAccessRoleFields is the type of the AWSInfrastructureAccessRole variable.
+type awsInfrastructureAccessRoleFields struct {
+	Description search.Field
+	DisplayName search.Field
+	ID          search.Field
+	State       search.Field
+}
+
+// awsInfrastructureAccessRoleGrantFields is the type of the AWSInfrastructureAccessRoleGrant variable.
+type awsInfrastructureAccessRoleGrantFields struct {
+	ConsoleURL       search.Field
+	ID               search.Field
+	Role             awsInfrastructureAccessRoleGrantRoleFields
+	State            search.Field
+	StateDescription search.Field
+	UserARN          search.Field
+}
+
+// awsInfrastructureAccessRoleGrantRoleFields contains the names of the fields of the 'role' object.
+type awsInfrastructureAccessRoleGrantRoleFields struct {
+	Description search.Field
+	DisplayName search.Field
+	ID          search.Field
+	State       search.Field
+}
+
+// awsstsPolicyFields is the type of the AWSSTSPolicy variable.
+type awsstsPolicyFields struct {
+	ARN     search.Field
+	Details search.Field
+	ID      search.Field
+	Type    search.Field
+}
+
+// addOnFields is the type of the AddOn variable.
+type addOnFields struct {
+	Config               addOnConfigFields
+	Description          search.Field
+	DocsLink             search.Field
+	Enabled              search.Field
+	HasExternalResources search.Field
+	Hidden               search.Field
+	ID                   search.Field
+	Icon                 search.Field
+	InstallMode          search.Field
+	Label                search.Field
+	ManagedService       search.Field
+	Name                 search.Field
+	OperatorName         search.Field
+	ResourceCost         search.Field
+	ResourceName         search.Field
+	TargetNamespace      search.Field
+	Version              addOnVersion2Fields
+}
+
+// addOnConfigFields contains the names of the fields of the 'config' object.
+type addOnConfigFields struct {
+	ID search.Field
+}
+
+// addOnVersion2Fields contains the names of the fields of the 'version' object.
+type addOnVersion2Fields struct {
+	Channel        search.Field
+	Config         addOnVersionConfigFields
+	Enabled        search.Field
+	ID             search.Field
+	PackageImage   search.Field
+	PullSecretName search.Field
+	SourceImage    search.Field
+}
+
+// addOnVersionConfigFields contains the names of the fields of the 'version.config' object.
+type addOnVersionConfigFields struct {
+	ID search.Field
+}
+
+// addOnInstallationFields is the type of the AddOnInstallation variable.
+type addOnInstallationFields struct {
+	Addon             addOnInstallationAddonFields
+	AddonVersion      addOnInstallationAddonVersion2Fields
+	Billing           addOnInstallationBillingFields
+	CreationTimestamp search.Field
+	ID                search.Field
+	OperatorVersion   search.Field
+	State             search.Field
+	StateDescription  search.Field
+	UpdatedTimestamp  search.Field
+}
+
+// addOnInstallationAddonFields contains the names of the fields of the 'addon' object.
+type addOnInstallationAddonFields struct {
+	Config               addOnInstallationAddonConfigFields
+	Description          search.Field
+	DocsLink             search.Field
+	Enabled              search.Field
+	HasExternalResources search.Field
+	Hidden               search.Field
+	ID                   search.Field
+	Icon                 search.Field
+	InstallMode          search.Field
+	Label                search.Field
+	ManagedService       search.Field
+	Name                 search.Field
+	OperatorName         search.Field
+	ResourceCost         search.Field
+	ResourceName         search.Field
+	TargetNamespace      search.Field
+	Version              addOnInstallationAddonVersionFields
+}
+
+// addOnInstallationAddonConfigFields contains the names of the fields of the 'addon.config' object.
+type addOnInstallationAddonConfigFields struct {
+	ID search.Field
+}
+
+// addOnInstallationAddonVersionFields contains the names of the fields of the 'addon.version' object.
+type addOnInstallationAddonVersionFields struct {
+	Channel        search.Field
+	Enabled        search.Field
+	ID             search.Field
+	PackageImage   search.Field
+	PullSecretName search.Field
+	SourceImage    search.Field
+}
+
+// addOnInstallationAddonVersion2Fields contains the names of the fields of the 'addon_version' object.
+type addOnInstallationAddonVersion2Fields struct {
+	Channel        search.Field
+	Config         addOnInstallationAddonVersionConfigFields
+	Enabled        search.Field
+	ID             search.Field
+	PackageImage   search.Field
+	PullSecretName search.Field
+	SourceImage    search.Field
+}
+
+// addOnInstallationAddonVersionConfigFields contains the names of the fields of the 'addon_version.config' object.
+type addOnInstallationAddonVersionConfigFields struct {
+	ID search.Field
+}
+
+// addOnInstallationBillingFields contains the names of the fields of the 'billing' object.
+type addOnInstallationBillingFields struct {
+	BillingMarketplaceAccount search.Field
+	BillingModel              search.Field
+	ID                        search.Field
+}
+
+// addOnVersionFields is the type of the AddOnVersion variable.
+type addOnVersionFields struct {
+	Channel        search.Field
+	Config         addOnVersionConfig2Fields
+	Enabled        search.Field
+	ID             search.Field
+	PackageImage   search.Field
+	PullSecretName search.Field
+	SourceImage    search.Field
+}
+
+// addOnVersionConfig2Fields contains the names of the fields of the 'config' object.
+type addOnVersionConfig2Fields struct {
+	ID search.Field
+}
+
+// breakGlassCredentialFields is the type of the BreakGlassCredential variable.
+type breakGlassCredentialFields struct {
+	ExpirationTimestamp search.Field
+	ID                  search.Field
+	Kubeconfig          search.Field
+	RevocationTimestamp search.Field
+	Status              search.Field
+	Username            search.Field
+}
+
+// cloudProviderFields is the type of the CloudProvider variable.
+type cloudProviderFields struct {
+	DisplayName search.Field
+	ID          search.Field
+	Name        search.Field
+}
+
+// clusterFields is the type of the Cluster variable.
+type clusterFields struct {
+	API                           clusterAPIFields
+	AWS                           clusterAWSFields
+	AdditionalTrustBundle         search.Field
+	Autoscaler                    clusterAutoscalerFields
+	Azure                         clusterAzureFields
+	BillingModel                  search.Field
+	ByoOIDC                       clusterByoOIDCFields
+	CCS                           clusterCCSFields
+	CloudProvider                 clusterCloudProviderFields
+	Console                       clusterConsoleFields
+	CreationTimestamp             search.Field
+	DNS                           clusterDNSFields
+	DeleteProtection              clusterDeleteProtectionFields
+	DisableUserWorkloadMonitoring search.Field
+	DomainPrefix                  search.Field
+	EtcdEncryption                search.Field
+	ExpirationTimestamp           search.Field
+	ExternalAuthConfig            clusterExternalAuthConfigFields
+	ExternalID                    search.Field
+	FIPS                          search.Field
+	Flavour                       clusterFlavourFields
+	GCP                           clusterGCPFields
+	GCPEncryptionKey              clusterGCPEncryptionKeyFields
+	GCPNetwork                    clusterGCPNetworkFields
+	HealthState                   search.Field
+	Htpasswd                      clusterHtpasswdFields
+	Hypershift                    clusterHypershiftFields
+	ID                            search.Field
+	InfraID                       search.Field
+	KubeletConfig                 clusterKubeletConfigFields
+	LoadBalancerQuota             search.Field
+	Managed                       search.Field
+	ManagedService                clusterManagedServiceFields
+	MultiAZ                       search.Field
+	MultiArchEnabled              search.Field
+	Name                          search.Field
+	Network                       clusterNetworkFields
+	NodeDrainGracePeriod          clusterNodeDrainGracePeriodFields
+	Nodes                         clusterNodesFields
+	OpenshiftVersion              search.Field
+	Product                       clusterProductFields
+	ProvisionShard                clusterProvisionShardFields
+	Proxy                         clusterProxyFields
+	Region                        clusterRegionFields
+	State                         search.Field
+	Status                        clusterStatusFields
+	StorageQuota                  clusterStorageQuotaFields
+	Subscription                  clusterSubscriptionFields
+	Version                       clusterVersionFields
+}
+
+// clusterAPIFields contains the names of the fields of the 'api' object.
+type clusterAPIFields struct {
+	Listening search.Field
+	URL       search.Field
+}
+
+// clusterAWSFields contains the names of the fields of the 'aws' object.
+type clusterAWSFields struct {
+	AccessKeyID              search.Field
+	AccountID                search.Field
+	AuditLog                 clusterAWSAuditLogFields
+	BillingAccountID         search.Field
+	Ec2MetadataHTTPTokens    search.Field
+	EtcdEncryption           clusterAWSEtcdEncryptionFields
+	KMSKeyARN                search.Field
+	PrivateHostedZoneID      search.Field
+	PrivateHostedZoneRoleARN search.Field
+	PrivateLink              search.Field
+	STS                      clusterAWSSTSFields
+	SecretAccessKey          search.Field
+}
+
+// clusterAWSAuditLogFields contains the names of the fields of the 'aws.audit_log' object.
+type clusterAWSAuditLogFields struct {
+	RoleARN search.Field
+}
+
+// clusterAWSEtcdEncryptionFields contains the names of the fields of the 'aws.etcd_encryption' object.
+type clusterAWSEtcdEncryptionFields struct {
+	KMSKeyARN search.Field
+}
+
+// clusterAWSSTSFields contains the names of the fields of the 'aws.sts' object.
+type clusterAWSSTSFields struct {
+	AutoMode           search.Field
+	Enabled            search.Field
+	ExternalID         search.Field
+	ManagedPolicies    search.Field
+	OIDCEndpointURL    search.Field
+	OperatorRolePrefix search.Field
+	PermissionBoundary search.Field
+	RoleARN            search.Field
+	SupportRoleARN     search.Field
+}
+
+// clusterAutoscalerFields contains the names of the fields of the 'autoscaler' object.
+type clusterAutoscalerFields struct {
+	BalanceSimilarNodeGroups    search.Field
+	ID                          search.Field
+	IgnoreDaemonsetsUtilization search.Field
+	LogVerbosity                search.Field
+	MaxNodeProvisionTime        search.Field
+	MaxPodGracePeriod           search.Field
+	PodPriorityThreshold        search.Field
+	ResourceLimits              clusterAutoscalerResourceLimitsFields
+	ScaleDown                   clusterAutoscalerScaleDownFields
+	SkipNodesWithLocalStorage   search.Field
+}
+
+// clusterAutoscalerResourceLimitsFields contains the names of the fields of the 'autoscaler.resource_limits' object.
+type clusterAutoscalerResourceLimitsFields struct {
+	MaxNodesTotal search.Field
+}
+
+// clusterAutoscalerScaleDownFields contains the names of the fields of the 'autoscaler.scale_down' object.
+type clusterAutoscalerScaleDownFields struct {
+	DelayAfterAdd        search.Field
+	DelayAfterDelete     search.Field
+	DelayAfterFailure    search.Field
+	Enabled              search.Field
+	UnneededTime         search.Field
+	UtilizationThreshold search.Field
+}
+
+// clusterAzureFields contains the names of the fields of the 'azure' object.
+type clusterAzureFields struct {
+	ManagedResourceGroupName       search.Field
+	NetworkSecurityGroupResourceID search.Field
+	ResourceGroupName              search.Field
+	ResourceName                   search.Field
+	SubnetResourceID               search.Field
+	SubscriptionID                 search.Field
+	TenantID                       search.Field
+}
+
+// clusterByoOIDCFields contains the names of the fields of the 'byo_oidc' object.
+type clusterByoOIDCFields struct {
+	Enabled search.Field
+}
+
+// clusterCCSFields contains the names of the fields of the 'ccs' object.
+type clusterCCSFields struct {
+	DisableScpChecks search.Field
+	Enabled          search.Field
+	ID               search.Field
+}
+
+// clusterCloudProviderFields contains the names of the fields of the 'cloud_provider' object.
+type clusterCloudProviderFields struct {
+	DisplayName search.Field
+	ID          search.Field
+	Name        search.Field
+}
+
+// clusterConsoleFields contains the names of the fields of the 'console' object.
+type clusterConsoleFields struct {
+	URL search.Field
+}
+
+// clusterDNSFields contains the names of the fields of the 'dns' object.
+type clusterDNSFields struct {
+	BaseDomain search.Field
+}
+
+// clusterDeleteProtectionFields contains the names of the fields of the 'delete_protection' object.
+type clusterDeleteProtectionFields struct {
+	Enabled search.Field
+}
+
+// clusterExternalAuthConfigFields contains the names of the fields of the 'external_auth_config' object.
+type clusterExternalAuthConfigFields struct {
+	Enabled search.Field
+}
+
+// clusterFlavourFields contains the names of the fields of the 'flavour' object.
+type clusterFlavourFields struct {
+	AWS     clusterFlavourAWSFields
+	GCP     clusterFlavourGCPFields
+	ID      search.Field
+	Name    search.Field
+	Network clusterFlavourNetworkFields
+	Nodes   clusterFlavourNodesFields
+}
+
+// clusterFlavourAWSFields contains the names of the fields of the 'flavour.aws' object.
+type clusterFlavourAWSFields struct {
+	ComputeInstanceType search.Field
+	InfraInstanceType   search.Field
+	MasterInstanceType  search.Field
+}
+
+// clusterFlavourGCPFields contains the names of the fields of the 'flavour.gcp' object.
+type clusterFlavourGCPFields struct {
+	ComputeInstanceType search.Field
+	InfraInstanceType   search.Field
+	MasterInstanceType  search.Field
+}
+
+// clusterFlavourNetworkFields contains the names of the fields of the 'flavour.network' object.
+type clusterFlavourNetworkFields struct {
+	HostPrefix  search.Field
+	MachineCIDR search.Field
+	PodCIDR     search.Field
+	ServiceCIDR search.Field
+	Type        search.Field
+}
+
+// clusterFlavourNodesFields contains the names of the fields of the 'flavour.nodes' object.
+type clusterFlavourNodesFields struct {
+	Master search.Field
+}
+
+// clusterGCPFields contains the names of the fields of the 'gcp' object.
+type clusterGCPFields struct {
+	AuthProviderX509CertURL search.Field
+	AuthUri                 search.Field
+	Authentication          clusterGCPAuthenticationFields
+	ClientEmail             search.Field
+	ClientID                search.Field
+	ClientX509CertURL       search.Field
+	PrivateKey              search.Field
+	PrivateKeyID            search.Field
+	ProjectID               search.Field
+	Security                clusterGCPSecurityFields
+	TokenUri                search.Field
+	Type                    search.Field
+}
+
+// clusterGCPAuthenticationFields contains the names of the fields of the 'gcp.authentication' object.
+type clusterGCPAuthenticationFields struct {
+	ID search.Field
+}
+
+// clusterGCPSecurityFields contains the names of the fields of the 'gcp.security' object.
+type clusterGCPSecurityFields struct {
+	SecureBoot search.Field
+}
+
+// clusterGCPEncryptionKeyFields contains the names of the fields of the 'gcp_encryption_key' object.
+type clusterGCPEncryptionKeyFields struct {
+	KMSKeyServiceAccount search.Field
+	KeyLocation          search.Field
+	KeyName              search.Field
+	KeyRing              search.Field
+}
+
+// clusterGCPNetworkFields contains the names of the fields of the 'gcp_network' object.
+type clusterGCPNetworkFields struct {
+	ComputeSubnet      search.Field
+	ControlPlaneSubnet search.Field
+	VPCName            search.Field
+	VPCProjectID       search.Field
+}
+
+// clusterHtpasswdFields contains the names of the fields of the 'htpasswd' object.
+type clusterHtpasswdFields struct {
+	Password search.Field
+	Username search.Field
+}
+
+// clusterHypershiftFields contains the names of the fields of the 'hypershift' object.
+type clusterHypershiftFields struct {
+	Enabled search.Field
+}
+
+// clusterKubeletConfigFields contains the names of the fields of the 'kubelet_config' object.
+type clusterKubeletConfigFields struct {
+	ID           search.Field
+	Name         search.Field
+	PodPidsLimit search.Field
+}
+
+// clusterManagedServiceFields contains the names of the fields of the 'managed_service' object.
+type clusterManagedServiceFields struct {
+	Enabled search.Field
+}
+
+// clusterNetworkFields contains the names of the fields of the 'network' object.
+type clusterNetworkFields struct {
+	HostPrefix  search.Field
+	MachineCIDR search.Field
+	PodCIDR     search.Field
+	ServiceCIDR search.Field
+	Type        search.Field
+}
+
+// clusterNodeDrainGracePeriodFields contains the names of the fields of the 'node_drain_grace_period' object.
+type clusterNodeDrainGracePeriodFields struct {
+	Unit  search.Field
+	Value search.Field
+}
+
+// clusterNodesFields contains the names of the fields of the 'nodes' object.
+type clusterNodesFields struct {
+	AutoscaleCompute   clusterNodesAutoscaleComputeFields
+	Compute            search.Field
+	ComputeMachineType clusterNodesComputeMachineTypeFields
+	Infra              search.Field
+	InfraMachineType   clusterNodesInfraMachineTypeFields
+	Master             search.Field
+	MasterMachineType  clusterNodesMasterMachineTypeFields
+	Total              search.Field
+}
+
+// clusterNodesAutoscaleComputeFields contains the names of the fields of the 'nodes.autoscale_compute' object.
+type clusterNodesAutoscaleComputeFields struct {
+	ID          search.Field
+	MaxReplicas search.Field
+	MinReplicas search.Field
+}
+
+// clusterNodesComputeMachineTypeFields contains the names of the fields of the 'nodes.compute_machine_type' object.
+type clusterNodesComputeMachineTypeFields struct {
+	Architecture search.Field
+	CCSOnly      search.Field
+	Category     search.Field
+	GenericName  search.Field
+	ID           search.Field
+	Name         search.Field
+	Size         search.Field
+}
+
+// clusterNodesInfraMachineTypeFields contains the names of the fields of the 'nodes.infra_machine_type' object.
+type clusterNodesInfraMachineTypeFields struct {
+	Architecture search.Field
+	CCSOnly      search.Field
+	Category     search.Field
+	GenericName  search.Field
+	ID           search.Field
+	Name         search.Field
+	Size         search.Field
+}
+
+// clusterNodesMasterMachineTypeFields contains the names of the fields of the 'nodes.master_machine_type' object.
+type clusterNodesMasterMachineTypeFields struct {
+	Architecture search.Field
+	CCSOnly      search.Field
+	Category     search.Field
+	GenericName  search.Field
+	ID           search.Field
+	Name         search.Field
+	Size         search.Field
+}
+
+// clusterProductFields contains the names of the fields of the 'product' object.
+type clusterProductFields struct {
+	ID   search.Field
+	Name search.Field
+}
+
+// clusterProvisionShardFields contains the names of the fields of the 'provision_shard' object.
+type clusterProvisionShardFields struct {
+	AWSAccountOperatorConfig clusterProvisionShardAWSAccountOperatorConfigFields
+	AWSBaseDomain            search.Field
+	CloudProvider            clusterProvisionShardCloudProviderFields
+	CreationTimestamp        search.Field
+	GCPBaseDomain            search.Field
+	GCPProjectOperator       clusterProvisionShardGCPProjectOperatorFields
+	HiveConfig               clusterProvisionShardHiveConfigFields
+	HypershiftConfig         clusterProvisionShardHypershiftConfigFields
+	ID                       search.Field
+	LastUpdateTimestamp      search.Field
+	ManagementCluster        search.Field
+	Region                   clusterProvisionShardRegionFields
+	Status                   search.Field
+}
+
+// clusterProvisionShardAWSAccountOperatorConfigFields contains the names of the fields of the 'provision_shard.aws_account_operator_config' object.
+type clusterProvisionShardAWSAccountOperatorConfigFields struct {
+	ID         search.Field
+	Kubeconfig search.Field
+	Server     search.Field
+	Topology   search.Field
+}
+
+// clusterProvisionShardCloudProviderFields contains the names of the fields of the 'provision_shard.cloud_provider' object.
+type clusterProvisionShardCloudProviderFields struct {
+	DisplayName search.Field
+	ID          search.Field
+	Name        search.Field
+}
+
+// clusterProvisionShardGCPProjectOperatorFields contains the names of the fields of the 'provision_shard.gcp_project_operator' object.
+type clusterProvisionShardGCPProjectOperatorFields struct {
+	ID         search.Field
+	Kubeconfig search.Field
+	Server     search.Field
+	Topology   search.Field
+}
+
+// clusterProvisionShardHiveConfigFields contains the names of the fields of the 'provision_shard.hive_config' object.
+type clusterProvisionShardHiveConfigFields struct {
+	ID         search.Field
+	Kubeconfig search.Field
+	Server     search.Field
+	Topology   search.Field
+}
+
+// clusterProvisionShardHypershiftConfigFields contains the names of the fields of the 'provision_shard.hypershift_config' object.
+type clusterProvisionShardHypershiftConfigFields struct {
+	ID         search.Field
+	Kubeconfig search.Field
+	Server     search.Field
+	Topology   search.Field
+}
+
+// clusterProvisionShardRegionFields contains the names of the fields of the 'provision_shard.region' object.
+type clusterProvisionShardRegionFields struct {
+	CCSOnly            search.Field
+	DisplayName        search.Field
+	Enabled            search.Field
+	Govcloud           search.Field
+	ID                 search.Field
+	KMSLocationID      search.Field
+	KMSLocationName    search.Field
+	Name               search.Field
+	SupportsHypershift search.Field
+	SupportsMultiAZ    search.Field
+}
+
+// clusterProxyFields contains the names of the fields of the 'proxy' object.
+type clusterProxyFields struct {
+	HTTPProxy  search.Field
+	HTTPSProxy search.Field
+	NoProxy    search.Field
+}
+
+// clusterRegionFields contains the names of the fields of the 'region' object.
+type clusterRegionFields struct {
+	CCSOnly            search.Field
+	CloudProvider      clusterRegionCloudProviderFields
+	DisplayName        search.Field
+	Enabled            search.Field
+	Govcloud           search.Field
+	ID                 search.Field
+	KMSLocationID      search.Field
+	KMSLocationName    search.Field
+	Name               search.Field
+	SupportsHypershift search.Field
+	SupportsMultiAZ    search.Field
+}
+
+// clusterRegionCloudProviderFields contains the names of the fields of the 'region.cloud_provider' object.
+type clusterRegionCloudProviderFields struct {
+	DisplayName search.Field
+	ID          search.Field
+	Name        search.Field
+}
+
+// clusterStatusFields contains the names of the fields of the 'status' object.
+type clusterStatusFields struct {
+	ConfigurationMode         search.Field
+	CurrentCompute            search.Field
+	DNSReady                  search.Field
+	Description               search.Field
+	ID                        search.Field
+	LimitedSupportReasonCount search.Field
+	OIDCReady                 search.Field
+	ProvisionErrorCode        search.Field
+	ProvisionErrorMessage     search.Field
+	State                     search.Field
+}
+
+// clusterStorageQuotaFields contains the names of the fields of the 'storage_quota' object.
+type clusterStorageQuotaFields struct {
+	Unit  search.Field
+	Value search.Field
+}
+
+// clusterSubscriptionFields contains the names of the fields of the 'subscription' object.
+type clusterSubscriptionFields struct {
+	ID search.Field
+}
+
+// clusterVersionFields contains the names of the fields of the 'version' object.
+type clusterVersionFields struct {
+	ChannelGroup              search.Field
+	Default                   search.Field
+	Enabled                   search.Field
+	EndOfLifeTimestamp        search.Field
+	GCPMarketplaceEnabled     search.Field
+	HostedControlPlaneDefault search.Field
+	HostedControlPlaneEnabled search.Field
+	ID                        search.Field
+	ImageOverrides            clusterVersionImageOverridesFields
+	RawID                     search.Field
+	ReleaseImage              search.Field
+	RosaEnabled               search.Field
+}
+
+// clusterVersionImageOverridesFields contains the names of the fields of the 'version.image_overrides' object.
+type clusterVersionImageOverridesFields struct {
+	ID search.Field
+}
+
+// dnsDomainFields is the type of the DNSDomain variable.
+type dnsDomainFields struct {
+	Cluster             dnsDomainClusterFields
+	ID                  search.Field
+	Organization        dnsDomainOrganizationFields
+	ReservedAtTimestamp search.Field
+	UserDefined         search.Field
+}
+
+// dnsDomainClusterFields contains the names of the fields of the 'cluster' object.
+type dnsDomainClusterFields struct {
+	ID search.Field
+}
+
+// dnsDomainOrganizationFields contains the names of the fields of the 'organization' object.
+type dnsDomainOrganizationFields struct {
+	ID search.Field
+}
+
+// flavourFields is the type of the Flavour variable.
+type flavourFields struct {
+	AWS     flavourAWSFields
+	GCP     flavourGCPFields
+	ID      search.Field
+	Name    search.Field
+	Network flavourNetworkFields
+	Nodes   flavourNodesFields
+}
+
+// flavourAWSFields contains the names of the fields of the 'aws' object.
+type flavourAWSFields struct {
+	ComputeInstanceType search.Field
+	InfraInstanceType   search.Field
+	InfraVolume         flavourAWSInfraVolumeFields
+	MasterInstanceType  search.Field
+	MasterVolume        flavourAWSMasterVolumeFields
+	WorkerVolume        flavourAWSWorkerVolumeFields
+}
+
+// flavourAWSInfraVolumeFields contains the names of the fields of the 'aws.infra_volume' object.
+type flavourAWSInfraVolumeFields struct {
+	Iops search.Field
+	Size search.Field
+}
+
+// flavourAWSMasterVolumeFields contains the names of the fields of the 'aws.master_volume' object.
+type flavourAWSMasterVolumeFields struct {
+	Iops search.Field
+	Size search.Field
+}
+
+// flavourAWSWorkerVolumeFields contains the names of the fields of the 'aws.worker_volume' object.
+type flavourAWSWorkerVolumeFields struct {
+	Iops search.Field
+	Size search.Field
+}
+
+// flavourGCPFields contains the names of the fields of the 'gcp' object.
+type flavourGCPFields struct {
+	ComputeInstanceType search.Field
+	InfraInstanceType   search.Field
+	InfraVolume         flavourGCPInfraVolumeFields
+	MasterInstanceType  search.Field
+	MasterVolume        flavourGCPMasterVolumeFields
+	WorkerVolume        flavourGCPWorkerVolumeFields
+}
+
+// flavourGCPInfraVolumeFields contains the names of the fields of the 'gcp.infra_volume' object.
+type flavourGCPInfraVolumeFields struct {
+	Size search.Field
+}
+
+// flavourGCPMasterVolumeFields contains the names of the fields of the 'gcp.master_volume' object.
+type flavourGCPMasterVolumeFields struct {
+	Size search.Field
+}
+
+// flavourGCPWorkerVolumeFields contains the names of the fields of the 'gcp.worker_volume' object.
+type flavourGCPWorkerVolumeFields struct {
+	Size search.Field
+}
+
+// flavourNetworkFields contains the names of the fields of the 'network' object.
+type flavourNetworkFields struct {
+	HostPrefix  search.Field
+	MachineCIDR search.Field
+	PodCIDR     search.Field
+	ServiceCIDR search.Field
+	Type        search.Field
+}
+
+// flavourNodesFields contains the names of the fields of the 'nodes' object.
+type flavourNodesFields struct {
+	Master search.Field
+}
+
+// machineTypeFields is the type of the MachineType variable.
+type machineTypeFields struct {
+	Architecture  search.Field
+	CCSOnly       search.Field
+	CPU           machineTypeCPUFields
+	Category      search.Field
+	CloudProvider machineTypeCloudProviderFields
+	GenericName   search.Field
+	ID            search.Field
+	Memory        machineTypeMemoryFields
+	Name          search.Field
+	Size          search.Field
+}
+
+// machineTypeCPUFields contains the names of the fields of the 'cpu' object.
+type machineTypeCPUFields struct {
+	Unit  search.Field
+	Value search.Field
+}
+
+// machineTypeCloudProviderFields contains the names of the fields of the 'cloud_provider' object.
+type machineTypeCloudProviderFields struct {
+	DisplayName search.Field
+	ID          search.Field
+	Name        search.Field
+}
+
+// machineTypeMemoryFields contains the names of the fields of the 'memory' object.
+type machineTypeMemoryFields struct {
+	Unit  search.Field
+	Value search.Field
+}
+
+// nodePoolFields is the type of the NodePool variable.
+type nodePoolFields struct {
+	AWSNodePool          nodePoolAWSNodePoolFields
+	AutoRepair           search.Field
+	Autoscaling          nodePoolAutoscalingFields
+	AvailabilityZone     search.Field
+	AzureNodePool        nodePoolAzureNodePoolFields
+	ID                   search.Field
+	ManagementUpgrade    nodePoolManagementUpgradeFields
+	NodeDrainGracePeriod nodePoolNodeDrainGracePeriodFields
+	Replicas             search.Field
+	Status               nodePoolStatusFields
+	Subnet               search.Field
+	Version              nodePoolVersionFields
+}
+
+// nodePoolAWSNodePoolFields contains the names of the fields of the 'aws_node_pool' object.
+type nodePoolAWSNodePoolFields struct {
+	Ec2MetadataHTTPTokens search.Field
+	ID                    search.Field
+	InstanceProfile       search.Field
+	InstanceType          search.Field
+	RootVolume            nodePoolAWSNodePoolRootVolumeFields
+}
+
+// nodePoolAWSNodePoolRootVolumeFields contains the names of the fields of the 'aws_node_pool.root_volume' object.
+type nodePoolAWSNodePoolRootVolumeFields struct {
+	Iops search.Field
+	Size search.Field
+}
+
+// nodePoolAutoscalingFields contains the names of the fields of the 'autoscaling' object.
+type nodePoolAutoscalingFields struct {
+	ID         search.Field
+	MaxReplica search.Field
+	MinReplica search.Field
+}
+
+// nodePoolAzureNodePoolFields contains the names of the fields of the 'azure_node_pool' object.
+type nodePoolAzureNodePoolFields struct {
+	EphemeralOsDiskEnabled   search.Field
+	OsDiskSizeGibibytes      search.Field
+	OsDiskStorageAccountType search.Field
+	ResourceName             search.Field
+	VmSize                   search.Field
+}
+
+// nodePoolManagementUpgradeFields contains the names of the fields of the 'management_upgrade' object.
+type nodePoolManagementUpgradeFields struct {
+	ID             search.Field
+	MaxSurge       search.Field
+	MaxUnavailable search.Field
+	Type           search.Field
+}
+
+// nodePoolNodeDrainGracePeriodFields contains the names of the fields of the 'node_drain_grace_period' object.
+type nodePoolNodeDrainGracePeriodFields struct {
+	Unit  search.Field
+	Value search.Field
+}
+
+// nodePoolStatusFields contains the names of the fields of the 'status' object.
+type nodePoolStatusFields struct {
+	CurrentReplicas search.Field
+	ID              search.Field
+	Message         search.Field
+}
+
+// nodePoolVersionFields contains the names of the fields of the 'version' object.
+type nodePoolVersionFields struct {
+	ChannelGroup              search.Field
+	Default                   search.Field
+	Enabled                   search.Field
+	EndOfLifeTimestamp        search.Field
+	GCPMarketplaceEnabled     search.Field
+	HostedControlPlaneDefault search.Field
+	HostedControlPlaneEnabled search.Field
+	ID                        search.Field
+	ImageOverrides            nodePoolVersionImageOverridesFields
+	RawID                     search.Field
+	ReleaseImage              search.Field
+	RosaEnabled               search.Field
+}
+
+// nodePoolVersionImageOverridesFields contains the names of the fields of the 'version.image_overrides' object.
+type nodePoolVersionImageOverridesFields struct {
+	ID search.Field
+}
+
+// pendingDeleteClusterFields is the type of the PendingDeleteCluster variable.
+type pendingDeleteClusterFields struct {
+	BestEffort        search.Field
+	Cluster           pendingDeleteClusterClusterFields
+	CreationTimestamp search.Field
+	ID                search.Field
+}
+
+// pendingDeleteClusterClusterFields contains the names of the fields of the 'cluster' object.
+type pendingDeleteClusterClusterFields struct {
+	API                           pendingDeleteClusterClusterAPIFields
+	AWS                           pendingDeleteClusterClusterAWSFields
+	AdditionalTrustBundle         search.Field
+	Autoscaler                    pendingDeleteClusterClusterAutoscalerFields
+	Azure                         pendingDeleteClusterClusterAzureFields
+	BillingModel                  search.Field
+	ByoOIDC                       pendingDeleteClusterClusterByoOIDCFields
+	CCS                           pendingDeleteClusterClusterCCSFields
+	CloudProvider                 pendingDeleteClusterClusterCloudProviderFields
+	Console                       pendingDeleteClusterClusterConsoleFields
+	CreationTimestamp             search.Field
+	DNS                           pendingDeleteClusterClusterDNSFields
+	DeleteProtection              pendingDeleteClusterClusterDeleteProtectionFields
+	DisableUserWorkloadMonitoring search.Field
+	DomainPrefix                  search.Field
+	EtcdEncryption                search.Field
+	ExpirationTimestamp           search.Field
+	ExternalAuthConfig            pendingDeleteClusterClusterExternalAuthConfigFields
+	ExternalID                    search.Field
+	FIPS                          search.Field
+	Flavour                       pendingDeleteClusterClusterFlavourFields
+	GCP                           pendingDeleteClusterClusterGCPFields
+	GCPEncryptionKey              pendingDeleteClusterClusterGCPEncryptionKeyFields
+	GCPNetwork                    pendingDeleteClusterClusterGCPNetworkFields
+	HealthState                   search.Field
+	Htpasswd                      pendingDeleteClusterClusterHtpasswdFields
+	Hypershift                    pendingDeleteClusterClusterHypershiftFields
+	ID                            search.Field
+	InfraID                       search.Field
+	KubeletConfig                 pendingDeleteClusterClusterKubeletConfigFields
+	LoadBalancerQuota             search.Field
+	Managed                       search.Field
+	ManagedService                pendingDeleteClusterClusterManagedServiceFields
+	MultiAZ                       search.Field
+	MultiArchEnabled              search.Field
+	Name                          search.Field
+	Network                       pendingDeleteClusterClusterNetworkFields
+	NodeDrainGracePeriod          pendingDeleteClusterClusterNodeDrainGracePeriodFields
+	Nodes                         pendingDeleteClusterClusterNodesFields
+	OpenshiftVersion              search.Field
+	Product                       pendingDeleteClusterClusterProductFields
+	ProvisionShard                pendingDeleteClusterClusterProvisionShardFields
+	Proxy                         pendingDeleteClusterClusterProxyFields
+	Region                        pendingDeleteClusterClusterRegionFields
+	State                         search.Field
+	Status                        pendingDeleteClusterClusterStatusFields
+	StorageQuota                  pendingDeleteClusterClusterStorageQuotaFields
+	Subscription                  pendingDeleteClusterClusterSubscriptionFields
+	Version                       pendingDeleteClusterClusterVersionFields
+}
+
+// pendingDeleteClusterClusterAPIFields contains the names of the fields of the 'cluster.api' object.
+type pendingDeleteClusterClusterAPIFields struct {
+	Listening search.Field
+	URL       search.Field
+}
+
+// pendingDeleteClusterClusterAWSFields contains the names of the fields of the 'cluster.aws' object.
+type pendingDeleteClusterClusterAWSFields struct {
+	AccessKeyID              search.Field
+	AccountID                search.Field
+	BillingAccountID         search.Field
+	Ec2MetadataHTTPTokens    search.Field
+	KMSKeyARN                search.Field
+	PrivateHostedZoneID      search.Field
+	PrivateHostedZoneRoleARN search.Field
+	PrivateLink              search.Field
+	SecretAccessKey          search.Field
+}
+
+// pendingDeleteClusterClusterAutoscalerFields contains the names of the fields of the 'cluster.autoscaler' object.
+type pendingDeleteClusterClusterAutoscalerFields struct {
+	BalanceSimilarNodeGroups    search.Field
+	ID                          search.Field
+	IgnoreDaemonsetsUtilization search.Field
+	LogVerbosity                search.Field
+	MaxNodeProvisionTime        search.Field
+	MaxPodGracePeriod           search.Field
+	PodPriorityThreshold        search.Field
+	SkipNodesWithLocalStorage   search.Field
+}
+
+// pendingDeleteClusterClusterAzureFields contains the names of the fields of the 'cluster.azure' object.
+type pendingDeleteClusterClusterAzureFields struct {
+	ManagedResourceGroupName       search.Field
+	NetworkSecurityGroupResourceID search.Field
+	ResourceGroupName              search.Field
+	ResourceName                   search.Field
+	SubnetResourceID               search.Field
+	SubscriptionID                 search.Field
+	TenantID                       search.Field
+}
+
+// pendingDeleteClusterClusterByoOIDCFields contains the names of the fields of the 'cluster.byo_oidc' object.
+type pendingDeleteClusterClusterByoOIDCFields struct {
+	Enabled search.Field
+}
+
+// pendingDeleteClusterClusterCCSFields contains the names of the fields of the 'cluster.ccs' object.
+type pendingDeleteClusterClusterCCSFields struct {
+	DisableScpChecks search.Field
+	Enabled          search.Field
+	ID               search.Field
+}
+
+// pendingDeleteClusterClusterCloudProviderFields contains the names of the fields of the 'cluster.cloud_provider' object.
+type pendingDeleteClusterClusterCloudProviderFields struct {
+	DisplayName search.Field
+	ID          search.Field
+	Name        search.Field
+}
+
+// pendingDeleteClusterClusterConsoleFields contains the names of the fields of the 'cluster.console' object.
+type pendingDeleteClusterClusterConsoleFields struct {
+	URL search.Field
+}
+
+// pendingDeleteClusterClusterDNSFields contains the names of the fields of the 'cluster.dns' object.
+type pendingDeleteClusterClusterDNSFields struct {
+	BaseDomain search.Field
+}
+
+// pendingDeleteClusterClusterDeleteProtectionFields contains the names of the fields of the 'cluster.delete_protection' object.
+type pendingDeleteClusterClusterDeleteProtectionFields struct {
+	Enabled search.Field
+}
+
+// pendingDeleteClusterClusterExternalAuthConfigFields contains the names of the fields of the 'cluster.external_auth_config' object.
+type pendingDeleteClusterClusterExternalAuthConfigFields struct {
+	Enabled search.Field
+}
+
+// pendingDeleteClusterClusterFlavourFields contains the names of the fields of the 'cluster.flavour' object.
+type pendingDeleteClusterClusterFlavourFields struct {
+	ID   search.Field
+	Name search.Field
+}
+
+// pendingDeleteClusterClusterGCPFields contains the names of the fields of the 'cluster.gcp' object.
+type pendingDeleteClusterClusterGCPFields struct {
+	AuthProviderX509CertURL search.Field
+	AuthUri                 search.Field
+	ClientEmail             search.Field
+	ClientID                search.Field
+	ClientX509CertURL       search.Field
+	PrivateKey              search.Field
+	PrivateKeyID            search.Field
+	ProjectID               search.Field
+	TokenUri                search.Field
+	Type                    search.Field
+}
+
+// pendingDeleteClusterClusterGCPEncryptionKeyFields contains the names of the fields of the 'cluster.gcp_encryption_key' object.
+type pendingDeleteClusterClusterGCPEncryptionKeyFields struct {
+	KMSKeyServiceAccount search.Field
+	KeyLocation          search.Field
+	KeyName              search.Field
+	KeyRing              search.Field
+}
+
+// pendingDeleteClusterClusterGCPNetworkFields contains the names of the fields of the 'cluster.gcp_network' object.
+type pendingDeleteClusterClusterGCPNetworkFields struct {
+	ComputeSubnet      search.Field
+	ControlPlaneSubnet search.Field
+	VPCName            search.Field
+	VPCProjectID       search.Field
+}
+
+// pendingDeleteClusterClusterHtpasswdFields contains the names of the fields of the 'cluster.htpasswd' object.
+type pendingDeleteClusterClusterHtpasswdFields struct {
+	Password search.Field
+	Username search.Field
+}
+
+// pendingDeleteClusterClusterHypershiftFields contains the names of the fields of the 'cluster.hypershift' object.
+type pendingDeleteClusterClusterHypershiftFields struct {
+	Enabled search.Field
+}
+
+// pendingDeleteClusterClusterKubeletConfigFields contains the names of the fields of the 'cluster.kubelet_config' object.
+type pendingDeleteClusterClusterKubeletConfigFields struct {
+	ID           search.Field
+	Name         search.Field
+	PodPidsLimit search.Field
+}
+
+// pendingDeleteClusterClusterManagedServiceFields contains the names of the fields of the 'cluster.managed_service' object.
+type pendingDeleteClusterClusterManagedServiceFields struct {
+	Enabled search.Field
+}
+
+// pendingDeleteClusterClusterNetworkFields contains the names of the fields of the 'cluster.network' object.
+type pendingDeleteClusterClusterNetworkFields struct {
+	HostPrefix  search.Field
+	MachineCIDR search.Field
+	PodCIDR     search.Field
+	ServiceCIDR search.Field
+	Type        search.Field
+}
+
+// pendingDeleteClusterClusterNodeDrainGracePeriodFields contains the names of the fields of the 'cluster.node_drain_grace_period' object.
+type pendingDeleteClusterClusterNodeDrainGracePeriodFields struct {
+	Unit  search.Field
+	Value search.Field
+}
+
+// pendingDeleteClusterClusterNodesFields contains the names of the fields of the 'cluster.nodes' object.
+type pendingDeleteClusterClusterNodesFields struct {
+	Compute search.Field
+	Infra   search.Field
+	Master  search.Field
+	Total   search.Field
+}
+
+// pendingDeleteClusterClusterProductFields contains the names of the fields of the 'cluster.product' object.
+type pendingDeleteClusterClusterProductFields struct {
+	ID   search.Field
+	Name search.Field
+}
+
+// pendingDeleteClusterClusterProvisionShardFields contains the names of the fields of the 'cluster.provision_shard' object.
+type pendingDeleteClusterClusterProvisionShardFields struct {
+	AWSBaseDomain       search.Field
+	CreationTimestamp   search.Field
+	GCPBaseDomain       search.Field
+	ID                  search.Field
+	LastUpdateTimestamp search.Field
+	ManagementCluster   search.Field
+	Status              search.Field
+}
+
+// pendingDeleteClusterClusterProxyFields contains the names of the fields of the 'cluster.proxy' object.
+type pendingDeleteClusterClusterProxyFields struct {
+	HTTPProxy  search.Field
+	HTTPSProxy search.Field
+	NoProxy    search.Field
+}
+
+// pendingDeleteClusterClusterRegionFields contains the names of the fields of the 'cluster.region' object.
+type pendingDeleteClusterClusterRegionFields struct {
+	CCSOnly            search.Field
+	DisplayName        search.Field
+	Enabled            search.Field
+	Govcloud           search.Field
+	ID                 search.Field
+	KMSLocationID      search.Field
+	KMSLocationName    search.Field
+	Name               search.Field
+	SupportsHypershift search.Field
+	SupportsMultiAZ    search.Field
+}
+
+// pendingDeleteClusterClusterStatusFields contains the names of the fields of the 'cluster.status' object.
+type pendingDeleteClusterClusterStatusFields struct {
+	ConfigurationMode         search.Field
+	CurrentCompute            search.Field
+	DNSReady                  search.Field
+	Description               search.Field
+	ID                        search.Field
+	LimitedSupportReasonCount search.Field
+	OIDCReady                 search.Field
+	ProvisionErrorCode        search.Field
+	ProvisionErrorMessage     search.Field
+	State                     search.Field
+}
+
+// pendingDeleteClusterClusterStorageQuotaFields contains the names of the fields of the 'cluster.storage_quota' object.
+type pendingDeleteClusterClusterStorageQuotaFields struct {
+	Unit  search.Field
+	Value search.Field
+}
+
+// pendingDeleteClusterClusterSubscriptionFields contains the names of the fields of the 'cluster.subscription' object.
+type pendingDeleteClusterClusterSubscriptionFields struct {
+	ID search.Field
+}
+
+// pendingDeleteClusterClusterVersionFields contains the names of the fields of the 'cluster.version' object.
+type pendingDeleteClusterClusterVersionFields struct {
+	ChannelGroup              search.Field
+	Default                   search.Field
+	Enabled                   search.Field
+	EndOfLifeTimestamp        search.Field
+	GCPMarketplaceEnabled     search.Field
+	HostedControlPlaneDefault search.Field
+	HostedControlPlaneEnabled search.Field
+	ID                        search.Field
+	RawID                     search.Field
+	ReleaseImage              search.Field
+	RosaEnabled               search.Field
+}
+
+// privateLinkPrincipalFields is the type of the PrivateLinkPrincipal variable.
+type privateLinkPrincipalFields struct {
+	ID        search.Field
+	Principal search.Field
+}
+
+// productFields is the type of the Product variable.
+type productFields struct {
+	ID   search.Field
+	Name search.Field
+}
+
+// productMinimalVersionFields is the type of the ProductMinimalVersion variable.
+type productMinimalVersionFields struct {
+	ID        search.Field
+	RosaCli   search.Field
+	StartDate search.Field
+}
+
+// productTechnologyPreviewFields is the type of the ProductTechnologyPreview variable.
+type productTechnologyPreviewFields struct {
+	AdditionalText search.Field
+	EndDate        search.Field
+	ID             search.Field
+	StartDate      search.Field
+}
+
+// provisionShardFields is the type of the ProvisionShard variable.
+type provisionShardFields struct {
+	AWSAccountOperatorConfig provisionShardAWSAccountOperatorConfigFields
+	AWSBaseDomain            search.Field
+	CloudProvider            provisionShardCloudProviderFields
+	CreationTimestamp        search.Field
+	GCPBaseDomain            search.Field
+	GCPProjectOperator       provisionShardGCPProjectOperatorFields
+	HiveConfig               provisionShardHiveConfigFields
+	HypershiftConfig         provisionShardHypershiftConfigFields
+	ID                       search.Field
+	LastUpdateTimestamp      search.Field
+	ManagementCluster        search.Field
+	Region                   provisionShardRegionFields
+	Status                   search.Field
+}
+
+// provisionShardAWSAccountOperatorConfigFields contains the names of the fields of the 'aws_account_operator_config' object.
+type provisionShardAWSAccountOperatorConfigFields struct {
+	ID         search.Field
+	Kubeconfig search.Field
+	Server     search.Field
+	Topology   search.Field
+}
+
+// provisionShardCloudProviderFields contains the names of the fields of the 'cloud_provider' object.
+type provisionShardCloudProviderFields struct {
+	DisplayName search.Field
+	ID          search.Field
+	Name        search.Field
+}
+
+// provisionShardGCPProjectOperatorFields contains the names of the fields of the 'gcp_project_operator' object.
+type provisionShardGCPProjectOperatorFields struct {
+	ID         search.Field
+	Kubeconfig search.Field
+	Server     search.Field
+	Topology   search.Field
+}
+
+// provisionShardHiveConfigFields contains the names of the fields of the 'hive_config' object.
+type provisionShardHiveConfigFields struct {
+	ID         search.Field
+	Kubeconfig search.Field
+	Server     search.Field
+	Topology   search.Field
+}
+
+// provisionShardHypershiftConfigFields contains the names of the fields of the 'hypershift_config' object.
+type provisionShardHypershiftConfigFields struct {
+	ID         search.Field
+	Kubeconfig search.Field
+	Server     search.Field
+	Topology   search.Field
+}
+
+// provisionShardRegionFields contains the names of the fields of the 'region' object.
+type provisionShardRegionFields struct {
+	CCSOnly            search.Field
+	CloudProvider      provisionShardRegionCloudProviderFields
+	DisplayName        search.Field
+	Enabled            search.Field
+	Govcloud           search.Field
+	ID                 search.Field
+	KMSLocationID      search.Field
+	KMSLocationName    search.Field
+	Name               search.Field
+	SupportsHypershift search.Field
+	SupportsMultiAZ    search.Field
+}
+
+// provisionShardRegionCloudProviderFields contains the names of the fields of the 'region.cloud_provider' object.
+type provisionShardRegionCloudProviderFields struct {
+	DisplayName search.Field
+	ID          search.Field
+	Name        search.Field
+}
+
+// versionFields is the type of the Version variable.
+type versionFields struct {
+	ChannelGroup              search.Field
+	Default                   search.Field
+	Enabled                   search.Field
+	EndOfLifeTimestamp        search.Field
+	GCPMarketplaceEnabled     search.Field
+	HostedControlPlaneDefault search.Field
+	HostedControlPlaneEnabled search.Field
+	ID                        search.Field
+	ImageOverrides            versionImageOverridesFields
+	RawID                     search.Field
+	ReleaseImage              search.Field
+	ReleaseImages             versionReleaseImagesFields
+	RosaEnabled               search.Field
+}
+
+// versionImageOverridesFields contains the names of the fields of the 'image_overrides' object.
+type versionImageOverridesFields struct {
+	ID search.Field
+}
+
+// versionReleaseImagesFields contains the names of the fields of the 'release_images' object.
+type versionReleaseImagesFields struct {
+	Arm64 versionReleaseImagesArm64Fields
+	Multi versionReleaseImagesMultiFields
+}
+
+// versionReleaseImagesArm64Fields contains the names of the fields of the 'release_images.arm64' object.
+type versionReleaseImagesArm64Fields struct {
+	ReleaseImage search.Field
+}
+
+// versionReleaseImagesMultiFields contains the names of the fields of the 'release_images.multi' object.
+type versionReleaseImagesMultiFields struct {
+	ReleaseImage search.Field
+}
+
+// versionGateFields is the type of the VersionGate variable.
+type versionGateFields struct {
+	CreationTimestamp  search.Field
+	Description        search.Field
+	DocumentationURL   search.Field
+	ID                 search.Field
+	Label              search.Field
+	STSOnly            search.Field
+	Value              search.Field
+	VersionRawIDPrefix search.Field
+	WarningMessage     search.Field
+}
+
+// wifConfigFields is the type of the WifConfig variable.
+type wifConfigFields struct {
+	DisplayName  search.Field
+	GCP          wifConfigGCPFields
+	ID           search.Field
+	Organization wifConfigOrganizationFields
+}
+
+// wifConfigGCPFields contains the names of the fields of the 'gcp' object.
+type wifConfigGCPFields struct {
+	ImpersonatorEmail    search.Field
+	ProjectID            search.Field
+	ProjectNumber        search.Field
+	RolePrefix           search.Field
+	WorkloadIdentityPool wifConfigGCPWorkloadIdentityPoolFields
+}
+
+// wifConfigGCPWorkloadIdentityPoolFields contains the names of the fields of the 'gcp.workload_identity_pool' object.
+type wifConfigGCPWorkloadIdentityPoolFields struct {
+	PoolID   search.Field
+	PoolName search.Field
+}
+
+// wifConfigOrganizationFields contains the names of the fields of the 'organization' object.
+type wifConfigOrganizationFields struct {
+	ID search.Field
+}
@@ -0,0 +1,23 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package search contains types and functions that simplify building the expressions used in the
+// `search` parameter of the list methods, making sure that values are correctly quoted. The
+// sub-packages contain the names of the fields of the types of the model, generated from the
+// OpenAPI specifications, so that typos in field names are detected at compile time.
+package search
+
+//go:generate go run ./internal/generator --openapi ../openapi --output .
@@ -0,0 +1,447 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the types and functions used to build search expressions.
+
+package search
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Expression is a search expression that can be rendered to the text accepted by the `search`
+// parameter of the list methods, for example clustersmgmt/v1.ClustersListRequest.Search. Use the
+// methods of the Field type and the And, Or and Not functions to create expressions. For example,
+// importing the field names of the clusters management service as `cmv1`:
+//
+//	expression := search.And(
+//		cmv1.Cluster.Name.Like("my%"),
+//		cmv1.Cluster.Region.ID.Eq("us-east-1"),
+//		cmv1.Cluster.Managed.Eq(true),
+//	)
+//	response, err := collection.List().
+//		Search(expression.String()).
+//		SendContext(ctx)
+//
+// That will send the following search string:
+//
+//	name like 'my%' and region.id = 'us-east-1' and managed = 't'
+type Expression interface {
+	// String renders the expression.
+	String() string
+
+	// precedence returns the precedence of the top level operator of the expression. It is used
+	// to decide when sub expressions need to be enclosed in parenthesis.
+	precedence() int
+}
+
+// Precedences of the operators, from lower to higher:
+const (
+	orPrecedence = iota
+	andPrecedence
+	notPrecedence
+	comparisonPrecedence
+)
+
+// Field is the name of a field that can be used in search expressions, for example `name` or
+// `region.id`. The names of the fields of the types of the model are available in the sub-packages
+// of this package, for example clustersmgmt/v1.Cluster.Region.ID.
+type Field string
+
+// String returns the name of the field.
+func (f Field) String() string {
+	return string(f)
+}
+
+// Eq creates an expression that checks if the field is equal to the given value. If the value is
+// nil, including nil pointers, the result is the same as IsNull.
+func (f Field) Eq(value interface{}) Expression {
+	if isNil(value) {
+		return f.IsNull()
+	}
+	return f.compare("=", value)
+}
+
+// Ne creates an expression that checks if the field is not equal to the given value. If the value
+// is nil, including nil pointers, the result is the same as IsNotNull.
+func (f Field) Ne(value interface{}) Expression {
+	if isNil(value) {
+		return f.IsNotNull()
+	}
+	return f.compare("!=", value)
+}
+
+// Lt creates an expression that checks if the field is less than the given value.
+func (f Field) Lt(value interface{}) Expression {
+	return f.compare("<", value)
+}
+
+// Le creates an expression that checks if the field is less or equal than the given value.
+func (f Field) Le(value interface{}) Expression {
+	return f.compare("<=", value)
+}
+
+// Gt creates an expression that checks if the field is greater than the given value.
+func (f Field) Gt(value interface{}) Expression {
+	return f.compare(">", value)
+}
+
+// Ge creates an expression that checks if the field is greater or equal than the given value.
+func (f Field) Ge(value interface{}) Expression {
+	return f.compare(">=", value)
+}
+
+// Like creates an expression that checks if the field matches the given pattern. The pattern uses
+// the SQL syntax, where `%` matches any sequence of characters and `_` matches any single
+// character.
+func (f Field) Like(pattern string) Expression {
+	return f.compare("like", pattern)
+}
+
+// ILike is like Like, but ignoring case.
+func (f Field) ILike(pattern string) Expression {
+	return f.compare("ilike", pattern)
+}
+
+// In creates an expression that checks if the field is equal to any of the given values. Nil
+// values are ignored. When there are no values the expression renders as `1 = 0`, which is never
+// true.
+func (f Field) In(values ...interface{}) Expression {
+	values = removeNils(values)
+	if len(values) == 0 {
+		return &constantExpression{}
+	}
+	return &listExpression{
+		field:    f,
+		operator: "in",
+		values:   values,
+	}
+}
+
+// NotIn creates an expression that checks if the field isn't equal to any of the given values.
+// Nil values are ignored. When there are no values the expression renders as `1 = 1`, which is
+// always true.
+func (f Field) NotIn(values ...interface{}) Expression {
+	values = removeNils(values)
+	if len(values) == 0 {
+		return &constantExpression{
+			value: true,
+		}
+	}
+	return &listExpression{
+		field:    f,
+		operator: "not in",
+		values:   values,
+	}
+}
+
+// IsNull creates an expression that checks if the field doesn't have a value.
+func (f Field) IsNull() Expression {
+	return &nullExpression{
+		field: f,
+	}
+}
+
+// IsNotNull creates an expression that checks if the field has a value.
+func (f Field) IsNotNull() Expression {
+	return &nullExpression{
+		field:  f,
+		negate: true,
+	}
+}
+
+// compare creates a comparison expression. Comparisons with nil values are never true, so in that
+// case it returns an expression that renders as `1 = 0` instead of a comparison with `null`.
+func (f Field) compare(operator string, value interface{}) Expression {
+	if isNil(value) {
+		return &constantExpression{}
+	}
+	return &comparisonExpression{
+		field:    f,
+		operator: operator,
+		value:    value,
+	}
+}
+
+// And creates an expression that is true when all the given expressions are true. Nil expressions
+// and expressions that render as empty strings are ignored, so it is safe to use this to
+// conditionally add criteria. If there are no expressions the result renders as an empty string,
+// which the server interprets as no search criteria.
+func And(expressions ...Expression) Expression {
+	return &junctionExpression{
+		operator:    "and",
+		level:       andPrecedence,
+		expressions: expressions,
+	}
+}
+
+// Or creates an expression that is true when any of the given expressions is true. Nil
+// expressions and expressions that render as empty strings are ignored.
+func Or(expressions ...Expression) Expression {
+	return &junctionExpression{
+		operator:    "or",
+		level:       orPrecedence,
+		expressions: expressions,
+	}
+}
+
+// Not creates an expression that is true when the given expression is false.
+func Not(expression Expression) Expression {
+	return &notExpression{
+		expression: expression,
+	}
+}
+
+// Raw creates an expression from the given text, without any check or escaping. This is intended
+// for constructs that aren't supported by the rest of the functions of this package. The text is
+// always enclosed in parenthesis when combined with other expressions.
+func Raw(text string) Expression {
+	return &rawExpression{
+		text: text,
+	}
+}
+
+// Quote renders the given value as a literal that can be used in a search expression. Strings are
+// enclosed in single quotes, doubling the single quotes that they contain. Booleans are rendered
+// as `'t'` and `'f'`. Times are converted to UTC and rendered as quoted RFC3339 strings. Numbers
+// are rendered without quotes. Fields are rendered as their names, so that they can be compared
+// to other fields. Nil values, including nil pointers, are rendered as `null`. Any other type is
+// converted to a string using the fmt.Sprintf function and then quoted.
+func Quote(value interface{}) string {
+	if isNil(value) {
+		return "null"
+	}
+	switch typed := value.(type) {
+	case string:
+		return quoteString(typed)
+	case Field:
+		return typed.String()
+	case bool:
+		if typed {
+			return "'t'"
+		}
+		return "'f'"
+	case time.Time:
+		return quoteString(typed.UTC().Format(time.RFC3339))
+	case *time.Time:
+		return Quote(*typed)
+	case int:
+		return strconv.FormatInt(int64(typed), 10)
+	case int8:
+		return strconv.FormatInt(int64(typed), 10)
+	case int16:
+		return strconv.FormatInt(int64(typed), 10)
+	case int32:
+		return strconv.FormatInt(int64(typed), 10)
+	case int64:
+		return strconv.FormatInt(typed, 10)
+	case uint:
+		return strconv.FormatUint(uint64(typed), 10)
+	case uint8:
+		return strconv.FormatUint(uint64(typed), 10)
+	case uint16:
+		return strconv.FormatUint(uint64(typed), 10)
+	case uint32:
+		return strconv.FormatUint(uint64(typed), 10)
+	case uint64:
+		return strconv.FormatUint(typed, 10)
+	case float32:
+		return strconv.FormatFloat(float64(typed), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(typed, 'f', -1, 64)
+	case fmt.Stringer:
+		return quoteString(typed.String())
+	default:
+		return quoteString(fmt.Sprintf("%v", value))
+	}
+}
+
+func quoteString(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// isNil checks if the given value is nil or a nil pointer, map, slice or interface.
+func isNil(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	reflected := reflect.ValueOf(value)
+	switch reflected.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface, reflect.Func, reflect.Chan:
+		return reflected.IsNil()
+	default:
+		return false
+	}
+}
+
+// removeNils returns a copy of the given values without the nil ones.
+func removeNils(values []interface{}) []interface{} {
+	var result []interface{}
+	for _, value := range values {
+		if !isNil(value) {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// comparisonExpression is the implementation of binary comparison expressions like `name = 'my'`.
+type comparisonExpression struct {
+	field    Field
+	operator string
+	value    interface{}
+}
+
+func (e *comparisonExpression) String() string {
+	return fmt.Sprintf("%s %s %s", e.field, e.operator, Quote(e.value))
+}
+
+func (e *comparisonExpression) precedence() int {
+	return comparisonPrecedence
+}
+
+// listExpression is the implementation of the `in` and `not in` expressions.
+type listExpression struct {
+	field    Field
+	operator string
+	values   []interface{}
+}
+
+func (e *listExpression) String() string {
+	items := make([]string, len(e.values))
+	for i, value := range e.values {
+		items[i] = Quote(value)
+	}
+	return fmt.Sprintf("%s %s (%s)", e.field, e.operator, strings.Join(items, ", "))
+}
+
+func (e *listExpression) precedence() int {
+	return comparisonPrecedence
+}
+
+// constantExpression is the implementation of expressions that are always true or always false.
+type constantExpression struct {
+	value bool
+}
+
+func (e *constantExpression) String() string {
+	if e.value {
+		return "1 = 1"
+	}
+	return "1 = 0"
+}
+
+func (e *constantExpression) precedence() int {
+	return comparisonPrecedence
+}
+
+// nullExpression is the implementation of the `is null` and `is not null` expressions.
+type nullExpression struct {
+	field  Field
+	negate bool
+}
+
+func (e *nullExpression) String() string {
+	if e.negate {
+		return fmt.Sprintf("%s is not null", e.field)
+	}
+	return fmt.Sprintf("%s is null", e.field)
+}
+
+func (e *nullExpression) precedence() int {
+	return comparisonPrecedence
+}
+
+// junctionExpression is the implementation of the `and` and `or` expressions.
+type junctionExpression struct {
+	operator    string
+	level       int
+	expressions []Expression
+}
+
+func (e *junctionExpression) String() string {
+	var items []string
+	for _, expression := range e.expressions {
+		if expression == nil {
+			continue
+		}
+		text := expression.String()
+		if text == "" {
+			continue
+		}
+		if expression.precedence() < e.level {
+			text = "(" + text + ")"
+		}
+		items = append(items, text)
+	}
+	return strings.Join(items, " "+e.operator+" ")
+}
+
+func (e *junctionExpression) precedence() int {
+	// A junction that contains only one non empty expression renders as that expression, so
+	// we need to return its precedence in order to avoid unnecessary parenthesis:
+	var last Expression
+	count := 0
+	for _, expression := range e.expressions {
+		if expression != nil && expression.String() != "" {
+			last = expression
+			count++
+		}
+	}
+	if count == 1 {
+		return last.precedence()
+	}
+	return e.level
+}
+
+// notExpression is the implementation of the `not` expression.
+type notExpression struct {
+	expression Expression
+}
+
+func (e *notExpression) String() string {
+	if e.expression == nil {
+		return ""
+	}
+	text := e.expression.String()
+	if text == "" {
+		return ""
+	}
+	return "not (" + text + ")"
+}
+
+func (e *notExpression) precedence() int {
+	return notPrecedence
+}
+
+// rawExpression is the implementation of expressions created from arbitrary text.
+type rawExpression struct {
+	text string
+}
+
+func (e *rawExpression) String() string {
+	return e.text
+}
+
+func (e *rawExpression) precedence() int {
+	// Raw text can contain any operator, so we use the lowest precedence to make sure that it
+	// is always enclosed in parenthesis when combined with other expressions.
+	return orPrecedence - 1
+}
@@ -0,0 +1,174 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for search expressions.
+
+package search_test
+
+import (
+	"time"
+
+	"github.com/openshift-online/ocm-sdk-go/search"
+	amv1 "github.com/openshift-online/ocm-sdk-go/search/accountsmgmt/v1"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/search/clustersmgmt/v1"
+	slv1 "github.com/openshift-online/ocm-sdk-go/search/servicelogs/v1"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"  // nolint
+	. "github.com/onsi/ginkgo/v2/dsl/table" // nolint
+	. "github.com/onsi/gomega"              // nolint
+)
+
+var _ = Describe("Expression", func() {
+	DescribeTable("Quoting",
+		func(value interface{}, expected string) {
+			Expect(search.Quote(value)).To(Equal(expected))
+		},
+		Entry("String", "my", "'my'"),
+		Entry("Empty string", "", "''"),
+		Entry("String with quote", "it's", "'it''s'"),
+		Entry("String with several quotes", "'a''b'", "'''a''''b'''"),
+		Entry("True", true, "'t'"),
+		Entry("False", false, "'f'"),
+		Entry("Integer", 42, "42"),
+		Entry("Negative integer", int64(-42), "-42"),
+		Entry("Unsigned integer", uint8(7), "7"),
+		Entry("Float", 1.5, "1.5"),
+		Entry(
+			"Time",
+			time.Date(2024, 1, 2, 3, 4, 5, 0, time.FixedZone("CET", 3600)),
+			"'2024-01-02T02:04:05Z'",
+		),
+		Entry("Nil time", (*time.Time)(nil), "null"),
+		Entry("Nil", nil, "null"),
+		Entry("Field", search.Field("other"), "other"),
+		Entry("Duration", time.Minute, "'1m0s'"),
+	)
+
+	DescribeTable("Rendering",
+		func(expression search.Expression, expected string) {
+			Expect(expression.String()).To(Equal(expected))
+		},
+		Entry("Equal", search.Field("name").Eq("my"), "name = 'my'"),
+		Entry("Not equal", search.Field("name").Ne("my"), "name != 'my'"),
+		Entry("Less than", search.Field("size").Lt(1), "size < 1"),
+		Entry("Less or equal", search.Field("size").Le(1), "size <= 1"),
+		Entry("Greater than", search.Field("size").Gt(1), "size > 1"),
+		Entry("Greater or equal", search.Field("size").Ge(1), "size >= 1"),
+		Entry("Like", search.Field("name").Like("my%"), "name like 'my%'"),
+		Entry("Case insensitive like", search.Field("name").ILike("my%"), "name ilike 'my%'"),
+		Entry("In", search.Field("state").In("ready", "error"), "state in ('ready', 'error')"),
+		Entry("Not in", search.Field("state").NotIn("ready"), "state not in ('ready')"),
+		Entry("Empty in", search.Field("state").In(), "1 = 0"),
+		Entry("Empty not in", search.Field("state").NotIn(), "1 = 1"),
+		Entry("In with nil", search.Field("state").In("ready", nil), "state in ('ready')"),
+		Entry("Equal to nil", search.Field("region.id").Eq(nil), "region.id is null"),
+		Entry("Not equal to nil", search.Field("region.id").Ne(nil), "region.id is not null"),
+		Entry(
+			"Equal to nil time",
+			search.Field("expiration_timestamp").Eq((*time.Time)(nil)),
+			"expiration_timestamp is null",
+		),
+		Entry("Less than nil", search.Field("size").Lt(nil), "1 = 0"),
+		Entry("Is null", search.Field("region.id").IsNull(), "region.id is null"),
+		Entry("Is not null", search.Field("region.id").IsNotNull(), "region.id is not null"),
+		Entry("Nested field", search.Field("aws.sts.enabled").Eq(true), "aws.sts.enabled = 't'"),
+		Entry(
+			"Injection attempt",
+			search.Field("name").Eq("x' or '1' = '1"),
+			"name = 'x'' or ''1'' = ''1'",
+		),
+		Entry(
+			"And",
+			search.And(search.Field("a").Eq(1), search.Field("b").Eq(2)),
+			"a = 1 and b = 2",
+		),
+		Entry(
+			"Or",
+			search.Or(search.Field("a").Eq(1), search.Field("b").Eq(2)),
+			"a = 1 or b = 2",
+		),
+		Entry(
+			"search.Or inside and",
+			search.And(search.Field("a").Eq(1), search.Or(search.Field("b").Eq(2), search.Field("c").Eq(3))),
+			"a = 1 and (b = 2 or c = 3)",
+		),
+		Entry(
+			"search.And inside or",
+			search.Or(search.And(search.Field("a").Eq(1), search.Field("b").Eq(2)), search.Field("c").Eq(3)),
+			"a = 1 and b = 2 or c = 3",
+		),
+		Entry(
+			"Not",
+			search.Not(search.Field("a").Eq(1)),
+			"not (a = 1)",
+		),
+		Entry(
+			"search.Not inside and",
+			search.And(search.Not(search.Field("a").Eq(1)), search.Field("b").Eq(2)),
+			"not (a = 1) and b = 2",
+		),
+		Entry(
+			"Empty and",
+			search.And(),
+			"",
+		),
+		Entry(
+			"search.And ignores nil and empty",
+			search.And(nil, search.Field("a").Eq(1), search.And(), search.Not(nil)),
+			"a = 1",
+		),
+		Entry(
+			"Single item or inside and",
+			search.And(search.Or(search.Field("a").Eq(1)), search.Field("b").Eq(2)),
+			"a = 1 and b = 2",
+		),
+		Entry(
+			"Raw",
+			search.And(search.Raw("a = 1 or b = 2"), search.Field("c").Eq(3)),
+			"(a = 1 or b = 2) and c = 3",
+		),
+		Entry(
+			"Compare fields",
+			search.Field("a").Lt(search.Field("b")),
+			"a < b",
+		),
+	)
+
+	DescribeTable("Generated fields",
+		func(expression search.Expression, expected string) {
+			Expect(expression.String()).To(Equal(expected))
+		},
+		Entry(
+			"Cluster",
+			search.And(
+				cmv1.Cluster.Name.Like("my%"),
+				cmv1.Cluster.Region.ID.Eq("us-east-1"),
+				cmv1.Cluster.AWS.STS.Enabled.Eq(true),
+			),
+			"name like 'my%' and region.id = 'us-east-1' and aws.sts.enabled = 't'",
+		),
+		Entry(
+			"Subscription",
+			amv1.Subscription.Plan.ID.In("OSD", "ROSA"),
+			"plan.id in ('OSD', 'ROSA')",
+		),
+		Entry(
+			"Log entry",
+			slv1.LogEntry.Timestamp.Ge(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
+			"timestamp >= '2024-01-01T00:00:00Z'",
+		),
+	)
+})
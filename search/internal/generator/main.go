@@ -0,0 +1,431 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This program generates the constants containing the names of the fields that can be used in
+// search expressions. It reads the OpenAPI specifications generated from the model, finds the
+// types returned by the list methods that accept the `search` parameter, and writes one Go package
+// per service version containing a constant for each scalar field of those types, including the
+// fields of nested objects.
+//
+// It is intended to be executed with `go generate` from the directory of the `search` package.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// maxDepth is the maximum number of nested objects that will be traversed to generate field
+// names. For example, with a value of two the `aws.sts.enabled` field of the cluster will be
+// generated, but not any field with four segments.
+const maxDepth = 2
+
+// document contains the parts of the OpenAPI specification that we need.
+type document struct {
+	Paths      map[string]map[string]*operation `json:"paths"`
+	Components struct {
+		Schemas map[string]*schema `json:"schemas"`
+	} `json:"components"`
+}
+
+type operation struct {
+	Parameters []struct {
+		Name string `json:"name"`
+	} `json:"parameters"`
+	Responses map[string]struct {
+		Content map[string]struct {
+			Schema *schema `json:"schema"`
+		} `json:"content"`
+	} `json:"responses"`
+}
+
+type schema struct {
+	Ref        string             `json:"$ref"`
+	Type       string             `json:"type"`
+	Format     string             `json:"format"`
+	Items      *schema            `json:"items"`
+	Properties map[string]*schema `json:"properties"`
+}
+
+// node is a field of a type. Scalar fields are leafs of the tree, and nested objects contain the
+// fields of the object.
+type node struct {
+	name     string
+	path     string
+	typ      string
+	scalar   bool
+	children []*node
+}
+
+func main() {
+	var input, output string
+	flag.StringVar(&input, "openapi", "", "Directory containing the OpenAPI specifications.")
+	flag.StringVar(&output, "output", "", "Directory where the packages will be generated.")
+	flag.Parse()
+	if input == "" || output == "" {
+		fmt.Fprintf(os.Stderr, "Flags '--openapi' and '--output' are mandatory\n")
+		os.Exit(1)
+	}
+	err := run(input, output)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(input, output string) error {
+	specs, err := filepath.Glob(filepath.Join(input, "*", "*", "openapi.json"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(specs)
+	for _, spec := range specs {
+		version := filepath.Base(filepath.Dir(spec))
+		service := filepath.Base(filepath.Dir(filepath.Dir(spec)))
+		err = generate(spec, service, version, output)
+		if err != nil {
+			return fmt.Errorf("can't generate fields for '%s': %w", spec, err)
+		}
+	}
+	return nil
+}
+
+func generate(spec, service, version, output string) error {
+	data, err := os.ReadFile(spec) // #nosec G304
+	if err != nil {
+		return err
+	}
+	var doc document
+	err = json.Unmarshal(data, &doc)
+	if err != nil {
+		return err
+	}
+
+	// Find the types returned by the list methods that support searching:
+	searched := map[string]bool{}
+	for _, operations := range doc.Paths {
+		get, ok := operations["get"]
+		if !ok || !searchable(get) {
+			continue
+		}
+		response, ok := get.Responses["200"]
+		if !ok {
+			continue
+		}
+		content, ok := response.Content["application/json"]
+		if !ok || content.Schema == nil {
+			continue
+		}
+		items := content.Schema.Properties["items"]
+		if items == nil || items.Items == nil || items.Items.Ref == "" {
+			continue
+		}
+		searched[refName(items.Items.Ref)] = true
+	}
+	if len(searched) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(searched))
+	for name := range searched {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Calculate the fields of each type:
+	roots := make([]*node, len(names))
+	for i, name := range names {
+		roots[i] = &node{
+			name: goName(name),
+		}
+		collect(doc.Components.Schemas, name, roots[i], 0, map[string]bool{name: true})
+	}
+
+	// Generate the source:
+	pkg := strings.ReplaceAll(service, "_", "")
+	buffer := &bytes.Buffer{}
+	buffer.WriteString(header)
+	fmt.Fprintf(buffer, "package %s // github.com/openshift-online/ocm-sdk-go/search/%s/%s\n\n",
+		version, pkg, version)
+	fmt.Fprintf(buffer, "import (\n\t\"github.com/openshift-online/ocm-sdk-go/search\"\n)\n\n")
+	types := map[string]bool{}
+	for _, root := range roots {
+		root.typ = unexported(root.name) + "Fields"
+		types[root.typ] = true
+	}
+	for _, root := range roots {
+		for _, child := range root.children {
+			if !child.scalar {
+				assignTypes(child, strings.TrimSuffix(root.typ, "Fields")+child.name, types)
+			}
+		}
+	}
+	for i, root := range roots {
+		fmt.Fprintf(
+			buffer,
+			"// %s contains the names of the fields of the '%s' type that can be used\n"+
+				"// in search expressions.\n",
+			root.name, names[i],
+		)
+		fmt.Fprintf(buffer, "var %s = ", root.name)
+		writeValue(buffer, root)
+		fmt.Fprintf(buffer, "\n\n")
+	}
+	for _, root := range roots {
+		writeTypes(buffer, root)
+	}
+	source, err := format.Source(buffer.Bytes())
+	if err != nil {
+		return err
+	}
+	dir := filepath.Join(output, pkg, version)
+	err = os.MkdirAll(dir, 0755) // #nosec G301
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "fields.go"), source, 0644) // #nosec G306
+}
+
+// collect adds to the given node the fields of the given type, traversing nested objects.
+func collect(schemas map[string]*schema, typ string, parent *node, depth int, seen map[string]bool) {
+	current := schemas[typ]
+	if current == nil {
+		return
+	}
+	for property, value := range current.Properties {
+		// Objects and links always contain these, but they can't be used for searching:
+		if property == "kind" || property == "href" {
+			continue
+		}
+		child := &node{
+			name: goName(property),
+			path: property,
+		}
+		if parent.path != "" {
+			child.path = parent.path + "." + property
+		}
+		if scalar(schemas, value) {
+			child.scalar = true
+			parent.children = append(parent.children, child)
+			continue
+		}
+		if value.Ref == "" || depth >= maxDepth {
+			continue
+		}
+		nested := refName(value.Ref)
+		if seen[nested] {
+			continue
+		}
+		seen[nested] = true
+		collect(schemas, nested, child, depth+1, seen)
+		delete(seen, nested)
+		if len(child.children) > 0 {
+			parent.children = append(parent.children, child)
+		}
+	}
+	sort.Slice(parent.children, func(i, j int) bool {
+		return parent.children[i].name < parent.children[j].name
+	})
+}
+
+// assignTypes assigns names to the struct types of the given node and its nested objects, making
+// sure that they are unique.
+func assignTypes(current *node, base string, used map[string]bool) {
+	current.typ = base + "Fields"
+	for i := 2; used[current.typ]; i++ {
+		current.typ = fmt.Sprintf("%s%dFields", base, i)
+	}
+	used[current.typ] = true
+	for _, child := range current.children {
+		if !child.scalar {
+			assignTypes(child, base+child.name, used)
+		}
+	}
+}
+
+// writeValue writes the composite literal that contains the names of the fields of the given
+// node.
+func writeValue(buffer *bytes.Buffer, current *node) {
+	fmt.Fprintf(buffer, "%s{\n", current.typ)
+	for _, child := range current.children {
+		if child.scalar {
+			fmt.Fprintf(buffer, "%s: %q,\n", child.name, child.path)
+			continue
+		}
+		fmt.Fprintf(buffer, "%s: ", child.name)
+		writeValue(buffer, child)
+		fmt.Fprintf(buffer, ",\n")
+	}
+	fmt.Fprintf(buffer, "}")
+}
+
+// writeTypes writes the definition of the struct type of the given node and of its nested
+// objects.
+func writeTypes(buffer *bytes.Buffer, current *node) {
+	if current.path == "" {
+		fmt.Fprintf(buffer, "// %s is the type of the %s variable.\n", current.typ, current.name)
+	} else {
+		fmt.Fprintf(buffer, "// %s contains the names of the fields of the '%s' object.\n",
+			current.typ, current.path)
+	}
+	fmt.Fprintf(buffer, "type %s struct {\n", current.typ)
+	for _, child := range current.children {
+		if child.scalar {
+			fmt.Fprintf(buffer, "%s search.Field\n", child.name)
+		} else {
+			fmt.Fprintf(buffer, "%s %s\n", child.name, child.typ)
+		}
+	}
+	fmt.Fprintf(buffer, "}\n\n")
+	for _, child := range current.children {
+		if !child.scalar {
+			writeTypes(buffer, child)
+		}
+	}
+}
+
+// searchable checks if the given operation accepts the `search` parameter.
+func searchable(operation *operation) bool {
+	for _, parameter := range operation.Parameters {
+		if parameter.Name == "search" {
+			return true
+		}
+	}
+	return false
+}
+
+// scalar checks if the given schema is a scalar type, either directly or via a reference to an
+// enumerated type.
+func scalar(schemas map[string]*schema, value *schema) bool {
+	if value.Ref != "" {
+		referenced := schemas[refName(value.Ref)]
+		return referenced != nil && referenced.Properties == nil && referenced.Type != "array" &&
+			referenced.Type != "object"
+	}
+	switch value.Type {
+	case "string", "boolean", "integer", "number":
+		return true
+	default:
+		return false
+	}
+}
+
+// unexported converts the given exported Go name into an unexported one, for example `DNSDomain`
+// is converted into `dnsDomain`.
+func unexported(name string) string {
+	runes := []rune(name)
+	i := 0
+	for i < len(runes) && unicode.IsUpper(runes[i]) {
+		i++
+	}
+	if i > 1 && i < len(runes) {
+		i--
+	}
+	return strings.ToLower(string(runes[:i])) + string(runes[i:])
+}
+
+// refName extracts the name of the type from a reference like `#/components/schemas/Cluster`.
+func refName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+// goName converts a name of the model, like `cloud_provider` or `AWSInfrastructureAccessRole`, to
+// the corresponding Go name, using the same conventions than the rest of the SDK.
+func goName(name string) string {
+	words := strings.Split(name, "_")
+	buffer := &strings.Builder{}
+	for _, word := range words {
+		if word == "" {
+			continue
+		}
+		upper := strings.ToUpper(word)
+		if initialisms[upper] {
+			buffer.WriteString(upper)
+			continue
+		}
+		buffer.WriteString(strings.ToUpper(word[0:1]))
+		buffer.WriteString(word[1:])
+	}
+	return buffer.String()
+}
+
+// initialisms is the set of words that are written in upper case in Go names.
+var initialisms = map[string]bool{
+	"API":   true,
+	"ARN":   true,
+	"AWS":   true,
+	"AZ":    true,
+	"CA":    true,
+	"CCS":   true,
+	"CIDR":  true,
+	"CPU":   true,
+	"DNS":   true,
+	"FIPS":  true,
+	"GCP":   true,
+	"HREF":  true,
+	"HTML":  true,
+	"HTTP":  true,
+	"HTTPS": true,
+	"ID":    true,
+	"IDP":   true,
+	"IDS":   true,
+	"IMDS":  true,
+	"IP":    true,
+	"JSON":  true,
+	"KMS":   true,
+	"LDAP":  true,
+	"OIDC":  true,
+	"OSD":   true,
+	"RH":    true,
+	"RHIT":  true,
+	"SKU":   true,
+	"SSH":   true,
+	"STS":   true,
+	"TLS":   true,
+	"URL":   true,
+	"UUID":  true,
+	"VPC":   true,
+}
+
+// header is the header added to all the generated files.
+const header = `/*
+Copyright (c) 2020 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// IMPORTANT: This file has been generated automatically, refrain from modifying it manually as all
+// your changes will be lost when the file is generated again.
+
+`
@@ -0,0 +1,63 @@
+/*
+Copyright (c) 2020 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// IMPORTANT: This file has been generated automatically, refrain from modifying it manually as all
+// your changes will be lost when the file is generated again.
+
+package v1 // github.com/openshift-online/ocm-sdk-go/search/servicelogs/v1
+
+import (
+	"github.com/openshift-online/ocm-sdk-go/search"
+)
+
+// LogEntry contains the names of the fields of the 'LogEntry' type that can be used
+// in search expressions.
+var LogEntry = logEntryFields{
+	ClusterID:      "cluster_id",
+	ClusterUUID:    "cluster_uuid",
+	CreatedAt:      "created_at",
+	CreatedBy:      "created_by",
+	Description:    "description",
+	EventStreamID:  "event_stream_id",
+	ID:             "id",
+	InternalOnly:   "internal_only",
+	LogType:        "log_type",
+	ServiceName:    "service_name",
+	Severity:       "severity",
+	SubscriptionID: "subscription_id",
+	Summary:        "summary",
+	Timestamp:      "timestamp",
+	Username:       "username",
+}
+
+// logEntryFields is the type of the LogEntry variable.
+type logEntryFields struct {
+	ClusterID      search.Field
+	ClusterUUID    search.Field
+	CreatedAt      search.Field
+	CreatedBy      search.Field
+	Description    search.Field
+	EventStreamID  search.Field
+	ID             search.Field
+	InternalOnly   search.Field
+	LogType        search.Field
+	ServiceName    search.Field
+	Severity       search.Field
+	SubscriptionID search.Field
+	Summary        search.Field
+	Timestamp      search.Field
+	Username       search.Field
+}
@@ -0,0 +1,146 @@
+/*
+Copyright (c) 2020 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// IMPORTANT: This file has been generated automatically, refrain from modifying it manually as all
+// your changes will be lost when the file is generated again.
+
+package v1 // github.com/openshift-online/ocm-sdk-go/search/statusboard/v1
+
+import (
+	"github.com/openshift-online/ocm-sdk-go/search"
+)
+
+// Application contains the names of the fields of the 'Application' type that can be used
+// in search expressions.
+var Application = applicationFields{
+	CreatedAt: "created_at",
+	Fullname:  "fullname",
+	ID:        "id",
+	Name:      "name",
+	Product: applicationProductFields{
+		CreatedAt: "product.created_at",
+		Fullname:  "product.fullname",
+		ID:        "product.id",
+		Name:      "product.name",
+		UpdatedAt: "product.updated_at",
+	},
+	UpdatedAt: "updated_at",
+}
+
+// Product contains the names of the fields of the 'Product' type that can be used
+// in search expressions.
+var Product = productFields{
+	CreatedAt: "created_at",
+	Fullname:  "fullname",
+	ID:        "id",
+	Name:      "name",
+	UpdatedAt: "updated_at",
+}
+
+// Service contains the names of the fields of the 'Service' type that can be used
+// in search expressions.
+var Service = serviceFields{
+	Application: serviceApplicationFields{
+		CreatedAt: "application.created_at",
+		Fullname:  "application.fullname",
+		ID:        "application.id",
+		Name:      "application.name",
+		Product: serviceApplicationProductFields{
+			CreatedAt: "application.product.created_at",
+			Fullname:  "application.product.fullname",
+			ID:        "application.product.id",
+			Name:      "application.product.name",
+			UpdatedAt: "application.product.updated_at",
+		},
+		UpdatedAt: "application.updated_at",
+	},
+	CreatedAt:       "created_at",
+	CurrentStatus:   "current_status",
+	Fullname:        "fullname",
+	ID:              "id",
+	LastPingAt:      "last_ping_at",
+	Name:            "name",
+	Private:         "private",
+	ServiceEndpoint: "service_endpoint",
+	StatusType:      "status_type",
+	StatusUpdatedAt: "status_updated_at",
+	Token:           "token",
+	UpdatedAt:       "updated_at",
+}
+
+// applicationFields is the type of the Application variable.
+type applicationFields struct {
+	CreatedAt search.Field
+	Fullname  search.Field
+	ID        search.Field
+	Name      search.Field
+	Product   applicationProductFields
+	UpdatedAt search.Field
+}
+
+// applicationProductFields contains the names of the fields of the 'product' object.
+type applicationProductFields struct {
+	CreatedAt search.Field
+	Fullname  search.Field
+	ID        search.Field
+	Name      search.Field
+	UpdatedAt search.Field
+}
+
+// productFields is the type of the Product variable.
+type productFields struct {
+	CreatedAt search.Field
+	Fullname  search.Field
+	ID        search.Field
+	Name      search.Field
+	UpdatedAt search.Field
+}
+
+// serviceFields is the type of the Service variable.
+type serviceFields struct {
+	Application     serviceApplicationFields
+	CreatedAt       search.Field
+	CurrentStatus   search.Field
+	Fullname        search.Field
+	ID              search.Field
+	LastPingAt      search.Field
+	Name            search.Field
+	Private         search.Field
+	ServiceEndpoint search.Field
+	StatusType      search.Field
+	StatusUpdatedAt search.Field
+	Token           search.Field
+	UpdatedAt       search.Field
+}
+
+// serviceApplicationFields contains the names of the fields of the 'application' object.
+type serviceApplicationFields struct {
+	CreatedAt search.Field
+	Fullname  search.Field
+	ID        search.Field
+	Name      search.Field
+	Product   serviceApplicationProductFields
+	UpdatedAt search.Field
+}
+
+// serviceApplicationProductFields contains the names of the fields of the 'application.product' object.
+type serviceApplicationProductFields struct {
+	CreatedAt search.Field
+	Fullname  search.Field
+	ID        search.Field
+	Name      search.Field
+	UpdatedAt search.Field
+}
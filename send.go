@@ -26,6 +26,7 @@ import (
 	"path"
 
 	"github.com/openshift-online/ocm-sdk-go/internal"
+	"github.com/openshift-online/ocm-sdk-go/logging"
 )
 
 // RoundTrip is the implementation of the http.RoundTripper interface.
@@ -60,10 +61,11 @@ func (c *Connection) RoundTrip(request *http.Request) (response *http.Response,
 	switch request.Method {
 	case http.MethodGet, http.MethodDelete:
 		if request.Body != nil {
-			c.logger.Warn(ctx,
-				"Request body is not allowed for the '%s' method",
-				request.Method,
-			)
+			logging.With(
+				c.logger,
+				logging.MethodField, request.Method,
+				logging.PathField, request.URL.Path,
+			).Warn(ctx, "Request body is not allowed for this method")
 		}
 	case http.MethodPost, http.MethodPatch, http.MethodPut:
 		// POST and PATCH and PUT don't need to have a body. It is up to the server to decide if
@@ -114,7 +116,7 @@ func (c *Connection) selectServer(ctx context.Context,
 	// Select the server corresponding to the longest matching prefix. Note that it is enough to
 	// pick the first match because the entries have already been sorted by descending prefix
 	// length when the connection was created.
-	for _, entry := range c.urlTable {
+	for _, entry := range c.currentURLTable() {
 		if entry.re.MatchString(request.URL.Path) {
 			base = entry.url
 			return
@@ -0,0 +1,517 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains a transport that records requests and responses to a file, and then replays
+// them, so that tests can run without a real server.
+
+package testing
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/openshift-online/ocm-sdk-go/internal"
+)
+
+// CassetteBuilder contains the data and logic needed to create a cassette. Don't create instances
+// of this type directly, use the NewCassette function instead.
+type CassetteBuilder struct {
+	file        string
+	record      bool
+	matchMethod bool
+	matchPath   bool
+	matchQuery  bool
+	matchBody   bool
+	matchers    []CassetteMatcher
+}
+
+// Cassette is a transport wrapper that records the requests and responses sent by a connection,
+// and then replays them. It is intended to write tests that use real request and response pairs
+// without requiring a real server. For example, to record the interactions of a test:
+//
+//	cassette, err := testing.NewCassette("testdata/clusters.yaml").
+//		Record(true).
+//		Build()
+//	Expect(err).ToNot(HaveOccurred())
+//	defer cassette.Close()
+//	connection, err := sdk.NewConnectionBuilder().
+//		Tokens(os.Getenv("OCM_TOKEN")).
+//		TransportWrapper(cassette.Wrap).
+//		Build()
+//
+// Once the cassette has been recorded the same code, without the call to the Record method, will
+// replay it without sending requests to the real server.
+//
+// Security sensitive values, like the authorization header, tokens and passwords, are redacted
+// before writing them to the file. Bodies that aren't JSON documents or URL encoded forms can't be
+// redacted reliably, so they are replaced by the redaction string. When replaying token responses the redacted tokens are replaced
+// with tokens generated with the MakeTokenString function, so that the connection will accept
+// them.
+type Cassette struct {
+	file         string
+	record       bool
+	matchers     []CassetteMatcher
+	lock         *sync.Mutex
+	interactions []*CassetteInteraction
+	used         []bool
+}
+
+// CassetteMismatchError is the error returned by the round trippers of a cassette in replay mode
+// when no recorded interaction matches the request. It is returned as an error instead of failing
+// the test directly because requests may be sent from goroutines other than the one running the
+// test, so tests should check the error returned by the request, for example using errors.As.
+type CassetteMismatchError struct {
+	// File is the name of the cassette file.
+	File string
+
+	// Method is the HTTP method of the request.
+	Method string
+
+	// URL is the URL of the request.
+	URL string
+}
+
+// Error is the implementation of the error interface.
+func (e *CassetteMismatchError) Error() string {
+	return fmt.Sprintf(
+		"no recorded interaction in cassette '%s' matches request for method %s and URL '%s'",
+		e.File, e.Method, e.URL,
+	)
+}
+
+// CassetteMatcher is a function that checks if a request matches a recorded request. The body is
+// passed already redacted, as it is stored in the cassette.
+type CassetteMatcher func(request *http.Request, body string, recorded *CassetteRequest) bool
+
+// CassetteInteraction is a request and response pair stored in a cassette.
+type CassetteInteraction struct {
+	Request  *CassetteRequest  `json:"request" yaml:"request"`
+	Response *CassetteResponse `json:"response" yaml:"response"`
+}
+
+// CassetteRequest is a request stored in a cassette.
+type CassetteRequest struct {
+	Method string      `json:"method" yaml:"method"`
+	URL    string      `json:"url" yaml:"url"`
+	Header http.Header `json:"header,omitempty" yaml:"header,omitempty"`
+	Body   string      `json:"body,omitempty" yaml:"body,omitempty"`
+}
+
+// CassetteResponse is a response stored in a cassette.
+type CassetteResponse struct {
+	Status int         `json:"status" yaml:"status"`
+	Header http.Header `json:"header,omitempty" yaml:"header,omitempty"`
+	Body   string      `json:"body,omitempty" yaml:"body,omitempty"`
+}
+
+// cassetteData is the structure of the cassette files.
+type cassetteData struct {
+	Interactions []*CassetteInteraction `json:"interactions" yaml:"interactions"`
+}
+
+// cassetteRoundTripper is the round tripper that records or replays the interactions.
+type cassetteRoundTripper struct {
+	owner     *Cassette
+	transport http.RoundTripper
+}
+
+// Make sure that we implement the interface:
+var _ http.RoundTripper = (*cassetteRoundTripper)(nil)
+
+// NewCassette creates a builder that can then be used to configure and create a cassette stored in
+// the given file. If the name of the file ends with `.json` the cassette will be stored in JSON
+// format, otherwise it will be stored in YAML format.
+//
+// By default the cassette is in replay mode, and requests are matched using the method, the path
+// and the query parameters.
+func NewCassette(file string) *CassetteBuilder {
+	return &CassetteBuilder{
+		file:        file,
+		matchMethod: true,
+		matchPath:   true,
+		matchQuery:  true,
+	}
+}
+
+// Record sets the flag that indicates if the cassette should record the interactions instead of
+// replaying them. In record mode the requests are sent using the wrapped transport, and the
+// interactions are written to the file when the cassette is closed. The default is false.
+func (b *CassetteBuilder) Record(value bool) *CassetteBuilder {
+	b.record = value
+	return b
+}
+
+// MatchMethod sets the flag that indicates if the HTTP method should be used to match requests to
+// recorded interactions. The default is true.
+func (b *CassetteBuilder) MatchMethod(value bool) *CassetteBuilder {
+	b.matchMethod = value
+	return b
+}
+
+// MatchPath sets the flag that indicates if the URL path should be used to match requests to
+// recorded interactions. The default is true.
+func (b *CassetteBuilder) MatchPath(value bool) *CassetteBuilder {
+	b.matchPath = value
+	return b
+}
+
+// MatchQuery sets the flag that indicates if the URL query parameters should be used to match
+// requests to recorded interactions. The order of the parameters isn't relevant. The default is
+// true.
+func (b *CassetteBuilder) MatchQuery(value bool) *CassetteBuilder {
+	b.matchQuery = value
+	return b
+}
+
+// MatchBody sets the flag that indicates if the request body should be used to match requests to
+// recorded interactions. The bodies are compared after redacting them. The default is false.
+func (b *CassetteBuilder) MatchBody(value bool) *CassetteBuilder {
+	b.matchBody = value
+	return b
+}
+
+// Matcher adds a custom function that will be used to match requests to recorded interactions, in
+// addition to the method, path, query and body matching.
+func (b *CassetteBuilder) Matcher(value CassetteMatcher) *CassetteBuilder {
+	if value != nil {
+		b.matchers = append(b.matchers, value)
+	}
+	return b
+}
+
+// Build uses the information stored in the builder to create a new cassette. In replay mode the
+// file must exist.
+func (b *CassetteBuilder) Build() (result *Cassette, err error) {
+	// Check parameters:
+	if b.file == "" {
+		err = fmt.Errorf("file is mandatory")
+		return
+	}
+
+	// Create the list of matchers:
+	var matchers []CassetteMatcher
+	if b.matchMethod {
+		matchers = append(matchers, matchCassetteMethod)
+	}
+	if b.matchPath {
+		matchers = append(matchers, matchCassettePath)
+	}
+	if b.matchQuery {
+		matchers = append(matchers, matchCassetteQuery)
+	}
+	if b.matchBody {
+		matchers = append(matchers, matchCassetteBody)
+	}
+	matchers = append(matchers, b.matchers...)
+
+	// Load the interactions when in replay mode:
+	var interactions []*CassetteInteraction
+	if !b.record {
+		interactions, err = loadCassette(b.file)
+		if err != nil {
+			return
+		}
+	}
+
+	// Create and populate the object:
+	result = &Cassette{
+		file:         b.file,
+		record:       b.record,
+		matchers:     matchers,
+		lock:         &sync.Mutex{},
+		interactions: interactions,
+		used:         make([]bool, len(interactions)),
+	}
+
+	return
+}
+
+// Wrap creates a round tripper on top of the given one that records or replays the interactions.
+// In replay mode the given transport isn't used.
+func (c *Cassette) Wrap(transport http.RoundTripper) http.RoundTripper {
+	return &cassetteRoundTripper{
+		owner:     c,
+		transport: transport,
+	}
+}
+
+// Interactions returns a copy of the list of interactions of the cassette.
+func (c *Cassette) Interactions() []*CassetteInteraction {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	result := make([]*CassetteInteraction, len(c.interactions))
+	copy(result, c.interactions)
+	return result
+}
+
+// Close releases the resources used by the cassette. In record mode it writes the recorded
+// interactions to the file.
+func (c *Cassette) Close() error {
+	if !c.record {
+		return nil
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return saveCassette(c.file, c.interactions)
+}
+
+// RoundTrip is the implementation of the round tripper interface.
+func (t *cassetteRoundTripper) RoundTrip(request *http.Request) (response *http.Response,
+	err error) {
+	// Read the request body, as we need it to record or match the request:
+	var body []byte
+	if request.Body != nil {
+		body, err = io.ReadAll(request.Body)
+		if err != nil {
+			return
+		}
+		err = request.Body.Close()
+		if err != nil {
+			return
+		}
+	}
+	redactedBody := internal.RedactBody(request.Header.Get("Content-Type"), body)
+
+	// Record or replay:
+	if t.owner.record {
+		response, err = t.recordInteraction(request, body, redactedBody)
+	} else {
+		response, err = t.replayInteraction(request, redactedBody)
+	}
+	return
+}
+
+func (t *cassetteRoundTripper) recordInteraction(request *http.Request, body []byte,
+	redactedBody string) (response *http.Response, err error) {
+	// Send the request:
+	if body != nil {
+		request.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	response, err = t.transport.RoundTrip(request)
+	if err != nil {
+		return
+	}
+
+	// Read the response body and replace it with a reader that reads it from memory:
+	responseBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		return
+	}
+	err = response.Body.Close()
+	if err != nil {
+		return
+	}
+	response.Body = io.NopCloser(bytes.NewReader(responseBody))
+
+	// Save the interaction:
+	interaction := &CassetteInteraction{
+		Request: &CassetteRequest{
+			Method: request.Method,
+			URL:    request.URL.String(),
+			Header: internal.RedactHeader(request.Header),
+			Body:   redactedBody,
+		},
+		Response: &CassetteResponse{
+			Status: response.StatusCode,
+			Header: internal.RedactHeader(response.Header),
+			Body:   internal.RedactBody(response.Header.Get("Content-Type"), responseBody),
+		},
+	}
+	t.owner.lock.Lock()
+	t.owner.interactions = append(t.owner.interactions, interaction)
+	t.owner.used = append(t.owner.used, true)
+	t.owner.lock.Unlock()
+
+	return
+}
+
+func (t *cassetteRoundTripper) replayInteraction(request *http.Request,
+	redactedBody string) (response *http.Response, err error) {
+	// Find the first interaction that matches and that hasn't been used yet:
+	interaction := t.owner.find(request, redactedBody)
+	if interaction == nil {
+		err = &CassetteMismatchError{
+			File:   t.owner.file,
+			Method: request.Method,
+			URL:    request.URL.String(),
+		}
+		return
+	}
+
+	// Generate the response:
+	header := interaction.Response.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	code := interaction.Response.Status
+	responseBody := restoreCassetteTokens(header, interaction.Response.Body)
+	response = &http.Response{
+		Status:        fmt.Sprintf("%d %s", code, http.StatusText(code)),
+		StatusCode:    code,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(responseBody)),
+		ContentLength: int64(len(responseBody)),
+		Request:       request,
+	}
+	return
+}
+
+// find returns the first unused interaction that matches the given request, and marks it as used.
+func (c *Cassette) find(request *http.Request, body string) *CassetteInteraction {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for i, interaction := range c.interactions {
+		if c.used[i] {
+			continue
+		}
+		matches := true
+		for _, matcher := range c.matchers {
+			if !matcher(request, body, interaction.Request) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			c.used[i] = true
+			return interaction
+		}
+	}
+	return nil
+}
+
+func matchCassetteMethod(request *http.Request, body string, recorded *CassetteRequest) bool {
+	return request.Method == recorded.Method
+}
+
+func matchCassettePath(request *http.Request, body string, recorded *CassetteRequest) bool {
+	parsed, err := url.Parse(recorded.URL)
+	if err != nil {
+		return false
+	}
+	return request.URL.Path == parsed.Path
+}
+
+func matchCassetteQuery(request *http.Request, body string, recorded *CassetteRequest) bool {
+	parsed, err := url.Parse(recorded.URL)
+	if err != nil {
+		return false
+	}
+	return reflect.DeepEqual(request.URL.Query(), parsed.Query())
+}
+
+func matchCassetteBody(request *http.Request, body string, recorded *CassetteRequest) bool {
+	return body == recorded.Body
+}
+
+// restoreCassetteTokens replaces the redacted tokens of a token response with tokens generated with
+// the MakeTokenString function, so that they will be accepted by the connection.
+func restoreCassetteTokens(header http.Header, body string) string {
+	mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	if mediaType != "application/json" && mediaType != "" {
+		return body
+	}
+	var data map[string]interface{}
+	err := json.Unmarshal([]byte(body), &data)
+	if err != nil {
+		return body
+	}
+	changed := false
+	for field, typ := range redactCassetteTokens {
+		if data[field] == internal.RedactionString {
+			data[field] = MakeTokenString(typ, 15*time.Minute)
+			changed = true
+		}
+	}
+	if !changed {
+		return body
+	}
+	restored, err := json.Marshal(data)
+	if err != nil {
+		return body
+	}
+	return string(restored)
+}
+
+// loadCassette loads the interactions from the given file.
+func loadCassette(file string) (result []*CassetteInteraction, err error) {
+	content, err := os.ReadFile(file) // #nosec G304
+	if err != nil {
+		err = fmt.Errorf("can't read cassette file '%s': %w", file, err)
+		return
+	}
+	var data cassetteData
+	if strings.HasSuffix(file, ".json") {
+		err = json.Unmarshal(content, &data)
+	} else {
+		err = yaml.Unmarshal(content, &data)
+	}
+	if err != nil {
+		err = fmt.Errorf("can't parse cassette file '%s': %w", file, err)
+		return
+	}
+	result = data.Interactions
+	return
+}
+
+// saveCassette writes the given interactions to the given file, creating the directory if needed.
+func saveCassette(file string, interactions []*CassetteInteraction) error {
+	data := &cassetteData{
+		Interactions: interactions,
+	}
+	var content []byte
+	var err error
+	if strings.HasSuffix(file, ".json") {
+		content, err = json.MarshalIndent(data, "", "  ")
+	} else {
+		content, err = yaml.Marshal(data)
+	}
+	if err != nil {
+		return fmt.Errorf("can't serialize cassette '%s': %w", file, err)
+	}
+	err = os.MkdirAll(filepath.Dir(file), 0700)
+	if err != nil {
+		return fmt.Errorf("can't create directory for cassette '%s': %w", file, err)
+	}
+	err = os.WriteFile(file, content, 0600)
+	if err != nil {
+		return fmt.Errorf("can't write cassette file '%s': %w", file, err)
+	}
+	return nil
+}
+
+// redactCassetteTokens are the names of the fields of token responses that are replaced by
+// generated tokens when replaying, and the type of the generated tokens.
+var redactCassetteTokens = map[string]string{
+	"access_token":  "Bearer",
+	"refresh_token": "Refresh",
+	"id_token":      "ID",
+}
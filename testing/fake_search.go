@@ -0,0 +1,611 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the implementation of the `search` and `order` parameters supported by the
+// fake server. It supports the subset of the SQL like syntax used by the API that is generated by
+// the search package: comparisons, `like`, `ilike`, `in`, `not in`, `is null`, `is not null`,
+// `and`, `or`, `not` and parenthesis.
+
+package testing
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// fakeFilter is a compiled search expression.
+type fakeFilter func(object map[string]interface{}) bool
+
+// fakeTokenKind is the kind of a token of a search expression.
+type fakeTokenKind int
+
+const (
+	fakeEndToken fakeTokenKind = iota
+	fakeIdentifierToken
+	fakeStringToken
+	fakeNumberToken
+	fakeSymbolToken
+)
+
+// fakeToken is a token of a search expression.
+type fakeToken struct {
+	kind fakeTokenKind
+	text string
+}
+
+// fakeSearchParser parses search expressions.
+type fakeSearchParser struct {
+	tokens []fakeToken
+	next   int
+}
+
+// compileFakeSearch compiles the given search expression. An empty expression matches all the
+// objects.
+func compileFakeSearch(text string) (result fakeFilter, err error) {
+	if strings.TrimSpace(text) == "" {
+		result = func(map[string]interface{}) bool {
+			return true
+		}
+		return
+	}
+	tokens, err := tokenizeFakeSearch(text)
+	if err != nil {
+		return
+	}
+	parser := &fakeSearchParser{
+		tokens: tokens,
+	}
+	result, err = parser.parseOr()
+	if err != nil {
+		return
+	}
+	if parser.peek().kind != fakeEndToken {
+		err = fmt.Errorf("unexpected '%s' in search expression", parser.peek().text)
+		result = nil
+	}
+	return
+}
+
+// tokenizeFakeSearch splits the given search expression into tokens.
+func tokenizeFakeSearch(text string) (result []fakeToken, err error) {
+	runes := []rune(text)
+	i := 0
+	for i < len(runes) {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '\'':
+			var buffer strings.Builder
+			i++
+			for {
+				if i >= len(runes) {
+					err = fmt.Errorf("unterminated string in search expression")
+					return
+				}
+				if runes[i] == '\'' {
+					if i+1 < len(runes) && runes[i+1] == '\'' {
+						buffer.WriteRune('\'')
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				buffer.WriteRune(runes[i])
+				i++
+			}
+			result = append(result, fakeToken{
+				kind: fakeStringToken,
+				text: buffer.String(),
+			})
+		case unicode.IsDigit(r) || (r == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			i++
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			result = append(result, fakeToken{
+				kind: fakeNumberToken,
+				text: string(runes[start:i]),
+			})
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) ||
+				runes[i] == '_' || runes[i] == '.') {
+				i++
+			}
+			result = append(result, fakeToken{
+				kind: fakeIdentifierToken,
+				text: string(runes[start:i]),
+			})
+		case strings.ContainsRune("(),", r):
+			result = append(result, fakeToken{
+				kind: fakeSymbolToken,
+				text: string(r),
+			})
+			i++
+		case strings.ContainsRune("=!<>", r):
+			start := i
+			i++
+			if i < len(runes) && strings.ContainsRune("=>", runes[i]) {
+				i++
+			}
+			symbol := string(runes[start:i])
+			switch symbol {
+			case "=", "!=", "<>", "<", "<=", ">", ">=":
+			default:
+				err = fmt.Errorf("unknown operator '%s' in search expression", symbol)
+				return
+			}
+			result = append(result, fakeToken{
+				kind: fakeSymbolToken,
+				text: symbol,
+			})
+		default:
+			err = fmt.Errorf("unexpected character '%c' in search expression", r)
+			return
+		}
+	}
+	return
+}
+
+// peek returns the next token without consuming it.
+func (p *fakeSearchParser) peek() fakeToken {
+	if p.next >= len(p.tokens) {
+		return fakeToken{
+			kind: fakeEndToken,
+		}
+	}
+	return p.tokens[p.next]
+}
+
+// take consumes and returns the next token.
+func (p *fakeSearchParser) take() fakeToken {
+	token := p.peek()
+	if token.kind != fakeEndToken {
+		p.next++
+	}
+	return token
+}
+
+// keyword checks if the next token is the given keyword, and consumes it if it is.
+func (p *fakeSearchParser) keyword(value string) bool {
+	token := p.peek()
+	if token.kind == fakeIdentifierToken && strings.EqualFold(token.text, value) {
+		p.next++
+		return true
+	}
+	return false
+}
+
+// symbol checks if the next token is the given symbol, and consumes it if it is.
+func (p *fakeSearchParser) symbol(value string) bool {
+	token := p.peek()
+	if token.kind == fakeSymbolToken && token.text == value {
+		p.next++
+		return true
+	}
+	return false
+}
+
+func (p *fakeSearchParser) parseOr() (result fakeFilter, err error) {
+	result, err = p.parseAnd()
+	if err != nil {
+		return
+	}
+	for p.keyword("or") {
+		var right fakeFilter
+		right, err = p.parseAnd()
+		if err != nil {
+			return
+		}
+		left := result
+		result = func(object map[string]interface{}) bool {
+			return left(object) || right(object)
+		}
+	}
+	return
+}
+
+func (p *fakeSearchParser) parseAnd() (result fakeFilter, err error) {
+	result, err = p.parseNot()
+	if err != nil {
+		return
+	}
+	for p.keyword("and") {
+		var right fakeFilter
+		right, err = p.parseNot()
+		if err != nil {
+			return
+		}
+		left := result
+		result = func(object map[string]interface{}) bool {
+			return left(object) && right(object)
+		}
+	}
+	return
+}
+
+func (p *fakeSearchParser) parseNot() (result fakeFilter, err error) {
+	if p.keyword("not") {
+		var operand fakeFilter
+		operand, err = p.parseNot()
+		if err != nil {
+			return
+		}
+		result = func(object map[string]interface{}) bool {
+			return !operand(object)
+		}
+		return
+	}
+	result, err = p.parsePrimary()
+	return
+}
+
+func (p *fakeSearchParser) parsePrimary() (result fakeFilter, err error) {
+	if p.symbol("(") {
+		result, err = p.parseOr()
+		if err != nil {
+			return
+		}
+		if !p.symbol(")") {
+			err = fmt.Errorf("expected ')' in search expression")
+			result = nil
+		}
+		return
+	}
+	token := p.take()
+	if token.kind != fakeIdentifierToken {
+		err = fmt.Errorf("expected field name but found '%s' in search expression", token.text)
+		return
+	}
+	field := token.text
+	switch {
+	case p.keyword("is"):
+		negated := p.keyword("not")
+		if !p.keyword("null") {
+			err = fmt.Errorf("expected 'null' after 'is' in search expression")
+			return
+		}
+		result = func(object map[string]interface{}) bool {
+			return (lookupFakeField(object, field) == nil) != negated
+		}
+	case p.keyword("like"):
+		result, err = p.parseLike(field, false)
+	case p.keyword("ilike"):
+		result, err = p.parseLike(field, true)
+	case p.keyword("in"):
+		result, err = p.parseIn(field, false)
+	case p.keyword("not"):
+		if !p.keyword("in") {
+			err = fmt.Errorf("expected 'in' after 'not' in search expression")
+			return
+		}
+		result, err = p.parseIn(field, true)
+	default:
+		operator := p.take()
+		if operator.kind != fakeSymbolToken || operator.text == "(" ||
+			operator.text == ")" || operator.text == "," {
+			err = fmt.Errorf(
+				"expected operator after '%s' in search expression",
+				field,
+			)
+			return
+		}
+		var value func(map[string]interface{}) interface{}
+		value, err = p.parseValue()
+		if err != nil {
+			return
+		}
+		result = func(object map[string]interface{}) bool {
+			return compareFakeValues(
+				lookupFakeField(object, field),
+				operator.text,
+				value(object),
+			)
+		}
+	}
+	return
+}
+
+func (p *fakeSearchParser) parseLike(field string, insensitive bool) (result fakeFilter,
+	err error) {
+	token := p.take()
+	if token.kind != fakeStringToken {
+		err = fmt.Errorf("expected pattern after 'like' in search expression")
+		return
+	}
+	var buffer strings.Builder
+	if insensitive {
+		buffer.WriteString("(?i)")
+	}
+	buffer.WriteString("^")
+	for _, r := range token.text {
+		switch r {
+		case '%':
+			buffer.WriteString(".*")
+		case '_':
+			buffer.WriteString(".")
+		default:
+			buffer.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	buffer.WriteString("$")
+	re, err := regexp.Compile(buffer.String())
+	if err != nil {
+		return
+	}
+	result = func(object map[string]interface{}) bool {
+		value, ok := lookupFakeField(object, field).(string)
+		return ok && re.MatchString(value)
+	}
+	return
+}
+
+func (p *fakeSearchParser) parseIn(field string, negated bool) (result fakeFilter, err error) {
+	if !p.symbol("(") {
+		err = fmt.Errorf("expected '(' after 'in' in search expression")
+		return
+	}
+	var values []func(map[string]interface{}) interface{}
+	for {
+		var value func(map[string]interface{}) interface{}
+		value, err = p.parseValue()
+		if err != nil {
+			return
+		}
+		values = append(values, value)
+		if p.symbol(")") {
+			break
+		}
+		if !p.symbol(",") {
+			err = fmt.Errorf("expected ',' or ')' in search expression")
+			return
+		}
+	}
+	result = func(object map[string]interface{}) bool {
+		actual := lookupFakeField(object, field)
+		if actual == nil {
+			return false
+		}
+		for _, value := range values {
+			if compareFakeValues(actual, "=", value(object)) {
+				return !negated
+			}
+		}
+		return negated
+	}
+	return
+}
+
+func (p *fakeSearchParser) parseValue() (result func(map[string]interface{}) interface{},
+	err error) {
+	token := p.take()
+	switch token.kind {
+	case fakeStringToken:
+		value := token.text
+		result = func(map[string]interface{}) interface{} {
+			return value
+		}
+	case fakeNumberToken:
+		var value float64
+		value, err = strconv.ParseFloat(token.text, 64)
+		if err != nil {
+			return
+		}
+		result = func(map[string]interface{}) interface{} {
+			return value
+		}
+	case fakeIdentifierToken:
+		if strings.EqualFold(token.text, "null") {
+			result = func(map[string]interface{}) interface{} {
+				return nil
+			}
+			return
+		}
+		field := token.text
+		result = func(object map[string]interface{}) interface{} {
+			return lookupFakeField(object, field)
+		}
+	default:
+		err = fmt.Errorf("expected value but found '%s' in search expression", token.text)
+	}
+	return
+}
+
+// lookupFakeField returns the value of the given field, which can be a path separated by dots,
+// for example `region.id`. It returns nil if the field doesn't exist.
+func lookupFakeField(object map[string]interface{}, field string) interface{} {
+	var current interface{} = object
+	for _, segment := range strings.Split(field, ".") {
+		fields, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = fields[segment]
+	}
+	return current
+}
+
+// compareFakeValues compares the value of a field with the given value. Comparisons with null
+// values are always false, like in SQL.
+func compareFakeValues(actual interface{}, operator string, expected interface{}) bool {
+	if actual == nil || expected == nil {
+		return false
+	}
+	result, ok := orderFakeValues(actual, expected)
+	if !ok {
+		return false
+	}
+	switch operator {
+	case "=":
+		return result == 0
+	case "!=", "<>":
+		return result != 0
+	case "<":
+		return result < 0
+	case "<=":
+		return result <= 0
+	case ">":
+		return result > 0
+	case ">=":
+		return result >= 0
+	}
+	return false
+}
+
+// orderFakeValues compares two values, converting the second one to the type of the first. It
+// returns a negative number, zero or a positive number if the first value is less, equal or
+// greater than the second. The second result will be false if the values can't be compared.
+func orderFakeValues(left, right interface{}) (result int, ok bool) {
+	switch typed := left.(type) {
+	case string:
+		var text string
+		switch value := right.(type) {
+		case string:
+			text = value
+		case float64:
+			text = strconv.FormatFloat(value, 'f', -1, 64)
+		default:
+			return
+		}
+		result = strings.Compare(typed, text)
+		ok = true
+	case float64:
+		var number float64
+		switch value := right.(type) {
+		case float64:
+			number = value
+		case string:
+			var err error
+			number, err = strconv.ParseFloat(value, 64)
+			if err != nil {
+				return
+			}
+		default:
+			return
+		}
+		switch {
+		case typed < number:
+			result = -1
+		case typed > number:
+			result = 1
+		}
+		ok = true
+	case bool:
+		var flag bool
+		switch value := right.(type) {
+		case bool:
+			flag = value
+		case string:
+			switch strings.ToLower(value) {
+			case "t", "true":
+				flag = true
+			case "f", "false":
+				flag = false
+			default:
+				return
+			}
+		default:
+			return
+		}
+		switch {
+		case typed == flag:
+		case !typed:
+			result = -1
+		default:
+			result = 1
+		}
+		ok = true
+	}
+	return
+}
+
+// fakeOrderItem is one of the criteria of an `order` parameter.
+type fakeOrderItem struct {
+	field      string
+	descending bool
+}
+
+// parseFakeOrder parses an `order` parameter like `name asc, creation_timestamp desc`.
+func parseFakeOrder(text string) (result []fakeOrderItem, err error) {
+	for _, chunk := range strings.Split(text, ",") {
+		words := strings.Fields(chunk)
+		if len(words) == 0 {
+			continue
+		}
+		item := fakeOrderItem{
+			field: words[0],
+		}
+		if len(words) > 2 {
+			err = fmt.Errorf("order criteria '%s' isn't valid", strings.TrimSpace(chunk))
+			return
+		}
+		if len(words) == 2 {
+			switch strings.ToLower(words[1]) {
+			case "asc":
+			case "desc":
+				item.descending = true
+			default:
+				err = fmt.Errorf(
+					"order direction '%s' isn't valid, it should be 'asc' or 'desc'",
+					words[1],
+				)
+				return
+			}
+		}
+		result = append(result, item)
+	}
+	return
+}
+
+// sortFakeObjects sorts the given objects using the given criteria. Objects that don't have the
+// field are sorted before the rest. The sort is stable, so objects that are equal according to the
+// criteria preserve their order.
+func sortFakeObjects(objects []map[string]interface{}, order []fakeOrderItem) {
+	if len(order) == 0 {
+		return
+	}
+	sort.SliceStable(objects, func(i, j int) bool {
+		for _, item := range order {
+			left := lookupFakeField(objects[i], item.field)
+			right := lookupFakeField(objects[j], item.field)
+			var result int
+			switch {
+			case left == nil && right == nil:
+				result = 0
+			case left == nil:
+				result = -1
+			case right == nil:
+				result = 1
+			default:
+				result, _ = orderFakeValues(left, right)
+			}
+			if item.descending {
+				result = -result
+			}
+			if result != 0 {
+				return result < 0
+			}
+		}
+		return false
+	})
+}
@@ -0,0 +1,949 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains a stateful in-memory server that implements a subset of the clusters and
+// accounts management services, so that tests can exercise code that uses the SDK without having
+// to mock every request.
+
+package testing
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/golang-jwt/jwt/v4"
+
+	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/openshift-online/ocm-sdk-go/errors"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core" // nolint
+)
+
+// Paths of the services and collections implemented by the fake server.
+const (
+	fakeClustersMgmtPath  = "/api/clusters_mgmt/v1"
+	fakeAccountsMgmtPath  = "/api/accounts_mgmt/v1"
+	fakeClustersPath      = fakeClustersMgmtPath + "/clusters"
+	fakeAccountsPath      = fakeAccountsMgmtPath + "/accounts"
+	fakeOrganizationsPath = fakeAccountsMgmtPath + "/organizations"
+	fakeSubscriptionsPath = fakeAccountsMgmtPath + "/subscriptions"
+)
+
+// Names of the cluster logs supported by the fake server.
+const (
+	fakeInstallLog   = "install"
+	fakeUninstallLog = "uninstall"
+)
+
+// fakeDefaultPageSize is the page size used when the request doesn't contain the `size` parameter.
+const fakeDefaultPageSize = 100
+
+// FakeServerBuilder contains the data and logic needed to create a fake server. Don't create
+// objects of this type directly, use the NewFakeServer function instead.
+type FakeServerBuilder struct {
+	authentication    bool
+	clusterStateDelay time.Duration
+}
+
+// FakeServer is an in-memory implementation of a subset of the clusters and accounts management
+// services. It supports creating, retrieving, updating, deleting and listing clusters, accounts,
+// organizations and subscriptions, including the `page`, `size`, `search` and `order` parameters
+// of the list operations. Objects are validated and normalized using the generated JSON
+// marshalling functions, so the server only stores the fields that the SDK knows about.
+//
+// Clusters go through the pending, installing, ready and uninstalling states. The transitions
+// happen automatically after the delay given with the ClusterStateDelay method of the builder, or
+// explicitly with the AdvanceCluster and SetClusterState methods.
+//
+// The install and uninstall logs of clusters are also available, and can be populated with the
+// AppendClusterLog method. Their `offset` and `tail` parameters are supported.
+//
+// Requests need a bearer token issued by the MakeTokenString function, unless authentication has
+// been disabled with the Authentication method of the builder. The URL of the server can be
+// passed directly to the URL method of the connection builder.
+type FakeServer struct {
+	lock              *sync.Mutex
+	server            *httptest.Server
+	authentication    bool
+	clusterStateDelay time.Duration
+	collections       []*fakeCollection
+	clusters          *fakeCollection
+	accounts          *fakeCollection
+	organizations     *fakeCollection
+	subscriptions     *fakeCollection
+	clusterChanges    map[string]time.Time
+	clusterLogs       map[string]map[string][]string
+}
+
+// fakeCollection stores the objects of one of the collections of the fake server, in the order
+// that they were created.
+type fakeCollection struct {
+	path      string
+	kind      string
+	code      string
+	normalize func(data []byte) ([]byte, error)
+	protected []string
+	objects   map[string]map[string]interface{}
+	ids       []string
+}
+
+// fakeList is the representation of the result of a list operation.
+type fakeList struct {
+	Kind  string                   `json:"kind"`
+	Page  int                      `json:"page"`
+	Size  int                      `json:"size"`
+	Total int                      `json:"total"`
+	Items []map[string]interface{} `json:"items"`
+}
+
+// NewFakeServer creates a builder that can then be used to configure and create a fake server.
+func NewFakeServer() *FakeServerBuilder {
+	return &FakeServerBuilder{
+		authentication: true,
+	}
+}
+
+// Authentication sets a flag that indicates if the server should require a valid bearer token in
+// the requests. Tokens are valid if they have been generated with the MakeTokenString function and
+// haven't expired. The default is true.
+func (b *FakeServerBuilder) Authentication(value bool) *FakeServerBuilder {
+	b.authentication = value
+	return b
+}
+
+// ClusterStateDelay sets the time that clusters stay in the pending, installing and uninstalling
+// states before moving automatically to the next state. The default is zero, which means that
+// clusters only change state when the AdvanceCluster or SetClusterState methods are called.
+func (b *FakeServerBuilder) ClusterStateDelay(value time.Duration) *FakeServerBuilder {
+	b.clusterStateDelay = value
+	return b
+}
+
+// Build uses the configuration stored in the builder to create and start a new fake server. The
+// server should be stopped with the Close method when it is no longer needed.
+func (b *FakeServerBuilder) Build() (result *FakeServer, err error) {
+	// Check parameters:
+	if b.clusterStateDelay < 0 {
+		err = fmt.Errorf(
+			"cluster state delay %s isn't valid, it should be greater or equal than zero",
+			b.clusterStateDelay,
+		)
+		return
+	}
+
+	// Create the collections:
+	clusters := &fakeCollection{
+		path:      fakeClustersPath,
+		kind:      "Cluster",
+		code:      "CLUSTERS-MGMT",
+		normalize: normalizeFakeCluster,
+		protected: []string{"state", "creation_timestamp"},
+	}
+	accounts := &fakeCollection{
+		path:      fakeAccountsPath,
+		kind:      "Account",
+		code:      "ACCT-MGMT",
+		normalize: normalizeFakeAccount,
+		protected: []string{"created_at"},
+	}
+	organizations := &fakeCollection{
+		path:      fakeOrganizationsPath,
+		kind:      "Organization",
+		code:      "ACCT-MGMT",
+		normalize: normalizeFakeOrganization,
+		protected: []string{"created_at"},
+	}
+	subscriptions := &fakeCollection{
+		path:      fakeSubscriptionsPath,
+		kind:      "Subscription",
+		code:      "ACCT-MGMT",
+		normalize: normalizeFakeSubscription,
+		protected: []string{"created_at"},
+	}
+	collections := []*fakeCollection{
+		clusters,
+		accounts,
+		organizations,
+		subscriptions,
+	}
+	for _, collection := range collections {
+		collection.objects = map[string]map[string]interface{}{}
+	}
+
+	// Create and start the server:
+	result = &FakeServer{
+		lock:              &sync.Mutex{},
+		authentication:    b.authentication,
+		clusterStateDelay: b.clusterStateDelay,
+		collections:       collections,
+		clusters:          clusters,
+		accounts:          accounts,
+		organizations:     organizations,
+		subscriptions:     subscriptions,
+		clusterChanges:    map[string]time.Time{},
+		clusterLogs:       map[string]map[string][]string{},
+	}
+	result.server = httptest.NewUnstartedServer(result)
+	result.server.Config.ErrorLog = log.New(GinkgoWriter, "", log.LstdFlags)
+	result.server.Start()
+
+	return
+}
+
+// URL returns the base URL of the server, for example `http://127.0.0.1:12345`.
+func (s *FakeServer) URL() string {
+	return s.server.URL
+}
+
+// Close stops the server and discards all the objects.
+func (s *FakeServer) Close() {
+	s.server.Close()
+}
+
+// AddCluster adds a cluster directly to the server, without sending a request. Unlike clusters
+// created with a request, the identifier and the state of the given cluster are preserved if they
+// have been set. Returns the cluster as stored by the server.
+func (s *FakeServer) AddCluster(object *cmv1.Cluster) (result *cmv1.Cluster, err error) {
+	buffer := &bytes.Buffer{}
+	err = cmv1.MarshalCluster(object, buffer)
+	if err != nil {
+		return
+	}
+	data, err := s.add(s.clusters, buffer.Bytes())
+	if err != nil {
+		return
+	}
+	result, err = cmv1.UnmarshalCluster(data)
+	return
+}
+
+// AddAccount adds an account directly to the server, without sending a request. The identifier
+// of the account is preserved if it has been set. Returns the account as stored by the server.
+func (s *FakeServer) AddAccount(object *amv1.Account) (result *amv1.Account, err error) {
+	buffer := &bytes.Buffer{}
+	err = amv1.MarshalAccount(object, buffer)
+	if err != nil {
+		return
+	}
+	data, err := s.add(s.accounts, buffer.Bytes())
+	if err != nil {
+		return
+	}
+	result, err = amv1.UnmarshalAccount(data)
+	return
+}
+
+// AddOrganization adds an organization directly to the server, without sending a request. The
+// identifier of the organization is preserved if it has been set. Returns the organization as
+// stored by the server.
+func (s *FakeServer) AddOrganization(object *amv1.Organization) (result *amv1.Organization,
+	err error) {
+	buffer := &bytes.Buffer{}
+	err = amv1.MarshalOrganization(object, buffer)
+	if err != nil {
+		return
+	}
+	data, err := s.add(s.organizations, buffer.Bytes())
+	if err != nil {
+		return
+	}
+	result, err = amv1.UnmarshalOrganization(data)
+	return
+}
+
+// AddSubscription adds a subscription directly to the server, without sending a request. The
+// identifier of the subscription is preserved if it has been set. Returns the subscription as
+// stored by the server.
+func (s *FakeServer) AddSubscription(object *amv1.Subscription) (result *amv1.Subscription,
+	err error) {
+	buffer := &bytes.Buffer{}
+	err = amv1.MarshalSubscription(object, buffer)
+	if err != nil {
+		return
+	}
+	data, err := s.add(s.subscriptions, buffer.Bytes())
+	if err != nil {
+		return
+	}
+	result, err = amv1.UnmarshalSubscription(data)
+	return
+}
+
+// ClusterState returns the current state of the cluster with the given identifier. The second
+// result will be false if the cluster doesn't exist, for example because it has been completely
+// uninstalled.
+func (s *FakeServer) ClusterState(id string) (result cmv1.ClusterState, ok bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.refreshClusters()
+	object, ok := s.clusters.objects[id]
+	if !ok {
+		return
+	}
+	state, _ := object["state"].(string)
+	result = cmv1.ClusterState(state)
+	return
+}
+
+// SetClusterState changes the state of the cluster with the given identifier. Any state can be
+// used, for example cmv1.ClusterStateError to simulate a failed installation.
+func (s *FakeServer) SetClusterState(id string, state cmv1.ClusterState) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.refreshClusters()
+	object, ok := s.clusters.objects[id]
+	if !ok {
+		return fmt.Errorf("cluster '%s' doesn't exist", id)
+	}
+	s.changeClusterState(id, object, state, time.Now())
+	return nil
+}
+
+// AdvanceCluster moves the cluster with the given identifier to the next state: from pending to
+// installing, from installing to ready, and from uninstalling to removed. Returns the new state,
+// which will be empty if the cluster has been removed. Clusters in other states can't be advanced.
+func (s *FakeServer) AdvanceCluster(id string) (result cmv1.ClusterState, err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.refreshClusters()
+	object, ok := s.clusters.objects[id]
+	if !ok {
+		err = fmt.Errorf("cluster '%s' doesn't exist", id)
+		return
+	}
+	result, ok = s.advanceCluster(id, object, time.Now())
+	if !ok {
+		err = fmt.Errorf(
+			"cluster '%s' is in state '%s' and can't be advanced",
+			id, object["state"],
+		)
+	}
+	return
+}
+
+// AppendClusterLog adds lines to the install or uninstall log of the cluster with the given
+// identifier. The name of the log should be `install` or `uninstall`.
+func (s *FakeServer) AppendClusterLog(id, name string, lines ...string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.refreshClusters()
+	if name != fakeInstallLog && name != fakeUninstallLog {
+		return fmt.Errorf(
+			"log name '%s' isn't valid, it should be '%s' or '%s'",
+			name, fakeInstallLog, fakeUninstallLog,
+		)
+	}
+	_, ok := s.clusters.objects[id]
+	if !ok {
+		return fmt.Errorf("cluster '%s' doesn't exist", id)
+	}
+	logs, ok := s.clusterLogs[id]
+	if !ok {
+		logs = map[string][]string{}
+		s.clusterLogs[id] = logs
+	}
+	logs[name] = append(logs[name], lines...)
+	return nil
+}
+
+// ServeHTTP is the implementation of the http.Handler interface.
+func (s *FakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.refreshClusters()
+
+	// The metadata of the services is available without authentication:
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	if path == fakeClustersMgmtPath || path == fakeAccountsMgmtPath {
+		if r.Method != http.MethodGet {
+			s.sendError(w, r, http.StatusMethodNotAllowed, "Method '%s' isn't allowed", r.Method)
+			return
+		}
+		s.sendJSON(w, http.StatusOK, map[string]interface{}{
+			"kind":           "Metadata",
+			"server_version": "fake",
+		})
+		return
+	}
+
+	// Check the token:
+	if s.authentication && !s.checkToken(r) {
+		s.sendError(w, r, http.StatusUnauthorized, "Request doesn't contain a valid token")
+		return
+	}
+
+	// Find the collection and the object:
+	for _, collection := range s.collections {
+		if path == collection.path {
+			switch r.Method {
+			case http.MethodGet:
+				s.list(w, r, collection)
+			case http.MethodPost:
+				s.post(w, r, collection)
+			default:
+				s.sendError(w, r, http.StatusMethodNotAllowed, "Method '%s' isn't allowed",
+					r.Method)
+			}
+			return
+		}
+		if strings.HasPrefix(path, collection.path+"/") {
+			id := strings.TrimPrefix(path, collection.path+"/")
+			if collection == s.clusters && strings.Contains(id, "/logs/") {
+				index := strings.Index(id, "/logs/")
+				s.getLog(w, r, id[:index], id[index+len("/logs/"):])
+				return
+			}
+			if strings.Contains(id, "/") {
+				break
+			}
+			switch r.Method {
+			case http.MethodGet:
+				s.get(w, r, collection, id)
+			case http.MethodPatch:
+				s.patch(w, r, collection, id)
+			case http.MethodDelete:
+				s.delete(w, r, collection, id)
+			default:
+				s.sendError(w, r, http.StatusMethodNotAllowed, "Method '%s' isn't allowed",
+					r.Method)
+			}
+			return
+		}
+	}
+	s.sendError(w, r, http.StatusNotFound, "Path '%s' doesn't exist", r.URL.Path)
+}
+
+func (s *FakeServer) list(w http.ResponseWriter, r *http.Request, collection *fakeCollection) {
+	query := r.URL.Query()
+
+	// Parse the paging parameters:
+	page := 1
+	size := fakeDefaultPageSize
+	var err error
+	if text := query.Get("page"); text != "" {
+		page, err = strconv.Atoi(text)
+		if err != nil || page < 1 {
+			s.sendError(w, r, http.StatusBadRequest,
+				"Page '%s' isn't valid, it should be greater than zero", text)
+			return
+		}
+	}
+	if text := query.Get("size"); text != "" {
+		size, err = strconv.Atoi(text)
+		if err != nil || size < 0 {
+			s.sendError(w, r, http.StatusBadRequest,
+				"Size '%s' isn't valid, it should be greater or equal than zero", text)
+			return
+		}
+	}
+
+	// Parse the search and order parameters:
+	filter, err := compileFakeSearch(query.Get("search"))
+	if err != nil {
+		s.sendError(w, r, http.StatusBadRequest, "Search isn't valid: %v", err)
+		return
+	}
+	order, err := parseFakeOrder(query.Get("order"))
+	if err != nil {
+		s.sendError(w, r, http.StatusBadRequest, "Order isn't valid: %v", err)
+		return
+	}
+
+	// Select, sort and paginate the objects:
+	matches := []map[string]interface{}{}
+	for _, id := range collection.ids {
+		object := collection.objects[id]
+		if filter(object) {
+			matches = append(matches, object)
+		}
+	}
+	sortFakeObjects(matches, order)
+	start := (page - 1) * size
+	if start > len(matches) {
+		start = len(matches)
+	}
+	end := start + size
+	if end > len(matches) {
+		end = len(matches)
+	}
+	items := matches[start:end]
+	s.sendJSON(w, http.StatusOK, &fakeList{
+		Kind:  collection.kind + "List",
+		Page:  page,
+		Size:  len(items),
+		Total: len(matches),
+		Items: items,
+	})
+}
+
+func (s *FakeServer) post(w http.ResponseWriter, r *http.Request, collection *fakeCollection) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.sendError(w, r, http.StatusBadRequest, "Can't read body: %v", err)
+		return
+	}
+	object, err := s.create(collection, body, false)
+	if err != nil {
+		s.sendError(w, r, http.StatusBadRequest, "Body isn't valid: %v", err)
+		return
+	}
+	s.sendJSON(w, http.StatusCreated, object)
+}
+
+func (s *FakeServer) get(w http.ResponseWriter, r *http.Request, collection *fakeCollection,
+	id string) {
+	object, ok := collection.objects[id]
+	if !ok {
+		s.sendNotFound(w, r, collection, id)
+		return
+	}
+	s.sendJSON(w, http.StatusOK, object)
+}
+
+func (s *FakeServer) patch(w http.ResponseWriter, r *http.Request, collection *fakeCollection,
+	id string) {
+	original, ok := collection.objects[id]
+	if !ok {
+		s.sendNotFound(w, r, collection, id)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.sendError(w, r, http.StatusBadRequest, "Can't read body: %v", err)
+		return
+	}
+	object, err := s.update(collection, original, body)
+	if err != nil {
+		s.sendError(w, r, http.StatusBadRequest, "Body isn't valid: %v", err)
+		return
+	}
+	s.sendJSON(w, http.StatusOK, object)
+}
+
+func (s *FakeServer) delete(w http.ResponseWriter, r *http.Request, collection *fakeCollection,
+	id string) {
+	object, ok := collection.objects[id]
+	if !ok {
+		s.sendNotFound(w, r, collection, id)
+		return
+	}
+	if collection == s.clusters {
+		// Clusters aren't removed immediately, they go first to the uninstalling state:
+		if object["state"] != string(cmv1.ClusterStateUninstalling) {
+			s.changeClusterState(id, object, cmv1.ClusterStateUninstalling, time.Now())
+		}
+	} else {
+		s.remove(collection, id)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getLog sends the content of the install or uninstall log of a cluster.
+func (s *FakeServer) getLog(w http.ResponseWriter, r *http.Request, id, name string) {
+	if r.Method != http.MethodGet {
+		s.sendError(w, r, http.StatusMethodNotAllowed, "Method '%s' isn't allowed", r.Method)
+		return
+	}
+	if name != fakeInstallLog && name != fakeUninstallLog {
+		s.sendError(w, r, http.StatusNotFound, "Path '%s' doesn't exist", r.URL.Path)
+		return
+	}
+	_, ok := s.clusters.objects[id]
+	if !ok {
+		s.sendNotFound(w, r, s.clusters, id)
+		return
+	}
+	query := r.URL.Query()
+	offset := 0
+	tail := 0
+	var err error
+	if text := query.Get("offset"); text != "" {
+		offset, err = strconv.Atoi(text)
+		if err != nil || offset < 0 {
+			s.sendError(w, r, http.StatusBadRequest,
+				"Offset '%s' isn't valid, it should be greater or equal than zero", text)
+			return
+		}
+	}
+	if text := query.Get("tail"); text != "" {
+		tail, err = strconv.Atoi(text)
+		if err != nil || tail < 0 {
+			s.sendError(w, r, http.StatusBadRequest,
+				"Tail '%s' isn't valid, it should be greater or equal than zero", text)
+			return
+		}
+	}
+	if offset > 0 && tail > 0 {
+		s.sendError(w, r, http.StatusBadRequest,
+			"Parameters 'offset' and 'tail' can't be used together")
+		return
+	}
+	lines := s.clusterLogs[id][name]
+	switch {
+	case offset > len(lines):
+		lines = nil
+	case offset > 0:
+		lines = lines[offset:]
+	case tail > 0 && tail < len(lines):
+		lines = lines[len(lines)-tail:]
+	}
+	content := &strings.Builder{}
+	for _, line := range lines {
+		content.WriteString(line)
+		content.WriteString("\n")
+	}
+	s.sendJSON(w, http.StatusOK, map[string]interface{}{
+		"kind":    "Log",
+		"id":      name,
+		"href":    fmt.Sprintf("%s/%s/logs/%s", fakeClustersPath, id, name),
+		"content": content.String(),
+	})
+}
+
+// add adds an object to a collection, preserving the identifier and the protected fields if they
+// are set. This is intended for the methods that add objects directly, without requests.
+func (s *FakeServer) add(collection *fakeCollection, data []byte) (result []byte, err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	object, err := s.create(collection, data, true)
+	if err != nil {
+		return
+	}
+	result, err = json.Marshal(object)
+	return
+}
+
+// create creates a new object in the given collection from the given JSON document. If the seed
+// flag is true the identifier and the protected fields given in the document are preserved,
+// otherwise they are generated by the server.
+func (s *FakeServer) create(collection *fakeCollection, data []byte,
+	seed bool) (result map[string]interface{}, err error) {
+	object, err := decodeFakeObject(collection, data)
+	if err != nil {
+		return
+	}
+	id, _ := object["id"].(string)
+	if !seed || id == "" {
+		id, err = generateFakeID()
+		if err != nil {
+			return
+		}
+	}
+	if _, ok := collection.objects[id]; ok {
+		err = fmt.Errorf("%s '%s' already exists", strings.ToLower(collection.kind), id)
+		return
+	}
+	if !seed {
+		for _, field := range collection.protected {
+			delete(object, field)
+		}
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	object["kind"] = collection.kind
+	object["id"] = id
+	object["href"] = collection.path + "/" + id
+	switch collection {
+	case s.clusters:
+		if _, ok := object["state"]; !ok {
+			object["state"] = string(cmv1.ClusterStatePending)
+		}
+		if _, ok := object["creation_timestamp"]; !ok {
+			object["creation_timestamp"] = now
+		}
+	default:
+		if _, ok := object["created_at"]; !ok {
+			object["created_at"] = now
+		}
+		object["updated_at"] = now
+	}
+	result, err = renormalizeFakeObject(collection, object)
+	if err != nil {
+		return
+	}
+	collection.objects[id] = result
+	collection.ids = append(collection.ids, id)
+	if collection == s.clusters {
+		s.clusterChanges[id] = time.Now()
+	}
+	return
+}
+
+// update applies the given merge patch to an object. The identifier and the protected fields
+// can't be changed.
+func (s *FakeServer) update(collection *fakeCollection, original map[string]interface{},
+	patch []byte) (result map[string]interface{}, err error) {
+	current, err := json.Marshal(original)
+	if err != nil {
+		return
+	}
+	merged, err := jsonpatch.MergePatch(current, patch)
+	if err != nil {
+		return
+	}
+	object, err := decodeFakeObject(collection, merged)
+	if err != nil {
+		return
+	}
+	for _, field := range append([]string{"kind", "id", "href"}, collection.protected...) {
+		value, ok := original[field]
+		if ok {
+			object[field] = value
+		} else {
+			delete(object, field)
+		}
+	}
+	if collection != s.clusters {
+		object["updated_at"] = time.Now().UTC().Format(time.RFC3339)
+	}
+	result, err = renormalizeFakeObject(collection, object)
+	if err != nil {
+		return
+	}
+	collection.objects[original["id"].(string)] = result
+	return
+}
+
+// remove removes an object from a collection.
+func (s *FakeServer) remove(collection *fakeCollection, id string) {
+	delete(collection.objects, id)
+	for i, current := range collection.ids {
+		if current == id {
+			collection.ids = append(collection.ids[:i], collection.ids[i+1:]...)
+			break
+		}
+	}
+	if collection == s.clusters {
+		delete(s.clusterChanges, id)
+		delete(s.clusterLogs, id)
+	}
+}
+
+// refreshClusters moves to the next state the clusters that have been in the current state for
+// longer than the configured delay. Note that this is done when the server is used, not in a
+// background goroutine, so that the behaviour of the server is easier to predict.
+func (s *FakeServer) refreshClusters() {
+	if s.clusterStateDelay <= 0 {
+		return
+	}
+	now := time.Now()
+	for _, id := range append([]string(nil), s.clusters.ids...) {
+		for {
+			object, ok := s.clusters.objects[id]
+			if !ok {
+				break
+			}
+			changed := s.clusterChanges[id]
+			if now.Sub(changed) < s.clusterStateDelay {
+				break
+			}
+			_, ok = s.advanceCluster(id, object, changed.Add(s.clusterStateDelay))
+			if !ok {
+				break
+			}
+		}
+	}
+}
+
+// advanceCluster moves a cluster to the next state. The second result will be false if the
+// cluster is in a state that doesn't have a next state.
+func (s *FakeServer) advanceCluster(id string, object map[string]interface{},
+	now time.Time) (result cmv1.ClusterState, ok bool) {
+	ok = true
+	switch object["state"] {
+	case string(cmv1.ClusterStatePending):
+		result = cmv1.ClusterStateInstalling
+		s.changeClusterState(id, object, result, now)
+	case string(cmv1.ClusterStateInstalling):
+		result = cmv1.ClusterStateReady
+		s.changeClusterState(id, object, result, now)
+	case string(cmv1.ClusterStateUninstalling):
+		s.remove(s.clusters, id)
+	default:
+		ok = false
+	}
+	return
+}
+
+// changeClusterState changes the state of a cluster and records the time of the change.
+func (s *FakeServer) changeClusterState(id string, object map[string]interface{},
+	state cmv1.ClusterState, now time.Time) {
+	object["state"] = string(state)
+	s.clusterChanges[id] = now
+}
+
+// checkToken checks that the request contains a bearer token signed with the key used by the
+// MakeTokenString function, and that it hasn't expired.
+func (s *FakeServer) checkToken(r *http.Request) bool {
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return false
+	}
+	_, err := jwt.Parse(
+		strings.TrimPrefix(header, "Bearer "),
+		func(token *jwt.Token) (key interface{}, err error) {
+			key = jwtPublicKey
+			return
+		},
+		jwt.WithValidMethods([]string{"RS256"}),
+	)
+	return err == nil
+}
+
+func (s *FakeServer) sendJSON(w http.ResponseWriter, code int, body interface{}) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_, err = w.Write(data)
+	if err != nil {
+		fmt.Fprintf(GinkgoWriter, "Can't write response body: %v\n", err)
+	}
+}
+
+func (s *FakeServer) sendNotFound(w http.ResponseWriter, r *http.Request,
+	collection *fakeCollection, id string) {
+	s.sendError(w, r, http.StatusNotFound, "%s '%s' not found", collection.kind, id)
+}
+
+func (s *FakeServer) sendError(w http.ResponseWriter, r *http.Request, code int, format string,
+	args ...interface{}) {
+	service := fakeClustersMgmtPath
+	prefix := "CLUSTERS-MGMT"
+	if strings.HasPrefix(r.URL.Path, fakeAccountsMgmtPath) {
+		service = fakeAccountsMgmtPath
+		prefix = "ACCT-MGMT"
+	}
+	id := strconv.Itoa(code)
+	object, err := errors.NewError().
+		Status(code).
+		ID(id).
+		HREF(service + "/errors/" + id).
+		Code(prefix + "-" + id).
+		Reason(fmt.Sprintf(format, args...)).
+		Build()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	buffer := &bytes.Buffer{}
+	err = errors.MarshalError(object, buffer)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_, err = w.Write(buffer.Bytes())
+	if err != nil {
+		fmt.Fprintf(GinkgoWriter, "Can't write response body: %v\n", err)
+	}
+}
+
+// decodeFakeObject normalizes the given JSON document using the generated marshalling functions
+// and converts the result into a map.
+func decodeFakeObject(collection *fakeCollection, data []byte) (result map[string]interface{},
+	err error) {
+	normalized, err := collection.normalize(data)
+	if err != nil {
+		return
+	}
+	result = map[string]interface{}{}
+	err = json.Unmarshal(normalized, &result)
+	return
+}
+
+// renormalizeFakeObject passes again an object through the generated marshalling functions, so
+// that the fields added by the server get the same representation than the rest.
+func renormalizeFakeObject(collection *fakeCollection,
+	object map[string]interface{}) (result map[string]interface{}, err error) {
+	data, err := json.Marshal(object)
+	if err != nil {
+		return
+	}
+	result, err = decodeFakeObject(collection, data)
+	return
+}
+
+// generateFakeID generates a random identifier similar to the ones used by the real services.
+func generateFakeID() (result string, err error) {
+	data := make([]byte, 20)
+	_, err = rand.Read(data)
+	if err != nil {
+		return
+	}
+	result = strings.ToLower(base32.HexEncoding.WithPadding(base32.NoPadding).EncodeToString(data))
+	return
+}
+
+func normalizeFakeCluster(data []byte) (result []byte, err error) {
+	object, err := cmv1.UnmarshalCluster(data)
+	if err != nil {
+		return
+	}
+	buffer := &bytes.Buffer{}
+	err = cmv1.MarshalCluster(object, buffer)
+	result = buffer.Bytes()
+	return
+}
+
+func normalizeFakeAccount(data []byte) (result []byte, err error) {
+	object, err := amv1.UnmarshalAccount(data)
+	if err != nil {
+		return
+	}
+	buffer := &bytes.Buffer{}
+	err = amv1.MarshalAccount(object, buffer)
+	result = buffer.Bytes()
+	return
+}
+
+func normalizeFakeOrganization(data []byte) (result []byte, err error) {
+	object, err := amv1.UnmarshalOrganization(data)
+	if err != nil {
+		return
+	}
+	buffer := &bytes.Buffer{}
+	err = amv1.MarshalOrganization(object, buffer)
+	result = buffer.Bytes()
+	return
+}
+
+func normalizeFakeSubscription(data []byte) (result []byte, err error) {
+	object, err := amv1.UnmarshalSubscription(data)
+	if err != nil {
+		return
+	}
+	buffer := &bytes.Buffer{}
+	err = amv1.MarshalSubscription(object, buffer)
+	result = buffer.Bytes()
+	return
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package testing
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/tls"
@@ -97,6 +99,29 @@ func MakeTCPTLSServer() (server *ghttp.Server, ca string) {
 	return
 }
 
+// MakeTCPMTLSServer creates a test server like the MakeTCPTLSServer function, but that also requires
+// clients to present a TLS client certificate. The certificate isn't verified, so any certificate
+// is accepted, but it is available to handlers in the TLS field of the request. It is the
+// responsibility of the caller to delete the returned CA file when it is no longer needed.
+func MakeTCPMTLSServer() (server *ghttp.Server, ca string) {
+	// Create and configure the server:
+	server = ghttp.NewUnstartedServer()
+	server.Writer = GinkgoWriter
+	server.HTTPTestServer.Config.ErrorLog = log.New(GinkgoWriter, "", log.LstdFlags)
+	server.HTTPTestServer.EnableHTTP2 = true
+	server.HTTPTestServer.TLS = &tls.Config{
+		ClientAuth: tls.RequireAnyClientCert,
+	}
+	server.HTTPTestServer.StartTLS()
+
+	// Fetch the CA certificate. Note that we need to present a client certificate for this.
+	address, err := url.Parse(server.URL())
+	Expect(err).ToNot(HaveOccurred())
+	ca = fetchCACertificate("tcp", address.Host, LocalhostCertificate())
+
+	return
+}
+
 // MakeUnixTLSServer creates a test server that listens in a Unix socket and configured so that it
 // sends log messages to the Ginkgo writer. It returns the created server, the name of a temporary
 // file that contains the CA certificate that the client should trust in order to connect to the
@@ -187,10 +212,11 @@ func MakeUnixH2CServer() (server *ghttp.Server, socket string) {
 // the TLS handshake. It returns the path of a temporary file containing that CA certificate encoded
 // in PEM format. It is the responsibility of the caller to delete that file when it is no longer
 // needed.
-func fetchCACertificate(network, address string) string {
+func fetchCACertificate(network, address string, certificates ...tls.Certificate) string {
 	// Connect to the server and do the TLS handshake to obtain the certificate chain:
 	conn, err := tls.Dial(network, address, &tls.Config{
 		InsecureSkipVerify: true, // nolint
+		Certificates:       certificates,
 	})
 	Expect(err).ToNot(HaveOccurred())
 	defer func() {
@@ -329,5 +355,38 @@ func LocalhostCertificate() tls.Certificate {
 	return *localhostCertificate
 }
 
+// MakeClientCertificate generates a self signed TLS client certificate with the given common name,
+// and returns the certificate and the private key encoded in PEM format.
+func MakeClientCertificate(name string) (cert, key []byte) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).ToNot(HaveOccurred())
+	now := time.Now()
+	spec := x509.Certificate{
+		SerialNumber: big.NewInt(now.UnixNano()),
+		Subject: pkix.Name{
+			CommonName: name,
+		},
+		NotBefore: now.Add(-time.Minute),
+		NotAfter:  now.Add(24 * time.Hour),
+		KeyUsage:  x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{
+			x509.ExtKeyUsageClientAuth,
+		},
+	}
+	data, err := x509.CreateCertificate(rand.Reader, &spec, &spec, &privateKey.PublicKey, privateKey)
+	Expect(err).ToNot(HaveOccurred())
+	cert = pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: data,
+	})
+	keyData, err := x509.MarshalECPrivateKey(privateKey)
+	Expect(err).ToNot(HaveOccurred())
+	key = pem.EncodeToMemory(&pem.Block{
+		Type:  "EC PRIVATE KEY",
+		Bytes: keyData,
+	})
+	return
+}
+
 // localhostCertificate contains the TLS certificate returned by the LocalhostCertificate function.
 var localhostCertificate *tls.Certificate
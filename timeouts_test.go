@@ -0,0 +1,227 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the transport timeouts.
+
+package sdk
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"             // nolint
+	. "github.com/onsi/gomega"                         // nolint
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Timeouts", func() {
+	var ctx context.Context
+	var token string
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		token = MakeTokenString("Bearer", 5*time.Minute)
+	})
+
+	// slowHandler returns a handler that waits the given time before sending the response
+	// headers.
+	slowHandler := func(delay time.Duration) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(delay)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte("{}"))
+		}
+	}
+
+	It("Uses the defaults", func() {
+		connection, err := NewConnectionBuilder().
+			Logger(logger).
+			Tokens(token).
+			BuildContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		defer func() {
+			err = connection.Close()
+			Expect(err).ToNot(HaveOccurred())
+		}()
+		Expect(connection.DialTimeout()).To(Equal(DefaultDialTimeout))
+		Expect(connection.TLSHandshakeTimeout()).To(Equal(DefaultTLSHandshakeTimeout))
+		Expect(connection.ResponseHeaderTimeout()).To(BeZero())
+		Expect(connection.IdleConnTimeout()).To(Equal(DefaultIdleConnTimeout))
+		Expect(connection.HTTP2ReadIdleTimeout()).To(Equal(DefaultHTTP2ReadIdleTimeout))
+		Expect(connection.HTTP2PingTimeout()).To(Equal(DefaultHTTP2PingTimeout))
+	})
+
+	It("Loads the timeouts from the configuration", func() {
+		config := strings.Join([]string{
+			"dial_timeout: 1s",
+			"tls_handshake_timeout: 2s",
+			"response_header_timeout: 3s",
+			"idle_conn_timeout: 4m",
+			"http2_read_idle_timeout: 5s",
+			"http2_ping_timeout: 6s",
+		}, "\n")
+		connection, err := NewConnectionBuilder().
+			Logger(logger).
+			Tokens(token).
+			Load(strings.NewReader(config)).
+			BuildContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		defer func() {
+			err = connection.Close()
+			Expect(err).ToNot(HaveOccurred())
+		}()
+		Expect(connection.DialTimeout()).To(Equal(1 * time.Second))
+		Expect(connection.TLSHandshakeTimeout()).To(Equal(2 * time.Second))
+		Expect(connection.ResponseHeaderTimeout()).To(Equal(3 * time.Second))
+		Expect(connection.IdleConnTimeout()).To(Equal(4 * time.Minute))
+		Expect(connection.HTTP2ReadIdleTimeout()).To(Equal(5 * time.Second))
+		Expect(connection.HTTP2PingTimeout()).To(Equal(6 * time.Second))
+	})
+
+	It("Rejects negative timeouts", func() {
+		connection, err := NewConnectionBuilder().
+			Logger(logger).
+			Tokens(token).
+			DialTimeout(-1 * time.Second).
+			BuildContext(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(connection).To(BeNil())
+		Expect(err.Error()).To(ContainSubstring("dial timeout"))
+		Expect(err.Error()).To(ContainSubstring("-1s"))
+	})
+
+	It("Rejects zero HTTP/2 ping timeout", func() {
+		connection, err := NewConnectionBuilder().
+			Logger(logger).
+			Tokens(token).
+			HTTP2PingTimeout(0).
+			BuildContext(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(connection).To(BeNil())
+		Expect(err.Error()).To(ContainSubstring("HTTP/2 ping timeout"))
+	})
+
+	It("Fails if response headers take too long with TCP", func() {
+		apiServer := MakeTCPServer()
+		defer apiServer.Close()
+		apiServer.AppendHandlers(slowHandler(500 * time.Millisecond))
+
+		connection, err := NewConnectionBuilder().
+			Logger(logger).
+			URL(apiServer.URL()).
+			Tokens(token).
+			RetryLimit(0).
+			ResponseHeaderTimeout(50 * time.Millisecond).
+			BuildContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		defer func() {
+			err = connection.Close()
+			Expect(err).ToNot(HaveOccurred())
+		}()
+
+		_, err = connection.Get().
+			Path("/api/clusters_mgmt/v1").
+			SendContext(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("timeout awaiting response headers"))
+	})
+
+	It("Fails if response headers take too long with Unix sockets", func() {
+		apiServer, apiSocket := MakeUnixServer()
+		defer apiServer.Close()
+		apiServer.AppendHandlers(slowHandler(500 * time.Millisecond))
+
+		connection, err := NewConnectionBuilder().
+			Logger(logger).
+			URL("unix://127.0.0.1" + apiSocket).
+			Tokens(token).
+			RetryLimit(0).
+			ResponseHeaderTimeout(50 * time.Millisecond).
+			BuildContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		defer func() {
+			err = connection.Close()
+			Expect(err).ToNot(HaveOccurred())
+		}()
+
+		_, err = connection.Get().
+			Path("/api/clusters_mgmt/v1").
+			SendContext(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("timeout awaiting response headers"))
+	})
+
+	It("Fails if response headers take too long with h2c", func() {
+		apiServer := MakeTCPH2CServer()
+		defer apiServer.Close()
+		apiServer.AppendHandlers(slowHandler(500 * time.Millisecond))
+		apiURL, err := url.Parse(apiServer.URL())
+		Expect(err).ToNot(HaveOccurred())
+		apiURL.Scheme = "h2c"
+
+		connection, err := NewConnectionBuilder().
+			Logger(logger).
+			URL(apiURL.String()).
+			Tokens(token).
+			RetryLimit(0).
+			ResponseHeaderTimeout(50 * time.Millisecond).
+			BuildContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		defer func() {
+			err = connection.Close()
+			Expect(err).ToNot(HaveOccurred())
+		}()
+
+		_, err = connection.Get().
+			Path("/api/clusters_mgmt/v1").
+			SendContext(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("timeout awaiting response headers"))
+	})
+
+	It("Doesn't fail if response headers arrive in time with h2c", func() {
+		apiServer := MakeTCPH2CServer()
+		defer apiServer.Close()
+		apiServer.AppendHandlers(slowHandler(10 * time.Millisecond))
+		apiURL, err := url.Parse(apiServer.URL())
+		Expect(err).ToNot(HaveOccurred())
+		apiURL.Scheme = "h2c"
+
+		connection, err := NewConnectionBuilder().
+			Logger(logger).
+			URL(apiURL.String()).
+			Tokens(token).
+			RetryLimit(0).
+			ResponseHeaderTimeout(5 * time.Second).
+			BuildContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		defer func() {
+			err = connection.Close()
+			Expect(err).ToNot(HaveOccurred())
+		}()
+
+		response, err := connection.Get().
+			Path("/api/clusters_mgmt/v1").
+			SendContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Status()).To(Equal(http.StatusOK))
+		Expect(response.String()).To(Equal("{}"))
+	})
+})
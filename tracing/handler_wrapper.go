@@ -0,0 +1,190 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the implementation of a handler wrapper that generates OpenTelemetry server
+// spans.
+
+package tracing
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// HandlerWrapperBuilder contains the data and logic needed to build a new tracing handler wrapper
+// that creates HTTP handlers that generate OpenTelemetry server spans.
+//
+// The trace context is extracted from the W3C trace context headers of the request, so the spans
+// will be children of the client spans when the client also uses tracing.
+//
+// The span names are calculated like in the transport wrapper, for example
+// `GET /api/clusters_mgmt/v1/clusters/-`.
+//
+// When used together with the authentication handler it is usually convenient to wrap the
+// authentication handler, so that the requests rejected by it are also traced:
+//
+//	authnHandler, err := authentication.NewHandler().
+//		Logger(logger).
+//		KeysURL("https://sso.redhat.com/.../certs").
+//		Next(apiHandler).
+//		Build()
+//	if err != nil {
+//		...
+//	}
+//	tracingWrapper, err := tracing.NewHandlerWrapper().Build()
+//	if err != nil {
+//		...
+//	}
+//	handler := tracingWrapper.Wrap(authnHandler)
+//
+// Don't create objects of this type directly; use the NewHandlerWrapper function instead.
+type HandlerWrapperBuilder struct {
+	tracerProvider trace.TracerProvider
+	propagator     propagation.TextMapPropagator
+}
+
+// HandlerWrapper contains the data and logic needed to wrap an HTTP handler with another one that
+// generates OpenTelemetry spans.
+type HandlerWrapper struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+// handler is an HTTP handler that generates OpenTelemetry spans.
+type handler struct {
+	owner   *HandlerWrapper
+	handler http.Handler
+}
+
+// Make sure that we implement the interface:
+var _ http.Handler = (*handler)(nil)
+
+// responseWriter is the HTTP response writer used to obtain the response code.
+type responseWriter struct {
+	code   int
+	writer http.ResponseWriter
+}
+
+// Make sure that we implement the interface:
+var _ http.ResponseWriter = (*responseWriter)(nil)
+
+// NewHandlerWrapper creates a new builder that can then be used to configure and create a new
+// tracing handler wrapper.
+func NewHandlerWrapper() *HandlerWrapperBuilder {
+	return &HandlerWrapperBuilder{}
+}
+
+// TracerProvider sets the OpenTelemetry tracer provider that will be used to create the spans. The
+// default is to use the global tracer provider.
+func (b *HandlerWrapperBuilder) TracerProvider(value trace.TracerProvider) *HandlerWrapperBuilder {
+	b.tracerProvider = value
+	return b
+}
+
+// Propagator sets the propagator that will be used to extract the trace context from the request
+// headers. The default is to use the W3C trace context propagator.
+func (b *HandlerWrapperBuilder) Propagator(
+	value propagation.TextMapPropagator) *HandlerWrapperBuilder {
+	b.propagator = value
+	return b
+}
+
+// Build uses the information stored in the builder to create a new handler wrapper.
+func (b *HandlerWrapperBuilder) Build() (result *HandlerWrapper, err error) {
+	// Set the defaults:
+	tracerProvider := b.tracerProvider
+	if tracerProvider == nil {
+		tracerProvider = otel.GetTracerProvider()
+	}
+	propagator := b.propagator
+	if propagator == nil {
+		propagator = propagation.TraceContext{}
+	}
+
+	// Create and populate the object:
+	result = &HandlerWrapper{
+		tracer:     tracerProvider.Tracer(instrumentationName),
+		propagator: propagator,
+	}
+
+	return
+}
+
+// Wrap creates a new handler that wraps the given one and generates the spans.
+func (w *HandlerWrapper) Wrap(h http.Handler) http.Handler {
+	return &handler{
+		owner:   w,
+		handler: h,
+	}
+}
+
+// ServeHTTP is the implementation of the HTTP handler interface.
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Extract the trace context from the request headers and start the span:
+	ctx := h.owner.propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	ctx, span := h.owner.tracer.Start(
+		ctx,
+		spanName(r.Method, r.URL.Path),
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(requestAttributes(r)...),
+	)
+	defer span.End()
+
+	// We need to replace the response writer with a custom one that captures the response code
+	// generated by the next handler:
+	writer := responseWriter{
+		code:   http.StatusOK,
+		writer: w,
+	}
+	h.handler.ServeHTTP(&writer, r.WithContext(ctx))
+
+	// Record the result. Note that for servers only the 5xx codes are errors, as the 4xx codes
+	// are errors of the client.
+	span.SetAttributes(semconv.HTTPResponseStatusCode(writer.code))
+	if writer.code >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(writer.code))
+	}
+}
+
+// Header is part of the implementation of the http.ResponseWriter interface.
+func (w *responseWriter) Header() http.Header {
+	return w.writer.Header()
+}
+
+// Write is part of the implementation of the http.ResponseWriter interface.
+func (w *responseWriter) Write(b []byte) (n int, err error) {
+	n, err = w.writer.Write(b)
+	return
+}
+
+// WriteHeader is part of the implementation of the http.ResponseWriter interface.
+func (w *responseWriter) WriteHeader(code int) {
+	w.code = code
+	w.writer.WriteHeader(code)
+}
+
+// Flush is the implementation of the http.Flusher interface.
+func (w *responseWriter) Flush() {
+	flusher, ok := w.writer.(http.Flusher)
+	if ok {
+		flusher.Flush()
+	}
+}
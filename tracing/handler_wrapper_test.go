@@ -0,0 +1,163 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the tracing handler wrapper.
+
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core" // nolint
+	. "github.com/onsi/gomega"             // nolint
+
+	"github.com/openshift-online/ocm-sdk-go/authentication"
+	"github.com/openshift-online/ocm-sdk-go/logging"
+)
+
+var _ = Describe("Handler wrapper", func() {
+	var ctx context.Context
+	var exporter *tracetest.InMemoryExporter
+	var provider *sdktrace.TracerProvider
+	var wrapper *HandlerWrapper
+
+	BeforeEach(func() {
+		var err error
+		ctx = context.Background()
+		exporter = tracetest.NewInMemoryExporter()
+		provider = sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+		wrapper, err = NewHandlerWrapper().
+			TracerProvider(provider).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		err := provider.Shutdown(ctx)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("Creates a server span", func() {
+		handler := wrapper.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+		}))
+		request := httptest.NewRequest(http.MethodPost, "/api/clusters_mgmt/v1/clusters", nil)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		Expect(recorder.Code).To(Equal(http.StatusCreated))
+		spans := exporter.GetSpans()
+		Expect(spans).To(HaveLen(1))
+		span := spans[0]
+		Expect(span.Name).To(Equal("POST /api/clusters_mgmt/v1/clusters"))
+		Expect(span.SpanKind).To(Equal(trace.SpanKindServer))
+		Expect(span.Attributes).To(ContainElement(
+			semconv.HTTPResponseStatusCode(http.StatusCreated),
+		))
+		Expect(span.Status.Code).To(Equal(codes.Unset))
+	})
+
+	It("Continues the trace of the client", func() {
+		// Create a client span and send the request with the trace context headers:
+		var serverCtx context.Context
+		handler := wrapper.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			serverCtx = r.Context()
+		}))
+		transport, err := NewTransportWrapper().
+			TracerProvider(provider).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		server := httptest.NewServer(handler)
+		defer server.Close()
+		client := &http.Client{
+			Transport: transport.Wrap(http.DefaultTransport),
+		}
+		response, err := client.Get(server.URL + "/api/clusters_mgmt/v1/clusters/123")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Body.Close()).To(Succeed())
+
+		// Check that the server span is a child of the client span:
+		spans := exporter.GetSpans()
+		Expect(spans).To(HaveLen(2))
+		serverSpan := spans[0]
+		clientSpan := spans[1]
+		Expect(serverSpan.SpanKind).To(Equal(trace.SpanKindServer))
+		Expect(clientSpan.SpanKind).To(Equal(trace.SpanKindClient))
+		Expect(serverSpan.Parent.SpanID()).To(Equal(clientSpan.SpanContext.SpanID()))
+		Expect(serverSpan.Parent.IsRemote()).To(BeTrue())
+
+		// Check that the span is available to the wrapped handler:
+		Expect(trace.SpanContextFromContext(serverCtx).SpanID()).To(
+			Equal(serverSpan.SpanContext.SpanID()),
+		)
+	})
+
+	It("Sets error status only for server errors", func() {
+		code := http.StatusBadRequest
+		handler := wrapper.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(code)
+		}))
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api", nil))
+		code = http.StatusInternalServerError
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api", nil))
+		spans := exporter.GetSpans()
+		Expect(spans).To(HaveLen(2))
+		Expect(spans[0].Status.Code).To(Equal(codes.Unset))
+		Expect(spans[1].Status.Code).To(Equal(codes.Error))
+	})
+
+	It("Traces requests rejected by the authentication handler", func() {
+		// Create the authentication handler:
+		logger, err := logging.NewStdLoggerBuilder().
+			Streams(GinkgoWriter, GinkgoWriter).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		keysFile := filepath.Join(GinkgoT().TempDir(), "keys.json")
+		err = os.WriteFile(keysFile, []byte(`{"keys":[]}`), 0600)
+		Expect(err).ToNot(HaveOccurred())
+		authnHandler, err := authentication.NewHandler().
+			Logger(logger).
+			KeysFile(keysFile).
+			Next(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+
+		// Send a request without a token:
+		handler := wrapper.Wrap(authnHandler)
+		request := httptest.NewRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters", nil)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+
+		// Check the span:
+		spans := exporter.GetSpans()
+		Expect(spans).To(HaveLen(1))
+		Expect(spans[0].Attributes).To(ContainElement(
+			semconv.HTTPResponseStatusCode(http.StatusUnauthorized),
+		))
+	})
+})
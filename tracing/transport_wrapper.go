@@ -0,0 +1,302 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the implementation of a transport wrapper that generates OpenTelemetry
+// client spans.
+
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/openshift-online/ocm-sdk-go/metrics"
+)
+
+// TransportWrapperBuilder contains the data and logic needed to build a new tracing transport
+// wrapper that creates HTTP clients that generate OpenTelemetry spans.
+//
+// Each request sent generates a client span. The name of the span is the HTTP method followed by
+// the template of the path, for example `GET /api/clusters_mgmt/v1/clusters/-`. The identifiers
+// of the objects are replaced by dashes, like in the `path` label of the metrics, so that the
+// number of different span names is small.
+//
+// The W3C trace context headers are added to the request, so that the server can continue the
+// trace.
+//
+// The span will contain an `attempt` event for each attempt to send the request, including the
+// retries, and a `token_request` event for each request sent to the token server to obtain the
+// access token.
+//
+// Don't create objects of this type directly; use the NewTransportWrapper function instead.
+type TransportWrapperBuilder struct {
+	tracerProvider trace.TracerProvider
+	propagator     propagation.TextMapPropagator
+}
+
+// TransportWrapper contains the data and logic needed to wrap an HTTP round tripper with another
+// one that generates OpenTelemetry spans.
+type TransportWrapper struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+// roundTripper is a round tripper that generates OpenTelemetry spans.
+type roundTripper struct {
+	owner     *TransportWrapper
+	transport http.RoundTripper
+}
+
+// attemptRoundTripper is a round tripper that adds attempt events to the span of the request.
+type attemptRoundTripper struct {
+	transport http.RoundTripper
+}
+
+// tokenRoundTripper is a round tripper that adds token request events to the span of the request.
+type tokenRoundTripper struct {
+	transport http.RoundTripper
+}
+
+// Make sure that we implement the interface:
+var _ http.RoundTripper = (*roundTripper)(nil)
+var _ http.RoundTripper = (*attemptRoundTripper)(nil)
+var _ http.RoundTripper = (*tokenRoundTripper)(nil)
+
+// attemptCounterKey is the key used to store the attempt counter in the context of the request.
+type attemptCounterKey struct{}
+
+// NewTransportWrapper creates a new builder that can then be used to configure and create a new
+// tracing round tripper.
+func NewTransportWrapper() *TransportWrapperBuilder {
+	return &TransportWrapperBuilder{}
+}
+
+// TracerProvider sets the OpenTelemetry tracer provider that will be used to create the spans. The
+// default is to use the global tracer provider.
+func (b *TransportWrapperBuilder) TracerProvider(value trace.TracerProvider) *TransportWrapperBuilder {
+	b.tracerProvider = value
+	return b
+}
+
+// Propagator sets the propagator that will be used to add the trace context to the request headers.
+// The default is to use the W3C trace context propagator.
+func (b *TransportWrapperBuilder) Propagator(
+	value propagation.TextMapPropagator) *TransportWrapperBuilder {
+	b.propagator = value
+	return b
+}
+
+// Build uses the information stored in the builder to create a new transport wrapper.
+func (b *TransportWrapperBuilder) Build() (result *TransportWrapper, err error) {
+	// Set the defaults:
+	tracerProvider := b.tracerProvider
+	if tracerProvider == nil {
+		tracerProvider = otel.GetTracerProvider()
+	}
+	propagator := b.propagator
+	if propagator == nil {
+		propagator = propagation.TraceContext{}
+	}
+
+	// Create and populate the object:
+	result = &TransportWrapper{
+		tracer:     tracerProvider.Tracer(instrumentationName),
+		propagator: propagator,
+	}
+
+	return
+}
+
+// Wrap creates a round tripper on top of the given one that generates the spans.
+func (w *TransportWrapper) Wrap(transport http.RoundTripper) http.RoundTripper {
+	return &roundTripper{
+		owner:     w,
+		transport: transport,
+	}
+}
+
+// WrapAttempt creates a round tripper on top of the given one that adds an `attempt` event to the
+// span of the request each time that it is called. It is intended for use inside the retry
+// wrapper, so that each retry is recorded.
+func (w *TransportWrapper) WrapAttempt(transport http.RoundTripper) http.RoundTripper {
+	return &attemptRoundTripper{
+		transport: transport,
+	}
+}
+
+// WrapToken creates a round tripper on top of the given one that adds a `token_request` event to
+// the span of the request. It is intended for the transport used to send requests to the token
+// server, as those requests use the context of the API request that needs the token.
+func (w *TransportWrapper) WrapToken(transport http.RoundTripper) http.RoundTripper {
+	return &tokenRoundTripper{
+		transport: transport,
+	}
+}
+
+// RoundTrip is the implementation of the round tripper interface.
+func (t *roundTripper) RoundTrip(request *http.Request) (response *http.Response, err error) {
+	// Start the span:
+	ctx, span := t.owner.tracer.Start(
+		request.Context(),
+		spanName(request.Method, request.URL.Path),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(requestAttributes(request)...),
+	)
+	defer span.End()
+
+	// Round trippers shouldn't modify the original request, so we need a copy that contains the
+	// new context and the trace headers:
+	ctx = context.WithValue(ctx, attemptCounterKey{}, new(int32))
+	request = request.Clone(ctx)
+	t.owner.propagator.Inject(ctx, propagation.HeaderCarrier(request.Header))
+
+	// Call the wrapped transport:
+	response, err = t.transport.RoundTrip(request)
+
+	// Record the result:
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return
+	}
+	span.SetAttributes(semconv.HTTPResponseStatusCode(response.StatusCode))
+	if response.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, http.StatusText(response.StatusCode))
+	}
+
+	return
+}
+
+// RoundTrip is the implementation of the round tripper interface.
+func (t *attemptRoundTripper) RoundTrip(request *http.Request) (response *http.Response,
+	err error) {
+	// Calculate the attempt number:
+	ctx := request.Context()
+	attempt := 1
+	counter, ok := ctx.Value(attemptCounterKey{}).(*int32)
+	if ok {
+		attempt = int(atomic.AddInt32(counter, 1))
+	}
+
+	// Call the wrapped transport:
+	start := time.Now()
+	response, err = t.transport.RoundTrip(request)
+	elapsed := time.Since(start)
+
+	// Add the event:
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+	attributes := []attribute.KeyValue{
+		attemptAttributeKey.Int(attempt),
+		durationAttributeKey.Float64(elapsed.Seconds()),
+	}
+	attributes = append(attributes, resultAttributes(response, err)...)
+	span.AddEvent(attemptEventName, trace.WithAttributes(attributes...))
+	if attempt > 1 {
+		span.SetAttributes(semconv.HTTPRequestResendCount(attempt - 1))
+	}
+
+	return
+}
+
+// RoundTrip is the implementation of the round tripper interface.
+func (t *tokenRoundTripper) RoundTrip(request *http.Request) (response *http.Response, err error) {
+	// Call the wrapped transport:
+	start := time.Now()
+	response, err = t.transport.RoundTrip(request)
+	elapsed := time.Since(start)
+
+	// Add the event:
+	span := trace.SpanFromContext(request.Context())
+	if !span.IsRecording() {
+		return
+	}
+	attributes := []attribute.KeyValue{
+		durationAttributeKey.Float64(elapsed.Seconds()),
+	}
+	attributes = append(attributes, resultAttributes(response, err)...)
+	span.AddEvent(tokenEventName, trace.WithAttributes(attributes...))
+
+	return
+}
+
+// spanName calculates the name of the span from the HTTP method and the URL path.
+func spanName(method, path string) string {
+	return method + " " + metrics.PathTemplate(path)
+}
+
+// requestAttributes calculates the span attributes that describe the given request.
+func requestAttributes(request *http.Request) []attribute.KeyValue {
+	result := []attribute.KeyValue{
+		semconv.HTTPRequestMethodKey.String(request.Method),
+		semconv.URLFull(request.URL.Redacted()),
+		semconv.HTTPRoute(metrics.PathTemplate(request.URL.Path)),
+	}
+	host := request.URL.Hostname()
+	if host != "" {
+		result = append(result, semconv.ServerAddress(host))
+	}
+	port, err := strconv.Atoi(request.URL.Port())
+	if err == nil {
+		result = append(result, semconv.ServerPort(port))
+	}
+	service := metrics.ServiceName(request.URL.Path)
+	if service != "" {
+		result = append(result, serviceAttributeKey.String(service))
+	}
+	return result
+}
+
+// resultAttributes calculates the event attributes that describe the result of sending a request.
+func resultAttributes(response *http.Response, err error) []attribute.KeyValue {
+	if err != nil {
+		return []attribute.KeyValue{
+			errorAttributeKey.String(err.Error()),
+		}
+	}
+	return []attribute.KeyValue{
+		semconv.HTTPResponseStatusCode(response.StatusCode),
+	}
+}
+
+// Name of the instrumentation library, used to create the tracers:
+const instrumentationName = "github.com/openshift-online/ocm-sdk-go/tracing"
+
+// Names of the events added to spans:
+const (
+	attemptEventName = "attempt"
+	tokenEventName   = "token_request"
+)
+
+// Names of the attributes added to spans and events that aren't part of the semantic conventions:
+const (
+	attemptAttributeKey  = attribute.Key("ocm.attempt")
+	durationAttributeKey = attribute.Key("ocm.duration")
+	errorAttributeKey    = attribute.Key("ocm.error")
+	serviceAttributeKey  = attribute.Key("ocm.apiservice")
+)
@@ -0,0 +1,231 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the tracing transport wrapper.
+
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"             // nolint
+	. "github.com/onsi/gomega"                         // nolint
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Transport wrapper", func() {
+	var ctx context.Context
+	var exporter *tracetest.InMemoryExporter
+	var provider *sdktrace.TracerProvider
+	var wrapper *TransportWrapper
+
+	BeforeEach(func() {
+		var err error
+		ctx = context.Background()
+		exporter = tracetest.NewInMemoryExporter()
+		provider = sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+		wrapper, err = NewTransportWrapper().
+			TracerProvider(provider).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		err := provider.Shutdown(ctx)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	// send sends a GET request for the given path using the given transport:
+	send := func(ctx context.Context, transport http.RoundTripper, path string) (*http.Response,
+		error) {
+		request, err := http.NewRequestWithContext(
+			ctx,
+			http.MethodGet,
+			"https://api.example.com:8443"+path,
+			nil,
+		)
+		Expect(err).ToNot(HaveOccurred())
+		return transport.RoundTrip(request)
+	}
+
+	It("Uses the path template as span name", func() {
+		transport := wrapper.Wrap(JSONTransport(http.StatusOK, `{}`))
+		_, err := send(ctx, transport, "/api/clusters_mgmt/v1/clusters/123")
+		Expect(err).ToNot(HaveOccurred())
+		spans := exporter.GetSpans()
+		Expect(spans).To(HaveLen(1))
+		span := spans[0]
+		Expect(span.Name).To(Equal("GET /api/clusters_mgmt/v1/clusters/-"))
+		Expect(span.SpanKind).To(Equal(trace.SpanKindClient))
+		Expect(span.Attributes).To(ContainElements(
+			semconv.HTTPRequestMethodKey.String(http.MethodGet),
+			semconv.HTTPRoute("/api/clusters_mgmt/v1/clusters/-"),
+			semconv.ServerAddress("api.example.com"),
+			semconv.ServerPort(8443),
+			semconv.HTTPResponseStatusCode(http.StatusOK),
+			serviceAttributeKey.String("ocm-clusters-service"),
+		))
+		Expect(span.Status.Code).To(Equal(codes.Unset))
+	})
+
+	It("Injects the trace context headers", func() {
+		var header string
+		transport := wrapper.Wrap(TransportFunc(
+			func(request *http.Request) (*http.Response, error) {
+				header = request.Header.Get("traceparent")
+				return JSONTransport(http.StatusOK, `{}`).RoundTrip(request)
+			},
+		))
+		_, err := send(ctx, transport, "/api/clusters_mgmt/v1/clusters")
+		Expect(err).ToNot(HaveOccurred())
+		spans := exporter.GetSpans()
+		Expect(spans).To(HaveLen(1))
+		Expect(header).To(ContainSubstring(spans[0].SpanContext.TraceID().String()))
+		Expect(header).To(ContainSubstring(spans[0].SpanContext.SpanID().String()))
+	})
+
+	It("Doesn't modify the original request", func() {
+		transport := wrapper.Wrap(JSONTransport(http.StatusOK, `{}`))
+		request, err := http.NewRequestWithContext(
+			ctx,
+			http.MethodGet,
+			"https://api.example.com/api/clusters_mgmt/v1/clusters",
+			nil,
+		)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = transport.RoundTrip(request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(request.Header.Get("traceparent")).To(BeEmpty())
+	})
+
+	It("Uses the span of the context as parent", func() {
+		parentCtx, parent := provider.Tracer("test").Start(ctx, "parent")
+		transport := wrapper.Wrap(JSONTransport(http.StatusOK, `{}`))
+		_, err := send(parentCtx, transport, "/api/clusters_mgmt/v1/clusters")
+		Expect(err).ToNot(HaveOccurred())
+		parent.End()
+		spans := exporter.GetSpans()
+		Expect(spans).To(HaveLen(2))
+		Expect(spans[0].Parent.SpanID()).To(Equal(parent.SpanContext().SpanID()))
+	})
+
+	It("Sets error status for error responses", func() {
+		transport := wrapper.Wrap(JSONTransport(http.StatusNotFound, `{}`))
+		_, err := send(ctx, transport, "/api/clusters_mgmt/v1/clusters/123")
+		Expect(err).ToNot(HaveOccurred())
+		spans := exporter.GetSpans()
+		Expect(spans).To(HaveLen(1))
+		Expect(spans[0].Status.Code).To(Equal(codes.Error))
+		Expect(spans[0].Attributes).To(ContainElement(
+			semconv.HTTPResponseStatusCode(http.StatusNotFound),
+		))
+	})
+
+	It("Records transport errors", func() {
+		transport := wrapper.Wrap(ErrorTransport(errors.New("my error")))
+		_, err := send(ctx, transport, "/api/clusters_mgmt/v1/clusters")
+		Expect(err).To(HaveOccurred())
+		spans := exporter.GetSpans()
+		Expect(spans).To(HaveLen(1))
+		Expect(spans[0].Status.Code).To(Equal(codes.Error))
+		Expect(spans[0].Status.Description).To(Equal("my error"))
+		Expect(spans[0].Events).To(HaveLen(1))
+		Expect(spans[0].Events[0].Name).To(Equal("exception"))
+	})
+
+	It("Adds an event for each attempt", func() {
+		attempts := wrapper.WrapAttempt(CombineTransports(
+			JSONTransport(http.StatusServiceUnavailable, `{}`),
+			JSONTransport(http.StatusOK, `{}`),
+		))
+		transport := wrapper.Wrap(TransportFunc(
+			func(request *http.Request) (response *http.Response, err error) {
+				for i := 0; i < 2; i++ {
+					response, err = attempts.RoundTrip(request)
+				}
+				return
+			},
+		))
+		_, err := send(ctx, transport, "/api/clusters_mgmt/v1/clusters")
+		Expect(err).ToNot(HaveOccurred())
+		spans := exporter.GetSpans()
+		Expect(spans).To(HaveLen(1))
+		events := spans[0].Events
+		Expect(events).To(HaveLen(2))
+		Expect(events[0].Name).To(Equal("attempt"))
+		Expect(events[0].Attributes).To(ContainElements(
+			attemptAttributeKey.Int(1),
+			semconv.HTTPResponseStatusCode(http.StatusServiceUnavailable),
+		))
+		Expect(events[1].Name).To(Equal("attempt"))
+		Expect(events[1].Attributes).To(ContainElements(
+			attemptAttributeKey.Int(2),
+			semconv.HTTPResponseStatusCode(http.StatusOK),
+		))
+		Expect(spans[0].Attributes).To(ContainElement(semconv.HTTPRequestResendCount(1)))
+	})
+
+	It("Adds an event for token requests", func() {
+		token := wrapper.WrapToken(JSONTransport(http.StatusOK, `{}`))
+		transport := wrapper.Wrap(TransportFunc(
+			func(request *http.Request) (*http.Response, error) {
+				// Token requests use the context of the original request:
+				tokenRequest, err := http.NewRequestWithContext(
+					request.Context(),
+					http.MethodPost,
+					"https://sso.example.com/token",
+					nil,
+				)
+				Expect(err).ToNot(HaveOccurred())
+				_, err = token.RoundTrip(tokenRequest)
+				Expect(err).ToNot(HaveOccurred())
+				return JSONTransport(http.StatusOK, `{}`).RoundTrip(request)
+			},
+		))
+		_, err := send(ctx, transport, "/api/clusters_mgmt/v1/clusters")
+		Expect(err).ToNot(HaveOccurred())
+		spans := exporter.GetSpans()
+		Expect(spans).To(HaveLen(1))
+		events := spans[0].Events
+		Expect(events).To(HaveLen(1))
+		Expect(events[0].Name).To(Equal("token_request"))
+		Expect(events[0].Attributes).To(ContainElement(
+			semconv.HTTPResponseStatusCode(http.StatusOK),
+		))
+		var keys []attribute.Key
+		for _, attribute := range events[0].Attributes {
+			keys = append(keys, attribute.Key)
+		}
+		Expect(keys).To(ContainElement(durationAttributeKey))
+	})
+
+	It("Doesn't add events without a span", func() {
+		token := wrapper.WrapToken(JSONTransport(http.StatusOK, `{}`))
+		response, err := send(ctx, token, "/api/clusters_mgmt/v1/clusters")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(http.StatusOK))
+		Expect(exporter.GetSpans()).To(BeEmpty())
+	})
+})
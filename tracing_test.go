@@ -0,0 +1,148 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for tracing.
+
+package sdk
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/onsi/gomega/ghttp"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"             // nolint
+	. "github.com/onsi/gomega"                         // nolint
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Tracing", func() {
+	var ctx context.Context
+	var exporter *tracetest.InMemoryExporter
+	var provider *sdktrace.TracerProvider
+	var oidServer *ghttp.Server
+	var apiServer *ghttp.Server
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		exporter = tracetest.NewInMemoryExporter()
+		provider = sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+		oidServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+	})
+
+	AfterEach(func() {
+		oidServer.Close()
+		apiServer.Close()
+		err := provider.Shutdown(ctx)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("Generates spans with token and attempt events", func() {
+		// Prepare the token server:
+		accessToken := MakeTokenString("Bearer", 5*time.Minute)
+		refreshToken := MakeTokenString("Refresh", 10*time.Hour)
+		oidServer.AppendHandlers(
+			RespondWithAccessAndRefreshTokens(accessToken, refreshToken),
+		)
+
+		// Prepare the API server so that the first attempt fails and the second one succeeds.
+		// It also saves the trace context header.
+		var header string
+		apiServer.AppendHandlers(
+			RespondWithJSON(http.StatusServiceUnavailable, "{}"),
+			ghttp.CombineHandlers(
+				func(w http.ResponseWriter, r *http.Request) {
+					header = r.Header.Get("traceparent")
+				},
+				RespondWithJSON(http.StatusOK, "{}"),
+			),
+		)
+
+		// Create the connection:
+		connection, err := NewConnectionBuilder().
+			Logger(logger).
+			URL(apiServer.URL()).
+			TokenURL(oidServer.URL()).
+			Tokens(refreshToken).
+			RetryInterval(10 * time.Millisecond).
+			TracerProvider(provider).
+			BuildContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		defer func() {
+			err = connection.Close()
+			Expect(err).ToNot(HaveOccurred())
+		}()
+		Expect(connection.TracerProvider()).To(BeIdenticalTo(provider))
+
+		// Send the request:
+		response, err := connection.Get().
+			Path("/api/clusters_mgmt/v1/clusters/123").
+			SendContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Status()).To(Equal(http.StatusOK))
+
+		// Check the span:
+		spans := exporter.GetSpans()
+		Expect(spans).To(HaveLen(1))
+		span := spans[0]
+		Expect(span.Name).To(Equal("GET /api/clusters_mgmt/v1/clusters/-"))
+		Expect(span.SpanKind).To(Equal(trace.SpanKindClient))
+		Expect(header).To(ContainSubstring(span.SpanContext.TraceID().String()))
+		var names []string
+		for _, event := range span.Events {
+			names = append(names, event.Name)
+		}
+		Expect(names).To(Equal([]string{
+			"token_request",
+			"attempt",
+			"attempt",
+		}))
+	})
+
+	It("Is disabled by default", func() {
+		token := MakeTokenString("Bearer", 5*time.Minute)
+		var header string
+		apiServer.AppendHandlers(
+			ghttp.CombineHandlers(
+				func(w http.ResponseWriter, r *http.Request) {
+					header = r.Header.Get("traceparent")
+				},
+				RespondWithJSON(http.StatusOK, "{}"),
+			),
+		)
+		connection, err := NewConnectionBuilder().
+			Logger(logger).
+			URL(apiServer.URL()).
+			Tokens(token).
+			BuildContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		defer func() {
+			err = connection.Close()
+			Expect(err).ToNot(HaveOccurred())
+		}()
+		Expect(connection.TracerProvider()).To(BeNil())
+		_, err = connection.Get().
+			Path("/api/clusters_mgmt/v1/clusters").
+			SendContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(header).To(BeEmpty())
+	})
+})